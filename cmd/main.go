@@ -2,38 +2,44 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/gin-demo/recipes-web/internal/audit"
+	"github.com/gin-demo/recipes-web/internal/blob"
 	"github.com/gin-demo/recipes-web/internal/bootstrap"
 	"github.com/gin-demo/recipes-web/internal/cache/redisrecipe"
+	"github.com/gin-demo/recipes-web/internal/config"
 	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/controller/shopping"
 	"github.com/gin-demo/recipes-web/internal/domain"
 	"github.com/gin-demo/recipes-web/internal/handler/httpapi"
+	"github.com/gin-demo/recipes-web/internal/handler/httpapi/admin"
 	"github.com/gin-demo/recipes-web/internal/handler/httpapi/auth"
 	"github.com/gin-demo/recipes-web/internal/handler/httpapi/middleware"
+	"github.com/gin-demo/recipes-web/internal/health"
+	"github.com/gin-demo/recipes-web/internal/logging"
+	"github.com/gin-demo/recipes-web/internal/metrics"
 	"github.com/gin-demo/recipes-web/internal/repository"
 	"github.com/gin-demo/recipes-web/internal/repository/memory"
 	"github.com/gin-demo/recipes-web/internal/repository/mongorepo"
+	"github.com/gin-demo/recipes-web/internal/similar"
+	"github.com/gin-demo/recipes-web/internal/tracing"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// Config holds the application configuration from environment variables.
-type Config struct {
-	RepoType string
-	DataPath string
-	MongoURI string
-	HttpAddr string
-	SeedData bool
-}
-
 // main initializes and runs the recipe application server.
 func main() {
 	/*
@@ -43,6 +49,13 @@ func main() {
 		PUT /recipes/{id} - Updates an existing recipes
 		DELETE /recipes/{id} - Deletes an existing recipes
 		GET /recipes/search?tag=X = Search recipe by tag
+		GET /recipes/facets - Count recipes by tag, cuisine, difficulty and time
+		POST /recipes/match - Rank recipes by the ingredients at hand
+		GET /recipes/{id}/similar - Recipes most like another
+		POST /cookbooks/render - Render recipes as a PDF cookbook
+		POST /recipes/{id}/images - Upload an image of a recipe
+		GET /admin/recipes/duplicates - Report suspected duplicate recipes
+		POST /shopping-lists - Build a shopping list from recipes
 	*/
 
 	var (
@@ -51,88 +64,290 @@ func main() {
 		err       error
 	)
 
-	if err := godotenv.Load(); err != nil {
-		log.Println("no .env file found here, using system environment variable")
+	envErr := godotenv.Load()
+
+	flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	printConfig := flags.Bool("print-config", false, "print the effective configuration with secrets masked and exit")
+	cfg, err := config.Load(flags, os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+	if *printConfig {
+		cfg.Print(os.Stdout)
+		return
 	}
 
-	cfg := loadConfig()
+	logger, err := logging.New(os.Stdout, logging.Config{Format: cfg.Log.Format, Level: cfg.Log.Level})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid logging configuration: %v\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
 
-	switch cfg.RepoType {
+	if envErr != nil {
+		slog.Info("no .env file found, using system environment variables")
+	}
+	slog.Info("configuration loaded", "config", cfg)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: cfg.Tracing.ServiceName,
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		FilePath:    cfg.Tracing.File,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal("failed to initialize tracing", "error", err)
+	}
+
+	checker := health.NewChecker(2 * time.Second)
+
+	var (
+		seeded  atomic.Bool
+		seedErr atomic.Pointer[error]
+	)
+	seeded.Store(true)
+	checker.Register("seed", true, func(ctx context.Context) error {
+		if err := seedErr.Load(); err != nil {
+			return fmt.Errorf("seeding failed: %w", *err)
+		}
+		if !seeded.Load() {
+			return fmt.Errorf("seeding in progress")
+		}
+		return nil
+	})
+
+	switch cfg.Repository.Type {
 	case "memory":
-		repo, err = memory.New(cfg.DataPath)
+		var memRepo *memory.Repository
+		memRepo, err = memory.New(cfg.Repository.DataPath)
+		if err == nil {
+			checker.Register("memory", true, func(ctx context.Context) error {
+				return memRepo.CheckWritable()
+			})
+			repo = memRepo
+		}
 	case "mongo":
-		mongoRepo, err := mongorepo.New(cfg.MongoURI, "recipes")
+		mongoRepo, err = mongorepo.New(cfg.Mongo.URI, cfg.Mongo.Database,
+			mongorepo.WithOperationTimeout(cfg.Mongo.OperationTimeout))
 		if err != nil {
-			log.Fatalf("failed to initialize mongo repository: %v", err)
+			fatal("failed to initialize mongo repository", "error", err)
 		}
+		checker.Register("mongo", true, mongoRepo.Ping)
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		if cfg.SeedData {
-			if err := bootstrap.SeedRecipe(ctx, mongoRepo, cfg.DataPath); err != nil {
-				log.Fatal(err)
-			}
+		indexCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := mongoRepo.EnsureIndexes(indexCtx); err != nil {
+			slog.Warn("failed to create recipe indexes", "error", err)
+		}
+		if n, err := mongoRepo.BackfillDerived(indexCtx); err != nil {
+			slog.Warn("failed to backfill recipe total times", "error", err)
+		} else if n > 0 {
+			slog.Info("backfilled recipe total times", "recipes", n)
 		}
+		cancel()
 
 		repo = mongoRepo
 
 	default:
-		log.Fatalf("unknown REPO_TYPE: %s", cfg.RepoType)
+		fatal("unknown repository type", "repoType", cfg.Repository.Type)
 	}
 
 	if err != nil {
-		log.Fatalf("failed to initialize repository: %v", err)
+		fatal("failed to initialize repository", "error", err)
 	}
 
-	redisClient, err := bootstrap.NewRedis(os.Getenv("REDIS_ADDR"), "", 0)
+	similarIndex := similar.NewIndex()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	appMetrics := metrics.New(registry)
+	repo = repository.NewMetricsRepository(repo, cfg.Repository.Type, appMetrics)
+
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	go similarIndex.Run(bgCtx, repo, cfg.Similar.RefreshInterval)
+
+	redisClient, err := bootstrap.NewRedis(cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB)
 	if err != nil {
-		log.Printf("redis client init error : %v\n", err)
+		slog.Warn("redis unavailable, cache disabled until it recovers", "error", err)
 	}
 
+	var cacheAdmin admin.CacheAdmin
 	if redisClient != nil {
-		cache := redisrecipe.NewCache(redisClient, 30*time.Minute)
+		cache := redisrecipe.NewCache(redisClient, cfg.Cache.TTL,
+			redisrecipe.WithOperationTimeout(cfg.Redis.OperationTimeout))
+		go cache.Monitor(bgCtx, cfg.Cache.MonitorInterval)
+
+		checker.Register("redis", false, func(ctx context.Context) error {
+			if err := cache.Ping(ctx); err != nil {
+				return fmt.Errorf("breaker %s: %w", cache.BreakerState(), err)
+			}
+			return nil
+		})
+
 		cachedRepo := repository.NewCachedRepository(repo, cache)
 		repo = cachedRepo
+		cacheAdmin = cachedRepo
+		appMetrics.RegisterCache(cachedRepo)
+
+		if cfg.Cache.WarmUp {
+			go warmUpCache(cachedRepo, cfg.Cache)
+		}
+	}
+
+	// Seed through the wrapped repository so the writes are measured and
+	// any cached copies invalidated.
+	if seedPath := cfg.Repository.SeedFile(); seedPath != "" {
+		seeded.Store(false)
+		go func(repo domain.RecipeRepository) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			// The server is already serving, so a failure only fails readiness.
+			result, err := bootstrap.SeedRecipes(ctx, repo, seedPath, bootstrap.RecipeLimits(cfg.Validation))
+			if err != nil {
+				slog.Error("failed to seed recipes", "error", err)
+				seedErr.Store(&err)
+				return
+			}
+			seeded.Store(true)
+
+			// Seeded recipes bypass the controller, so index them now
+			// rather than at the next refresh.
+			if result.Created > 0 {
+				if err := similarIndex.Rebuild(ctx, repo); err != nil {
+					slog.Warn("failed to rebuild similar-recipe index", "error", err)
+				}
+			}
+		}(repo)
 	}
 
-	router := gin.Default()
+	router := gin.New()
+	router.Use(
+		gin.Recovery(),
+		middleware.RequestID(),
+		middleware.AuditContext(),
+		middleware.Tracing(),
+		middleware.Logger(logger),
+		middleware.Metrics(appMetrics),
+	)
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})))
+
+	auditLog := newAuditLog(cfg.Audit, mongoRepo)
+	imageStore := newImageStore(cfg.Images)
 
-	ctrl := recipe.New(repo)
+	ctrl := recipe.New(repo,
+		recipe.WithAuditor(auditLog),
+		recipe.WithLimits(bootstrap.RecipeLimits(cfg.Validation)),
+		recipe.WithImages(imageStore, cfg.Images.BaseURL),
+		recipe.WithSimilarIndex(similarIndex),
+	)
 	handler := httpapi.New(ctrl)
+	healthHandler := httpapi.NewHealthHandler(checker)
+	imageHandler := httpapi.NewImageHandler(imageStore)
 
-	if os.Getenv("JWT_SECRET") == "" {
-		log.Fatal("JWT_SECRET is required but not set")
-	}
+	router.GET("/healthz", healthHandler.LivenessHandler)
+	router.GET("/readyz", healthHandler.ReadinessHandler)
 
 	authHandler := auth.New(auth.Config{
-		Secret: os.Getenv("JWT_SECRET"),
-		Issuer: "recipe-app",
+		Secret:   cfg.Auth.JWTSecret,
+		Issuer:   cfg.Auth.Issuer,
+		TokenTTL: cfg.Auth.TokenTTL,
+		Auditor:  auditLog,
+		Users:    newUserRepository(cfg, mongoRepo),
 	})
 
 	router.POST("/signin", authHandler.SignInHandler)
 
 	router.GET("/recipes", handler.ListRecipeHandler)
 	router.GET("/recipes/search", handler.ListRecipesByTagHandler)
+	router.GET("/recipes/export", handler.ExportHandler)
+	router.GET("/recipes/facets", handler.FacetsHandler)
+	router.POST("/recipes/match", handler.MatchHandler)
+	router.GET("/images/*key", imageHandler.ServeHandler)
 
 	authorized := router.Group("/recipes")
-	authorized.Use(middleware.AuthMiddleware())
+	authorized.Use(middleware.AuthMiddleware(cfg.Auth.JWTSecret))
 	{
 		authorized.GET("/:id", handler.GetRecipeByIDHandler)
+		authorized.GET("/:id/similar", handler.SimilarHandler)
 		authorized.POST("/", handler.CreateRecipeHandler)
 		authorized.DELETE("/:id", handler.DeleteRecipeHandler)
 		authorized.PUT("/:id", handler.UpdateRecipeHandler)
+		authorized.POST("/:id/images", handler.AddImageHandler)
+		authorized.DELETE("/:id/images/:imageID", handler.DeleteImageHandler)
+	}
+
+	// Batch routes sit beside /recipes rather than under it, so they are
+	// registered from the root; the colon is escaped to keep it literal.
+	batch := router.Group("", middleware.AuthMiddleware(cfg.Auth.JWTSecret))
+	{
+		batch.POST(`/recipes\:batchCreate`, handler.BatchCreateHandler)
+		batch.POST(`/recipes\:batchGet`, handler.BatchGetHandler)
+		batch.POST(`/recipes\:batchUpdate`, handler.BatchUpdateHandler)
+		batch.POST(`/recipes\:batchDelete`, handler.BatchDeleteHandler)
+		batch.POST(`/recipes\:import`, handler.ImportHandler)
+	}
+
+	cookbooks := router.Group("/cookbooks", middleware.AuthMiddleware(cfg.Auth.JWTSecret))
+	{
+		cookbooks.POST("/render", handler.CookbookHandler)
+	}
+
+	shoppingHandler := httpapi.NewShoppingHandler(shopping.New(newShoppingListRepository(cfg, mongoRepo), repo))
+
+	lists := router.Group("/shopping-lists", middleware.AuthMiddleware(cfg.Auth.JWTSecret))
+	{
+		lists.POST("", shoppingHandler.CreateHandler)
+		lists.GET("", shoppingHandler.ListHandler)
+		lists.GET("/:id", shoppingHandler.GetHandler)
+		lists.PATCH("/:id/items/:itemID", shoppingHandler.CheckItemHandler)
+		lists.DELETE("/:id", shoppingHandler.DeleteHandler)
+	}
+
+	cacheHandler := admin.NewCacheHandler(cacheAdmin, admin.WithWarmUpLimits(cfg.Cache.WarmUpLimit, cfg.Cache.WarmUpConcurrency))
+	duplicateHandler := admin.NewDuplicateHandler(ctrl)
+
+	adminGroup := router.Group("/admin")
+	adminGroup.Use(middleware.AuthMiddleware(cfg.Auth.JWTSecret), middleware.RequireRole("admin"))
+	{
+		adminGroup.GET("/cache/stats", cacheHandler.StatsHandler)
+		adminGroup.GET("/cache/recipes/:id", cacheHandler.InspectHandler)
+		adminGroup.DELETE("/cache/recipes/:id", cacheHandler.EvictByIDHandler)
+		adminGroup.DELETE("/cache/tags/:tag", cacheHandler.EvictByTagHandler)
+		adminGroup.DELETE("/cache", cacheHandler.FlushHandler)
+		adminGroup.POST("/cache/warmup", cacheHandler.WarmUpHandler)
+		adminGroup.GET("/recipes/duplicates", duplicateHandler.ReportHandler)
+		adminGroup.POST("/recipes/merge", duplicateHandler.MergeHandler)
+	}
+
+	auditHandler := admin.NewAuditHandler(auditLog)
+
+	auditGroup := router.Group("/audit")
+	auditGroup.Use(middleware.AuthMiddleware(cfg.Auth.JWTSecret), middleware.RequireRole("admin"))
+	{
+		auditGroup.GET("", auditHandler.QueryHandler)
+		auditGroup.GET("/verify", auditHandler.VerifyHandler)
 	}
 
 	srv := &http.Server{
-		Addr:    cfg.HttpAddr,
+		Addr:    cfg.Server.Addr,
 		Handler: router,
 	}
 
 	go func() {
-		log.Printf("HTTP server listening on %s", cfg.HttpAddr)
+		slog.Info("HTTP server listening", "addr", cfg.Server.Addr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %s\n", err)
+			fatal("HTTP server failed", "error", err)
 		}
 	}()
 
@@ -140,59 +355,162 @@ func main() {
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
+	slog.Info("shutting down server")
+	checker.SetShuttingDown()
+	if cfg.Server.DrainDelay > 0 {
+		slog.Info("waiting for load balancers to drain", "delay", cfg.Server.DrainDelay)
+		time.Sleep(cfg.Server.DrainDelay)
+	}
+	stopBackground()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("Server forced to shutdown: %v", err)
+		slog.Error("server forced to shutdown", "error", err)
 	}
 
 	if mongoRepo != nil {
-		log.Println("Closing MongoDB connection...")
+		slog.Info("closing MongoDB connection")
 		if err := mongoRepo.Close(ctx); err != nil {
-			log.Printf("Mongo close error: %v", err)
+			slog.Error("mongo close error", "error", err)
 		}
 	}
 
-	log.Println("Server exiting")
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("tracing shutdown error", "error", err)
+	}
+
+	slog.Info("server exiting")
 }
 
-// loadConfig reads configuration from environment variables with defaults.
-func loadConfig() Config {
-	// default configuration
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+// fatal logs msg at error level and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// warmUpCache preloads recipes into the cache in the background so startup is not delayed.
+func warmUpCache(cachedRepo *repository.CachedRepository, cfg config.CacheConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
 
-	cfg := Config{
-		RepoType: "memory",
-		DataPath: "data/recipe.json",
-		MongoURI: os.Getenv("MONGO_URI"),
-		HttpAddr: ":" + port,
+	result, err := cachedRepo.WarmUp(ctx, repository.WarmUpOptions{
+		Limit:       cfg.WarmUpLimit,
+		Concurrency: cfg.WarmUpConcurrency,
+	})
+	if err != nil {
+		slog.Warn("cache warm-up skipped", "error", err)
+		return
 	}
 
-	if v := os.Getenv("REPO_TYPE"); v != "" {
-		cfg.RepoType = v
+	slog.Info("cache warm-up finished", "loaded", result.Loaded, "failed", result.Failed)
+}
+
+// newUserRepository opens the account store for the configured backend. While
+// it holds no accounts, the built-in demo admin can sign in.
+func newUserRepository(cfg config.Config, mongoRepo *mongorepo.Repository) domain.UserRepository {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var (
+		users domain.UserRepository
+		err   error
+	)
+	if mongoRepo != nil {
+		users, err = mongoRepo.Users(ctx)
+	} else {
+		users, err = memory.NewUserRepository(cfg.Auth.UsersFile)
 	}
-	if v := os.Getenv("DATA_PATH"); v != "" {
-		cfg.DataPath = v
+	if err != nil {
+		fatal("failed to open user repository", "error", err)
 	}
-	if v := os.Getenv("MONGO_URI"); v != "" {
-		cfg.MongoURI = v
+
+	count, err := users.CountUsers(ctx)
+	if err != nil {
+		fatal("failed to count users", "error", err)
 	}
-	if v := os.Getenv("HTTP_ADDR"); v != "" {
-		cfg.HttpAddr = v
+	if count == 0 {
+		slog.Warn("no user accounts found, only the built-in demo admin can sign in until one is created with recipectl user create")
 	}
-	if v := os.Getenv("SEED_DATA"); v != "" {
-		value, err := strconv.ParseBool(v)
-		if err != nil {
-			fmt.Printf("error parsing SEED_DATA env variable: %v\n", err)
+	return users
+}
+
+// newAuditLog opens the configured audit sink and exits if it cannot, since
+// running without the audit trail would silently lose mutations.
+func newAuditLog(cfg config.AuditConfig, mongoRepo *mongorepo.Repository) *audit.Logger {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var (
+		sink audit.Sink
+		err  error
+	)
+	switch cfg.Sink {
+	case "file":
+		sink, err = audit.NewFileSink(cfg.File)
+	case "mongo":
+		if mongoRepo == nil {
+			fatal("audit sink mongo requires the mongo repository")
 		}
-		cfg.SeedData = value
+		sink, err = audit.NewMongoSink(ctx, mongoRepo.Collection("audit"))
+	default:
+		fatal("unknown audit sink", "auditSink", cfg.Sink)
+	}
+	if err != nil {
+		fatal("failed to open audit sink", "error", err)
+	}
+
+	auditLog, err := audit.NewLogger(ctx, sink)
+	if err != nil {
+		fatal("failed to load audit log", "error", err)
+	}
+	return auditLog
+}
+
+// newImageStore opens the configured image store and exits if it cannot.
+func newImageStore(cfg config.ImagesConfig) blob.Store {
+	var (
+		store blob.Store
+		err   error
+	)
+	switch cfg.Store {
+	case "fs":
+		store, err = blob.NewFS(cfg.Dir)
+	case "s3":
+		store, err = blob.NewS3(blob.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+		})
+	default:
+		fatal("unknown image store", "imageStore", cfg.Store)
+	}
+	if err != nil {
+		fatal("failed to open image store", "error", err)
 	}
+	return store
+}
+
+// newShoppingListRepository opens the shopping list store for the configured backend.
+func newShoppingListRepository(cfg config.Config, mongoRepo *mongorepo.Repository) domain.ShoppingListRepository {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	return cfg
+	var (
+		lists domain.ShoppingListRepository
+		err   error
+	)
+	if mongoRepo != nil {
+		lists, err = mongoRepo.ShoppingLists(ctx)
+	} else {
+		lists, err = memory.NewShoppingListRepository(cfg.Shopping.ListsFile)
+	}
+	if err != nil {
+		fatal("failed to open shopping list repository", "error", err)
+	}
+	return lists
 }
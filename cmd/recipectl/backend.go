@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/repository/memory"
+	"github.com/gin-demo/recipes-web/internal/repository/mongorepo"
+)
+
+// store is a backend together with what is needed to release it.
+type store struct {
+	spec  string
+	repo  domain.RecipeRepository
+	mongo *mongorepo.Repository
+}
+
+// Close disconnects from MongoDB; memory stores need no cleanup.
+func (s *store) Close() {
+	if s.mongo == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = s.mongo.Close(ctx)
+}
+
+// defaultSpec names the backend the server is configured to use.
+func defaultSpec() string {
+	if cfg.Repository.Type == "mongo" {
+		return cfg.Mongo.URI
+	}
+	return cfg.Repository.DataPath
+}
+
+// backendFlags registers the flags selecting one backend.
+func backendFlags(fs *flag.FlagSet, name, usage string) (spec, database *string) {
+	spec = fs.String(name, defaultSpec(), usage+": recipe file (memory:PATH or PATH) or MongoDB URI")
+	database = fs.String(name+"-db", cfg.Mongo.Database, "MongoDB database for -"+name)
+	return spec, database
+}
+
+func isMongo(spec string) bool {
+	return strings.HasPrefix(spec, "mongodb://") || strings.HasPrefix(spec, "mongodb+srv://")
+}
+
+// openStore opens the backend named by spec. With create set, a missing
+// recipe file is created empty so it can be written to.
+func openStore(spec, database string, create bool) (*store, error) {
+	if spec == "" {
+		return nil, errors.New("no backend given")
+	}
+
+	if isMongo(spec) {
+		repo, err := mongorepo.New(spec, database, mongorepo.WithOperationTimeout(cfg.Mongo.OperationTimeout))
+		if err != nil {
+			return nil, err
+		}
+		return &store{spec: "mongo:" + database, repo: repo, mongo: repo}, nil
+	}
+
+	path := strings.TrimPrefix(spec, "memory:")
+	if create {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			if err := os.WriteFile(path, []byte("[]\n"), 0o644); err != nil {
+				return nil, fmt.Errorf("create %s: %w", path, err)
+			}
+		}
+	}
+	repo, err := memory.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	return &store{spec: "memory:" + path, repo: repo}, nil
+}
+
+// openTarget opens a backend to write to. A dry run never creates files, so a
+// missing recipe file is stood in for by an empty temporary one.
+func openTarget(spec, database string, dryRun bool) (*store, error) {
+	path := strings.TrimPrefix(spec, "memory:")
+	if !dryRun || isMongo(spec) {
+		return openStore(spec, database, true)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		return openStore(spec, database, false)
+	}
+
+	empty, err := os.CreateTemp("", "recipectl-*.json")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(empty.Name())
+	_, err = empty.WriteString("[]")
+	if closeErr := empty.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := openStore(empty.Name(), database, false)
+	if err != nil {
+		return nil, err
+	}
+	s.spec = "memory:" + path
+	return s, nil
+}
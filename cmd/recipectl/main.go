@@ -0,0 +1,123 @@
+// Command recipectl moves and maintains recipe data using the same repository
+// implementations as the server.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/gin-demo/recipes-web/internal/config"
+	"github.com/joho/godotenv"
+)
+
+// command is one recipectl subcommand.
+type command struct {
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = map[string]command{
+	"import":    {"upsert recipes from a json, ndjson or csv file or Cooklang files", runImport},
+	"export":    {"write every recipe to a json, ndjson or csv file", runExport},
+	"copy":      {"copy recipes between backends, keeping their IDs", runCopy},
+	"reindex":   {"drop and rebuild the MongoDB recipe indexes", runReindex},
+	"validate":  {"report recipes with missing or inconsistent data", runValidate},
+	"normalize": {"clean up text fields of stored recipes in place", runNormalize},
+	"user":      {"manage accounts that can sign in (user create)", runUser},
+}
+
+// cfg is the server's configuration, read from CONFIG_FILE and the
+// environment; flags default to the backend and limits it names.
+var cfg config.Config
+
+// errUsage marks errors caused by wrong arguments; the command's usage has
+// already been printed.
+var errUsage = errors.New("usage error")
+
+func main() {
+	_ = godotenv.Load()
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage(os.Stdout)
+		return
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "recipectl: unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	var err error
+	cfg, err = config.Load(flag.NewFlagSet("recipectl", flag.ContinueOnError), nil, os.LookupEnv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "recipectl: invalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = cmd.run(ctx, os.Args[2:])
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	default:
+		fmt.Fprintf(os.Stderr, "recipectl %s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: recipectl <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-9s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Backends are given as a recipe file path (memory:PATH or PATH) or a MongoDB URI;")
+	fmt.Fprintln(w, "the default is the server's, read from CONFIG_FILE and the environment like the server.")
+	fmt.Fprintln(w, "Run 'recipectl <command> -h' for the command's flags.")
+}
+
+// newFlagSet returns a flag set that prints its usage line on errors.
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet("recipectl "+name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: recipectl %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses args and maps flag errors to errUsage.
+func parse(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return errUsage
+	}
+	return err
+}
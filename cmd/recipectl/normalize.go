@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/normalize"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// normalizeReport lists the edits made to one recipe.
+type normalizeReport struct {
+	ID      model.RecipeID     `json:"id"`
+	Name    string             `json:"name"`
+	Changes []normalize.Change `json:"changes"`
+}
+
+// runNormalize applies the normalization done on every create and update to
+// recipes stored before it existed.
+func runNormalize(ctx context.Context, args []string) error {
+	fs := newFlagSet("normalize", "")
+	repoSpec, database := backendFlags(fs, "repo", "backend to normalize in place")
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing")
+	asJSON := fs.Bool("json", false, "print the changes as JSON instead of text")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	target, err := openStore(*repoSpec, *database, false)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	upserter, ok := target.repo.(domain.RecipeUpserter)
+	if !ok {
+		return fmt.Errorf("%s cannot store recipes under their own IDs", target.spec)
+	}
+
+	recipes, err := target.repo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	reports := []normalizeReport{}
+	var changed []model.Recipe
+	for _, recipe := range recipes {
+		clean, changes := normalize.Recipe(recipe)
+		if len(changes) == 0 {
+			continue
+		}
+		reports = append(reports, normalizeReport{ID: recipe.ID, Name: clean.Name, Changes: changes})
+		changed = append(changed, clean)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			return err
+		}
+	} else {
+		for _, report := range reports {
+			fmt.Printf("%s %q\n", report.ID, report.Name)
+			for _, change := range report.Changes {
+				fmt.Printf("  %s\n", change)
+			}
+		}
+	}
+
+	if !*dryRun && len(changed) > 0 {
+		if batch, ok := target.repo.(domain.RecipeBatchUpserter); ok {
+			_, err = batch.UpsertMany(ctx, changed)
+		} else {
+			for _, recipe := range changed {
+				if _, err = upserter.Upsert(ctx, recipe); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	verb := "normalized"
+	if *dryRun {
+		verb = "would normalize"
+	}
+	fmt.Fprintf(os.Stderr, "%s %d of %d recipes in %s\n", verb, len(changed), len(recipes), target.spec)
+	return nil
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gin-demo/recipes-web/internal/bootstrap"
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/importer"
+	"github.com/gin-demo/recipes-web/internal/recipeio"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+func runImport(ctx context.Context, args []string) error {
+	fs := newFlagSet("import", "FILE|DIR")
+	repoSpec, database := backendFlags(fs, "repo", "target backend")
+	formatName := fs.String("format", "", "file format: json, ndjson or csv (default from the file extension; .cook files and directories are read as Cooklang)")
+	batchSize := fs.Int("batch", 100, "recipes written per batch")
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing")
+	duplicates := fs.String("duplicates", "warn", "records looking like earlier ones: warn or reject")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *duplicates != "warn" && *duplicates != "reject" {
+		return fmt.Errorf("-duplicates must be warn or reject, got %q", *duplicates)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	var (
+		src importer.Source
+		err error
+	)
+	if *formatName == "" && importer.IsCooklang(fs.Arg(0)) {
+		if src, err = importer.CooklangFiles(fs.Arg(0)); err != nil {
+			return err
+		}
+	} else {
+		format, err := pickFormat(*formatName, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		r, closeInput, err := openInput(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer closeInput()
+
+		if src, err = recipeio.NewDecoder(r, format); err != nil {
+			return err
+		}
+	}
+
+	target, err := openTarget(*repoSpec, *database, *dryRun)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	return runImporter(ctx, target, src, *batchSize, *dryRun, false, *duplicates == "reject")
+}
+
+func runExport(ctx context.Context, args []string) error {
+	fs := newFlagSet("export", "")
+	repoSpec, database := backendFlags(fs, "repo", "source backend")
+	formatName := fs.String("format", "", "file format: json, ndjson or csv (default from -o, else json)")
+	output := fs.String("o", "-", "output file, - for stdout")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	format, err := pickFormat(*formatName, *output)
+	if err != nil {
+		return err
+	}
+
+	source, err := openStore(*repoSpec, *database, false)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	w := io.Writer(os.Stdout)
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	buf := bufio.NewWriter(w)
+	enc, err := recipeio.NewEncoder(buf, format)
+	if err != nil {
+		return err
+	}
+	if err := domain.Stream(ctx, source.repo, domain.RecipeFilter{}, enc.Encode); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %d recipes from %s\n", enc.Count(), source.spec)
+	return nil
+}
+
+func runCopy(ctx context.Context, args []string) error {
+	fs := newFlagSet("copy", "")
+	fromSpec, fromDB := backendFlags(fs, "from", "source backend")
+	toSpec, toDB := backendFlags(fs, "to", "target backend")
+	batchSize := fs.Int("batch", 100, "recipes written per batch")
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *fromSpec == *toSpec && *fromDB == *toDB {
+		return errors.New("-from and -to name the same backend")
+	}
+
+	source, err := openStore(*fromSpec, *fromDB, false)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	recipes, err := source.repo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	target, err := openTarget(*toSpec, *toDB, *dryRun)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	return runImporter(ctx, target, importer.FromSlice(recipes), *batchSize, *dryRun, true, false)
+}
+
+func runReindex(ctx context.Context, args []string) error {
+	fs := newFlagSet("reindex", "")
+	repoSpec, database := backendFlags(fs, "repo", "MongoDB backend")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if !isMongo(*repoSpec) {
+		return errors.New("only the MongoDB backend has indexes; the memory backend scans its file")
+	}
+
+	target, err := openStore(*repoSpec, *database, false)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	if err := target.mongo.Reindex(ctx); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "rebuilt recipe indexes on %s\n", target.spec)
+	return nil
+}
+
+func runValidate(ctx context.Context, args []string) error {
+	fs := newFlagSet("validate", "[FILE]")
+	repoSpec, database := backendFlags(fs, "repo", "backend to check when no FILE is given")
+	formatName := fs.String("format", "", "format of FILE: json, ndjson or csv (default from the file extension)")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	var (
+		recipes []model.Recipe
+		name    string
+		err     error
+	)
+	switch fs.NArg() {
+	case 0:
+		var source *store
+		source, err = openStore(*repoSpec, *database, false)
+		if err != nil {
+			return err
+		}
+		defer source.Close()
+		name = source.spec
+		recipes, err = source.repo.GetAll(ctx)
+	case 1:
+		name = fs.Arg(0)
+		recipes, err = readFile(name, *formatName)
+	default:
+		fs.Usage()
+		return errUsage
+	}
+	if err != nil {
+		return err
+	}
+
+	problems := recipeio.Check(recipes)
+	printProblems(os.Stdout, problems)
+	errs := recipeio.Errors(problems)
+	if errs > 0 {
+		return fmt.Errorf("%d errors and %d warnings in %d recipes from %s", errs, len(problems)-errs, len(recipes), name)
+	}
+	fmt.Fprintf(os.Stderr, "%d recipes from %s are valid, %d warnings\n", len(recipes), name, len(problems))
+	return nil
+}
+
+// runImporter upserts every recipe from src into target, printing progress to
+// stderr. Records are validated against the configured limits, and invalid or
+// failing records are reported and skipped. Images are kept only by copies.
+// Records looking like earlier ones are reported, or rejected with
+// rejectDuplicates.
+func runImporter(ctx context.Context, target *store, src importer.Source, batchSize int, dryRun, images, rejectDuplicates bool) error {
+	limits := bootstrap.RecipeLimits(cfg.Validation)
+	imp, err := importer.New(target.repo,
+		importer.WithBatchSize(batchSize),
+		importer.WithDryRun(dryRun),
+		importer.WithImages(images),
+		importer.WithRejectDuplicates(rejectDuplicates),
+		importer.WithValidator(func(r model.Recipe) error { return recipe.Validate(r, limits) }),
+		importer.WithProgress(func(r importer.Result) {
+			fmt.Fprintf(os.Stderr, "%d recipes processed\n", r.Processed())
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	result, err := imp.Run(ctx, src)
+	for _, f := range result.Failures {
+		fmt.Fprintf(os.Stderr, "record %d (%s): %s\n", f.Record, f.ID, f.Error)
+	}
+	for _, d := range result.Duplicates {
+		fmt.Fprintf(os.Stderr, "record %d (%s): possible duplicate of %s\n", d.Record, d.ID, d.Of[0].ID)
+	}
+
+	verb := "imported into"
+	if dryRun {
+		verb = "dry run against"
+	}
+	fmt.Fprintf(os.Stderr, "%s %s: %d created, %d updated, %d unchanged, %d failed\n",
+		verb, target.spec, result.Created, result.Updated, result.Skipped, result.Failed)
+
+	if err != nil {
+		return err
+	}
+	if result.Failed > 0 {
+		return fmt.Errorf("%d recipes failed", result.Failed)
+	}
+	return nil
+}
+
+func readFile(path, formatName string) ([]model.Recipe, error) {
+	format, err := pickFormat(formatName, path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, closeInput, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeInput()
+	return recipeio.Read(r, format)
+}
+
+// openInput opens path for reading, with - meaning stdin.
+func openInput(path string) (io.Reader, func(), error) {
+	if path == "-" {
+		return os.Stdin, func() {}, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, func() { file.Close() }, nil
+}
+
+// pickFormat uses the explicit format name when given, else the path's extension.
+func pickFormat(name, path string) (recipeio.Format, error) {
+	if name != "" {
+		return recipeio.ParseFormat(name)
+	}
+	return recipeio.FormatFromPath(path), nil
+}
+
+func printProblems(w io.Writer, problems []recipeio.Problem) {
+	for _, p := range problems {
+		fmt.Fprintln(w, p)
+	}
+}
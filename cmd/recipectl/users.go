@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/password"
+	"github.com/gin-demo/recipes-web/internal/repository/memory"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+func runUser(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "create" {
+		fmt.Fprintln(os.Stderr, "Usage: recipectl user create [flags] NAME")
+		return errUsage
+	}
+	return runUserCreate(ctx, args[1:])
+}
+
+func runUserCreate(ctx context.Context, args []string) error {
+	fs := newFlagSet("user create", "NAME")
+	repoSpec, database := backendFlags(fs, "repo", "backend whose accounts to manage; Mongo uses the users collection")
+	usersFile := fs.String("users-file", cfg.Auth.UsersFile, "account file used with the memory backend")
+	role := fs.String("role", "user", "role claim of the account's tokens; admin can use /admin and /audit")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	name := fs.Arg(0)
+
+	fmt.Fprintf(os.Stderr, "password for %s (read from stdin): ", name)
+	plain, err := readLine()
+	if err != nil {
+		return err
+	}
+	hash, err := password.Hash(plain)
+	if err != nil {
+		return err
+	}
+
+	users, closeUsers, err := openUsers(ctx, *repoSpec, *database, *usersFile)
+	if err != nil {
+		return err
+	}
+	defer closeUsers()
+
+	user, err := users.CreateUser(ctx, model.User{UserName: name, Password: hash, Role: *role})
+	if errors.Is(err, domain.ErrConflict) {
+		return fmt.Errorf("user %q already exists", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "created user %s (%s) with role %s\n", user.UserName, user.ID, user.Role)
+	return nil
+}
+
+func openUsers(ctx context.Context, spec, database, usersFile string) (domain.UserRepository, func(), error) {
+	if !isMongo(spec) {
+		users, err := memory.NewUserRepository(usersFile)
+		return users, func() {}, err
+	}
+
+	s, err := openStore(spec, database, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	users, err := s.mongo.Users(ctx)
+	if err != nil {
+		s.Close()
+		return nil, nil, err
+	}
+	return users, s.Close, nil
+}
+
+// readLine reads one line from stdin without its line ending.
+func readLine() (string, error) {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("no password given on stdin")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
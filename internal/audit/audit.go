@@ -0,0 +1,238 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/logging"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// Audited actions.
+const (
+	ActionRecipeCreate = "recipe.create"
+	ActionRecipeUpdate = "recipe.update"
+	ActionRecipeDelete = "recipe.delete"
+	ActionRecipeMerge  = "recipe.merge"
+	ActionSignIn       = "auth.signin"
+)
+
+// Outcomes of an audited action.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// anonymous is the actor recorded when no user is authenticated.
+const anonymous = "anonymous"
+
+// ErrSequenceConflict is returned by a Sink when another writer already
+// appended an event with the same sequence number.
+var ErrSequenceConflict = errors.New("audit: sequence number already used")
+
+// Event is one entry of the audit trail. Seq, PrevHash and Hash chain every
+// event to the previous one so that edits or deletions can be detected.
+type Event struct {
+	Seq        int64     `json:"seq" bson:"seq"`
+	Time       time.Time `json:"time" bson:"time"`
+	Actor      string    `json:"actor" bson:"actor"`
+	Action     string    `json:"action" bson:"action"`
+	Outcome    string    `json:"outcome" bson:"outcome"`
+	Target     string    `json:"target,omitempty" bson:"target,omitempty"`
+	BeforeHash string    `json:"beforeHash,omitempty" bson:"beforeHash,omitempty"`
+	AfterHash  string    `json:"afterHash,omitempty" bson:"afterHash,omitempty"`
+	ClientIP   string    `json:"clientIp,omitempty" bson:"clientIp,omitempty"`
+	RequestID  string    `json:"requestId,omitempty" bson:"requestId,omitempty"`
+	Error      string    `json:"error,omitempty" bson:"error,omitempty"`
+	PrevHash   string    `json:"prevHash" bson:"prevHash"`
+	Hash       string    `json:"hash" bson:"hash"`
+}
+
+// Filter narrows an audit query. Zero values match everything.
+type Filter struct {
+	Actor  string
+	Action string
+	From   time.Time
+	To     time.Time
+	// Limit keeps only the most recent matches; zero returns all of them
+	Limit int
+}
+
+// Match reports whether ev satisfies the filter, ignoring Limit.
+func (f Filter) Match(ev Event) bool {
+	if f.Actor != "" && ev.Actor != f.Actor {
+		return false
+	}
+	if f.Action != "" && ev.Action != f.Action {
+		return false
+	}
+	if !f.From.IsZero() && ev.Time.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && ev.Time.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// Sink is append-only storage for audit events.
+type Sink interface {
+	// Append stores ev, failing with ErrSequenceConflict if ev.Seq is taken
+	Append(ctx context.Context, ev Event) error
+	// Last returns the event with the highest sequence number
+	Last(ctx context.Context) (Event, bool, error)
+	// Query returns matching events in ascending sequence order
+	Query(ctx context.Context, f Filter) ([]Event, error)
+}
+
+// Recorder records audit events. Callers fill in the action, outcome, target
+// and snapshot hashes; the recorder adds actor and request details from ctx.
+type Recorder interface {
+	Record(ctx context.Context, ev Event) error
+}
+
+// Logger chains events and writes them to a Sink.
+type Logger struct {
+	mu   sync.Mutex
+	sink Sink
+	last Event
+	now  func() time.Time
+}
+
+// maxAppendAttempts bounds retries when another writer extends the chain first.
+const maxAppendAttempts = 3
+
+// NewLogger creates a Logger that continues the chain already stored in sink.
+func NewLogger(ctx context.Context, sink Sink) (*Logger, error) {
+	last, _, err := sink.Last(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("audit: load last event: %w", err)
+	}
+	return &Logger{sink: sink, last: last, now: time.Now}, nil
+}
+
+// Record completes ev with the actor, request ID, client IP and time, links it
+// to the previous event and appends it.
+func (l *Logger) Record(ctx context.Context, ev Event) error {
+	if ev.Actor == "" {
+		ev.Actor = logging.UserName(ctx)
+	}
+	if ev.Actor == "" {
+		ev.Actor = anonymous
+	}
+	if ev.RequestID == "" {
+		ev.RequestID = logging.RequestID(ctx)
+	}
+	if ev.ClientIP == "" {
+		ev.ClientIP = ClientIP(ctx)
+	}
+	if ev.Outcome == "" {
+		ev.Outcome = OutcomeSuccess
+	}
+	// Mongo stores milliseconds in UTC, so hash exactly what can be read back.
+	ev.Time = l.now().UTC().Truncate(time.Millisecond)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		ev.Seq = l.last.Seq + 1
+		ev.PrevHash = l.last.Hash
+		ev.Hash = Hash(ev)
+
+		err = l.sink.Append(ctx, ev)
+		if err == nil {
+			l.last = ev
+			return nil
+		}
+		if !errors.Is(err, ErrSequenceConflict) {
+			return err
+		}
+
+		last, _, lastErr := l.sink.Last(ctx)
+		if lastErr != nil {
+			return lastErr
+		}
+		l.last = last
+	}
+
+	return err
+}
+
+// Query returns matching events in ascending sequence order.
+func (l *Logger) Query(ctx context.Context, f Filter) ([]Event, error) {
+	return l.sink.Query(ctx, f)
+}
+
+// VerifyResult reports whether the stored chain is intact.
+type VerifyResult struct {
+	Valid    bool   `json:"valid"`
+	Events   int    `json:"events"`
+	BrokenAt int64  `json:"brokenAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Verify walks the whole chain and reports the first event that was modified,
+// removed or inserted out of order.
+func (l *Logger) Verify(ctx context.Context) (VerifyResult, error) {
+	events, err := l.sink.Query(ctx, Filter{})
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	return VerifyChain(events), nil
+}
+
+// VerifyChain checks events, which must be the full chain in ascending order.
+func VerifyChain(events []Event) VerifyResult {
+	prev := Event{}
+	for _, ev := range events {
+		switch {
+		case ev.Seq != prev.Seq+1:
+			return VerifyResult{Events: len(events), BrokenAt: ev.Seq, Reason: fmt.Sprintf("expected sequence %d", prev.Seq+1)}
+		case ev.PrevHash != prev.Hash:
+			return VerifyResult{Events: len(events), BrokenAt: ev.Seq, Reason: "previous hash does not match"}
+		case ev.Hash != Hash(ev):
+			return VerifyResult{Events: len(events), BrokenAt: ev.Seq, Reason: "event hash does not match its content"}
+		}
+		prev = ev
+	}
+	return VerifyResult{Valid: true, Events: len(events)}
+}
+
+// Hash returns the chain hash of ev, computed over every field except Hash.
+func Hash(ev Event) string {
+	ev.Hash = ""
+	ev.Time = ev.Time.UTC()
+	data, _ := json.Marshal(ev)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// HashRecipe returns a snapshot hash of a recipe for before/after comparison.
+func HashRecipe(r model.Recipe) string {
+	data, _ := json.Marshal(r)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type contextKey int
+
+const clientIPKey contextKey = iota
+
+// WithClientIP returns a context carrying the caller's IP address for audit events.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey, ip)
+}
+
+// ClientIP returns the caller's IP address stored in ctx, if any.
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
@@ -0,0 +1,191 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/logging"
+)
+
+func newFileLogger(t *testing.T, path string) *Logger {
+	t.Helper()
+
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+	t.Cleanup(func() { sink.Close() })
+
+	l, err := NewLogger(context.Background(), sink)
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	return l
+}
+
+func TestLoggerRecordChainsEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l := newFileLogger(t, path)
+
+	ctx := logging.WithUserName(logging.WithRequestID(context.Background(), "req-1"), "alice")
+	ctx = WithClientIP(ctx, "10.0.0.1")
+
+	if err := l.Record(ctx, Event{Action: ActionRecipeCreate, Target: "r1", AfterHash: "h1"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := l.Record(context.Background(), Event{Action: ActionSignIn, Actor: "bob", Outcome: OutcomeFailure}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	events, err := l.Query(context.Background(), Filter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+
+	first := events[0]
+	if first.Seq != 1 || first.Actor != "alice" || first.RequestID != "req-1" || first.ClientIP != "10.0.0.1" {
+		t.Errorf("Unexpected first event: %+v", first)
+	}
+	if first.Outcome != OutcomeSuccess {
+		t.Errorf("Expected default outcome success, got %s", first.Outcome)
+	}
+	if events[1].PrevHash != first.Hash {
+		t.Error("Expected second event to link to the first")
+	}
+
+	result, err := l.Verify(context.Background())
+	if err != nil || !result.Valid || result.Events != 2 {
+		t.Errorf("Expected valid chain of 2 events, got %+v, %v", result, err)
+	}
+}
+
+func TestLoggerContinuesChainAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	l := newFileLogger(t, path)
+	_ = l.Record(context.Background(), Event{Action: ActionRecipeDelete, Target: "r1"})
+
+	restarted := newFileLogger(t, path)
+	_ = restarted.Record(context.Background(), Event{Action: ActionRecipeDelete, Target: "r2"})
+
+	result, _ := restarted.Verify(context.Background())
+	if !result.Valid || result.Events != 2 {
+		t.Errorf("Expected chain to continue across restarts, got %+v", result)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l := newFileLogger(t, path)
+	for _, target := range []string{"r1", "r2", "r3"} {
+		_ = l.Record(context.Background(), Event{Action: ActionRecipeUpdate, Actor: "alice", Target: target})
+	}
+
+	data, _ := os.ReadFile(path)
+	tampered := strings.Replace(string(data), `"actor":"alice","action":"recipe.update","outcome":"success","target":"r2"`,
+		`"actor":"mallory","action":"recipe.update","outcome":"success","target":"r2"`, 1)
+	if tampered == string(data) {
+		t.Fatal("Test setup failed to tamper with the file")
+	}
+	os.WriteFile(path, []byte(tampered), 0o600)
+
+	result, err := l.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if result.Valid || result.BrokenAt != 2 {
+		t.Errorf("Expected chain broken at 2, got %+v", result)
+	}
+
+	// Removing an event breaks the sequence
+	lines := strings.SplitAfter(string(data), "\n")
+	os.WriteFile(path, []byte(lines[0]+lines[2]), 0o600)
+
+	result, _ = l.Verify(context.Background())
+	if result.Valid || result.BrokenAt != 3 {
+		t.Errorf("Expected chain broken at 3, got %+v", result)
+	}
+}
+
+func TestQueryFilters(t *testing.T) {
+	l := newFileLogger(t, filepath.Join(t.TempDir(), "audit.jsonl"))
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tick := 0
+	l.now = func() time.Time {
+		tick++
+		return base.Add(time.Duration(tick) * time.Hour)
+	}
+
+	_ = l.Record(context.Background(), Event{Action: ActionRecipeCreate, Actor: "alice"})
+	_ = l.Record(context.Background(), Event{Action: ActionRecipeDelete, Actor: "alice"})
+	_ = l.Record(context.Background(), Event{Action: ActionRecipeCreate, Actor: "bob"})
+	_ = l.Record(context.Background(), Event{Action: ActionRecipeCreate, Actor: "alice"})
+
+	byActor, _ := l.Query(context.Background(), Filter{Actor: "alice", Action: ActionRecipeCreate})
+	if len(byActor) != 2 {
+		t.Errorf("Expected 2 creates by alice, got %d", len(byActor))
+	}
+
+	byTime, _ := l.Query(context.Background(), Filter{From: base.Add(2 * time.Hour), To: base.Add(3 * time.Hour)})
+	if len(byTime) != 2 || byTime[0].Seq != 2 || byTime[1].Seq != 3 {
+		t.Errorf("Expected events 2 and 3, got %+v", byTime)
+	}
+
+	latest, _ := l.Query(context.Background(), Filter{Limit: 1})
+	if len(latest) != 1 || latest[0].Seq != 4 {
+		t.Errorf("Expected most recent event, got %+v", latest)
+	}
+}
+
+// racingSink simulates another writer appending between Last and Append.
+type racingSink struct {
+	events   []Event
+	conflict bool
+}
+
+func (s *racingSink) Append(_ context.Context, ev Event) error {
+	if s.conflict {
+		s.conflict = false
+		other := Event{Seq: ev.Seq, Action: ActionSignIn, Actor: "other", PrevHash: ev.PrevHash}
+		other.Hash = Hash(other)
+		s.events = append(s.events, other)
+		return ErrSequenceConflict
+	}
+	s.events = append(s.events, ev)
+	return nil
+}
+
+func (s *racingSink) Last(context.Context) (Event, bool, error) {
+	if len(s.events) == 0 {
+		return Event{}, false, nil
+	}
+	return s.events[len(s.events)-1], true, nil
+}
+
+func (s *racingSink) Query(context.Context, Filter) ([]Event, error) {
+	return s.events, nil
+}
+
+func TestLoggerRetriesOnSequenceConflict(t *testing.T) {
+	sink := &racingSink{conflict: true}
+	l, _ := NewLogger(context.Background(), sink)
+
+	if err := l.Record(context.Background(), Event{Action: ActionRecipeCreate}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	if len(sink.events) != 2 || sink.events[1].Seq != 2 {
+		t.Fatalf("Expected event appended after the concurrent one, got %+v", sink.events)
+	}
+	if result := VerifyChain(sink.events); !result.Valid {
+		t.Errorf("Expected valid chain, got %+v", result)
+	}
+}
@@ -0,0 +1,118 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FileSink appends events as JSON lines to a file opened in append-only mode.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	last int64
+}
+
+// NewFileSink opens (or creates) the audit file at path.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("audit: open %s: %w", path, err)
+	}
+
+	s := &FileSink{path: path, file: f}
+	last, _, err := s.Last(context.Background())
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	s.last = last.Seq
+	return s, nil
+}
+
+// Append writes ev as one line and syncs it to disk.
+func (s *FileSink) Append(_ context.Context, ev Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ev.Seq <= s.last {
+		return ErrSequenceConflict
+	}
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("audit: write: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("audit: sync: %w", err)
+	}
+	s.last = ev.Seq
+	return nil
+}
+
+// Last returns the final event in the file.
+func (s *FileSink) Last(ctx context.Context) (Event, bool, error) {
+	var last Event
+	found := false
+	err := s.scan(ctx, func(ev Event) {
+		last = ev
+		found = true
+	})
+	return last, found, err
+}
+
+// Query scans the file and returns the matching events.
+func (s *FileSink) Query(ctx context.Context, f Filter) ([]Event, error) {
+	events := make([]Event, 0)
+	err := s.scan(ctx, func(ev Event) {
+		if f.Match(ev) {
+			events = append(events, ev)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Limit > 0 && len(events) > f.Limit {
+		events = events[len(events)-f.Limit:]
+	}
+	return events, nil
+}
+
+// Close closes the underlying file.
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+func (s *FileSink) scan(ctx context.Context, fn func(Event)) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("audit: open %s: %w", s.path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var ev Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			return fmt.Errorf("audit: %s line %d: %w", s.path, line, err)
+		}
+		fn(ev)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// MongoSink stores events in a MongoDB collection. A unique index on seq lets
+// several server instances extend the same chain without forking it.
+type MongoSink struct {
+	collection *mongo.Collection
+}
+
+// NewMongoSink uses collection for audit events and ensures its indexes exist.
+func NewMongoSink(ctx context.Context, collection *mongo.Collection) (*MongoSink, error) {
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "seq", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "actor", Value: 1}, {Key: "time", Value: 1}}},
+		{Keys: bson.D{{Key: "action", Value: 1}, {Key: "time", Value: 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("audit: create indexes: %w", err)
+	}
+	return &MongoSink{collection: collection}, nil
+}
+
+// Append inserts ev. Events are never updated or deleted through this sink.
+func (s *MongoSink) Append(ctx context.Context, ev Event) error {
+	_, err := s.collection.InsertOne(ctx, ev)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrSequenceConflict
+	}
+	return err
+}
+
+// Last returns the event with the highest sequence number.
+func (s *MongoSink) Last(ctx context.Context) (Event, bool, error) {
+	var ev Event
+	err := s.collection.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.D{{Key: "seq", Value: -1}})).Decode(&ev)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Event{}, false, nil
+	}
+	if err != nil {
+		return Event{}, false, err
+	}
+	return ev, true, nil
+}
+
+// Query returns matching events in ascending sequence order.
+func (s *MongoSink) Query(ctx context.Context, f Filter) ([]Event, error) {
+	filter := bson.M{}
+	if f.Actor != "" {
+		filter["actor"] = f.Actor
+	}
+	if f.Action != "" {
+		filter["action"] = f.Action
+	}
+	timeRange := bson.M{}
+	if !f.From.IsZero() {
+		timeRange["$gte"] = f.From
+	}
+	if !f.To.IsZero() {
+		timeRange["$lte"] = f.To
+	}
+	if len(timeRange) > 0 {
+		filter["time"] = timeRange
+	}
+
+	// Newest first so Limit keeps the most recent events, then restore ascending order.
+	opts := options.Find().SetSort(bson.D{{Key: "seq", Value: -1}})
+	if f.Limit > 0 {
+		opts.SetLimit(int64(f.Limit))
+	}
+
+	cur, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	events := make([]Event, 0)
+	if err := cur.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	slices.Reverse(events)
+	return events, nil
+}
@@ -0,0 +1,49 @@
+// Package blob stores binary objects such as recipe images under
+// slash-separated keys, on the local filesystem or in an S3-compatible bucket.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned by Get for a key that holds no object.
+	ErrNotFound = errors.New("blob not found")
+	// ErrInvalidKey is returned for an empty key, one starting or ending with a
+	// slash, or one with an empty, "." or ".." segment.
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// Store keeps objects under keys such as "recipes/abc/thumbnail.jpg".
+type Store interface {
+	// Put stores data under key, replacing any object already there.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get opens the object under key; the caller closes it.
+	Get(ctx context.Context, key string) (*Object, error)
+	// Delete removes the object under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Object is a stored object being read.
+type Object struct {
+	io.ReadCloser
+	ContentType string
+	// Size is the length in bytes, or -1 when unknown
+	Size int64
+}
+
+// CheckKey returns ErrInvalidKey unless key can name an object in every Store.
+func CheckKey(key string) error {
+	if key == "" || strings.ContainsAny(key, "\\\x00") {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
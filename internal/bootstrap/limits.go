@@ -0,0 +1,25 @@
+package bootstrap
+
+import (
+	"github.com/gin-demo/recipes-web/internal/config"
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+)
+
+// RecipeLimits returns the validation limits the configuration sets, shared
+// by the server's controller, seeding and recipectl imports.
+func RecipeLimits(cfg config.ValidationConfig) recipe.Limits {
+	return recipe.Limits{
+		MaxNameLength:   cfg.MaxNameLength,
+		MaxTags:         cfg.MaxTags,
+		MaxTagLength:    cfg.MaxTagLength,
+		MaxIngredients:  cfg.MaxIngredients,
+		MaxSteps:        cfg.MaxSteps,
+		MaxEquipment:    cfg.MaxEquipment,
+		MaxItemLength:   cfg.MaxItemLength,
+		MaxPayloadBytes: cfg.MaxPayloadBytes,
+		MaxBatchSize:    cfg.MaxBatchSize,
+		MaxImages:       cfg.MaxImages,
+		MaxImageBytes:   cfg.MaxImageBytes,
+		MaxImagePixels:  cfg.MaxImagePixels,
+	}
+}
@@ -2,27 +2,39 @@ package bootstrap
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
+	"github.com/gin-demo/recipes-web/internal/resilience"
 	"github.com/redis/go-redis/v9"
 )
 
-// NewRedis creates and tests a new Redis client with the given configuration.
+// NewRedis creates a new Redis client with the given configuration and pings
+// it with bounded retries. The client is returned even when every ping fails
+// so callers can keep it behind a circuit breaker and reconnect later.
 func NewRedis(addr, password string, db int) (*redis.Client, error) {
 	rdb := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: password,
-		DB:       db,
+		Addr:         addr,
+		Password:     password,
+		DB:           db,
+		DialTimeout:  2 * time.Second,
+		ReadTimeout:  500 * time.Millisecond,
+		WriteTimeout: 500 * time.Millisecond,
+		MaxRetries:   1,
 	})
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := rdb.Ping(ctx).Err(); err != nil {
-		return nil, err
+	policy := resilience.DefaultRetryPolicy
+	policy.Timeout = time.Second
+	err := resilience.Retry(ctx, policy, func(ctx context.Context) error {
+		return rdb.Ping(ctx).Err()
+	})
+	if err != nil {
+		return rdb, err
 	}
 
-	log.Println("Connected to Redis 🚀")
+	slog.Info("connected to redis", "addr", addr)
 	return rdb, nil
 }
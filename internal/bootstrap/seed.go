@@ -2,11 +2,39 @@ package bootstrap
 
 import (
 	"context"
+	"log/slog"
 
-	"github.com/gin-demo/recipes-web/internal/repository/mongorepo"
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/importer"
+	"github.com/gin-demo/recipes-web/model"
 )
 
-// SeedRecipe populates the repository with initial recipe data from the specified file.
-func SeedRecipe(ctx context.Context, repo *mongorepo.Repository, seedPath string) error {
-	return repo.SeedFromFile(ctx, seedPath)
+// SeedRecipes adds the recipes in seedPath whose IDs repo does not hold yet.
+// Stored recipes are skipped, so seeding on every start is safe and keeps
+// the edits made to seeded recipes through the API. Records are validated
+// against limits like recipes created through the API.
+func SeedRecipes(ctx context.Context, repo domain.RecipeRepository, seedPath string, limits recipe.Limits) (importer.Result, error) {
+	imp, err := importer.New(repo,
+		importer.WithCreateOnly(true),
+		importer.WithValidator(func(r model.Recipe) error { return recipe.Validate(r, limits) }),
+	)
+	if err != nil {
+		return importer.Result{}, err
+	}
+
+	result, err := imp.ImportFile(ctx, seedPath)
+	if err != nil {
+		return result, err
+	}
+
+	for _, f := range result.Failures {
+		slog.WarnContext(ctx, "seed record rejected", "record", f.Record, "id", f.ID, "error", f.Error)
+	}
+	for _, d := range result.Duplicates {
+		slog.WarnContext(ctx, "seed record looks like an earlier one", "record", d.Record, "id", d.ID, "of", d.Of[0].ID)
+	}
+	slog.InfoContext(ctx, "seeded recipes", "file", seedPath,
+		"created", result.Created, "updated", result.Updated, "skipped", result.Skipped, "failed", result.Failed)
+	return result, nil
 }
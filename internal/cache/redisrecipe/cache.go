@@ -3,57 +3,474 @@ package redisrecipe
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"log/slog"
+	"sync/atomic"
 	"time"
 
+	"github.com/gin-demo/recipes-web/internal/resilience"
+	"github.com/gin-demo/recipes-web/internal/tracing"
 	"github.com/gin-demo/recipes-web/model"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Cache manages Redis-based caching for recipes.
+var tracer = otel.Tracer("github.com/gin-demo/recipes-web/internal/cache/redisrecipe")
+
+const (
+	keyPrefix = "Recipe:"
+	tagPrefix = "RecipeTag:"
+	viewsKey  = "RecipeViews"
+
+	defaultOperationTimeout = 250 * time.Millisecond
+)
+
+// Cache manages Redis-based caching for recipes. Every Redis call runs behind a
+// circuit breaker with a short deadline so an unhealthy Redis is skipped
+// instead of slowing down each request.
 type Cache struct {
-	client *redis.Client
-	ttl    time.Duration
+	client    *redis.Client
+	ttl       time.Duration
+	opTimeout time.Duration
+	breaker   *resilience.Breaker
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+// Entry is a cached recipe together with its remaining time to live.
+type Entry struct {
+	Recipe model.Recipe  `json:"recipe"`
+	TTL    time.Duration `json:"ttl"`
+}
+
+// Stats reports cache lookups observed by this process since start.
+type Stats struct {
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	Errors   int64   `json:"errors"`
+	HitRatio float64 `json:"hitRatio"`
+	Entries  int64   `json:"entries"`
+	Breaker  string  `json:"breaker"`
+}
+
+// Option configures optional Cache behaviour.
+type Option func(*Cache)
+
+// WithOperationTimeout sets the deadline applied to each single-key Redis call.
+func WithOperationTimeout(timeout time.Duration) Option {
+	return func(c *Cache) {
+		c.opTimeout = timeout
+	}
+}
+
+// WithBreaker replaces the default circuit breaker.
+func WithBreaker(breaker *resilience.Breaker) Option {
+	return func(c *Cache) {
+		c.breaker = breaker
+	}
 }
 
 // NewCache creates a new Cache instance with the given Redis client and TTL.
-func NewCache(client *redis.Client, ttl time.Duration) *Cache {
-	return &Cache{
-		client: client,
-		ttl:    ttl,
+func NewCache(client *redis.Client, ttl time.Duration, opts ...Option) *Cache {
+	c := &Cache{
+		client:    client,
+		ttl:       ttl,
+		opTimeout: defaultOperationTimeout,
+		breaker:   resilience.NewBreaker(resilience.BreakerConfig{}),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 func recipeKey(id model.RecipeID) string {
-	return fmt.Sprintf("Recipe:%s", id)
+	return keyPrefix + string(id)
+}
+
+func tagKey(tag string) string {
+	return tagPrefix + tag
+}
+
+// do runs fn behind the circuit breaker, bounded by timeout when it is positive,
+// inside a client span named after op. Cache misses and caller cancellations do
+// not count as Redis failures.
+func (c *Cache) do(ctx context.Context, op string, timeout time.Duration, fn func(context.Context) error) error {
+	ctx, span := tracer.Start(ctx, "redis."+op, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "redis"),
+			attribute.String("db.operation.name", op),
+		),
+	)
+
+	if err := c.breaker.Allow(); err != nil {
+		span.SetAttributes(attribute.String("cache.breaker", c.breaker.State().String()))
+		tracing.End(span, err)
+		return err
+	}
+
+	err := resilience.WithTimeout(ctx, timeout, fn)
+	switch {
+	case err == nil, err == redis.Nil:
+		c.breaker.Success()
+		span.End()
+	case ctx.Err() != nil:
+		tracing.End(span, err)
+	default:
+		c.breaker.Failure()
+		tracing.End(span, err)
+	}
+	return err
 }
 
+// Ping checks that Redis is reachable.
+func (c *Cache) Ping(ctx context.Context) error {
+	return c.do(ctx, "ping", c.opTimeout, func(ctx context.Context) error {
+		return c.client.Ping(ctx).Err()
+	})
+}
+
+// BreakerState reports whether the cache is currently bypassing Redis.
+func (c *Cache) BreakerState() resilience.State {
+	return c.breaker.State()
+}
+
+// Monitor pings Redis every interval until ctx is done. A failed ping trips the
+// breaker so requests skip the cache; a successful one closes it again, which
+// is how the cache reconnects after Redis comes back.
+func (c *Cache) Monitor(ctx context.Context, interval time.Duration) {
+	check := func() {
+		err := resilience.WithTimeout(ctx, c.opTimeout, func(ctx context.Context) error {
+			return c.client.Ping(ctx).Err()
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		state := c.breaker.State()
+		switch {
+		case err != nil && state != resilience.StateOpen:
+			slog.Warn("redis unavailable, bypassing cache", "error", err)
+			c.breaker.Trip()
+		case err != nil:
+			c.breaker.Trip()
+		case state != resilience.StateClosed:
+			slog.Info("redis reachable again, cache re-enabled")
+			c.breaker.Success()
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	check()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// GetByID returns the cached recipe for id and records the lookup as a hit, miss or error.
 func (c *Cache) GetByID(ctx context.Context, id model.RecipeID) (model.Recipe, bool, error) {
-	value, err := c.client.Get(ctx, recipeKey(id)).Result()
+	var value string
+	err := c.do(ctx, "get", c.opTimeout, func(ctx context.Context) error {
+		var err error
+		value, err = c.client.Get(ctx, recipeKey(id)).Result()
+		return err
+	})
 	if err == redis.Nil {
+		c.misses.Add(1)
 		return model.Recipe{}, false, err
 	}
 	if err != nil {
+		c.errors.Add(1)
 		return model.Recipe{}, false, err
 	}
 
 	var recipe model.Recipe
 	if err := json.Unmarshal([]byte(value), &recipe); err != nil {
+		c.errors.Add(1)
 		return model.Recipe{}, false, err
 	}
 
+	c.hits.Add(1)
 	return recipe, true, nil
 }
 
+// SetByID caches the recipe and indexes it under each of its tags.
 func (c *Cache) SetByID(ctx context.Context, recipe model.Recipe) error {
 	data, err := json.Marshal(&recipe)
 	if err != nil {
 		return err
 	}
-	return c.client.Set(ctx, recipeKey(recipe.ID), data, c.ttl).Err()
+
+	return c.do(ctx, "set", c.opTimeout, func(ctx context.Context) error {
+		_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, recipeKey(recipe.ID), data, c.ttl)
+			for _, tag := range recipe.Tags {
+				pipe.SAdd(ctx, tagKey(tag), string(recipe.ID))
+				pipe.Expire(ctx, tagKey(tag), c.ttl)
+			}
+			return nil
+		})
+		return err
+	})
 }
 
 // DeleteByID removes a recipe from the cache by ID.
 func (c *Cache) DeleteByID(ctx context.Context, id model.RecipeID) error {
-	return c.client.Del(ctx, recipeKey(id)).Err()
+	return c.do(ctx, "del", c.opTimeout, func(ctx context.Context) error {
+		return c.client.Del(ctx, recipeKey(id)).Err()
+	})
+}
+
+// GetByIDs returns the cached recipes among ids with a single MGET, recording
+// a hit or miss for each ID.
+func (c *Cache) GetByIDs(ctx context.Context, ids []model.RecipeID) (map[model.RecipeID]model.Recipe, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = recipeKey(id)
+	}
+
+	var values []any
+	err := c.do(ctx, "mget", c.opTimeout, func(ctx context.Context) error {
+		var err error
+		values, err = c.client.MGet(ctx, keys...).Result()
+		return err
+	})
+	if err != nil {
+		c.errors.Add(1)
+		return nil, err
+	}
+
+	found := make(map[model.RecipeID]model.Recipe, len(ids))
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			c.misses.Add(1)
+			continue
+		}
+		var recipe model.Recipe
+		if err := json.Unmarshal([]byte(data), &recipe); err != nil {
+			c.errors.Add(1)
+			continue
+		}
+		c.hits.Add(1)
+		found[ids[i]] = recipe
+	}
+	return found, nil
+}
+
+// SetByIDs caches many recipes and their tag indexes in one pipeline.
+func (c *Cache) SetByIDs(ctx context.Context, recipes []model.Recipe) error {
+	if len(recipes) == 0 {
+		return nil
+	}
+	data := make([][]byte, len(recipes))
+	for i := range recipes {
+		var err error
+		if data[i], err = json.Marshal(&recipes[i]); err != nil {
+			return err
+		}
+	}
+
+	return c.do(ctx, "mset", c.opTimeout, func(ctx context.Context) error {
+		_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, recipe := range recipes {
+				pipe.Set(ctx, recipeKey(recipe.ID), data[i], c.ttl)
+				for _, tag := range recipe.Tags {
+					pipe.SAdd(ctx, tagKey(tag), string(recipe.ID))
+					pipe.Expire(ctx, tagKey(tag), c.ttl)
+				}
+			}
+			return nil
+		})
+		return err
+	})
+}
+
+// DeleteByIDs removes many recipes from the cache with a single DEL.
+func (c *Cache) DeleteByIDs(ctx context.Context, ids []model.RecipeID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = recipeKey(id)
+	}
+	return c.do(ctx, "del", c.opTimeout, func(ctx context.Context) error {
+		return c.client.Del(ctx, keys...).Err()
+	})
+}
+
+// Inspect returns the cached entry for id without affecting hit/miss statistics.
+func (c *Cache) Inspect(ctx context.Context, id model.RecipeID) (Entry, bool, error) {
+	var (
+		get *redis.StringCmd
+		ttl *redis.DurationCmd
+	)
+	err := c.do(ctx, "inspect", c.opTimeout, func(ctx context.Context) error {
+		_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			get = pipe.Get(ctx, recipeKey(id))
+			ttl = pipe.PTTL(ctx, recipeKey(id))
+			return nil
+		})
+		return err
+	})
+	if err == redis.Nil {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	var recipe model.Recipe
+	if err := json.Unmarshal([]byte(get.Val()), &recipe); err != nil {
+		return Entry{}, false, err
+	}
+
+	return Entry{Recipe: recipe, TTL: ttl.Val()}, true, nil
+}
+
+// DeleteByTag evicts every cached recipe indexed under tag and returns how many were removed.
+func (c *Cache) DeleteByTag(ctx context.Context, tag string) (int64, error) {
+	var evicted int64
+	err := c.do(ctx, "del_tag", 0, func(ctx context.Context) error {
+		ids, err := c.client.SMembers(ctx, tagKey(tag)).Result()
+		if err != nil {
+			return err
+		}
+
+		keys := make([]string, 0, len(ids))
+		for _, id := range ids {
+			keys = append(keys, recipeKey(model.RecipeID(id)))
+		}
+
+		var del *redis.IntCmd
+		_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if len(keys) > 0 {
+				del = pipe.Del(ctx, keys...)
+			}
+			pipe.Del(ctx, tagKey(tag))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if del != nil {
+			evicted = del.Val()
+		}
+		return nil
+	})
+
+	return evicted, err
+}
+
+// Flush removes all cached recipes and tag indexes. View counts are kept so
+// that a later warm-up can still pick the most-viewed recipes.
+func (c *Cache) Flush(ctx context.Context) (int64, error) {
+	var removed int64
+	err := c.do(ctx, "flush", 0, func(ctx context.Context) error {
+		for _, pattern := range []string{keyPrefix + "*", tagPrefix + "*"} {
+			iter := c.client.Scan(ctx, 0, pattern, 100).Iterator()
+			for iter.Next(ctx) {
+				n, err := c.client.Del(ctx, iter.Val()).Result()
+				if err != nil {
+					return err
+				}
+				if pattern == keyPrefix+"*" {
+					removed += n
+				}
+			}
+			if err := iter.Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return removed, err
+}
+
+// RecordView increments the view counter used to rank recipes for warm-up.
+func (c *Cache) RecordView(ctx context.Context, id model.RecipeID) error {
+	return c.do(ctx, "zincrby", c.opTimeout, func(ctx context.Context) error {
+		return c.client.ZIncrBy(ctx, viewsKey, 1, string(id)).Err()
+	})
+}
+
+// RemoveViews drops the view counts of deleted recipes so warm-up no longer
+// ranks them.
+func (c *Cache) RemoveViews(ctx context.Context, ids []model.RecipeID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	members := make([]any, len(ids))
+	for i, id := range ids {
+		members[i] = string(id)
+	}
+	return c.do(ctx, "zrem", c.opTimeout, func(ctx context.Context) error {
+		return c.client.ZRem(ctx, viewsKey, members...).Err()
+	})
+}
+
+// TopViewed returns up to n recipe IDs ordered by descending view count.
+func (c *Cache) TopViewed(ctx context.Context, n int) ([]model.RecipeID, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	var members []string
+	err := c.do(ctx, "zrevrange", c.opTimeout, func(ctx context.Context) error {
+		var err error
+		members, err = c.client.ZRevRange(ctx, viewsKey, 0, int64(n-1)).Result()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]model.RecipeID, len(members))
+	for i, m := range members {
+		ids[i] = model.RecipeID(m)
+	}
+	return ids, nil
+}
+
+// Counters returns the cumulative hit, miss and error counts without contacting Redis.
+func (c *Cache) Counters() (hits, misses, errors int64) {
+	return c.hits.Load(), c.misses.Load(), c.errors.Load()
+}
+
+// Stats returns the lookup counters together with the number of cached recipes.
+func (c *Cache) Stats(ctx context.Context) (Stats, error) {
+	stats := Stats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Errors:  c.errors.Load(),
+		Breaker: c.breaker.State().String(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+
+	err := c.do(ctx, "scan", 0, func(ctx context.Context) error {
+		iter := c.client.Scan(ctx, 0, keyPrefix+"*", 100).Iterator()
+		for iter.Next(ctx) {
+			stats.Entries++
+		}
+		return iter.Err()
+	})
+
+	return stats, err
 }
@@ -2,9 +2,11 @@ package redisrecipe
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/gin-demo/recipes-web/internal/resilience"
 	"github.com/gin-demo/recipes-web/model"
 	"github.com/redis/go-redis/v9"
 )
@@ -222,3 +224,229 @@ func TestRecipeKey(t *testing.T) {
 		t.Errorf("Expected key %s, got %s", expected, key)
 	}
 }
+
+func TestCacheInspect(t *testing.T) {
+	client := setupTestRedis(t)
+	defer teardownTestRedis(t, client)
+
+	cache := NewCache(client, 1*time.Hour)
+	ctx := context.Background()
+
+	recipe := model.Recipe{ID: "test-recipe-5", Name: "Inspected Recipe"}
+	if err := cache.SetByID(ctx, recipe); err != nil {
+		t.Fatalf("SetByID failed: %v", err)
+	}
+
+	entry, found, err := cache.Inspect(ctx, recipe.ID)
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if !found {
+		t.Fatal("Expected entry to be found")
+	}
+	if entry.Recipe.Name != recipe.Name {
+		t.Errorf("Expected name %s, got %s", recipe.Name, entry.Recipe.Name)
+	}
+	if entry.TTL <= 0 || entry.TTL > 1*time.Hour {
+		t.Errorf("Unexpected TTL %v", entry.TTL)
+	}
+
+	// Inspect must not count as a lookup
+	stats, _ := cache.Stats(ctx)
+	if stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("Inspect should not affect stats, got %+v", stats)
+	}
+
+	_, found, err = cache.Inspect(ctx, "non-existent-id")
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if found {
+		t.Error("Expected entry not to be found")
+	}
+}
+
+func TestCacheDeleteByTag(t *testing.T) {
+	client := setupTestRedis(t)
+	defer teardownTestRedis(t, client)
+
+	cache := NewCache(client, 1*time.Hour)
+	ctx := context.Background()
+
+	recipes := []model.Recipe{
+		{ID: "tagged-1", Tags: []string{"italian", "main"}},
+		{ID: "tagged-2", Tags: []string{"italian"}},
+		{ID: "tagged-3", Tags: []string{"dessert"}},
+	}
+	for _, r := range recipes {
+		if err := cache.SetByID(ctx, r); err != nil {
+			t.Fatalf("SetByID failed: %v", err)
+		}
+	}
+
+	evicted, err := cache.DeleteByTag(ctx, "italian")
+	if err != nil {
+		t.Fatalf("DeleteByTag failed: %v", err)
+	}
+	if evicted != 2 {
+		t.Errorf("Expected 2 evicted, got %d", evicted)
+	}
+
+	if _, found, _ := cache.GetByID(ctx, "tagged-1"); found {
+		t.Error("tagged-1 should be evicted")
+	}
+	if _, found, _ := cache.GetByID(ctx, "tagged-3"); !found {
+		t.Error("tagged-3 should still be cached")
+	}
+
+	// Unknown tag is a no-op
+	evicted, err = cache.DeleteByTag(ctx, "unknown")
+	if err != nil || evicted != 0 {
+		t.Errorf("Expected no-op for unknown tag, got %d, %v", evicted, err)
+	}
+}
+
+func TestCacheFlush(t *testing.T) {
+	client := setupTestRedis(t)
+	defer teardownTestRedis(t, client)
+
+	cache := NewCache(client, 1*time.Hour)
+	ctx := context.Background()
+
+	for _, id := range []model.RecipeID{"flush-1", "flush-2"} {
+		_ = cache.SetByID(ctx, model.Recipe{ID: id, Tags: []string{"tag1"}})
+	}
+	_ = cache.RecordView(ctx, "flush-1")
+
+	removed, err := cache.Flush(ctx)
+	if err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Expected 2 removed, got %d", removed)
+	}
+
+	stats, _ := cache.Stats(ctx)
+	if stats.Entries != 0 {
+		t.Errorf("Expected no entries after flush, got %d", stats.Entries)
+	}
+
+	// View counts survive a flush
+	ids, _ := cache.TopViewed(ctx, 1)
+	if len(ids) != 1 || ids[0] != "flush-1" {
+		t.Errorf("Expected view counts to survive flush, got %v", ids)
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	client := setupTestRedis(t)
+	defer teardownTestRedis(t, client)
+
+	cache := NewCache(client, 1*time.Hour)
+	ctx := context.Background()
+
+	_ = cache.SetByID(ctx, model.Recipe{ID: "stats-1"})
+	cache.GetByID(ctx, "stats-1")
+	cache.GetByID(ctx, "stats-1")
+	cache.GetByID(ctx, "missing")
+
+	stats, err := cache.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("Expected 2 hits and 1 miss, got %+v", stats)
+	}
+	if stats.Entries != 1 {
+		t.Errorf("Expected 1 entry, got %d", stats.Entries)
+	}
+	if stats.HitRatio < 0.66 || stats.HitRatio > 0.67 {
+		t.Errorf("Unexpected hit ratio %f", stats.HitRatio)
+	}
+}
+
+func TestCacheTopViewed(t *testing.T) {
+	client := setupTestRedis(t)
+	defer teardownTestRedis(t, client)
+
+	cache := NewCache(client, 1*time.Hour)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_ = cache.RecordView(ctx, "popular")
+	}
+	_ = cache.RecordView(ctx, "rare")
+
+	ids, err := cache.TopViewed(ctx, 5)
+	if err != nil {
+		t.Fatalf("TopViewed failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "popular" {
+		t.Errorf("Expected popular first, got %v", ids)
+	}
+
+	ids, _ = cache.TopViewed(ctx, 0)
+	if len(ids) != 0 {
+		t.Errorf("Expected no IDs for zero limit, got %v", ids)
+	}
+
+	if err := cache.RemoveViews(ctx, []model.RecipeID{"popular"}); err != nil {
+		t.Fatalf("RemoveViews failed: %v", err)
+	}
+	if ids, _ = cache.TopViewed(ctx, 5); len(ids) != 1 || ids[0] != "rare" {
+		t.Errorf("Expected only rare left, got %v", ids)
+	}
+}
+
+func TestCacheBreakerBypassesUnavailableRedis(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:1", MaxRetries: -1})
+	defer client.Close()
+
+	breaker := resilience.NewBreaker(resilience.BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	cache := NewCache(client, time.Minute, WithBreaker(breaker), WithOperationTimeout(100*time.Millisecond))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := cache.GetByID(ctx, "any"); err == nil {
+			t.Fatal("Expected error from unreachable Redis")
+		}
+	}
+	if cache.BreakerState() != resilience.StateOpen {
+		t.Fatalf("Expected breaker to open, got %s", cache.BreakerState())
+	}
+
+	start := time.Now()
+	_, _, err := cache.GetByID(ctx, "any")
+	if !errors.Is(err, resilience.ErrOpen) {
+		t.Errorf("Expected ErrOpen, got %v", err)
+	}
+	if time.Since(start) > 10*time.Millisecond {
+		t.Error("Open breaker should short-circuit without contacting Redis")
+	}
+}
+
+func TestCacheMonitorReconnects(t *testing.T) {
+	client := setupTestRedis(t)
+	defer teardownTestRedis(t, client)
+
+	cache := NewCache(client, time.Minute)
+	cache.breaker.Trip()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		cache.Monitor(ctx, time.Hour)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for cache.BreakerState() != resilience.StateClosed && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if cache.BreakerState() != resilience.StateClosed {
+		t.Errorf("Expected monitor to close the breaker, got %s", cache.BreakerState())
+	}
+}
@@ -0,0 +1,331 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Config is the complete application configuration.
+type Config struct {
+	Server     ServerConfig
+	Repository RepositoryConfig
+	Mongo      MongoConfig
+	Redis      RedisConfig
+	Cache      CacheConfig
+	Auth       AuthConfig
+	Audit      AuditConfig
+	Images     ImagesConfig
+	Similar    SimilarConfig
+	Shopping   ShoppingConfig
+	Log        LogConfig
+	Tracing    TracingConfig
+	Validation ValidationConfig
+
+	// sources records where each setting came from, keyed like the config file
+	sources map[string]Source
+}
+
+// ServerConfig configures the HTTP server.
+type ServerConfig struct {
+	Addr            string
+	ShutdownTimeout time.Duration
+	// DrainDelay is how long /readyz fails before the server stops accepting connections
+	DrainDelay time.Duration
+}
+
+// RepositoryConfig selects the recipe storage backend.
+type RepositoryConfig struct {
+	// Type is memory or mongo
+	Type string
+	// DataPath is the memory backend's file
+	DataPath string
+	// SeedData upserts the recipes in SeedPath at startup
+	SeedData bool
+	// SeedPath defaults to DataPath
+	SeedPath string
+}
+
+// SeedFile returns the file to seed from, or "" when there is nothing to
+// seed. The memory backend is never seeded from its own data file.
+func (r RepositoryConfig) SeedFile() string {
+	if !r.SeedData {
+		return ""
+	}
+	path := r.SeedPath
+	if path == "" {
+		path = r.DataPath
+	}
+	if r.Type == "memory" && path == r.DataPath {
+		return ""
+	}
+	return path
+}
+
+// MongoConfig configures the MongoDB backend.
+type MongoConfig struct {
+	URI              string
+	Database         string
+	OperationTimeout time.Duration
+}
+
+// RedisConfig configures the Redis connection used by the cache.
+type RedisConfig struct {
+	Addr             string
+	Password         string
+	DB               int
+	OperationTimeout time.Duration
+}
+
+// CacheConfig configures recipe caching and warm-up.
+type CacheConfig struct {
+	TTL               time.Duration
+	MonitorInterval   time.Duration
+	WarmUp            bool
+	WarmUpLimit       int
+	WarmUpConcurrency int
+}
+
+// AuthConfig configures JWT issuing and validation.
+type AuthConfig struct {
+	JWTSecret string
+	Issuer    string
+	TokenTTL  time.Duration
+	// UsersFile stores accounts for the memory backend; Mongo uses the users collection
+	UsersFile string
+}
+
+// AuditConfig selects where the audit trail is stored.
+type AuditConfig struct {
+	// Sink is file or mongo
+	Sink string
+	File string
+}
+
+// ImagesConfig selects where uploaded recipe images are stored.
+type ImagesConfig struct {
+	// Store is fs or s3
+	Store string
+	// Dir is the fs store's directory
+	Dir string
+	// BaseURL prefixes the image URLs returned with recipes; the default
+	// /images is served by the application from the store
+	BaseURL string
+	// The S3 settings address a bucket of Amazon S3 or a compatible service
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3PathStyle       bool
+}
+
+// SimilarConfig configures the similar-recipe index.
+type SimilarConfig struct {
+	// RefreshInterval is how often the index is rebuilt from the repository,
+	// picking up changes made by other servers
+	RefreshInterval time.Duration
+}
+
+// ShoppingConfig configures where shopping lists are kept.
+type ShoppingConfig struct {
+	// ListsFile stores lists for the memory backend; Mongo uses the shopping_lists collection
+	ListsFile string
+}
+
+// LogConfig configures structured logging.
+type LogConfig struct {
+	Format string
+	Level  string
+}
+
+// TracingConfig configures OpenTelemetry span export.
+type TracingConfig struct {
+	ServiceName string
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+	File        string
+	SampleRatio float64
+}
+
+// ValidationConfig bounds the recipes accepted on create and update; zero disables a limit.
+type ValidationConfig struct {
+	MaxNameLength   int
+	MaxTags         int
+	MaxTagLength    int
+	MaxIngredients  int
+	MaxSteps        int
+	MaxEquipment    int
+	MaxItemLength   int
+	MaxPayloadBytes int
+	MaxBatchSize    int
+	MaxImages       int
+	MaxImageBytes   int
+	MaxImagePixels  int
+}
+
+// Default returns the configuration used when nothing else is set.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			ShutdownTimeout: 10 * time.Second,
+		},
+		Repository: RepositoryConfig{
+			Type:     "memory",
+			DataPath: "data/recipe.json",
+		},
+		Mongo: MongoConfig{
+			Database:         "recipes",
+			OperationTimeout: 5 * time.Second,
+		},
+		Redis: RedisConfig{
+			Addr:             "localhost:6379",
+			OperationTimeout: 250 * time.Millisecond,
+		},
+		Cache: CacheConfig{
+			TTL:               30 * time.Minute,
+			MonitorInterval:   5 * time.Second,
+			WarmUp:            true,
+			WarmUpLimit:       100,
+			WarmUpConcurrency: 8,
+		},
+		Auth: AuthConfig{
+			Issuer:    "recipe-app",
+			TokenTTL:  15 * time.Minute,
+			UsersFile: "data/users.json",
+		},
+		Audit: AuditConfig{
+			Sink: "file",
+			File: "audit.jsonl",
+		},
+		Images: ImagesConfig{
+			Store:    "fs",
+			Dir:      "data/images",
+			BaseURL:  "/images",
+			S3Region: "us-east-1",
+		},
+		Similar: SimilarConfig{
+			RefreshInterval: 15 * time.Minute,
+		},
+		Shopping: ShoppingConfig{
+			ListsFile: "data/shopping_lists.json",
+		},
+		Log: LogConfig{
+			Format: "text",
+			Level:  "info",
+		},
+		Tracing: TracingConfig{
+			ServiceName: "recipes-web",
+			Exporter:    "none",
+			File:        "traces.jsonl",
+			SampleRatio: 1,
+		},
+		Validation: ValidationConfig{
+			MaxNameLength:   200,
+			MaxTags:         20,
+			MaxTagLength:    40,
+			MaxIngredients:  100,
+			MaxSteps:        200,
+			MaxEquipment:    50,
+			MaxItemLength:   2000,
+			MaxPayloadBytes: 64 << 10,
+			MaxBatchSize:    500,
+			MaxImages:       20,
+			MaxImageBytes:   10 << 20,
+			MaxImagePixels:  40_000_000,
+		},
+	}
+}
+
+// Validate checks the configuration and reports every problem at once.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Addr != "", "server.addr is required")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	check(c.Server.DrainDelay >= 0, "server.drain_delay must not be negative")
+
+	check(oneOf(c.Repository.Type, "memory", "mongo"), "repository.type must be memory or mongo, got %q", c.Repository.Type)
+	check(c.Repository.DataPath != "" || c.Repository.Type != "memory", "repository.data_path is required for the memory backend")
+	check(c.Repository.DataPath != "" || c.Repository.SeedPath != "" || !c.Repository.SeedData, "repository.seed_path or repository.data_path is required when repository.seed_data is set")
+
+	if c.Repository.Type == "mongo" {
+		check(c.Mongo.URI != "", "mongo.uri is required when repository.type is mongo")
+		check(c.Mongo.Database != "", "mongo.database is required when repository.type is mongo")
+	}
+	check(c.Mongo.OperationTimeout > 0, "mongo.operation_timeout must be positive")
+
+	check(c.Redis.DB >= 0, "redis.db must not be negative")
+	check(c.Redis.OperationTimeout > 0, "redis.operation_timeout must be positive")
+
+	check(c.Cache.TTL > 0, "cache.ttl must be positive")
+	check(c.Cache.MonitorInterval > 0, "cache.monitor_interval must be positive")
+	check(c.Cache.WarmUpLimit >= 0, "cache.warmup_limit must not be negative")
+	check(c.Cache.WarmUpConcurrency > 0, "cache.warmup_concurrency must be positive")
+
+	check(c.Auth.JWTSecret != "", "auth.jwt_secret is required")
+	check(c.Auth.TokenTTL > 0, "auth.token_ttl must be positive")
+	check(c.Auth.UsersFile != "" || c.Repository.Type != "memory", "auth.users_file is required for the memory backend")
+
+	check(oneOf(c.Audit.Sink, "file", "mongo"), "audit.sink must be file or mongo, got %q", c.Audit.Sink)
+	check(c.Audit.Sink != "file" || c.Audit.File != "", "audit.file is required for the file sink")
+	check(c.Audit.Sink != "mongo" || c.Repository.Type == "mongo", "audit.sink mongo requires repository.type mongo")
+
+	check(oneOf(c.Images.Store, "fs", "s3"), "images.store must be fs or s3, got %q", c.Images.Store)
+	check(c.Images.Store != "fs" || c.Images.Dir != "", "images.dir is required for the fs store")
+	check(c.Images.BaseURL != "", "images.base_url is required")
+	if c.Images.Store == "s3" {
+		check(c.Images.S3Endpoint != "", "images.s3_endpoint is required for the s3 store")
+		check(c.Images.S3Bucket != "", "images.s3_bucket is required for the s3 store")
+		check(c.Images.S3Region != "", "images.s3_region is required for the s3 store")
+	}
+
+	check(c.Similar.RefreshInterval > 0, "similar.refresh_interval must be positive")
+	check(c.Shopping.ListsFile != "" || c.Repository.Type != "memory", "shopping.lists_file is required for the memory backend")
+
+	check(oneOf(c.Log.Format, "text", "json"), "log.format must be text or json, got %q", c.Log.Format)
+	check(oneOf(c.Log.Level, "debug", "info", "warn", "error"), "log.level must be debug, info, warn or error, got %q", c.Log.Level)
+
+	check(oneOf(c.Tracing.Exporter, "none", "otlp", "stdout", "file"), "tracing.exporter must be none, otlp, stdout or file, got %q", c.Tracing.Exporter)
+	check(c.Tracing.Exporter != "file" || c.Tracing.File != "", "tracing.file is required for the file exporter")
+	check(c.Tracing.SampleRatio > 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be greater than 0 and at most 1")
+
+	v := c.Validation
+	for _, limit := range []struct {
+		key   string
+		value int
+	}{
+		{"validation.max_name_length", v.MaxNameLength},
+		{"validation.max_tags", v.MaxTags},
+		{"validation.max_tag_length", v.MaxTagLength},
+		{"validation.max_ingredients", v.MaxIngredients},
+		{"validation.max_steps", v.MaxSteps},
+		{"validation.max_equipment", v.MaxEquipment},
+		{"validation.max_item_length", v.MaxItemLength},
+		{"validation.max_payload_bytes", v.MaxPayloadBytes},
+		{"validation.max_batch_size", v.MaxBatchSize},
+		{"validation.max_images", v.MaxImages},
+		{"validation.max_image_bytes", v.MaxImageBytes},
+		{"validation.max_image_pixels", v.MaxImagePixels},
+	} {
+		check(limit.value >= 0, "%s must not be negative", limit.key)
+	}
+
+	return errors.Join(errs...)
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func env(values map[string]string) LookupEnvFunc {
+	return func(key string) (string, bool) {
+		v, ok := values[key]
+		return v, ok
+	}
+}
+
+func load(args []string, values map[string]string) (Config, error) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return Load(fs, args, env(values))
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := load(nil, map[string]string{"JWT_SECRET": "s"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Server.Addr != ":8080" || cfg.Repository.Type != "memory" || cfg.Cache.TTL != 30*time.Minute {
+		t.Fatalf("unexpected defaults: %+v", cfg)
+	}
+	if cfg.sources["server.addr"] != SourceDefault || cfg.sources["auth.jwt_secret"] != SourceEnv {
+		t.Fatalf("unexpected sources: %v", cfg.sources)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  addr: ":7000"
+cache:
+  ttl: 1m
+  warmup_limit: 5
+log:
+  level: debug
+auth:
+  jwt_secret: from-file
+`)
+
+	cfg, err := load(
+		[]string{"-config", path, "-cache-ttl", "3m"},
+		map[string]string{"CACHE_TTL": "2m", "CACHE_WARMUP_LIMIT": "7", "PORT": "9000"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Cache.TTL != 3*time.Minute || cfg.sources["cache.ttl"] != SourceFlag {
+		t.Fatalf("expected flag to win, got %v from %s", cfg.Cache.TTL, cfg.sources["cache.ttl"])
+	}
+	if cfg.Cache.WarmUpLimit != 7 || cfg.sources["cache.warmup_limit"] != SourceEnv {
+		t.Fatalf("expected env to beat file, got %d", cfg.Cache.WarmUpLimit)
+	}
+	if cfg.Log.Level != "debug" || cfg.sources["log.level"] != SourceFile {
+		t.Fatalf("expected file to beat default, got %q", cfg.Log.Level)
+	}
+	if cfg.Server.Addr != ":9000" {
+		t.Fatalf("expected PORT to override the file, got %q", cfg.Server.Addr)
+	}
+}
+
+func TestLoad_HTTPAddrBeatsPort(t *testing.T) {
+	cfg, err := load(nil, map[string]string{"JWT_SECRET": "s", "PORT": "9000", "HTTP_ADDR": "127.0.0.1:9100"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Server.Addr != "127.0.0.1:9100" {
+		t.Fatalf("expected HTTP_ADDR, got %q", cfg.Server.Addr)
+	}
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[repository]
+type = "mongo"
+seed_data = true
+
+[mongo]
+uri = "mongodb://app:hunter2@db:27017"
+
+[redis]
+db = 2
+
+[auth]
+jwt_secret = "s"
+
+[tracing]
+sample_ratio = 0.25
+`)
+
+	cfg, err := load(nil, map[string]string{ConfigFileEnv: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Repository.Type != "mongo" || !cfg.Repository.SeedData || cfg.Redis.DB != 2 || cfg.Tracing.SampleRatio != 0.25 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoad_AggregatesErrors(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+cache:
+  ttl: soon
+  colour: blue
+`)
+
+	_, err := load([]string{"-config", path}, map[string]string{
+		"REDIS_DB":   "two",
+		"REPO_TYPE":  "postgres",
+		"LOG_FORMAT": "xml",
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, want := range []string{"cache.ttl from file", `unknown setting "cache.colour"`, "redis.db from env"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+
+	_, err = load(nil, map[string]string{"REPO_TYPE": "postgres", "LOG_FORMAT": "xml", "RECIPE_MAX_TAGS": "-1", "IMAGE_STORE": "s3"})
+	if err == nil {
+		t.Fatal("expected a validation error")
+	}
+	for _, want := range []string{"repository.type", "log.format", "auth.jwt_secret", "validation.max_tags", "images.s3_endpoint", "images.s3_bucket"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
+
+func TestLoad_UnsupportedFile(t *testing.T) {
+	path := writeFile(t, "config.json", `{}`)
+	if _, err := load([]string{"-config", path}, nil); err == nil {
+		t.Fatal("expected an error for a .json file")
+	}
+}
+
+func TestPrint_MasksSecrets(t *testing.T) {
+	cfg, err := load([]string{"-redis-password", "p4ss"}, map[string]string{
+		"JWT_SECRET": "top-secret",
+		"REPO_TYPE":  "mongo",
+		"MONGO_URI":  "mongodb://app:hunter2@db:27017/?authSource=admin",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	cfg.Print(&buf)
+	out := buf.String()
+
+	for _, secret := range []string{"top-secret", "hunter2", "p4ss"} {
+		if strings.Contains(out, secret) {
+			t.Fatalf("secret %q leaked:\n%s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "db:27017") {
+		t.Fatalf("expected the mongo host to stay visible:\n%s", out)
+	}
+	if !strings.Contains(out, "(flag)") || !strings.Contains(out, "(env)") {
+		t.Fatalf("expected sources in output:\n%s", out)
+	}
+}
@@ -0,0 +1,387 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Source tells where the effective value of a setting came from.
+type Source string
+
+// Sources in increasing order of precedence.
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// ConfigFileEnv names the environment variable that points at a config file;
+// the -config flag takes precedence over it.
+const ConfigFileEnv = "CONFIG_FILE"
+
+// masked replaces secret values when the configuration is printed.
+const masked = "****"
+
+// setting binds one configuration value to its file key, environment variable
+// and command-line flag.
+type setting struct {
+	key    string
+	env    string
+	usage  string
+	secret bool
+	set    func(*Config, string) error
+	get    func(*Config) string
+}
+
+func stringSetting(key, env, usage string, field func(*Config) *string) setting {
+	return setting{
+		key: key, env: env, usage: usage,
+		set: func(c *Config, v string) error { *field(c) = v; return nil },
+		get: func(c *Config) string { return *field(c) },
+	}
+}
+
+func secretSetting(key, env, usage string, field func(*Config) *string) setting {
+	s := stringSetting(key, env, usage, field)
+	s.secret = true
+	return s
+}
+
+func boolSetting(key, env, usage string, field func(*Config) *bool) setting {
+	return setting{
+		key: key, env: env, usage: usage,
+		set: func(c *Config, v string) error {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("expected true or false, got %q", v)
+			}
+			*field(c) = b
+			return nil
+		},
+		get: func(c *Config) string { return strconv.FormatBool(*field(c)) },
+	}
+}
+
+func intSetting(key, env, usage string, field func(*Config) *int) setting {
+	return setting{
+		key: key, env: env, usage: usage,
+		set: func(c *Config, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("expected an integer, got %q", v)
+			}
+			*field(c) = n
+			return nil
+		},
+		get: func(c *Config) string { return strconv.Itoa(*field(c)) },
+	}
+}
+
+func floatSetting(key, env, usage string, field func(*Config) *float64) setting {
+	return setting{
+		key: key, env: env, usage: usage,
+		set: func(c *Config, v string) error {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return fmt.Errorf("expected a number, got %q", v)
+			}
+			*field(c) = f
+			return nil
+		},
+		get: func(c *Config) string { return strconv.FormatFloat(*field(c), 'g', -1, 64) },
+	}
+}
+
+func durationSetting(key, env, usage string, field func(*Config) *time.Duration) setting {
+	return setting{
+		key: key, env: env, usage: usage,
+		set: func(c *Config, v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("expected a duration such as 5s, got %q", v)
+			}
+			*field(c) = d
+			return nil
+		},
+		get: func(c *Config) string { return field(c).String() },
+	}
+}
+
+// settings lists every configurable value. Environment variable names are
+// kept compatible with earlier releases.
+var settings = []setting{
+	stringSetting("server.addr", "HTTP_ADDR", "HTTP listen address", func(c *Config) *string { return &c.Server.Addr }),
+	durationSetting("server.shutdown_timeout", "SHUTDOWN_TIMEOUT", "time allowed for in-flight requests on shutdown", func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout }),
+	durationSetting("server.drain_delay", "SHUTDOWN_DRAIN_DELAY", "time /readyz fails before the server stops accepting connections", func(c *Config) *time.Duration { return &c.Server.DrainDelay }),
+
+	stringSetting("repository.type", "REPO_TYPE", "recipe backend: memory or mongo", func(c *Config) *string { return &c.Repository.Type }),
+	stringSetting("repository.data_path", "DATA_PATH", "recipe data file", func(c *Config) *string { return &c.Repository.DataPath }),
+	boolSetting("repository.seed_data", "SEED_DATA", "upsert the seed file's recipes at startup", func(c *Config) *bool { return &c.Repository.SeedData }),
+	stringSetting("repository.seed_path", "SEED_PATH", "json, ndjson or csv seed file, or a .cook file or directory; defaults to the data file", func(c *Config) *string { return &c.Repository.SeedPath }),
+
+	secretSetting("mongo.uri", "MONGO_URI", "MongoDB connection string", func(c *Config) *string { return &c.Mongo.URI }),
+	stringSetting("mongo.database", "MONGO_DATABASE", "MongoDB database name", func(c *Config) *string { return &c.Mongo.Database }),
+	durationSetting("mongo.operation_timeout", "MONGO_OPERATION_TIMEOUT", "deadline for each MongoDB call", func(c *Config) *time.Duration { return &c.Mongo.OperationTimeout }),
+
+	stringSetting("redis.addr", "REDIS_ADDR", "Redis address", func(c *Config) *string { return &c.Redis.Addr }),
+	secretSetting("redis.password", "REDIS_PASSWORD", "Redis password", func(c *Config) *string { return &c.Redis.Password }),
+	intSetting("redis.db", "REDIS_DB", "Redis database number", func(c *Config) *int { return &c.Redis.DB }),
+	durationSetting("redis.operation_timeout", "REDIS_OPERATION_TIMEOUT", "deadline for each Redis call", func(c *Config) *time.Duration { return &c.Redis.OperationTimeout }),
+
+	durationSetting("cache.ttl", "CACHE_TTL", "time cached recipes live", func(c *Config) *time.Duration { return &c.Cache.TTL }),
+	durationSetting("cache.monitor_interval", "CACHE_MONITOR_INTERVAL", "interval between Redis health pings", func(c *Config) *time.Duration { return &c.Cache.MonitorInterval }),
+	boolSetting("cache.warmup", "CACHE_WARMUP", "preload the cache at startup", func(c *Config) *bool { return &c.Cache.WarmUp }),
+	intSetting("cache.warmup_limit", "CACHE_WARMUP_LIMIT", "number of most-viewed recipes to preload; 0 preloads all", func(c *Config) *int { return &c.Cache.WarmUpLimit }),
+	intSetting("cache.warmup_concurrency", "CACHE_WARMUP_CONCURRENCY", "parallel loads during warm-up", func(c *Config) *int { return &c.Cache.WarmUpConcurrency }),
+
+	secretSetting("auth.jwt_secret", "JWT_SECRET", "HMAC key for signing tokens", func(c *Config) *string { return &c.Auth.JWTSecret }),
+	stringSetting("auth.issuer", "JWT_ISSUER", "issuer claim of signed tokens", func(c *Config) *string { return &c.Auth.Issuer }),
+	durationSetting("auth.token_ttl", "JWT_TOKEN_TTL", "lifetime of signed tokens", func(c *Config) *time.Duration { return &c.Auth.TokenTTL }),
+	stringSetting("auth.users_file", "USERS_FILE", "account file for the memory backend", func(c *Config) *string { return &c.Auth.UsersFile }),
+
+	stringSetting("audit.sink", "AUDIT_SINK", "audit storage: file or mongo", func(c *Config) *string { return &c.Audit.Sink }),
+	stringSetting("audit.file", "AUDIT_FILE", "audit file for the file sink", func(c *Config) *string { return &c.Audit.File }),
+
+	stringSetting("images.store", "IMAGE_STORE", "image storage: fs or s3", func(c *Config) *string { return &c.Images.Store }),
+	stringSetting("images.dir", "IMAGE_DIR", "image directory for the fs store", func(c *Config) *string { return &c.Images.Dir }),
+	stringSetting("images.base_url", "IMAGE_BASE_URL", "prefix of the image URLs returned with recipes", func(c *Config) *string { return &c.Images.BaseURL }),
+	stringSetting("images.s3_endpoint", "S3_ENDPOINT", "S3 or compatible service URL for the s3 store", func(c *Config) *string { return &c.Images.S3Endpoint }),
+	stringSetting("images.s3_region", "S3_REGION", "S3 region", func(c *Config) *string { return &c.Images.S3Region }),
+	stringSetting("images.s3_bucket", "S3_BUCKET", "S3 bucket", func(c *Config) *string { return &c.Images.S3Bucket }),
+	stringSetting("images.s3_access_key_id", "S3_ACCESS_KEY_ID", "S3 access key ID", func(c *Config) *string { return &c.Images.S3AccessKeyID }),
+	secretSetting("images.s3_secret_access_key", "S3_SECRET_ACCESS_KEY", "S3 secret access key", func(c *Config) *string { return &c.Images.S3SecretAccessKey }),
+	boolSetting("images.s3_path_style", "S3_PATH_STYLE", "address the bucket in the path, as most S3-compatible services expect", func(c *Config) *bool { return &c.Images.S3PathStyle }),
+
+	durationSetting("similar.refresh_interval", "SIMILAR_REFRESH_INTERVAL", "interval between rebuilds of the similar-recipe index", func(c *Config) *time.Duration { return &c.Similar.RefreshInterval }),
+	stringSetting("shopping.lists_file", "SHOPPING_LISTS_FILE", "shopping list file for the memory backend", func(c *Config) *string { return &c.Shopping.ListsFile }),
+
+	stringSetting("log.format", "LOG_FORMAT", "log format: text or json", func(c *Config) *string { return &c.Log.Format }),
+	stringSetting("log.level", "LOG_LEVEL", "minimum log level", func(c *Config) *string { return &c.Log.Level }),
+
+	stringSetting("tracing.service_name", "OTEL_SERVICE_NAME", "service name reported on spans", func(c *Config) *string { return &c.Tracing.ServiceName }),
+	stringSetting("tracing.exporter", "OTEL_TRACES_EXPORTER", "span exporter: none, otlp, stdout or file", func(c *Config) *string { return &c.Tracing.Exporter }),
+	stringSetting("tracing.endpoint", "TRACING_ENDPOINT", "OTLP/HTTP collector host:port; empty uses the OTEL_EXPORTER_OTLP_* variables", func(c *Config) *string { return &c.Tracing.Endpoint }),
+	boolSetting("tracing.insecure", "TRACING_INSECURE", "disable TLS for the OTLP exporter", func(c *Config) *bool { return &c.Tracing.Insecure }),
+	stringSetting("tracing.file", "OTEL_TRACES_FILE", "span file for the file exporter", func(c *Config) *string { return &c.Tracing.File }),
+	floatSetting("tracing.sample_ratio", "OTEL_TRACES_SAMPLER_ARG", "fraction of new traces recorded", func(c *Config) *float64 { return &c.Tracing.SampleRatio }),
+
+	intSetting("validation.max_name_length", "RECIPE_MAX_NAME_LENGTH", "longest recipe name in characters; 0 disables the limit", func(c *Config) *int { return &c.Validation.MaxNameLength }),
+	intSetting("validation.max_tags", "RECIPE_MAX_TAGS", "most tags per recipe", func(c *Config) *int { return &c.Validation.MaxTags }),
+	intSetting("validation.max_tag_length", "RECIPE_MAX_TAG_LENGTH", "longest tag in characters", func(c *Config) *int { return &c.Validation.MaxTagLength }),
+	intSetting("validation.max_ingredients", "RECIPE_MAX_INGREDIENTS", "most ingredients per recipe", func(c *Config) *int { return &c.Validation.MaxIngredients }),
+	intSetting("validation.max_steps", "RECIPE_MAX_STEPS", "most instruction steps per recipe", func(c *Config) *int { return &c.Validation.MaxSteps }),
+	intSetting("validation.max_equipment", "RECIPE_MAX_EQUIPMENT", "most equipment entries per recipe", func(c *Config) *int { return &c.Validation.MaxEquipment }),
+	intSetting("validation.max_item_length", "RECIPE_MAX_ITEM_LENGTH", "longest ingredient or step in characters", func(c *Config) *int { return &c.Validation.MaxItemLength }),
+	intSetting("validation.max_payload_bytes", "RECIPE_MAX_PAYLOAD_BYTES", "largest recipe or request body in bytes", func(c *Config) *int { return &c.Validation.MaxPayloadBytes }),
+	intSetting("validation.max_batch_size", "RECIPE_MAX_BATCH_SIZE", "most items in one batch request", func(c *Config) *int { return &c.Validation.MaxBatchSize }),
+	intSetting("validation.max_images", "RECIPE_MAX_IMAGES", "most images per recipe", func(c *Config) *int { return &c.Validation.MaxImages }),
+	intSetting("validation.max_image_bytes", "RECIPE_MAX_IMAGE_BYTES", "largest uploaded image in bytes", func(c *Config) *int { return &c.Validation.MaxImageBytes }),
+	intSetting("validation.max_image_pixels", "RECIPE_MAX_IMAGE_PIXELS", "most pixels, width times height, of an uploaded image", func(c *Config) *int { return &c.Validation.MaxImagePixels }),
+}
+
+// flagName turns a file key such as cache.warmup_limit into cache-warmup-limit.
+func flagName(key string) string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(key)
+}
+
+// LookupEnvFunc looks up an environment variable, like os.LookupEnv.
+type LookupEnvFunc func(string) (string, bool)
+
+// Load builds the configuration from defaults, an optional YAML or TOML file,
+// environment variables and command-line flags, each overriding the previous
+// one, then validates it. Every problem is reported together. The settings'
+// flags are added to fs, so callers can register flags of their own first.
+// A nil lookupEnv ignores the environment.
+func Load(fs *flag.FlagSet, args []string, lookupEnv LookupEnvFunc) (Config, error) {
+	if lookupEnv == nil {
+		lookupEnv = func(string) (string, bool) { return "", false }
+	}
+
+	cfg := Default()
+	cfg.sources = make(map[string]Source, len(settings))
+	for _, s := range settings {
+		cfg.sources[s.key] = SourceDefault
+	}
+
+	configFile := fs.String("config", "", "path to a YAML or TOML config file (env "+ConfigFileEnv+")")
+	flagValues := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		usage := fmt.Sprintf("%s (env %s, default %q)", s.usage, s.env, s.get(&cfg))
+		flagValues[flagName(s.key)] = fs.String(flagName(s.key), "", usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	var errs []error
+	apply := func(s setting, value string, source Source) {
+		if err := s.set(&cfg, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s from %s: %w", s.key, source, err))
+			return
+		}
+		cfg.sources[s.key] = source
+	}
+
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv(ConfigFileEnv)
+	}
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		for _, s := range settings {
+			if v, ok := values[s.key]; ok {
+				apply(s, v, SourceFile)
+				delete(values, s.key)
+			}
+		}
+		for key := range values {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %q", path, key))
+		}
+	}
+
+	// PORT predates HTTP_ADDR and is still honoured when HTTP_ADDR is unset.
+	if port, ok := lookupEnv("PORT"); ok && port != "" {
+		if addr, _ := lookupEnv("HTTP_ADDR"); addr == "" {
+			cfg.Server.Addr = ":" + port
+			cfg.sources["server.addr"] = SourceEnv
+		}
+	}
+	for _, s := range settings {
+		if v, ok := lookupEnv(s.env); ok && v != "" {
+			apply(s, v, SourceEnv)
+		}
+	}
+
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	for _, s := range settings {
+		if name := flagName(s.key); given[name] {
+			apply(s, *flagValues[name], SourceFlag)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// readFile parses a YAML or TOML file, chosen by extension, into dotted keys
+// such as cache.ttl with their values as strings.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	raw := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("config: unsupported file type %q, use .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: parse %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", raw, values)
+	return values, nil
+}
+
+func flatten(prefix string, in map[string]any, out map[string]string) {
+	for k, v := range in {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]any); ok {
+			flatten(key, nested, out)
+			continue
+		}
+		out[key] = fmt.Sprint(v)
+	}
+}
+
+// Setting is one effective configuration value as shown to operators.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source Source `json:"source"`
+}
+
+// Effective lists every setting with its value and source. Secrets are masked,
+// and so are passwords embedded in connection strings.
+func (c Config) Effective() []Setting {
+	out := make([]Setting, 0, len(settings))
+	for _, s := range settings {
+		value := s.get(&c)
+		switch {
+		case s.secret && s.key == "mongo.uri":
+			value = maskURI(value)
+		case s.secret && value != "":
+			value = masked
+		}
+
+		source := c.sources[s.key]
+		if source == "" {
+			source = SourceDefault
+		}
+		out = append(out, Setting{Key: s.key, Value: value, Source: source})
+	}
+	return out
+}
+
+// Print writes the effective configuration to w, one setting per line.
+func (c Config) Print(w io.Writer) {
+	for _, s := range c.Effective() {
+		fmt.Fprintf(w, "%-28s %-40s (%s)\n", s.Key, s.Value, s.Source)
+	}
+}
+
+// LogValue logs the effective configuration with secrets masked.
+func (c Config) LogValue() slog.Value {
+	settings := c.Effective()
+	attrs := make([]slog.Attr, 0, len(settings))
+	for _, s := range settings {
+		attrs = append(attrs, slog.String(s.Key, s.Value))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// maskURI hides the password of a connection string but keeps the host visible.
+func maskURI(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return masked
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), masked)
+	}
+	return u.String()
+}
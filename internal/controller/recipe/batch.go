@@ -0,0 +1,300 @@
+package recipe
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/gin-demo/recipes-web/internal/audit"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/normalize"
+	"github.com/gin-demo/recipes-web/internal/similar"
+	"github.com/gin-demo/recipes-web/internal/tracing"
+	"github.com/gin-demo/recipes-web/model"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BatchResult is the outcome of one item of a batch, in input order.
+type BatchResult struct {
+	// Recipe is the stored recipe; it is empty for deletes and failed items
+	Recipe model.Recipe
+	// Duplicates are the stored recipes a created one looks like
+	Duplicates []domain.Duplicate
+	// Err is nil when the item succeeded. Items of an aborted atomic batch that
+	// did not fail themselves carry domain.ErrBatchAborted.
+	Err error
+}
+
+// CreateRecipes normalizes, validates and creates many recipes in one
+// repository call. In atomic mode an invalid recipe, or one rejected as a
+// duplicate, aborts the whole batch. The error is domain.ErrBatchAborted for
+// an aborted batch, or the storage error that failed it; the results are
+// complete either way.
+func (ctrl *Controller) CreateRecipes(ctx context.Context, recipes []model.Recipe, mode domain.BatchMode, dup DuplicateMode) ([]BatchResult, error) {
+	ctx, span := ctrl.startBatch(ctx, "Controller.CreateRecipes", len(recipes), mode)
+	results, err := ctrl.createRecipes(ctx, recipes, mode, dup)
+	tracing.End(span, err)
+
+	for _, result := range results {
+		ev := audit.Event{Action: audit.ActionRecipeCreate, Target: string(result.Recipe.ID)}
+		if result.Err == nil {
+			ev.AfterHash = audit.HashRecipe(result.Recipe)
+			ctrl.similar.Put(result.Recipe)
+		}
+		ctrl.record(ctx, ev, result.Err)
+	}
+	return results, err
+}
+
+func (ctrl *Controller) createRecipes(ctx context.Context, recipes []model.Recipe, mode domain.BatchMode, dup DuplicateMode) ([]BatchResult, error) {
+	if err := ctrl.checkBatch("recipes", len(recipes)); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(recipes))
+	valid := make([]model.Recipe, 0, len(recipes))
+	index := make([]int, 0, len(recipes))
+	// accepted holds the valid items so later ones are checked against them
+	// too; matches are the accepted items each item looks like
+	accepted := similar.NewBatch()
+	matches := make([][]similar.BatchMatch, len(recipes))
+	for i, recipe := range recipes {
+		recipe = normalize.Apply(recipe)
+		if err := ctrl.validateNew(recipe); err != nil {
+			results[i].Err = err
+			continue
+		}
+		dups, err := ctrl.checkDuplicates(recipe, dup)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		matches[i] = accepted.Duplicates(recipe)
+		if len(matches[i]) > 0 && dup == DuplicatesReject {
+			results[i].Err = &domain.DuplicateError{}
+			continue
+		}
+		results[i].Duplicates = dups
+		accepted.Add(recipe)
+		valid = append(valid, recipe)
+		index = append(index, i)
+	}
+
+	var err error
+	switch {
+	case mode == domain.BatchAtomic && len(valid) < len(recipes):
+		results, err = abort(results)
+	case len(valid) > 0:
+		created, errs, createErr := domain.Batch(ctrl.repo).CreateMany(ctx, valid, mode)
+		results, err = merge(results, index, created, errs, createErr)
+	}
+	addBatchDuplicates(results, valid, index, matches)
+	return results, err
+}
+
+// addBatchDuplicates reports the earlier items of a batch each item looks
+// like, in its *domain.DuplicateError when it was rejected and beside the
+// stored recipes it looks like otherwise. An item is known by the ID it was
+// created with, and only by its name when it was not created.
+func addBatchDuplicates(results []BatchResult, valid []model.Recipe, index []int, matches [][]similar.BatchMatch) {
+	for i, found := range matches {
+		if len(found) == 0 {
+			continue
+		}
+		dups := make([]domain.Duplicate, len(found))
+		for k, m := range found {
+			dups[k] = domain.Duplicate{ID: results[index[m.Index]].Recipe.ID, Name: valid[m.Index].Name, Score: m.Score}
+		}
+
+		var derr *domain.DuplicateError
+		if errors.As(results[i].Err, &derr) {
+			derr.Duplicates = dups[:min(len(dups), MaxDuplicates)]
+			continue
+		}
+		if errors.Is(results[i].Err, domain.ErrBatchAborted) {
+			continue
+		}
+		dups = append(results[i].Duplicates, dups...)
+		slices.SortStableFunc(dups, func(a, b domain.Duplicate) int { return cmp.Compare(b.Score, a.Score) })
+		results[i].Duplicates = dups[:min(len(dups), MaxDuplicates)]
+	}
+}
+
+// GetRecipes retrieves many recipes by ID in one repository call.
+func (ctrl *Controller) GetRecipes(ctx context.Context, ids []model.RecipeID) ([]BatchResult, error) {
+	ctx, span := ctrl.startBatch(ctx, "Controller.GetRecipes", len(ids), domain.BatchBestEffort)
+	results, err := ctrl.getRecipes(ctx, ids)
+	tracing.End(span, err)
+	return results, err
+}
+
+func (ctrl *Controller) getRecipes(ctx context.Context, ids []model.RecipeID) ([]BatchResult, error) {
+	if err := ctrl.checkBatch("ids", len(ids)); err != nil {
+		return nil, err
+	}
+
+	recipes, errs, err := domain.Batch(ctrl.repo).GetMany(ctx, ids)
+	return merge(make([]BatchResult, len(ids)), identity(len(ids)), recipes, errs, err)
+}
+
+// UpdateRecipes applies many update commands in one repository call,
+// validating the fields each one changes. In atomic mode a missing or invalid
+// recipe aborts the whole batch.
+func (ctrl *Controller) UpdateRecipes(ctx context.Context, cmds []BatchUpdateCommand, mode domain.BatchMode) ([]BatchResult, error) {
+	ctx, span := ctrl.startBatch(ctx, "Controller.UpdateRecipes", len(cmds), mode)
+	before, results, err := ctrl.updateRecipes(ctx, cmds, mode)
+	tracing.End(span, err)
+
+	for i, result := range results {
+		ev := audit.Event{Action: audit.ActionRecipeUpdate, Target: string(cmds[i].ID)}
+		if before[i].ID != "" {
+			ev.BeforeHash = audit.HashRecipe(before[i])
+		}
+		if result.Err == nil {
+			ev.AfterHash = audit.HashRecipe(result.Recipe)
+			ctrl.similar.Put(result.Recipe)
+		}
+		ctrl.record(ctx, ev, result.Err)
+	}
+	return results, err
+}
+
+// updateRecipes returns the recipes as they were before the batch along with the results.
+func (ctrl *Controller) updateRecipes(ctx context.Context, cmds []BatchUpdateCommand, mode domain.BatchMode) ([]model.Recipe, []BatchResult, error) {
+	if err := ctrl.checkBatch("updates", len(cmds)); err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]model.RecipeID, len(cmds))
+	for i, cmd := range cmds {
+		ids[i] = cmd.ID
+	}
+	before, errs, err := domain.Batch(ctrl.repo).GetMany(ctx, ids)
+	if err != nil {
+		results, err := merge(make([]BatchResult, len(cmds)), identity(len(cmds)), nil, nil, err)
+		return make([]model.Recipe, len(cmds)), results, err
+	}
+
+	results := make([]BatchResult, len(cmds))
+	valid := make([]model.Recipe, 0, len(cmds))
+	index := make([]int, 0, len(cmds))
+	for i, cmd := range cmds {
+		if errs[i] != nil {
+			results[i].Err = errs[i]
+			continue
+		}
+		updated := normalize.Apply(cmd.apply(before[i]))
+		if err := ctrl.validateUpdate(updated, cmd.UpdateRecipeCommand); err != nil {
+			results[i].Err = err
+			continue
+		}
+		valid = append(valid, updated)
+		index = append(index, i)
+	}
+	if mode == domain.BatchAtomic && len(valid) < len(cmds) {
+		results, err := abort(results)
+		return before, results, err
+	}
+	if len(valid) == 0 {
+		return before, results, nil
+	}
+
+	updated, errs, err := domain.Batch(ctrl.repo).UpdateMany(ctx, valid, mode)
+	results, err = merge(results, index, updated, errs, err)
+	return before, results, err
+}
+
+// DeleteRecipes deletes many recipes in one repository call, and then the
+// images of those deleted. In atomic mode a missing recipe aborts the whole
+// batch.
+func (ctrl *Controller) DeleteRecipes(ctx context.Context, ids []model.RecipeID, mode domain.BatchMode) ([]BatchResult, error) {
+	ctx, span := ctrl.startBatch(ctx, "Controller.DeleteRecipes", len(ids), mode)
+	var before []model.Recipe
+	if (ctrl.auditor != nil || ctrl.images != nil) && len(ids) > 0 {
+		before, _, _ = domain.Batch(ctrl.repo).GetMany(ctx, ids)
+	}
+	results, err := ctrl.deleteRecipes(ctx, ids, mode)
+	tracing.End(span, err)
+
+	for i, result := range results {
+		ev := audit.Event{Action: audit.ActionRecipeDelete, Target: string(ids[i])}
+		if result.Err == nil {
+			ctrl.similar.Remove(ids[i])
+		}
+		if i < len(before) && before[i].ID != "" {
+			ev.BeforeHash = audit.HashRecipe(before[i])
+			if result.Err == nil {
+				ctrl.deleteImages(ctx, ids[i], before[i].Images...)
+			}
+		}
+		ctrl.record(ctx, ev, result.Err)
+	}
+	return results, err
+}
+
+func (ctrl *Controller) deleteRecipes(ctx context.Context, ids []model.RecipeID, mode domain.BatchMode) ([]BatchResult, error) {
+	if err := ctrl.checkBatch("ids", len(ids)); err != nil {
+		return nil, err
+	}
+
+	errs, err := domain.Batch(ctrl.repo).DeleteMany(ctx, ids, mode)
+	return merge(make([]BatchResult, len(ids)), identity(len(ids)), nil, errs, err)
+}
+
+// checkBatch rejects empty batches and batches over the size limit.
+func (ctrl *Controller) checkBatch(field string, n int) error {
+	v := &validator{limits: ctrl.limits}
+	switch {
+	case n == 0:
+		v.add(field, "must not be empty")
+	case ctrl.limits.MaxBatchSize > 0 && n > ctrl.limits.MaxBatchSize:
+		v.add(field, "must have at most %d entries, got %d", ctrl.limits.MaxBatchSize, n)
+	}
+	return v.err()
+}
+
+func (ctrl *Controller) startBatch(ctx context.Context, name string, size int, mode domain.BatchMode) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(
+		tracing.AttrBatchSize.Int(size),
+		tracing.AttrAtomic.Bool(mode == domain.BatchAtomic),
+	))
+}
+
+// merge copies the repository's per-item outcomes into results, where index
+// maps each repository item back to its position in the batch. A batch-level
+// error fails every item that has no error of its own.
+func merge(results []BatchResult, index []int, recipes []model.Recipe, errs []error, err error) ([]BatchResult, error) {
+	for j, i := range index {
+		switch {
+		case j < len(errs) && errs[j] != nil:
+			results[i].Err = errs[j]
+		case err != nil:
+			results[i].Err = err
+		case j < len(recipes):
+			results[i].Recipe = recipes[j]
+		}
+	}
+	if errors.Is(err, domain.ErrBatchAborted) {
+		return abort(results)
+	}
+	return results, err
+}
+
+// abort marks every item without an error of its own as aborted.
+func abort(results []BatchResult) ([]BatchResult, error) {
+	for i := range results {
+		if results[i].Err == nil {
+			results[i] = BatchResult{Err: domain.ErrBatchAborted}
+		}
+	}
+	return results, domain.ErrBatchAborted
+}
+
+func identity(n int) []int {
+	index := make([]int, n)
+	for i := range index {
+		index[i] = i
+	}
+	return index
+}
@@ -0,0 +1,125 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/repository/memory"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+func newMemoryRepo(t *testing.T, data string) *memory.Repository {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "recipes.json")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := memory.New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestControllerCreateRecipesBestEffort(t *testing.T) {
+	repo := newMemoryRepo(t, `[]`)
+	recorder := &recordedEvents{}
+	ctrl := New(repo, WithAuditor(recorder))
+
+	results, err := ctrl.CreateRecipes(context.Background(), []model.Recipe{
+		{Name: " Soup "},
+		{Name: ""},
+	}, domain.BatchBestEffort, DuplicatesWarn)
+	if err != nil {
+		t.Fatalf("CreateRecipes failed: %v", err)
+	}
+	if results[0].Err != nil || results[0].Recipe.Name != "Soup" || results[0].Recipe.ID == "" {
+		t.Errorf("Expected the first recipe to be created normalized, got %+v", results[0])
+	}
+	if !errors.Is(results[1].Err, domain.ErrInvalidInput) {
+		t.Errorf("Expected the second recipe to fail validation, got %v", results[1].Err)
+	}
+	if all, _ := repo.GetAll(context.Background()); len(all) != 1 {
+		t.Errorf("Expected 1 stored recipe, got %d", len(all))
+	}
+	if len(recorder.events) != 2 {
+		t.Errorf("Expected an audit event per item, got %d", len(recorder.events))
+	}
+}
+
+func TestControllerCreateRecipesAtomicAborts(t *testing.T) {
+	repo := newMemoryRepo(t, `[]`)
+	ctrl := New(repo)
+
+	results, err := ctrl.CreateRecipes(context.Background(), []model.Recipe{{Name: "Soup"}, {Name: ""}}, domain.BatchAtomic, DuplicatesWarn)
+	if !errors.Is(err, domain.ErrBatchAborted) {
+		t.Fatalf("Expected ErrBatchAborted, got %v", err)
+	}
+	if !errors.Is(results[0].Err, domain.ErrBatchAborted) || !errors.Is(results[1].Err, domain.ErrInvalidInput) {
+		t.Errorf("Unexpected item errors: %v, %v", results[0].Err, results[1].Err)
+	}
+	if all, _ := repo.GetAll(context.Background()); len(all) != 0 {
+		t.Errorf("Aborted batch must not store anything, got %d recipes", len(all))
+	}
+}
+
+func TestControllerUpdateRecipesBatch(t *testing.T) {
+	repo := newMemoryRepo(t, `[{"id":"a","name":"A"},{"id":"b","name":"B"}]`)
+	ctrl := New(repo)
+
+	name, empty := "A2", ""
+	results, err := ctrl.UpdateRecipes(context.Background(), []BatchUpdateCommand{
+		{ID: "a", UpdateRecipeCommand: UpdateRecipeCommand{Name: &name}},
+		{ID: "b", UpdateRecipeCommand: UpdateRecipeCommand{Name: &empty}},
+		{ID: "missing", UpdateRecipeCommand: UpdateRecipeCommand{Name: &name}},
+	}, domain.BatchBestEffort)
+	if err != nil {
+		t.Fatalf("UpdateRecipes failed: %v", err)
+	}
+	if results[0].Err != nil || results[0].Recipe.Name != "A2" {
+		t.Errorf("Expected a to be renamed, got %+v", results[0])
+	}
+	if !errors.Is(results[1].Err, domain.ErrInvalidInput) || !errors.Is(results[2].Err, domain.ErrNotFound) {
+		t.Errorf("Unexpected item errors: %v, %v", results[1].Err, results[2].Err)
+	}
+	if b, _ := repo.GetByID(context.Background(), "b"); b.Name != "B" {
+		t.Errorf("Invalid update must not be applied, got %q", b.Name)
+	}
+}
+
+func TestControllerDeleteRecipesFallsBackToSingleCalls(t *testing.T) {
+	deleted := 0
+	repo := &mockRepo{deleteFunc: func(ctx context.Context, id model.RecipeID) error {
+		if id == "missing" {
+			return domain.ErrNotFound
+		}
+		deleted++
+		return nil
+	}}
+	ctrl := New(repo)
+
+	results, err := ctrl.DeleteRecipes(context.Background(), []model.RecipeID{"a", "missing"}, domain.BatchBestEffort)
+	if err != nil || results[0].Err != nil || !errors.Is(results[1].Err, domain.ErrNotFound) || deleted != 1 {
+		t.Errorf("Unexpected results %+v, %v", results, err)
+	}
+
+	_, err = ctrl.DeleteRecipes(context.Background(), []model.RecipeID{"a"}, domain.BatchAtomic)
+	if !errors.Is(err, domain.ErrBatchUnsupported) {
+		t.Errorf("Expected ErrBatchUnsupported for an atomic batch without repository support, got %v", err)
+	}
+}
+
+func TestControllerBatchSizeLimits(t *testing.T) {
+	ctrl := New(&mockRepo{}, WithLimits(Limits{MaxBatchSize: 1}))
+
+	if _, err := ctrl.GetRecipes(context.Background(), nil); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected an empty batch to be rejected, got %v", err)
+	}
+	if _, err := ctrl.GetRecipes(context.Background(), []model.RecipeID{"a", "b"}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected an oversized batch to be rejected, got %v", err)
+	}
+}
@@ -1,5 +1,7 @@
 package recipe
 
+import "github.com/gin-demo/recipes-web/model"
+
 // UpdateRecipeCommand contains the fields that can be updated for a recipe.
 type UpdateRecipeCommand struct {
 	// Name is the optional new name for the recipe
@@ -8,4 +10,59 @@ type UpdateRecipeCommand struct {
 	Tags []string
 	// Ingredients is the optional new list of ingredients for the recipe
 	Ingredients []string
+	// Yield and the times are optional; an empty string clears the field
+	Yield     *string
+	PrepTime  *string
+	CookTime  *string
+	TotalTime *string
+	// Difficulty, Cuisine and Course are optional; an empty string clears the field
+	Difficulty *string
+	Cuisine    *string
+	Course     *string
+	// Equipment and Diets optionally replace their lists; an empty list clears them
+	Equipment []string
+	Diets     []string
+}
+
+// apply returns recipe with the fields set in cmd replaced.
+func (cmd UpdateRecipeCommand) apply(recipe model.Recipe) model.Recipe {
+	if cmd.Name != nil {
+		recipe.Name = *cmd.Name
+	}
+	if cmd.Tags != nil {
+		recipe.Tags = cmd.Tags
+	}
+	if cmd.Ingredients != nil {
+		recipe.Ingredients = cmd.Ingredients
+	}
+	for _, f := range []struct {
+		value *string
+		field *string
+	}{
+		{cmd.Yield, &recipe.Yield},
+		{cmd.PrepTime, &recipe.PrepTime},
+		{cmd.CookTime, &recipe.CookTime},
+		{cmd.TotalTime, &recipe.TotalTime},
+		{cmd.Difficulty, &recipe.Difficulty},
+		{cmd.Cuisine, &recipe.Cuisine},
+		{cmd.Course, &recipe.Course},
+	} {
+		if f.value != nil {
+			*f.field = *f.value
+		}
+	}
+	if cmd.Equipment != nil {
+		recipe.Equipment = cmd.Equipment
+	}
+	if cmd.Diets != nil {
+		recipe.Diets = cmd.Diets
+	}
+	return recipe
+}
+
+// BatchUpdateCommand updates one recipe as part of a batch.
+type BatchUpdateCommand struct {
+	// ID identifies the recipe to update
+	ID model.RecipeID
+	UpdateRecipeCommand
 }
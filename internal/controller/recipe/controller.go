@@ -2,59 +2,170 @@ package recipe
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 
+	"github.com/gin-demo/recipes-web/internal/audit"
 	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/normalize"
+	"github.com/gin-demo/recipes-web/internal/similar"
+	"github.com/gin-demo/recipes-web/internal/tracing"
 	"github.com/gin-demo/recipes-web/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/gin-demo/recipes-web/internal/controller/recipe")
+
 // Controller handles business logic for recipe operations.
 type Controller struct {
-	repo domain.RecipeRepository
+	repo    domain.RecipeRepository
+	auditor audit.Recorder
+	limits  Limits
+	images  *imageStore
+	similar *similar.Index
+}
+
+// Option configures optional Controller behaviour.
+type Option func(*Controller)
+
+// WithAuditor records every mutation, successful or not, with the given recorder.
+func WithAuditor(auditor audit.Recorder) Option {
+	return func(ctrl *Controller) {
+		ctrl.auditor = auditor
+	}
+}
+
+// WithSimilarIndex answers SimilarRecipes from idx, which the controller keeps
+// current as recipes change. Without it the controller has an index of its
+// own that stays empty until rebuilt.
+func WithSimilarIndex(idx *similar.Index) Option {
+	return func(ctrl *Controller) {
+		ctrl.similar = idx
+	}
 }
 
 // New creates a new Controller with the given repository.
-func New(repo domain.RecipeRepository) *Controller {
-	return &Controller{repo}
+func New(repo domain.RecipeRepository, opts ...Option) *Controller {
+	ctrl := &Controller{repo: repo, limits: DefaultLimits(), similar: similar.NewIndex()}
+	for _, opt := range opts {
+		opt(ctrl)
+	}
+	return ctrl
 }
 
-// CreateRecipe creates a new recipe in the repository.
-func (ctrl *Controller) CreateRecipe(ctx context.Context, recipe model.Recipe) (model.Recipe, error) {
-	return ctrl.repo.Create(ctx, recipe)
+// CreateRecipe normalizes and validates the recipe and creates it in the
+// repository. Invalid input returns a *domain.ValidationError listing every
+// problem found. Stored recipes suspected to be the same are returned, or
+// fail the create with a *domain.DuplicateError as dup selects.
+func (ctrl *Controller) CreateRecipe(ctx context.Context, recipe model.Recipe, dup DuplicateMode) (model.Recipe, []domain.Duplicate, error) {
+	ctx, span := tracer.Start(ctx, "Controller.CreateRecipe")
+	recipe = normalize.Apply(recipe)
+	var (
+		created model.Recipe
+		dups    []domain.Duplicate
+	)
+	err := ctrl.validateNew(recipe)
+	if err == nil {
+		dups, err = ctrl.checkDuplicates(recipe, dup)
+	}
+	if err == nil {
+		created, err = ctrl.repo.Create(ctx, recipe)
+	}
+	span.SetAttributes(tracing.AttrRecipeID.String(string(created.ID)))
+	tracing.End(span, err)
+
+	ev := audit.Event{Action: audit.ActionRecipeCreate, Target: string(created.ID)}
+	if err == nil {
+		ev.AfterHash = audit.HashRecipe(created)
+		ctrl.similar.Put(created)
+	}
+	ctrl.record(ctx, ev, err)
+
+	return created, dups, err
 }
 
 // GetRecipeByID retrieves a recipe by its ID.
 func (ctrl *Controller) GetRecipeByID(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
-	return ctrl.repo.GetByID(ctx, id)
+	ctx, span := tracer.Start(ctx, "Controller.GetRecipeByID", trace.WithAttributes(tracing.AttrRecipeID.String(string(id))))
+	recipe, err := ctrl.repo.GetByID(ctx, id)
+	tracing.End(span, err)
+	return recipe, err
+}
+
+// RecordView counts a client reading a recipe, which ranks recipes for cache
+// warm-up. Reads made by the controller itself are not counted, and a failure
+// only loses the count.
+func (ctrl *Controller) RecordView(ctx context.Context, id model.RecipeID) {
+	_ = domain.RecordView(ctx, ctrl.repo, id)
 }
 
 // ListRecipes returns all recipes.
 func (ctrl *Controller) ListRecipes(ctx context.Context) ([]model.Recipe, error) {
-	return ctrl.repo.GetAll(ctx)
+	ctx, span := tracer.Start(ctx, "Controller.ListRecipes")
+	recipes, err := ctrl.repo.GetAll(ctx)
+	span.SetAttributes(tracing.AttrResults.Int(len(recipes)))
+	tracing.End(span, err)
+	return recipes, err
 }
 
-// UpdateRecipe updates an existing recipe with the provided command.
+// UpdateRecipe updates an existing recipe with the provided command, validating
+// the fields it changes.
 func (ctrl *Controller) UpdateRecipe(ctx context.Context, id model.RecipeID, cmd UpdateRecipeCommand) (model.Recipe, error) {
-	existing, err := ctrl.repo.GetByID(ctx, id)
-	if err != nil {
-		return model.Recipe{}, err
-	}
+	ctx, span := tracer.Start(ctx, "Controller.UpdateRecipe", trace.WithAttributes(tracing.AttrRecipeID.String(string(id))))
+	before, updated, err := ctrl.updateRecipe(ctx, id, cmd)
+	tracing.End(span, err)
 
-	if cmd.Name != nil {
-		existing.Name = *cmd.Name
+	ev := audit.Event{Action: audit.ActionRecipeUpdate, Target: string(id)}
+	if before.ID != "" {
+		ev.BeforeHash = audit.HashRecipe(before)
 	}
-	if cmd.Tags != nil {
-		existing.Tags = cmd.Tags
+	if err == nil {
+		ev.AfterHash = audit.HashRecipe(updated)
+		ctrl.similar.Put(updated)
 	}
-	if cmd.Ingredients != nil {
-		existing.Ingredients = cmd.Ingredients
+	ctrl.record(ctx, ev, err)
+
+	return updated, err
+}
+
+// updateRecipe applies cmd and returns the recipe as it was before and after the update.
+func (ctrl *Controller) updateRecipe(ctx context.Context, id model.RecipeID, cmd UpdateRecipeCommand) (model.Recipe, model.Recipe, error) {
+	before, err := ctrl.repo.GetByID(ctx, id)
+	if err != nil {
+		return model.Recipe{}, model.Recipe{}, err
+	}
+
+	existing := normalize.Apply(cmd.apply(before))
+	if err := ctrl.validateUpdate(existing, cmd); err != nil {
+		return before, model.Recipe{}, err
 	}
 
-	return ctrl.repo.Update(ctx, existing)
+	updated, err := ctrl.repo.Update(ctx, existing)
+	return before, updated, err
 }
 
-// DeleteRecipe deletes a recipe by its ID.
+// DeleteRecipe deletes a recipe by its ID, and then its images.
 func (ctrl *Controller) DeleteRecipe(ctx context.Context, id model.RecipeID) error {
-	return ctrl.repo.Delete(ctx, id)
+	ctx, span := tracer.Start(ctx, "Controller.DeleteRecipe", trace.WithAttributes(tracing.AttrRecipeID.String(string(id))))
+	ev := audit.Event{Action: audit.ActionRecipeDelete, Target: string(id)}
+	var before model.Recipe
+	if ctrl.auditor != nil || ctrl.images != nil {
+		if recipe, err := ctrl.repo.GetByID(ctx, id); err == nil {
+			before = recipe
+			ev.BeforeHash = audit.HashRecipe(before)
+		}
+	}
+
+	err := ctrl.repo.Delete(ctx, id)
+	if err == nil {
+		ctrl.similar.Remove(id)
+		ctrl.deleteImages(ctx, id, before.Images...)
+	}
+	tracing.End(span, err)
+	ctrl.record(ctx, ev, err)
+
+	return err
 }
 
 // GetRecipeByTag retrieves recipes that have the specified tag.
@@ -63,5 +174,92 @@ func (ctrl *Controller) GetRecipeByTag(ctx context.Context, tag string) ([]model
 		return []model.Recipe{}, domain.ErrInvalidInput
 	}
 
-	return ctrl.repo.GetByTag(ctx, tag)
+	ctx, span := tracer.Start(ctx, "Controller.GetRecipeByTag", trace.WithAttributes(tracing.AttrRecipeTag.String(tag)))
+	recipes, err := ctrl.repo.GetByTag(ctx, tag)
+	span.SetAttributes(tracing.AttrResults.Int(len(recipes)))
+	tracing.End(span, err)
+	return recipes, err
+}
+
+// SearchRecipes returns the recipes matching filter, which is normalized and
+// validated first. The first required tag is looked up through the
+// repository, as GetRecipeByTag does, and the other criteria are applied to
+// the result.
+func (ctrl *Controller) SearchRecipes(ctx context.Context, filter domain.RecipeFilter) ([]model.Recipe, error) {
+	filter = normalizeFilter(filter)
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+
+	ctx, span := tracer.Start(ctx, "Controller.SearchRecipes", trace.WithAttributes(tracing.AttrRecipeTag.StringSlice(filter.Tags)))
+	var (
+		recipes []model.Recipe
+		err     error
+	)
+	if len(filter.Tags) > 0 {
+		recipes, err = ctrl.repo.GetByTag(ctx, filter.Tags[0])
+	} else {
+		recipes, err = ctrl.repo.GetAll(ctx)
+	}
+	if errors.Is(err, domain.ErrNotFound) {
+		// Some repositories report a tag nothing carries as not found.
+		err = nil
+	}
+	matches := []model.Recipe{}
+	for _, recipe := range recipes {
+		if filter.Matches(recipe) {
+			matches = append(matches, recipe)
+		}
+	}
+	span.SetAttributes(tracing.AttrResults.Int(len(matches)))
+	tracing.End(span, err)
+	return matches, err
+}
+
+// ExportRecipes calls fn for every recipe matching filter without loading them
+// all first. It stops at the first error from fn or when ctx is done.
+func (ctrl *Controller) ExportRecipes(ctx context.Context, filter domain.RecipeFilter, fn func(model.Recipe) error) error {
+	filter = normalizeFilter(filter)
+	if err := validateFilter(filter); err != nil {
+		return err
+	}
+	ctx, span := tracer.Start(ctx, "Controller.ExportRecipes", trace.WithAttributes(tracing.AttrRecipeTag.StringSlice(filter.Tags)))
+	n := 0
+	err := domain.Stream(ctx, ctrl.repo, filter, func(recipe model.Recipe) error {
+		n++
+		return fn(recipe)
+	})
+	span.SetAttributes(tracing.AttrResults.Int(n))
+	tracing.End(span, err)
+	return err
+}
+
+// Facets counts the recipes matching filter by tag, cuisine, difficulty and
+// total time. The filter is normalized and validated as for SearchRecipes.
+func (ctrl *Controller) Facets(ctx context.Context, filter domain.RecipeFilter) (domain.Facets, error) {
+	filter = normalizeFilter(filter)
+	if err := validateFilter(filter); err != nil {
+		return domain.Facets{}, err
+	}
+	ctx, span := tracer.Start(ctx, "Controller.Facets", trace.WithAttributes(tracing.AttrRecipeTag.StringSlice(filter.Tags)))
+	facets, err := domain.CountFacets(ctx, ctrl.repo, filter)
+	span.SetAttributes(tracing.AttrResults.Int(facets.Total))
+	tracing.End(span, err)
+	return facets, err
+}
+
+// record writes an audit event for a mutation. A failure to audit is logged
+// but does not undo or fail the mutation, which has already happened.
+func (ctrl *Controller) record(ctx context.Context, ev audit.Event, err error) {
+	if ctrl.auditor == nil {
+		return
+	}
+	if err != nil {
+		ev.Outcome = audit.OutcomeFailure
+		ev.Error = err.Error()
+	}
+
+	if auditErr := ctrl.auditor.Record(ctx, ev); auditErr != nil {
+		slog.ErrorContext(ctx, "failed to record audit event", "action", ev.Action, "target", ev.Target, "error", auditErr)
+	}
 }
@@ -3,10 +3,14 @@ package recipe
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/gin-demo/recipes-web/internal/audit"
 	"github.com/gin-demo/recipes-web/internal/domain"
 	"github.com/gin-demo/recipes-web/internal/repository/memory"
+	"github.com/gin-demo/recipes-web/internal/similar"
 	"github.com/gin-demo/recipes-web/model"
 )
 
@@ -81,7 +85,7 @@ func TestControllerCreateRecipe(t *testing.T) {
 	ctrl := New(repo)
 
 	recipe := model.Recipe{Name: "Test"}
-	created, err := ctrl.CreateRecipe(context.Background(), recipe)
+	created, _, err := ctrl.CreateRecipe(context.Background(), recipe, DuplicatesWarn)
 	if err != nil {
 		t.Fatalf("CreateRecipe failed: %v", err)
 	}
@@ -93,7 +97,7 @@ func TestControllerCreateRecipe(t *testing.T) {
 	repo.createFunc = func(ctx context.Context, r model.Recipe) (model.Recipe, error) {
 		return model.Recipe{}, errors.New("create error")
 	}
-	_, err = ctrl.CreateRecipe(context.Background(), recipe)
+	_, _, err = ctrl.CreateRecipe(context.Background(), recipe, DuplicatesWarn)
 	if err == nil {
 		t.Error("Expected error")
 	}
@@ -242,6 +246,263 @@ func TestControllerGetRecipeByTag(t *testing.T) {
 	}
 }
 
+func TestControllerSearchRecipes(t *testing.T) {
+	repo := &mockRepo{
+		recipes: []model.Recipe{
+			{ID: "1", Tags: []string{"a"}, Cuisine: "indian", TotalTime: "PT25M", Diets: []string{"vegan", "gluten-free"}},
+			{ID: "2", Tags: []string{"a"}, Cuisine: "indian", PrepTime: "PT20M", CookTime: "PT40M"},
+			{ID: "3", Tags: []string{"b"}, Cuisine: "italian", TotalTime: "PT15M"},
+			{ID: "4", Cuisine: "indian"},
+		},
+	}
+	ctrl := New(repo)
+	ctx := context.Background()
+
+	ids := func(recipes []model.Recipe) []model.RecipeID {
+		out := []model.RecipeID{}
+		for _, r := range recipes {
+			out = append(out, r.ID)
+		}
+		return out
+	}
+	tests := []struct {
+		filter domain.RecipeFilter
+		want   []model.RecipeID
+	}{
+		{domain.RecipeFilter{Cuisine: "Indian"}, []model.RecipeID{"1", "2", "4"}},
+		{domain.RecipeFilter{Cuisine: "indian", MaxTotalTime: 30 * time.Minute}, []model.RecipeID{"1"}},
+		{domain.RecipeFilter{MaxTotalTime: time.Hour}, []model.RecipeID{"1", "2", "3"}},
+		{domain.RecipeFilter{Tags: []string{"a"}, Diets: []string{"vegan", "Gluten-Free"}}, []model.RecipeID{"1"}},
+		{domain.RecipeFilter{Tags: []string{"b"}, Cuisine: "indian"}, []model.RecipeID{}},
+		{domain.RecipeFilter{AnyTags: []string{"A", "b"}, ExcludeTags: []string{"b"}}, []model.RecipeID{"1", "2"}},
+		{domain.RecipeFilter{Tags: []string{"a", "b"}}, []model.RecipeID{}},
+	}
+	for _, tt := range tests {
+		recipes, err := ctrl.SearchRecipes(ctx, tt.filter)
+		if err != nil {
+			t.Fatalf("SearchRecipes(%+v) failed: %v", tt.filter, err)
+		}
+		if got := ids(recipes); !slices.Equal(got, tt.want) {
+			t.Errorf("SearchRecipes(%+v) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	_, err := ctrl.SearchRecipes(ctx, domain.RecipeFilter{Difficulty: "trivial", Diets: []string{"paleo"}})
+	fields := fieldsOf(t, err)
+	if len(fields) != 2 || fields["difficulty"] == "" || fields["diet"] == "" {
+		t.Errorf("Expected difficulty and diet errors, got %v", fields)
+	}
+}
+
+func TestControllerSearchRecipesTagNotFound(t *testing.T) {
+	repo := &mockRepo{
+		getByTagFunc: func(ctx context.Context, tag string) ([]model.Recipe, error) {
+			return nil, domain.ErrNotFound
+		},
+	}
+	recipes, err := New(repo).SearchRecipes(context.Background(), domain.RecipeFilter{Tags: []string{"missing"}})
+	if err != nil {
+		t.Fatalf("SearchRecipes failed: %v", err)
+	}
+	if recipes == nil || len(recipes) != 0 {
+		t.Errorf("Expected an empty result, got %v", recipes)
+	}
+}
+
+func TestControllerFacets(t *testing.T) {
+	repo := &mockRepo{
+		recipes: []model.Recipe{
+			{ID: "1", Tags: []string{"a", "b"}, Cuisine: "indian", Difficulty: "easy", TotalTime: "PT25M"},
+			{ID: "2", Tags: []string{"a"}, Cuisine: "indian", PrepTime: "PT20M", CookTime: "PT50M"},
+			{ID: "3", Tags: []string{"b"}, Cuisine: "italian", Difficulty: "hard"},
+		},
+	}
+	ctrl := New(repo)
+
+	facets, err := ctrl.Facets(context.Background(), domain.RecipeFilter{Tags: []string{" A "}})
+	if err != nil {
+		t.Fatalf("Facets failed: %v", err)
+	}
+	if facets.Total != 2 {
+		t.Errorf("Expected 2 recipes, got %d", facets.Total)
+	}
+	if want := []domain.FacetCount{{Value: "a", Count: 2}, {Value: "b", Count: 1}}; !slices.Equal(facets.Tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, facets.Tags)
+	}
+	if want := []domain.FacetCount{{Value: "indian", Count: 2}}; !slices.Equal(facets.Cuisines, want) {
+		t.Errorf("Expected cuisines %v, got %v", want, facets.Cuisines)
+	}
+	if facets.Difficulties[0] != (domain.FacetCount{Value: "easy", Count: 1}) || facets.Difficulties[2].Count != 0 {
+		t.Errorf("Unexpected difficulties %v", facets.Difficulties)
+	}
+	if facets.TotalTimes[1].Count != 1 || facets.TotalTimes[3].Count != 1 {
+		t.Errorf("Unexpected total times %v", facets.TotalTimes)
+	}
+
+	_, err = ctrl.Facets(context.Background(), domain.RecipeFilter{Difficulty: "trivial"})
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestControllerMatchPantry(t *testing.T) {
+	repo := &mockRepo{
+		recipes: []model.Recipe{
+			{ID: "1", Name: "B", Ingredients: []string{"2 eggs", "1 cup milk", "1 cup flour"}},
+			{ID: "2", Name: "A", Ingredients: []string{"2 eggs", "1 cup milk", "1 tbsp sugar"}},
+			{ID: "3", Name: "C", Ingredients: []string{"2 eggs", "Salt to taste"}},
+			{ID: "4", Name: "D", Ingredients: []string{"1 leek", "1 carrot", "1 onion"}},
+			{ID: "5", Name: "E", Ingredients: []string{"water"}},
+		},
+	}
+	ctrl := New(repo)
+	ctx := context.Background()
+
+	matches, err := ctrl.MatchPantry(ctx, MatchQuery{Ingredients: []string{"egg", "whole milk"}, MaxMissing: 2})
+	if err != nil {
+		t.Fatalf("MatchPantry failed: %v", err)
+	}
+	var ids []model.RecipeID
+	for _, m := range matches {
+		ids = append(ids, m.Recipe.ID)
+	}
+	// C is makeable; A and B both miss one of three, so they are ordered by name.
+	if want := []model.RecipeID{"3", "2", "1"}; !slices.Equal(ids, want) {
+		t.Errorf("Expected %v, got %v", want, ids)
+	}
+	if matches[0].Needed != 1 || matches[0].Coverage() != 1 {
+		t.Errorf("Expected salt to be ignored, got %+v", matches[0])
+	}
+
+	matches, _ = ctrl.MatchPantry(ctx, MatchQuery{Ingredients: []string{"egg", "milk"}, Ignore: []string{"flour"}, Limit: 2})
+	if len(matches) != 2 || matches[0].Recipe.ID != "1" || len(matches[0].Missing) != 0 {
+		t.Errorf("Expected flour to be ignored, got %+v", matches)
+	}
+
+	_, err = ctrl.MatchPantry(ctx, MatchQuery{MaxMissing: -1})
+	fields := fieldsOf(t, err)
+	if fields["ingredients"] == "" {
+		t.Errorf("Expected an ingredients error, got %v", fields)
+	}
+	_, err = ctrl.MatchPantry(ctx, MatchQuery{Ingredients: []string{"egg"}, MaxMissing: -1})
+	if fields := fieldsOf(t, err); fields["maxMissing"] == "" {
+		t.Errorf("Expected a maxMissing error, got %v", fields)
+	}
+}
+
+func TestControllerSimilarRecipes(t *testing.T) {
+	repo := &mockRepo{
+		recipes: []model.Recipe{
+			{ID: "1", Name: "Tomato soup", Tags: []string{"soup"}, Ingredients: []string{"4 tomatoes", "1 onion"}},
+			{ID: "2", Name: "Tomato salad", Tags: []string{"salad"}, Ingredients: []string{"2 tomatoes", "1 cucumber"}},
+			{ID: "3", Name: "Leek soup", Tags: []string{"soup"}, Ingredients: []string{"2 leeks", "1 onion"}},
+			{ID: "4", Name: "Pancakes", Tags: []string{"breakfast"}, Ingredients: []string{"2 eggs", "1 cup flour"}},
+		},
+	}
+	repo.createFunc = func(ctx context.Context, recipe model.Recipe) (model.Recipe, error) {
+		recipe.ID = "5"
+		repo.recipes = append(repo.recipes, recipe)
+		return recipe, nil
+	}
+	idx := similar.NewIndex()
+	ctrl := New(repo, WithSimilarIndex(idx))
+	ctx := context.Background()
+
+	if _, err := ctrl.SimilarRecipes(ctx, "1", 0); !errors.Is(err, similar.ErrNotReady) {
+		t.Errorf("Expected ErrNotReady before the index is built, got %v", err)
+	}
+	if err := idx.Rebuild(ctx, repo); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	results, err := ctrl.SimilarRecipes(ctx, "1", 0)
+	if err != nil {
+		t.Fatalf("SimilarRecipes failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Recipe.ID != "3" || results[1].Recipe.ID != "2" {
+		t.Errorf("Expected the leek soup then the tomato salad, got %+v", results)
+	}
+	if results[0].Score <= results[1].Score || results[0].Score > 1 {
+		t.Errorf("Expected descending scores up to 1, got %+v", results)
+	}
+
+	// Created recipes are indexed straight away.
+	if _, _, err := ctrl.CreateRecipe(ctx, model.Recipe{Name: "Tomato onion soup", Tags: []string{"soup"},
+		Ingredients: []string{"4 tomatoes", "2 onions"}, Instructions: []string{"Simmer"}}, DuplicatesWarn); err != nil {
+		t.Fatalf("CreateRecipe failed: %v", err)
+	}
+	results, _ = ctrl.SimilarRecipes(ctx, "1", 1)
+	if len(results) != 1 || results[0].Recipe.ID != "5" {
+		t.Errorf("Expected the new soup to rank first, got %+v", results)
+	}
+
+	if _, err := ctrl.SimilarRecipes(ctx, "missing", 0); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	_, err = ctrl.SimilarRecipes(ctx, "1", MaxSimilarLimit+1)
+	if fields := fieldsOf(t, err); fields["limit"] == "" {
+		t.Errorf("Expected a limit error, got %v", fields)
+	}
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
+
+type recordedEvents struct {
+	events []audit.Event
+}
+
+func (r *recordedEvents) Record(ctx context.Context, ev audit.Event) error {
+	r.events = append(r.events, ev)
+	return nil
+}
+
+func TestControllerAuditsMutations(t *testing.T) {
+	existing := model.Recipe{ID: "r1", Name: "Old"}
+	repo := &mockRepo{recipes: []model.Recipe{existing}}
+	repo.createFunc = func(ctx context.Context, r model.Recipe) (model.Recipe, error) {
+		r.ID = "r2"
+		return r, nil
+	}
+	recorder := &recordedEvents{}
+	ctrl := New(repo, WithAuditor(recorder))
+
+	created, _, _ := ctrl.CreateRecipe(context.Background(), model.Recipe{Name: "New"}, DuplicatesWarn)
+	name := "Renamed"
+	updated, _ := ctrl.UpdateRecipe(context.Background(), "r1", UpdateRecipeCommand{Name: &name})
+	_ = ctrl.DeleteRecipe(context.Background(), "r1")
+
+	if len(recorder.events) != 3 {
+		t.Fatalf("Expected 3 audit events, got %d", len(recorder.events))
+	}
+
+	create, update, del := recorder.events[0], recorder.events[1], recorder.events[2]
+	if create.Action != audit.ActionRecipeCreate || create.Target != "r2" || create.AfterHash != audit.HashRecipe(created) {
+		t.Errorf("Unexpected create event: %+v", create)
+	}
+	if update.BeforeHash != audit.HashRecipe(existing) || update.AfterHash != audit.HashRecipe(updated) {
+		t.Errorf("Expected before/after hashes on update, got %+v", update)
+	}
+	if del.Action != audit.ActionRecipeDelete || del.BeforeHash != audit.HashRecipe(existing) || del.AfterHash != "" {
+		t.Errorf("Unexpected delete event: %+v", del)
+	}
+}
+
+func TestControllerAuditsFailedMutations(t *testing.T) {
+	repo := &mockRepo{}
+	repo.deleteFunc = func(ctx context.Context, id model.RecipeID) error {
+		return domain.ErrNotFound
+	}
+	recorder := &recordedEvents{}
+	ctrl := New(repo, WithAuditor(recorder))
+
+	_ = ctrl.DeleteRecipe(context.Background(), "missing")
+
+	if len(recorder.events) != 1 {
+		t.Fatalf("Expected 1 audit event, got %d", len(recorder.events))
+	}
+	if ev := recorder.events[0]; ev.Outcome != audit.OutcomeFailure || ev.Error == "" {
+		t.Errorf("Expected failure outcome, got %+v", ev)
+	}
+}
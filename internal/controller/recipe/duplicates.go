@@ -0,0 +1,220 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gin-demo/recipes-web/internal/audit"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/normalize"
+	"github.com/gin-demo/recipes-web/internal/tracing"
+	"github.com/gin-demo/recipes-web/model"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MaxDuplicates bounds the suspected duplicates reported for a new recipe.
+const MaxDuplicates = 5
+
+// DuplicateMode selects what creating a recipe that looks like a stored one does.
+type DuplicateMode int
+
+const (
+	// DuplicatesWarn creates the recipe and reports the suspected duplicates.
+	DuplicatesWarn DuplicateMode = iota
+	// DuplicatesReject fails with a *domain.DuplicateError instead.
+	DuplicatesReject
+)
+
+// DuplicateCluster is a group of stored recipes suspected to be the same.
+type DuplicateCluster struct {
+	// Recipes are ordered by ID
+	Recipes []model.Recipe
+	// Score is how alike the least alike pair linking them is, from 0 to 1
+	Score float64
+}
+
+// checkDuplicates returns the stored recipes suspected to be the same as
+// recipe, or a *domain.DuplicateError listing them in DuplicatesReject mode.
+// Nothing is found while the index is first being built.
+func (ctrl *Controller) checkDuplicates(recipe model.Recipe, mode DuplicateMode) ([]domain.Duplicate, error) {
+	dups, err := ctrl.similar.Duplicates(recipe, MaxDuplicates)
+	if err != nil || len(dups) == 0 {
+		return nil, nil
+	}
+	if mode == DuplicatesReject {
+		return nil, &domain.DuplicateError{Duplicates: dups}
+	}
+	return dups, nil
+}
+
+// DuplicateClusters groups the stored recipes suspected to be the same as
+// another, most alike first. It fails with similar.ErrNotReady until the
+// index has been built.
+func (ctrl *Controller) DuplicateClusters(ctx context.Context) ([]DuplicateCluster, error) {
+	ctx, span := tracer.Start(ctx, "Controller.DuplicateClusters")
+	clusters, err := ctrl.duplicateClusters(ctx)
+	span.SetAttributes(tracing.AttrResults.Int(len(clusters)))
+	tracing.End(span, err)
+	return clusters, err
+}
+
+func (ctrl *Controller) duplicateClusters(ctx context.Context) ([]DuplicateCluster, error) {
+	found, err := ctrl.similar.Clusters()
+	if err != nil {
+		return nil, err
+	}
+	clusters := make([]DuplicateCluster, 0, len(found))
+	for _, c := range found {
+		recipes, errs, err := domain.Batch(ctrl.repo).GetMany(ctx, c.IDs)
+		if err != nil {
+			return nil, err
+		}
+		cluster := DuplicateCluster{Score: c.Score}
+		for i, recipe := range recipes {
+			switch {
+			case errors.Is(errs[i], domain.ErrNotFound):
+				// Deleted elsewhere since the index last saw it.
+				continue
+			case errs[i] != nil:
+				return nil, errs[i]
+			}
+			cluster.Recipes = append(cluster.Recipes, recipe)
+		}
+		if len(cluster.Recipes) > 1 {
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters, nil
+}
+
+// MergeRecipes folds duplicates into the recipe kept and then deletes them.
+// The kept recipe gains their tags, diets and equipment, and takes any other
+// field it lacks from the first duplicate having it. Images stay with the
+// recipe they were uploaded to, so the duplicates' images are deleted too.
+// The merged recipe is validated like an update of the fields it gains, and
+// nothing is changed when it fails. A failure after the update may leave some duplicates behind; merging them
+// again finishes the job.
+func (ctrl *Controller) MergeRecipes(ctx context.Context, keep model.RecipeID, duplicates []model.RecipeID) (model.Recipe, error) {
+	ctx, span := tracer.Start(ctx, "Controller.MergeRecipes", trace.WithAttributes(
+		tracing.AttrRecipeID.String(string(keep)),
+		tracing.AttrBatchSize.Int(len(duplicates)),
+	))
+	before, merged, deleted, err := ctrl.mergeRecipes(ctx, keep, duplicates)
+	tracing.End(span, err)
+
+	if before.ID != "" {
+		ev := audit.Event{Action: audit.ActionRecipeMerge, Target: string(keep), BeforeHash: audit.HashRecipe(before)}
+		mergeErr := err
+		if merged.ID != "" {
+			ev.AfterHash = audit.HashRecipe(merged)
+			ctrl.similar.Put(merged)
+			mergeErr = nil
+		}
+		ctrl.record(ctx, ev, mergeErr)
+	}
+	for _, dup := range deleted {
+		ctrl.similar.Remove(dup.ID)
+		ctrl.record(ctx, audit.Event{Action: audit.ActionRecipeDelete, Target: string(dup.ID), BeforeHash: audit.HashRecipe(dup)}, nil)
+	}
+	return merged, err
+}
+
+// mergeRecipes returns the kept recipe before and after the merge and the
+// duplicates deleted.
+func (ctrl *Controller) mergeRecipes(ctx context.Context, keep model.RecipeID, ids []model.RecipeID) (model.Recipe, model.Recipe, []model.Recipe, error) {
+	if err := ctrl.validateMerge(keep, ids); err != nil {
+		return model.Recipe{}, model.Recipe{}, nil, err
+	}
+	before, err := ctrl.repo.GetByID(ctx, keep)
+	if err != nil {
+		return model.Recipe{}, model.Recipe{}, nil, err
+	}
+	dups, errs, err := domain.Batch(ctrl.repo).GetMany(ctx, ids)
+	if err != nil {
+		return before, model.Recipe{}, nil, err
+	}
+	for i, err := range errs {
+		if err != nil {
+			return before, model.Recipe{}, nil, fmt.Errorf("recipe %s: %w", ids[i], err)
+		}
+	}
+
+	merged := normalize.Apply(mergeInto(before, dups))
+	if err := ctrl.validateMerged(before, merged); err != nil {
+		return before, model.Recipe{}, nil, err
+	}
+	merged, err = ctrl.repo.Update(ctx, merged)
+	if err != nil {
+		return before, model.Recipe{}, nil, err
+	}
+	deleted := make([]model.Recipe, 0, len(dups))
+	for _, dup := range dups {
+		if err := ctrl.repo.Delete(ctx, dup.ID); err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return before, merged, deleted, err
+		}
+		ctrl.deleteImages(ctx, dup.ID, dup.Images...)
+		deleted = append(deleted, dup)
+	}
+	return before, merged, deleted, nil
+}
+
+func (ctrl *Controller) validateMerge(keep model.RecipeID, ids []model.RecipeID) error {
+	if err := ctrl.checkBatch("duplicates", len(ids)); err != nil {
+		return err
+	}
+	v := &validator{limits: ctrl.limits}
+	if keep == "" {
+		v.add("keep", "is required")
+	}
+	for i, id := range ids {
+		switch first := slices.Index(ids, id); {
+		case id == keep:
+			v.add(fmt.Sprintf("duplicates[%d]", i), "must not be the recipe kept")
+		case first < i:
+			v.add(fmt.Sprintf("duplicates[%d]", i), "duplicates duplicates[%d]", first)
+		}
+	}
+	return v.err()
+}
+
+// mergeInto adds what dups have and keep lacks to keep.
+func mergeInto(keep model.Recipe, dups []model.Recipe) model.Recipe {
+	for _, dup := range dups {
+		keep.Tags = union(keep.Tags, dup.Tags)
+		keep.Diets = union(keep.Diets, dup.Diets)
+		keep.Equipment = union(keep.Equipment, dup.Equipment)
+		if len(keep.Ingredients) == 0 {
+			keep.Ingredients = dup.Ingredients
+		}
+		if len(keep.Instructions) == 0 {
+			keep.Instructions, keep.Sections = dup.Instructions, dup.Sections
+		}
+		for _, field := range []struct{ keep, dup *string }{
+			{&keep.Yield, &dup.Yield},
+			{&keep.PrepTime, &dup.PrepTime},
+			{&keep.CookTime, &dup.CookTime},
+			{&keep.TotalTime, &dup.TotalTime},
+			{&keep.Difficulty, &dup.Difficulty},
+			{&keep.Cuisine, &dup.Cuisine},
+			{&keep.Course, &dup.Course},
+		} {
+			if *field.keep == "" {
+				*field.keep = *field.dup
+			}
+		}
+	}
+	return keep
+}
+
+// union appends the values of b missing from a.
+func union(a, b []string) []string {
+	out := slices.Clone(a)
+	for _, v := range b {
+		if !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,149 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/audit"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/similar"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+const cakes = `[
+	{"id":"a","name":"Chocolate cake","tags":["cake"],"ingredients":["2 cups flour","1 cup sugar","1/2 cup cocoa"],"instructions":["Bake"]},
+	{"id":"b","name":"Easy Chocolate Cake","tags":["dessert"],"ingredients":["2 cups flour","1 cup sugar","1/2 cup cocoa","2 eggs"],"yield":"8 slices","diets":["vegetarian"]},
+	{"id":"c","name":"Lemon tart","ingredients":["3 lemons","4 eggs"],"instructions":["Bake"]}
+]`
+
+func TestControllerCreateChecksDuplicates(t *testing.T) {
+	repo := newMemoryRepo(t, cakes)
+	idx := similar.NewIndex()
+	ctrl := New(repo, WithSimilarIndex(idx))
+	ctx := context.Background()
+	cake := model.Recipe{Name: "Chocolate cake", Ingredients: []string{"flour", "sugar", "cocoa"}}
+
+	// Nothing is checked until the index is built.
+	if _, dups, err := ctrl.CreateRecipe(ctx, cake, DuplicatesReject); err != nil || dups != nil {
+		t.Fatalf("Expected the create to go ahead, got %v, %v", dups, err)
+	}
+	if err := idx.Rebuild(ctx, repo); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	_, _, err := ctrl.CreateRecipe(ctx, cake, DuplicatesReject)
+	var derr *domain.DuplicateError
+	if !errors.As(err, &derr) || !errors.Is(err, domain.ErrConflict) || len(derr.Duplicates) != 3 {
+		t.Fatalf("Expected the three cakes as duplicates, got %v", err)
+	}
+
+	created, dups, err := ctrl.CreateRecipe(ctx, model.Recipe{Name: "Lemon Tarts", Ingredients: []string{"lemon", "egg"}}, DuplicatesWarn)
+	if err != nil || created.ID == "" || len(dups) != 1 || dups[0].ID != "c" {
+		t.Errorf("Expected the tart to be created with a warning, got %v, %v", dups, err)
+	}
+
+	results, err := ctrl.CreateRecipes(ctx, []model.Recipe{cake, {Name: "Apple pie"}}, domain.BatchBestEffort, DuplicatesReject)
+	if err != nil {
+		t.Fatalf("CreateRecipes failed: %v", err)
+	}
+	if !errors.Is(results[0].Err, domain.ErrConflict) || results[1].Err != nil || results[1].Duplicates != nil {
+		t.Errorf("Expected only the cake to be rejected, got %+v", results)
+	}
+}
+
+func TestControllerCreateRecipesChecksWithinBatch(t *testing.T) {
+	ctrl := New(newMemoryRepo(t, "[]"), WithSimilarIndex(similar.NewIndex()))
+	ctx := context.Background()
+	batch := []model.Recipe{
+		{Name: "Chocolate cake", Ingredients: []string{"flour", "sugar", "cocoa"}},
+		{Name: "Chocolate cakes", Ingredients: []string{"flour", "sugar", "cocoa"}},
+		{Name: "Lemon tart", Ingredients: []string{"lemon", "egg"}},
+	}
+
+	results, err := ctrl.CreateRecipes(ctx, batch, domain.BatchBestEffort, DuplicatesReject)
+	if err != nil {
+		t.Fatalf("CreateRecipes failed: %v", err)
+	}
+	var derr *domain.DuplicateError
+	if results[0].Err != nil || results[2].Err != nil || !errors.As(results[1].Err, &derr) {
+		t.Fatalf("Expected only the second cake to be rejected, got %+v", results)
+	}
+	if len(derr.Duplicates) != 1 || derr.Duplicates[0].ID != results[0].Recipe.ID {
+		t.Errorf("Expected the first cake as the duplicate, got %+v", derr.Duplicates)
+	}
+
+	results, _ = ctrl.CreateRecipes(ctx, batch[:2], domain.BatchBestEffort, DuplicatesWarn)
+	if results[1].Err != nil || len(results[1].Duplicates) == 0 || results[1].Duplicates[0].ID != results[0].Recipe.ID {
+		t.Errorf("Expected the second cake to be created with a warning about the first, got %+v", results[1])
+	}
+
+	results, err = ctrl.CreateRecipes(ctx, []model.Recipe{{Name: "Apple pie", Ingredients: []string{"apple"}}, {Name: "Apple pies", Ingredients: []string{"apples"}}}, domain.BatchAtomic, DuplicatesReject)
+	if !errors.Is(err, domain.ErrBatchAborted) || !errors.Is(results[0].Err, domain.ErrBatchAborted) {
+		t.Fatalf("Expected the batch to be aborted, got %+v, %v", results, err)
+	}
+	if results[1].Err == nil || results[1].Err.Error() != `recipe conflict: possible duplicate of "Apple pie"` {
+		t.Errorf("Expected the uncreated item to be named, got %v", results[1].Err)
+	}
+}
+
+func TestControllerMergeRecipes(t *testing.T) {
+	repo := newMemoryRepo(t, cakes)
+	idx := similar.NewIndex()
+	recorder := &recordedEvents{}
+	ctrl := New(repo, WithSimilarIndex(idx), WithAuditor(recorder))
+	ctx := context.Background()
+	if err := idx.Rebuild(ctx, repo); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	clusters, err := ctrl.DuplicateClusters(ctx)
+	if err != nil {
+		t.Fatalf("DuplicateClusters failed: %v", err)
+	}
+	if len(clusters) != 1 || len(clusters[0].Recipes) != 2 || clusters[0].Recipes[0].ID != "a" {
+		t.Fatalf("Expected the two cakes in one cluster, got %+v", clusters)
+	}
+
+	merged, err := ctrl.MergeRecipes(ctx, "a", []model.RecipeID{"b"})
+	if err != nil {
+		t.Fatalf("MergeRecipes failed: %v", err)
+	}
+	if !slices.Equal(merged.Tags, []string{"cake", "dessert"}) || merged.Yield != "8 slices" || len(merged.Diets) != 1 || len(merged.Ingredients) != 3 {
+		t.Errorf("Expected the duplicate's tags and missing fields merged in, got %+v", merged)
+	}
+	if _, err := repo.GetByID(ctx, "b"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected the duplicate to be deleted, got %v", err)
+	}
+	if clusters, _ := ctrl.DuplicateClusters(ctx); len(clusters) != 0 {
+		t.Errorf("Expected no clusters after the merge, got %+v", clusters)
+	}
+	var actions []string
+	for _, ev := range recorder.events {
+		actions = append(actions, ev.Action)
+	}
+	if want := []string{audit.ActionRecipeMerge, audit.ActionRecipeDelete}; !slices.Equal(actions, want) {
+		t.Errorf("Expected %v audited, got %v", want, actions)
+	}
+
+	if _, err := ctrl.MergeRecipes(ctx, "a", []model.RecipeID{"b"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a deleted duplicate, got %v", err)
+	}
+	_, err = ctrl.MergeRecipes(ctx, "a", []model.RecipeID{"a", "c", "c"})
+	if fields := fieldsOf(t, err); fields["duplicates[0]"] == "" || fields["duplicates[2]"] == "" {
+		t.Errorf("Expected errors for the kept and repeated IDs, got %v", fields)
+	}
+
+	// Two tags each fit, but not together.
+	limits := DefaultLimits()
+	limits.MaxTags = 1
+	ctrl = New(newMemoryRepo(t, cakes), WithLimits(limits))
+	_, err = ctrl.MergeRecipes(ctx, "a", []model.RecipeID{"b"})
+	if fields := fieldsOf(t, err); fields["tags"] == "" {
+		t.Fatalf("Expected the merged tags to be rejected, got %v", fields)
+	}
+	if b, err := ctrl.repo.GetByID(ctx, "b"); err != nil || b.ID != "b" {
+		t.Errorf("Expected the duplicate to be kept, got %v", err)
+	}
+}
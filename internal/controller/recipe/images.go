@@ -0,0 +1,186 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/internal/audit"
+	"github.com/gin-demo/recipes-web/internal/blob"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/imaging"
+	"github.com/gin-demo/recipes-web/internal/tracing"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/rs/xid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrImagesDisabled is returned by the image methods of a controller created
+// without WithImages.
+var ErrImagesDisabled = errors.New("image storage is not configured")
+
+// imageStore stores the variants of uploaded images.
+type imageStore struct {
+	store blob.Store
+	// baseURL prefixes blob keys in the URLs returned with recipes
+	baseURL string
+}
+
+// WithImages stores uploaded images in store and returns their URLs under
+// baseURL, such as "/images" or a CDN address. Deleting a recipe then
+// deletes its images too.
+func WithImages(store blob.Store, baseURL string) Option {
+	return func(ctrl *Controller) {
+		ctrl.images = &imageStore{store: store, baseURL: strings.TrimSuffix(baseURL, "/")}
+	}
+}
+
+// imageKey returns the blob key of one variant of an image.
+func imageKey(id model.RecipeID, imageID, variant string) string {
+	return "recipes/" + url.PathEscape(string(id)) + "/" + imageID + "/" + variant + ".jpg"
+}
+
+func (im *imageStore) url(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return im.baseURL + "/" + strings.Join(segments, "/")
+}
+
+// AddRecipeImage resizes an uploaded image into its variants, stores them
+// and adds the image to the recipe. Data that is not a supported image
+// returns imaging.ErrUnsupportedType, and one with too many pixels
+// imaging.ErrTooLarge.
+func (ctrl *Controller) AddRecipeImage(ctx context.Context, id model.RecipeID, data []byte) (model.Image, error) {
+	ctx, span := tracer.Start(ctx, "Controller.AddRecipeImage", trace.WithAttributes(
+		tracing.AttrRecipeID.String(string(id)),
+		tracing.AttrImageSize.Int(len(data)),
+	))
+	before, updated, image, err := ctrl.addRecipeImage(ctx, id, data)
+	tracing.End(span, err)
+	ctrl.recordImageChange(ctx, id, before, updated, err)
+
+	if err != nil {
+		return model.Image{}, err
+	}
+	return image, nil
+}
+
+func (ctrl *Controller) addRecipeImage(ctx context.Context, id model.RecipeID, data []byte) (before, updated model.Recipe, image model.Image, err error) {
+	if ctrl.images == nil {
+		return model.Recipe{}, model.Recipe{}, model.Image{}, ErrImagesDisabled
+	}
+	before, err = ctrl.repo.GetByID(ctx, id)
+	if err != nil {
+		return model.Recipe{}, model.Recipe{}, model.Image{}, err
+	}
+
+	v := &validator{limits: ctrl.limits}
+	if max := ctrl.limits.MaxImages; max > 0 && len(before.Images) >= max {
+		v.add("images", "must have at most %d entries", max)
+	}
+	if max := ctrl.limits.MaxImageBytes; max > 0 && len(data) > max {
+		v.add("image", "must be at most %d bytes, got %d", max, len(data))
+	}
+	if err := v.err(); err != nil {
+		return before, model.Recipe{}, model.Image{}, err
+	}
+
+	result, err := imaging.Process(data, ctrl.limits.MaxImagePixels)
+	if err != nil {
+		return before, model.Recipe{}, model.Image{}, err
+	}
+	image = model.Image{ID: xid.New().String(), Width: result.Width, Height: result.Height}
+	var keys []string
+	for _, variant := range result.Variants {
+		key := imageKey(id, image.ID, variant.Name)
+		if err := ctrl.images.store.Put(ctx, key, variant.Data, "image/jpeg"); err != nil {
+			ctrl.deleteBlobs(ctx, keys)
+			return before, model.Recipe{}, model.Image{}, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+		}
+		keys = append(keys, key)
+		image.Variants = append(image.Variants, model.ImageVariant{
+			Name: variant.Name, Width: variant.Width, Height: variant.Height, URL: ctrl.images.url(key),
+		})
+	}
+
+	updated, err = domain.AddImage(ctx, ctrl.repo, id, image)
+	if err != nil {
+		ctrl.deleteBlobs(ctx, keys)
+		return before, model.Recipe{}, model.Image{}, err
+	}
+	return before, updated, image, nil
+}
+
+// DeleteRecipeImage removes an image from the recipe and deletes its
+// variants. An unknown image returns domain.ErrImageNotFound.
+func (ctrl *Controller) DeleteRecipeImage(ctx context.Context, id model.RecipeID, imageID string) error {
+	ctx, span := tracer.Start(ctx, "Controller.DeleteRecipeImage", trace.WithAttributes(tracing.AttrRecipeID.String(string(id))))
+	before, updated, err := ctrl.deleteRecipeImage(ctx, id, imageID)
+	tracing.End(span, err)
+	ctrl.recordImageChange(ctx, id, before, updated, err)
+	return err
+}
+
+func (ctrl *Controller) deleteRecipeImage(ctx context.Context, id model.RecipeID, imageID string) (model.Recipe, model.Recipe, error) {
+	if ctrl.images == nil {
+		return model.Recipe{}, model.Recipe{}, ErrImagesDisabled
+	}
+	before, err := ctrl.repo.GetByID(ctx, id)
+	if err != nil {
+		return model.Recipe{}, model.Recipe{}, err
+	}
+	i := slices.IndexFunc(before.Images, func(image model.Image) bool { return image.ID == imageID })
+	if i < 0 {
+		return before, model.Recipe{}, domain.ErrImageNotFound
+	}
+
+	updated, err := domain.RemoveImage(ctx, ctrl.repo, id, imageID)
+	if err != nil {
+		return before, model.Recipe{}, err
+	}
+	ctrl.deleteImages(ctx, id, before.Images[i])
+	return before, updated, nil
+}
+
+// recordImageChange audits adding or removing an image as an update of the recipe.
+func (ctrl *Controller) recordImageChange(ctx context.Context, id model.RecipeID, before, updated model.Recipe, err error) {
+	ev := audit.Event{Action: audit.ActionRecipeUpdate, Target: string(id)}
+	if before.ID != "" {
+		ev.BeforeHash = audit.HashRecipe(before)
+	}
+	if err == nil {
+		ev.AfterHash = audit.HashRecipe(updated)
+	}
+	ctrl.record(ctx, ev, err)
+}
+
+// deleteImages deletes the variants of images of a recipe that no longer
+// refers to them.
+func (ctrl *Controller) deleteImages(ctx context.Context, id model.RecipeID, images ...model.Image) {
+	if ctrl.images == nil {
+		return
+	}
+	var keys []string
+	for _, image := range images {
+		for _, variant := range image.Variants {
+			keys = append(keys, imageKey(id, image.ID, variant.Name))
+		}
+	}
+	ctrl.deleteBlobs(ctx, keys)
+}
+
+// deleteBlobs deletes stored objects. A failure leaves an unreferenced
+// object behind, so it is logged rather than returned.
+func (ctrl *Controller) deleteBlobs(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := ctrl.images.store.Delete(ctx, key); err != nil {
+			slog.WarnContext(ctx, "failed to delete image", "key", key, "error", err)
+		}
+	}
+}
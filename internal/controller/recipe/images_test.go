@@ -0,0 +1,178 @@
+package recipe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/blob"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/imaging"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 400, 300))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// storedFiles lists the files below dir, relative to it.
+func storedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return files
+}
+
+// imageRepo keeps one recipe, r1, that updates replace.
+func imageRepo() *mockRepo {
+	repo := &mockRepo{recipes: []model.Recipe{{ID: "r1", Name: "Soup"}}}
+	repo.updateFunc = func(ctx context.Context, recipe model.Recipe) (model.Recipe, error) {
+		repo.recipes[0] = recipe
+		return recipe, nil
+	}
+	return repo
+}
+
+func TestAddAndDeleteRecipeImage(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := blob.NewFS(dir)
+	repo := imageRepo()
+	ctrl := New(repo, WithImages(store, "/images/"))
+	ctx := context.Background()
+
+	img, err := ctrl.AddRecipeImage(ctx, "r1", testPNG(t))
+	if err != nil {
+		t.Fatalf("AddRecipeImage: %v", err)
+	}
+	if img.ID == "" || img.Width != 400 || img.Height != 300 || len(img.Variants) != len(imaging.Sizes) {
+		t.Fatalf("unexpected image %+v", img)
+	}
+	if want := "/images/recipes/r1/" + img.ID + "/thumbnail.jpg"; img.Variants[0].URL != want {
+		t.Errorf("thumbnail URL = %q, want %q", img.Variants[0].URL, want)
+	}
+	if len(repo.recipes[0].Images) != 1 || repo.recipes[0].Images[0].ID != img.ID {
+		t.Errorf("expected the image stored with the recipe, got %+v", repo.recipes[0].Images)
+	}
+	if files := storedFiles(t, dir); len(files) != 3 || !strings.HasPrefix(files[0], "recipes/r1/"+img.ID+"/") {
+		t.Errorf("expected 3 stored variants, got %v", files)
+	}
+
+	if err := ctrl.DeleteRecipeImage(ctx, "r1", "nope"); !errors.Is(err, domain.ErrImageNotFound) {
+		t.Errorf("expected ErrImageNotFound, got %v", err)
+	}
+	if err := ctrl.DeleteRecipeImage(ctx, "r1", img.ID); err != nil {
+		t.Fatalf("DeleteRecipeImage: %v", err)
+	}
+	if len(repo.recipes[0].Images) != 0 {
+		t.Error("expected the image removed from the recipe")
+	}
+	if files := storedFiles(t, dir); len(files) != 0 {
+		t.Errorf("expected the variants deleted, got %v", files)
+	}
+}
+
+func TestAddRecipeImageFailures(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := blob.NewFS(dir)
+	repo := imageRepo()
+	limits := DefaultLimits()
+	limits.MaxImages = 1
+	ctrl := New(repo, WithImages(store, "/images"), WithLimits(limits))
+	ctx := context.Background()
+
+	if _, err := ctrl.AddRecipeImage(ctx, "r1", []byte("GIF? no, plain text")); !errors.Is(err, imaging.ErrUnsupportedType) {
+		t.Errorf("expected ErrUnsupportedType, got %v", err)
+	}
+	if _, err := ctrl.AddRecipeImage(ctx, "missing", testPNG(t)); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	// A failed update leaves no variants behind.
+	updateErr := errors.New("update failed")
+	update := repo.updateFunc
+	repo.updateFunc = func(context.Context, model.Recipe) (model.Recipe, error) { return model.Recipe{}, updateErr }
+	if _, err := ctrl.AddRecipeImage(ctx, "r1", testPNG(t)); !errors.Is(err, updateErr) {
+		t.Errorf("expected the update error, got %v", err)
+	}
+	if files := storedFiles(t, dir); len(files) != 0 {
+		t.Errorf("expected no stored variants after a failed update, got %v", files)
+	}
+	repo.updateFunc = update
+
+	if _, err := ctrl.AddRecipeImage(ctx, "r1", testPNG(t)); err != nil {
+		t.Fatalf("AddRecipeImage: %v", err)
+	}
+	if _, err := ctrl.AddRecipeImage(ctx, "r1", testPNG(t)); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("expected the image limit to apply, got %v", err)
+	}
+
+	if _, err := New(repo).AddRecipeImage(ctx, "r1", testPNG(t)); !errors.Is(err, ErrImagesDisabled) {
+		t.Errorf("expected ErrImagesDisabled, got %v", err)
+	}
+}
+
+func TestDeleteRecipeDeletesImages(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := blob.NewFS(dir)
+	repo := imageRepo()
+	repo.recipes = append(repo.recipes, model.Recipe{ID: "r2", Name: "Bread"})
+	ctrl := New(repo, WithImages(store, "/images"))
+	ctx := context.Background()
+
+	if _, err := ctrl.AddRecipeImage(ctx, "r1", testPNG(t)); err != nil {
+		t.Fatalf("AddRecipeImage: %v", err)
+	}
+	if err := ctrl.DeleteRecipe(ctx, "r1"); err != nil {
+		t.Fatalf("DeleteRecipe: %v", err)
+	}
+	if files := storedFiles(t, dir); len(files) != 0 {
+		t.Errorf("expected the recipe's images deleted, got %v", files)
+	}
+
+	// A batch delete cleans up too, but only for the recipes it deleted.
+	repo.updateFunc = func(ctx context.Context, recipe model.Recipe) (model.Recipe, error) {
+		repo.recipes[1] = recipe
+		return recipe, nil
+	}
+	if _, err := ctrl.AddRecipeImage(ctx, "r2", testPNG(t)); err != nil {
+		t.Fatalf("AddRecipeImage: %v", err)
+	}
+	repo.deleteFunc = func(context.Context, model.RecipeID) error { return domain.ErrPersistence }
+	ctrl.DeleteRecipes(ctx, []model.RecipeID{"r2"}, domain.BatchBestEffort)
+	if files := storedFiles(t, dir); len(files) != 3 {
+		t.Errorf("expected the images of a recipe that failed to delete kept, got %v", files)
+	}
+	repo.deleteFunc = nil
+	if _, err := ctrl.DeleteRecipes(ctx, []model.RecipeID{"r2"}, domain.BatchBestEffort); err != nil {
+		t.Fatalf("DeleteRecipes: %v", err)
+	}
+	if files := storedFiles(t, dir); len(files) != 0 {
+		t.Errorf("expected the batch-deleted recipe's images deleted, got %v", files)
+	}
+}
+
+func TestCreateRecipeRejectsImages(t *testing.T) {
+	ctrl := New(&mockRepo{})
+	_, _, err := ctrl.CreateRecipe(context.Background(), model.Recipe{Name: "Soup", Images: []model.Image{{ID: "x"}}}, DuplicatesWarn)
+	var verr *domain.ValidationError
+	if !errors.As(err, &verr) || verr.Fields[0].Field != "images" {
+		t.Errorf("expected images to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package recipe
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/ingredient"
+	"github.com/gin-demo/recipes-web/internal/tracing"
+	"github.com/gin-demo/recipes-web/model"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Pantry matching defaults and bounds.
+const (
+	DefaultMaxMissing = 2
+	DefaultMatchLimit = 20
+	MaxMatchLimit     = 100
+)
+
+// MatchQuery describes what someone has in their kitchen.
+type MatchQuery struct {
+	// Ingredients are names or ingredient lines, such as "green onions"
+	Ingredients []string
+	// Ignore adds to ingredient.Staples, which never count as missing
+	Ignore []string
+	// MaxMissing drops recipes lacking more ingredients
+	MaxMissing int
+	// Limit bounds the matches returned; zero means DefaultMatchLimit
+	Limit int
+}
+
+// PantryMatch is a recipe and how much of it a pantry covers.
+type PantryMatch struct {
+	Recipe model.Recipe
+	// Needed counts the recipe's ingredients that are not staples
+	Needed int
+	// Missing are the ingredient lines the pantry lacks
+	Missing []string
+}
+
+// Coverage is the share of the needed ingredients at hand, from 0 to 1.
+func (m PantryMatch) Coverage() float64 {
+	return float64(m.Needed-len(m.Missing)) / float64(m.Needed)
+}
+
+// MatchPantry ranks the recipes that can be made from the ingredients in
+// query: those missing nothing first, then by the number missing, then by
+// coverage and name. Recipes without ingredients other than staples are left out.
+func (ctrl *Controller) MatchPantry(ctx context.Context, query MatchQuery) ([]PantryMatch, error) {
+	if err := ctrl.validateMatch(query); err != nil {
+		return nil, err
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultMatchLimit
+	}
+
+	ctx, span := tracer.Start(ctx, "Controller.MatchPantry", trace.WithAttributes(tracing.AttrBatchSize.Int(len(query.Ingredients))))
+	pantry := ingredient.NewPantry(query.Ingredients, query.Ignore)
+	var matches []PantryMatch
+	err := domain.Stream(ctx, ctrl.repo, domain.RecipeFilter{}, func(recipe model.Recipe) error {
+		c := pantry.Check(recipe.Ingredients)
+		if c.Needed > 0 && len(c.Missing) <= query.MaxMissing {
+			matches = append(matches, PantryMatch{Recipe: recipe, Needed: c.Needed, Missing: c.Missing})
+		}
+		return nil
+	})
+	span.SetAttributes(tracing.AttrResults.Int(len(matches)))
+	tracing.End(span, err)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(matches, func(a, b PantryMatch) int {
+		return cmp.Or(
+			cmp.Compare(len(a.Missing), len(b.Missing)),
+			cmp.Compare(b.Coverage(), a.Coverage()),
+			cmp.Compare(a.Recipe.Name, b.Recipe.Name),
+			cmp.Compare(a.Recipe.ID, b.Recipe.ID),
+		)
+	})
+	if len(matches) > query.Limit {
+		matches = matches[:query.Limit]
+	}
+	if matches == nil {
+		matches = []PantryMatch{}
+	}
+	return matches, nil
+}
+
+func (ctrl *Controller) validateMatch(query MatchQuery) error {
+	if err := ctrl.checkBatch("ingredients", len(query.Ingredients)); err != nil {
+		return err
+	}
+	v := &validator{limits: ctrl.limits}
+	if ctrl.limits.MaxBatchSize > 0 && len(query.Ignore) > ctrl.limits.MaxBatchSize {
+		v.add("ignore", "must have at most %d entries, got %d", ctrl.limits.MaxBatchSize, len(query.Ignore))
+	}
+	if query.MaxMissing < 0 {
+		v.add("maxMissing", "must not be negative")
+	}
+	if query.Limit < 0 || query.Limit > MaxMatchLimit {
+		v.add("limit", "must be between 1 and %d", MaxMatchLimit)
+	}
+	return v.err()
+}
@@ -0,0 +1,75 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/tracing"
+	"github.com/gin-demo/recipes-web/model"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Similar recipe defaults and bounds.
+const (
+	DefaultSimilarLimit = 10
+	MaxSimilarLimit     = 50
+)
+
+// SimilarRecipe is a recipe related to another and how closely, from 0 to 1.
+type SimilarRecipe struct {
+	Recipe model.Recipe
+	Score  float64
+}
+
+// SimilarRecipes returns up to limit recipes most like the recipe with id,
+// best first; a limit of zero means DefaultSimilarLimit. It fails with
+// similar.ErrNotReady until the index has been built.
+func (ctrl *Controller) SimilarRecipes(ctx context.Context, id model.RecipeID, limit int) ([]SimilarRecipe, error) {
+	if limit < 0 || limit > MaxSimilarLimit {
+		v := &validator{limits: ctrl.limits}
+		v.add("limit", "must be between 1 and %d", MaxSimilarLimit)
+		return nil, v.err()
+	}
+	if limit == 0 {
+		limit = DefaultSimilarLimit
+	}
+
+	ctx, span := tracer.Start(ctx, "Controller.SimilarRecipes", trace.WithAttributes(tracing.AttrRecipeID.String(string(id))))
+	results, err := ctrl.similarRecipes(ctx, id, limit)
+	span.SetAttributes(tracing.AttrResults.Int(len(results)))
+	tracing.End(span, err)
+	return results, err
+}
+
+func (ctrl *Controller) similarRecipes(ctx context.Context, id model.RecipeID, limit int) ([]SimilarRecipe, error) {
+	recipe, err := ctrl.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := ctrl.similar.Similar(recipe, limit)
+	if err != nil || len(matches) == 0 {
+		return []SimilarRecipe{}, err
+	}
+
+	ids := make([]model.RecipeID, len(matches))
+	for i, m := range matches {
+		ids[i] = m.ID
+	}
+	recipes, errs, err := domain.Batch(ctrl.repo).GetMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]SimilarRecipe, 0, len(matches))
+	for i, m := range matches {
+		switch {
+		case errors.Is(errs[i], domain.ErrNotFound):
+			// Deleted elsewhere since the index last saw it.
+			continue
+		case errs[i] != nil:
+			return nil, errs[i]
+		}
+		results = append(results, SimilarRecipe{Recipe: recipes[i], Score: m.Score})
+	}
+	return results, nil
+}
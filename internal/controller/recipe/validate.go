@@ -0,0 +1,372 @@
+package recipe
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/normalize"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// Limits bounds the recipes the controller accepts. A zero limit disables its check.
+type Limits struct {
+	// MaxNameLength is counted in characters
+	MaxNameLength int
+	MaxTags       int
+	MaxTagLength  int
+	// MaxIngredients and MaxSteps bound the number of entries
+	MaxIngredients int
+	MaxSteps       int
+	// MaxEquipment bounds the number of tools listed
+	MaxEquipment int
+	// MaxItemLength bounds each ingredient and step, in characters
+	MaxItemLength int
+	// MaxPayloadBytes bounds the recipe encoded as JSON
+	MaxPayloadBytes int
+	// MaxBatchSize bounds the number of items in one batch request
+	MaxBatchSize int
+	// MaxImages bounds the images of one recipe
+	MaxImages int
+	// MaxImageBytes bounds an uploaded image; MaxImagePixels bounds its
+	// decoded size, width times height
+	MaxImageBytes  int
+	MaxImagePixels int
+}
+
+// DefaultLimits returns limits that every recipe in the sample data fits comfortably.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxNameLength:   200,
+		MaxTags:         20,
+		MaxTagLength:    40,
+		MaxIngredients:  100,
+		MaxSteps:        200,
+		MaxEquipment:    50,
+		MaxItemLength:   2000,
+		MaxPayloadBytes: 64 << 10,
+		MaxBatchSize:    500,
+		MaxImages:       20,
+		MaxImageBytes:   10 << 20,
+		MaxImagePixels:  40_000_000,
+	}
+}
+
+// WithLimits replaces the default validation limits.
+func WithLimits(limits Limits) Option {
+	return func(ctrl *Controller) {
+		ctrl.limits = limits
+	}
+}
+
+// Limits returns the limits recipes are validated against.
+func (ctrl *Controller) Limits() Limits {
+	return ctrl.limits
+}
+
+// tagPattern allows letters, digits, spaces, hyphens and underscores, starting with a letter or digit.
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N} _-]*$`)
+
+// validator collects field errors for one recipe.
+type validator struct {
+	limits Limits
+	errs   []domain.FieldError
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.errs = append(v.errs, domain.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &domain.ValidationError{Fields: v.errs}
+}
+
+func (v *validator) name(name string) {
+	switch n := utf8.RuneCountInString(name); {
+	case n == 0:
+		v.add("name", "is required")
+	case v.limits.MaxNameLength > 0 && n > v.limits.MaxNameLength:
+		v.add("name", "must be at most %d characters, got %d", v.limits.MaxNameLength, n)
+	}
+}
+
+func (v *validator) tags(tags []string) {
+	if v.limits.MaxTags > 0 && len(tags) > v.limits.MaxTags {
+		v.add("tags", "must have at most %d entries, got %d", v.limits.MaxTags, len(tags))
+	}
+	for i, tag := range tags {
+		v.keyword(fmt.Sprintf("tags[%d]", i), tag)
+	}
+}
+
+// keyword checks a value that follows the tag rules, such as a tag or a cuisine.
+func (v *validator) keyword(field, value string) {
+	if !tagPattern.MatchString(value) {
+		v.add(field, "must contain only letters, digits, spaces, hyphens and underscores")
+	}
+	if n := utf8.RuneCountInString(value); v.limits.MaxTagLength > 0 && n > v.limits.MaxTagLength {
+		v.add(field, "must be at most %d characters, got %d", v.limits.MaxTagLength, n)
+	}
+}
+
+func (v *validator) ingredients(ingredients []string) {
+	v.items("ingredients", ingredients, v.limits.MaxIngredients)
+	seen := make(map[string]int, len(ingredients))
+	for i, ingredient := range ingredients {
+		key := strings.ToLower(ingredient)
+		if first, ok := seen[key]; ok {
+			v.add(fmt.Sprintf("ingredients[%d]", i), "duplicates ingredients[%d]", first)
+			continue
+		}
+		seen[key] = i
+	}
+}
+
+func (v *validator) instructions(instructions []string) {
+	v.items("instructions", instructions, v.limits.MaxSteps)
+}
+
+func (v *validator) yield(yield string) {
+	if v.limits.MaxItemLength > 0 {
+		if n := utf8.RuneCountInString(yield); n > v.limits.MaxItemLength {
+			v.add("yield", "must be at most %d characters, got %d", v.limits.MaxItemLength, n)
+		}
+	}
+}
+
+func (v *validator) times(r model.Recipe) {
+	for _, d := range []struct{ field, value string }{
+		{"prepTime", r.PrepTime}, {"cookTime", r.CookTime}, {"totalTime", r.TotalTime},
+	} {
+		if d.value == "" {
+			continue
+		}
+		if _, err := model.ParseDuration(d.value); err != nil {
+			v.add(d.field, "must be an ISO 8601 duration such as PT1H30M")
+		}
+	}
+}
+
+func (v *validator) difficulty(difficulty string) {
+	if difficulty != "" && !slices.Contains(model.Difficulties, difficulty) {
+		v.add("difficulty", "must be one of %s", strings.Join(model.Difficulties, ", "))
+	}
+}
+
+func (v *validator) cuisine(cuisine string) {
+	if cuisine != "" {
+		v.keyword("cuisine", cuisine)
+	}
+}
+
+func (v *validator) course(course string) {
+	if course != "" {
+		v.keyword("course", course)
+	}
+}
+
+func (v *validator) equipment(equipment []string) {
+	v.items("equipment", equipment, v.limits.MaxEquipment)
+}
+
+func (v *validator) diets(diets []string) {
+	for i, diet := range diets {
+		if !slices.Contains(model.Diets, diet) {
+			v.add(fmt.Sprintf("diets[%d]", i), "must be one of %s", strings.Join(model.Diets, ", "))
+		}
+	}
+}
+
+func (v *validator) items(field string, items []string, max int) {
+	if max > 0 && len(items) > max {
+		v.add(field, "must have at most %d entries, got %d", max, len(items))
+	}
+	if v.limits.MaxItemLength <= 0 {
+		return
+	}
+	for i, item := range items {
+		if n := utf8.RuneCountInString(item); n > v.limits.MaxItemLength {
+			v.add(fmt.Sprintf("%s[%d]", field, i), "must be at most %d characters, got %d", v.limits.MaxItemLength, n)
+		}
+	}
+}
+
+func (v *validator) payload(r model.Recipe) {
+	if v.limits.MaxPayloadBytes <= 0 {
+		return
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		v.add("", "cannot be encoded: %v", err)
+		return
+	}
+	if len(data) > v.limits.MaxPayloadBytes {
+		v.add("", "recipe is %d bytes, more than the limit of %d", len(data), v.limits.MaxPayloadBytes)
+	}
+}
+
+// validateNew checks a normalized recipe about to be created.
+func (ctrl *Controller) validateNew(r model.Recipe) error {
+	v := &validator{limits: ctrl.limits}
+	if r.ID != "" {
+		v.add("id", "is assigned by the server and must not be set")
+	}
+	v.recipe(r)
+	if len(r.Images) > 0 {
+		v.add("images", "must not be set; upload images once the recipe exists")
+	}
+	v.payload(r)
+	return v.err()
+}
+
+// Validate checks a normalized recipe against limits the way a create does,
+// for recipes written under their own IDs by imports and seeding. The ID and
+// images are left to the caller.
+func Validate(r model.Recipe, limits Limits) error {
+	v := &validator{limits: limits}
+	v.recipe(r)
+	v.payload(r)
+	return v.err()
+}
+
+// recipe checks every field a client sets.
+func (v *validator) recipe(r model.Recipe) {
+	v.name(r.Name)
+	v.tags(r.Tags)
+	v.ingredients(r.Ingredients)
+	v.instructions(r.Instructions)
+	v.yield(r.Yield)
+	v.times(r)
+	v.difficulty(r.Difficulty)
+	v.cuisine(r.Cuisine)
+	v.course(r.Course)
+	v.equipment(r.Equipment)
+	v.diets(r.Diets)
+}
+
+// validateUpdate checks the fields cmd changes on the normalized, updated
+// recipe, so stored data that predates a rule does not block unrelated edits.
+func (ctrl *Controller) validateUpdate(r model.Recipe, cmd UpdateRecipeCommand) error {
+	v := &validator{limits: ctrl.limits}
+	if cmd.Name != nil {
+		v.name(r.Name)
+	}
+	if cmd.Tags != nil {
+		v.tags(r.Tags)
+	}
+	if cmd.Ingredients != nil {
+		v.ingredients(r.Ingredients)
+	}
+	if cmd.Yield != nil {
+		v.yield(r.Yield)
+	}
+	if cmd.PrepTime != nil || cmd.CookTime != nil || cmd.TotalTime != nil {
+		v.times(r)
+	}
+	if cmd.Difficulty != nil {
+		v.difficulty(r.Difficulty)
+	}
+	if cmd.Cuisine != nil {
+		v.cuisine(r.Cuisine)
+	}
+	if cmd.Course != nil {
+		v.course(r.Course)
+	}
+	if cmd.Equipment != nil {
+		v.equipment(r.Equipment)
+	}
+	if cmd.Diets != nil {
+		v.diets(r.Diets)
+	}
+	v.payload(r)
+	return v.err()
+}
+
+// validateMerged checks the fields a merge changed on the normalized, merged
+// recipe, as an update setting them would.
+func (ctrl *Controller) validateMerged(before, merged model.Recipe) error {
+	v := &validator{limits: ctrl.limits}
+	if !slices.Equal(before.Tags, merged.Tags) {
+		v.tags(merged.Tags)
+	}
+	if !slices.Equal(before.Ingredients, merged.Ingredients) {
+		v.ingredients(merged.Ingredients)
+	}
+	if !slices.Equal(before.Instructions, merged.Instructions) {
+		v.instructions(merged.Instructions)
+	}
+	if before.Yield != merged.Yield {
+		v.yield(merged.Yield)
+	}
+	if before.PrepTime != merged.PrepTime || before.CookTime != merged.CookTime || before.TotalTime != merged.TotalTime {
+		v.times(merged)
+	}
+	if before.Difficulty != merged.Difficulty {
+		v.difficulty(merged.Difficulty)
+	}
+	if before.Cuisine != merged.Cuisine {
+		v.cuisine(merged.Cuisine)
+	}
+	if before.Course != merged.Course {
+		v.course(merged.Course)
+	}
+	if !slices.Equal(before.Equipment, merged.Equipment) {
+		v.equipment(merged.Equipment)
+	}
+	if !slices.Equal(before.Diets, merged.Diets) {
+		v.diets(merged.Diets)
+	}
+	v.payload(merged)
+	return v.err()
+}
+
+// normalizeFilter cleans filter values the way normalize cleans the fields they are compared with.
+func normalizeFilter(f domain.RecipeFilter) domain.RecipeFilter {
+	f.Cuisine = keyword(f.Cuisine)
+	f.Course = keyword(f.Course)
+	f.Difficulty = keyword(f.Difficulty)
+	f.Tags = keywords(f.Tags)
+	f.AnyTags = keywords(f.AnyTags)
+	f.ExcludeTags = keywords(f.ExcludeTags)
+	f.Diets = keywords(f.Diets)
+	return f
+}
+
+func keyword(s string) string {
+	return strings.ToLower(normalize.Text(s))
+}
+
+// keywords cleans each value and drops those left empty.
+func keywords(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = keyword(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// validateFilter rejects filter values no recipe can have, which are most
+// likely typos. Fields are named after the query parameters.
+func validateFilter(f domain.RecipeFilter) error {
+	v := &validator{}
+	v.difficulty(f.Difficulty)
+	for _, diet := range f.Diets {
+		if !slices.Contains(model.Diets, diet) {
+			v.add("diet", "must be one of %s", strings.Join(model.Diets, ", "))
+			break
+		}
+	}
+	if f.MaxTotalTime < 0 {
+		v.add("maxTotalTime", "must not be negative")
+	}
+	return v.err()
+}
@@ -0,0 +1,153 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+func fieldsOf(t *testing.T, err error) map[string]string {
+	t.Helper()
+	var verr *domain.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *domain.ValidationError, got %v", err)
+	}
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected validation error to match ErrInvalidInput")
+	}
+	fields := make(map[string]string, len(verr.Fields))
+	for _, f := range verr.Fields {
+		fields[f.Field] = f.Message
+	}
+	return fields
+}
+
+func TestControllerCreateRecipeReportsAllViolations(t *testing.T) {
+	created := false
+	repo := &mockRepo{createFunc: func(ctx context.Context, r model.Recipe) (model.Recipe, error) {
+		created = true
+		return r, nil
+	}}
+	ctrl := New(repo, WithLimits(Limits{MaxTags: 2, MaxSteps: 1}))
+
+	_, _, err := ctrl.CreateRecipe(context.Background(), model.Recipe{
+		ID:           "client-id",
+		Name:         "   ",
+		Tags:         []string{"quick", "main,", "dinner"},
+		Ingredients:  []string{"2 eggs", "Salt", "2  Eggs"},
+		Instructions: []string{"Beat", "Cook"},
+	}, DuplicatesWarn)
+
+	fields := fieldsOf(t, err)
+	for _, field := range []string{"id", "name", "tags", "tags[1]", "ingredients[2]", "instructions"} {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("Expected an error for %s, got %v", field, fields)
+		}
+	}
+	if len(fields) != 6 {
+		t.Errorf("Expected 6 field errors, got %v", fields)
+	}
+	if created {
+		t.Error("Invalid recipe should not reach the repository")
+	}
+}
+
+func TestControllerCreateRecipeLengthLimits(t *testing.T) {
+	ctrl := New(&mockRepo{}, WithLimits(Limits{MaxNameLength: 5, MaxTagLength: 3, MaxItemLength: 4, MaxPayloadBytes: 150}))
+
+	_, _, err := ctrl.CreateRecipe(context.Background(), model.Recipe{
+		Name:         "Omelette",
+		Tags:         []string{"eggs"},
+		Ingredients:  []string{"eggs", "butter"},
+		Instructions: []string{strings.Repeat("x", 100)},
+	}, DuplicatesWarn)
+
+	fields := fieldsOf(t, err)
+	for _, field := range []string{"name", "tags[0]", "ingredients[1]", "instructions[0]", ""} {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("Expected an error for %q, got %v", field, fields)
+		}
+	}
+}
+
+func TestControllerUpdateRecipeValidatesChangedFields(t *testing.T) {
+	// The stored recipe breaks the tag rule; renaming it must still work.
+	repo := &mockRepo{recipes: []model.Recipe{{ID: "1", Name: "Old", Tags: []string{"main,"}}}}
+	ctrl := New(repo)
+
+	name := "New"
+	if _, err := ctrl.UpdateRecipe(context.Background(), "1", UpdateRecipeCommand{Name: &name}); err != nil {
+		t.Fatalf("UpdateRecipe failed: %v", err)
+	}
+
+	empty := " "
+	_, err := ctrl.UpdateRecipe(context.Background(), "1", UpdateRecipeCommand{Name: &empty, Ingredients: []string{"a", "A"}})
+	fields := fieldsOf(t, err)
+	if len(fields) != 2 || fields["name"] == "" || fields["ingredients[1]"] == "" {
+		t.Errorf("Expected name and ingredients[1] errors, got %v", fields)
+	}
+}
+
+func TestControllerCreateRecipeValidatesTimes(t *testing.T) {
+	ctrl := New(&mockRepo{})
+
+	_, _, err := ctrl.CreateRecipe(context.Background(), model.Recipe{
+		Name:      "Soup",
+		PrepTime:  "pt10m",
+		CookTime:  "20 minutes",
+		TotalTime: "P1M",
+	}, DuplicatesWarn)
+
+	fields := fieldsOf(t, err)
+	if len(fields) != 2 || fields["cookTime"] == "" || fields["totalTime"] == "" {
+		t.Errorf("Expected errors for cookTime and totalTime only, got %v", fields)
+	}
+}
+
+func TestControllerValidatesMetadata(t *testing.T) {
+	ctrl := New(&mockRepo{}, WithLimits(Limits{MaxIngredients: 1, MaxEquipment: 1}))
+
+	_, _, err := ctrl.CreateRecipe(context.Background(), model.Recipe{
+		Name:       "Soup",
+		Difficulty: "trivial",
+		Cuisine:    "indian;",
+		Course:     "main",
+		Equipment:  []string{"pot", "ladle"},
+		Diets:      []string{"Vegan", "paleo"},
+	}, DuplicatesWarn)
+	fields := fieldsOf(t, err)
+	if len(fields) != 4 || fields["difficulty"] == "" || fields["cuisine"] == "" || fields["equipment"] == "" || fields["diets[1]"] == "" {
+		t.Errorf("Expected difficulty, cuisine, equipment and diets[1] errors, got %v", fields)
+	}
+
+	repo := &mockRepo{recipes: []model.Recipe{{ID: "1", Name: "Soup", Difficulty: "trivial"}}}
+	ctrl = New(repo)
+	updated, err := ctrl.UpdateRecipe(context.Background(), "1", UpdateRecipeCommand{Cuisine: stringPtr("Thai"), TotalTime: stringPtr("pt20m")})
+	if err != nil {
+		t.Fatalf("UpdateRecipe failed: %v", err)
+	}
+	if updated.Cuisine != "thai" || updated.TotalTime != "PT20M" {
+		t.Errorf("Expected normalized cuisine and total time, got %q and %q", updated.Cuisine, updated.TotalTime)
+	}
+	_, err = ctrl.UpdateRecipe(context.Background(), "1", UpdateRecipeCommand{Difficulty: stringPtr("hard-ish"), CookTime: stringPtr("soon")})
+	fields = fieldsOf(t, err)
+	if len(fields) != 2 || fields["difficulty"] == "" || fields["cookTime"] == "" {
+		t.Errorf("Expected difficulty and cookTime errors, got %v", fields)
+	}
+}
+
+func TestValidateImportedRecipe(t *testing.T) {
+	r := model.Recipe{ID: "kept", Name: "Soup", Tags: []string{"a", "b"}, Difficulty: "trivial", Images: []model.Image{{ID: "img"}}}
+	fields := fieldsOf(t, Validate(r, Limits{MaxTags: 1}))
+	if len(fields) != 2 || fields["tags"] == "" || fields["difficulty"] == "" {
+		t.Errorf("Expected tags and difficulty errors only, got %v", fields)
+	}
+
+	if err := Validate(model.Recipe{ID: "kept", Name: "Soup"}, DefaultLimits()); err != nil {
+		t.Errorf("Expected a valid recipe, got %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+// Package shopping builds shopping lists from recipes and keeps them per user.
+package shopping
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/tracing"
+	"github.com/gin-demo/recipes-web/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/gin-demo/recipes-web/internal/controller/shopping")
+
+// Bounds on the lists the controller accepts.
+const (
+	MaxRecipes    = 50
+	MaxServings   = 1000
+	MaxNameLength = 200
+)
+
+// Controller handles business logic for shopping lists. Every operation acts
+// for an owner, and lists of other owners are reported as not found.
+type Controller struct {
+	lists   domain.ShoppingListRepository
+	recipes domain.RecipeRepository
+}
+
+// New creates a new Controller storing lists in lists and reading the recipes
+// they are made from in recipes.
+func New(lists domain.ShoppingListRepository, recipes domain.RecipeRepository) *Controller {
+	return &Controller{lists: lists, recipes: recipes}
+}
+
+// RecipeServings selects a recipe for a list and how many servings to shop
+// for; zero Servings keeps the recipe's own yield.
+type RecipeServings struct {
+	ID       model.RecipeID
+	Servings int
+}
+
+// CreateListCommand describes a list to create.
+type CreateListCommand struct {
+	// Name defaults to the names of the recipes
+	Name    string
+	Recipes []RecipeServings
+}
+
+// CreateList builds a list for owner from the recipes in cmd, scaling each to
+// the servings asked for, and stores it. An unknown recipe fails with
+// domain.ErrNotFound.
+func (ctrl *Controller) CreateList(ctx context.Context, owner string, cmd CreateListCommand) (model.ShoppingList, error) {
+	ctx, span := tracer.Start(ctx, "Controller.CreateList", trace.WithAttributes(tracing.AttrBatchSize.Int(len(cmd.Recipes))))
+	list, err := ctrl.createList(ctx, owner, cmd)
+	tracing.End(span, err)
+	return list, err
+}
+
+func (ctrl *Controller) createList(ctx context.Context, owner string, cmd CreateListCommand) (model.ShoppingList, error) {
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	if err := validateCreate(cmd); err != nil {
+		return model.ShoppingList{}, err
+	}
+
+	ids := make([]model.RecipeID, len(cmd.Recipes))
+	servings := make([]int, len(cmd.Recipes))
+	for i, r := range cmd.Recipes {
+		ids[i], servings[i] = r.ID, r.Servings
+	}
+	recipes, errs, err := domain.Batch(ctrl.recipes).GetMany(ctx, ids)
+	if err != nil {
+		return model.ShoppingList{}, err
+	}
+	for i, err := range errs {
+		if err != nil {
+			return model.ShoppingList{}, fmt.Errorf("recipe %s: %w", ids[i], err)
+		}
+	}
+
+	list := Build(recipes, servings)
+	list.Owner = owner
+	list.Name = cmd.Name
+	if list.Name == "" {
+		list.Name = defaultName(recipes)
+	}
+	return ctrl.lists.CreateShoppingList(ctx, list)
+}
+
+// defaultName joins the names of recipes, cut to MaxNameLength characters.
+func defaultName(recipes []model.Recipe) string {
+	names := make([]string, len(recipes))
+	for i, recipe := range recipes {
+		names[i] = recipe.Name
+	}
+	name := strings.Join(names, ", ")
+	if utf8.RuneCountInString(name) > MaxNameLength {
+		name = string([]rune(name)[:MaxNameLength-1]) + "…"
+	}
+	return name
+}
+
+func validateCreate(cmd CreateListCommand) error {
+	var fields []domain.FieldError
+	add := func(field, format string, args ...any) {
+		fields = append(fields, domain.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n := utf8.RuneCountInString(cmd.Name); n > MaxNameLength {
+		add("name", "must be at most %d characters, got %d", MaxNameLength, n)
+	}
+	switch {
+	case len(cmd.Recipes) == 0:
+		add("recipes", "must not be empty")
+	case len(cmd.Recipes) > MaxRecipes:
+		add("recipes", "must have at most %d entries, got %d", MaxRecipes, len(cmd.Recipes))
+	}
+	for i, r := range cmd.Recipes {
+		switch {
+		case r.ID == "":
+			add(fmt.Sprintf("recipes[%d].id", i), "is required")
+		case slices.ContainsFunc(cmd.Recipes[:i], func(other RecipeServings) bool { return other.ID == r.ID }):
+			add(fmt.Sprintf("recipes[%d].id", i), "repeats recipe %s", r.ID)
+		}
+		if r.Servings < 0 || r.Servings > MaxServings {
+			add(fmt.Sprintf("recipes[%d].servings", i), "must be between 0 and %d", MaxServings)
+		}
+	}
+	if len(fields) > 0 {
+		return &domain.ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// ListLists returns owner's lists, newest first.
+func (ctrl *Controller) ListLists(ctx context.Context, owner string) ([]model.ShoppingList, error) {
+	ctx, span := tracer.Start(ctx, "Controller.ListLists")
+	lists, err := ctrl.lists.ListShoppingLists(ctx, owner)
+	span.SetAttributes(tracing.AttrResults.Int(len(lists)))
+	tracing.End(span, err)
+	return lists, err
+}
+
+// GetList returns one of owner's lists.
+func (ctrl *Controller) GetList(ctx context.Context, owner string, id model.ShoppingListID) (model.ShoppingList, error) {
+	ctx, span := tracer.Start(ctx, "Controller.GetList")
+	list, err := ctrl.get(ctx, owner, id)
+	tracing.End(span, err)
+	return list, err
+}
+
+// get returns the list with the given ID when it belongs to owner.
+func (ctrl *Controller) get(ctx context.Context, owner string, id model.ShoppingListID) (model.ShoppingList, error) {
+	list, err := ctrl.lists.GetShoppingList(ctx, id)
+	if err != nil {
+		return model.ShoppingList{}, err
+	}
+	if list.Owner != owner {
+		return model.ShoppingList{}, fmt.Errorf("shopping list %s: %w", id, domain.ErrListNotFound)
+	}
+	return list, nil
+}
+
+// CheckItem checks an item of one of owner's lists off, or back on when
+// checked is false. An unknown item returns domain.ErrItemNotFound.
+func (ctrl *Controller) CheckItem(ctx context.Context, owner string, id model.ShoppingListID, itemID string, checked bool) (model.ShoppingList, error) {
+	ctx, span := tracer.Start(ctx, "Controller.CheckItem")
+	list, err := ctrl.checkItem(ctx, owner, id, itemID, checked)
+	tracing.End(span, err)
+	return list, err
+}
+
+func (ctrl *Controller) checkItem(ctx context.Context, owner string, id model.ShoppingListID, itemID string, checked bool) (model.ShoppingList, error) {
+	list, err := ctrl.get(ctx, owner, id)
+	if err != nil {
+		return model.ShoppingList{}, err
+	}
+	i := slices.IndexFunc(list.Items, func(item model.ShoppingItem) bool { return item.ID == itemID })
+	if i < 0 {
+		return model.ShoppingList{}, fmt.Errorf("item %s: %w", itemID, domain.ErrItemNotFound)
+	}
+	if list.Items[i].Checked == checked {
+		return list, nil
+	}
+	list.Items = slices.Clone(list.Items)
+	list.Items[i].Checked = checked
+	return ctrl.lists.UpdateShoppingList(ctx, list)
+}
+
+// DeleteList deletes one of owner's lists.
+func (ctrl *Controller) DeleteList(ctx context.Context, owner string, id model.ShoppingListID) error {
+	ctx, span := tracer.Start(ctx, "Controller.DeleteList")
+	_, err := ctrl.get(ctx, owner, id)
+	if err == nil {
+		err = ctrl.lists.DeleteShoppingList(ctx, id)
+	}
+	tracing.End(span, err)
+	return err
+}
@@ -0,0 +1,186 @@
+package shopping
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/repository/memory"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+const recipesJSON = `[
+	{"id": "pancakes", "name": "Pancakes", "yield": "4 servings", "ingredients": [
+		"1 1/2 cups flour", "2 tbsp sugar", "2 eggs", "1 cup milk", "a pinch of salt"]},
+	{"id": "cake", "name": "Sponge Cake", "yield": "8 servings", "ingredients": [
+		"2 cups all-purpose flour", "1/2 cup sugar", "4 large eggs", "200 g butter", "1 tsp vanilla"]},
+	{"id": "salad", "name": "Salad", "ingredients": ["1 head lettuce", "Olive oil to taste"]}
+]`
+
+func newController(t *testing.T) *Controller {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "recipes.json")
+	if err := os.WriteFile(path, []byte(recipesJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	recipes, err := memory.New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lists, err := memory.NewShoppingListRepository(filepath.Join(dir, "lists.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(lists, recipes)
+}
+
+func item(list model.ShoppingList, name string) model.ShoppingItem {
+	i := slices.IndexFunc(list.Items, func(item model.ShoppingItem) bool { return item.Name == name })
+	if i < 0 {
+		return model.ShoppingItem{}
+	}
+	return list.Items[i]
+}
+
+func TestControllerCreateList(t *testing.T) {
+	ctrl := newController(t)
+	ctx := context.Background()
+
+	list, err := ctrl.CreateList(ctx, "ed", CreateListCommand{Recipes: []RecipeServings{
+		{ID: "pancakes", Servings: 8},
+		{ID: "cake"},
+		{ID: "salad", Servings: 2},
+	}})
+	if err != nil {
+		t.Fatalf("CreateList failed: %v", err)
+	}
+	if list.ID == "" || list.Owner != "ed" || list.Name != "Pancakes, Sponge Cake, Salad" {
+		t.Errorf("Expected a stored list named after its recipes, got %+v", list)
+	}
+	if got := []int{list.Recipes[0].Servings, list.Recipes[1].Servings, list.Recipes[2].Servings}; !slices.Equal(got, []int{8, 8, 0}) {
+		t.Errorf("Expected servings 8, 8 and 0 for the salad without a yield, got %v", got)
+	}
+
+	// The pancakes are doubled: 3 cups of flour and 4 eggs, plus the cake's.
+	if flour := item(list, "flour"); len(flour.Quantities) != 1 || flour.Quantities[0] != (model.Quantity{Amount: 5, Unit: "cup"}) || len(flour.Lines) != 2 {
+		t.Errorf("Expected 5 cups of flour merged from two lines, got %+v", flour)
+	}
+	if eggs := item(list, "egg"); len(eggs.Quantities) != 1 || eggs.Quantities[0] != (model.Quantity{Amount: 8}) || eggs.Aisle != "dairy and eggs" {
+		t.Errorf("Expected 8 eggs in dairy and eggs, got %+v", eggs)
+	}
+	// 4 tbsp and 1/2 cup of sugar add up to 3/4 cup.
+	if sugar := item(list, "sugar"); len(sugar.Quantities) != 1 || sugar.Quantities[0] != (model.Quantity{Amount: 0.75, Unit: "cup"}) {
+		t.Errorf("Expected 3/4 cup of sugar, got %+v", sugar)
+	}
+	if oil := item(list, "olive oil"); oil.Name == "" || len(oil.Quantities) != 0 {
+		t.Errorf("Expected olive oil without a quantity, got %+v", oil)
+	}
+	if salt := item(list, "salt"); salt.Name != "" {
+		t.Errorf("Expected staples to be left off, got %+v", salt)
+	}
+
+	aisles := make([]string, len(list.Items))
+	for i, item := range list.Items {
+		aisles[i] = item.Aisle
+		if item.ID == "" {
+			t.Errorf("Expected item %q to have an ID", item.Name)
+		}
+	}
+	if !slices.IsSortedFunc(aisles, func(a, b string) int { return strings.Compare(aisleRank(a), aisleRank(b)) }) {
+		t.Errorf("Expected items grouped by aisle, got %v", aisles)
+	}
+}
+
+func aisleRank(aisle string) string {
+	return map[string]string{"produce": "0", "dairy and eggs": "2", "baking": "5", "condiments and oils": "8", "other": "9"}[aisle]
+}
+
+func TestControllerCreateListRejects(t *testing.T) {
+	ctrl := newController(t)
+	ctx := context.Background()
+
+	_, err := ctrl.CreateList(ctx, "ed", CreateListCommand{Recipes: []RecipeServings{{ID: "cake"}, {ID: "cake", Servings: -1}, {}}})
+	var verr *domain.ValidationError
+	if !errors.As(err, &verr) || len(verr.Fields) != 3 {
+		t.Errorf("Expected three field errors, got %v", err)
+	}
+	if _, err := ctrl.CreateList(ctx, "ed", CreateListCommand{}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput without recipes, got %v", err)
+	}
+	if _, err := ctrl.CreateList(ctx, "ed", CreateListCommand{Recipes: []RecipeServings{{ID: "missing"}}}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown recipe, got %v", err)
+	}
+}
+
+func TestControllerListOwnership(t *testing.T) {
+	ctrl := newController(t)
+	ctx := context.Background()
+
+	list, err := ctrl.CreateList(ctx, "ed", CreateListCommand{Name: "Weekend", Recipes: []RecipeServings{{ID: "pancakes"}}})
+	if err != nil {
+		t.Fatalf("CreateList failed: %v", err)
+	}
+
+	if _, err := ctrl.GetList(ctx, "al", list.ID); !errors.Is(err, domain.ErrListNotFound) {
+		t.Errorf("Expected another user's list to be not found, got %v", err)
+	}
+	if _, err := ctrl.CheckItem(ctx, "al", list.ID, "1", true); !errors.Is(err, domain.ErrListNotFound) {
+		t.Errorf("Expected another user not to check items, got %v", err)
+	}
+	if err := ctrl.DeleteList(ctx, "al", list.ID); !errors.Is(err, domain.ErrListNotFound) {
+		t.Errorf("Expected another user not to delete the list, got %v", err)
+	}
+	if lists, _ := ctrl.ListLists(ctx, "al"); len(lists) != 0 {
+		t.Errorf("Expected no lists for another user, got %d", len(lists))
+	}
+
+	checked, err := ctrl.CheckItem(ctx, "ed", list.ID, "1", true)
+	if err != nil || !checked.Items[0].Checked {
+		t.Errorf("Expected item 1 checked off, got %+v, %v", checked.Items[0], err)
+	}
+	if got, _ := ctrl.GetList(ctx, "ed", list.ID); !got.Items[0].Checked || got.Items[1].Checked {
+		t.Errorf("Expected only item 1 checked after reload, got %+v", got.Items)
+	}
+	if _, err := ctrl.CheckItem(ctx, "ed", list.ID, "99", true); !errors.Is(err, domain.ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound, got %v", err)
+	}
+
+	if err := ctrl.DeleteList(ctx, "ed", list.ID); err != nil {
+		t.Fatalf("DeleteList failed: %v", err)
+	}
+	if lists, _ := ctrl.ListLists(ctx, "ed"); len(lists) != 0 {
+		t.Errorf("Expected no lists after delete, got %d", len(lists))
+	}
+}
+
+func TestText(t *testing.T) {
+	list := Build([]model.Recipe{{
+		Name: "Pancakes", Yield: "4 servings",
+		Ingredients: []string{"1 1/2 cups flour", "2 eggs", "3 green onions", "250 ml milk"},
+	}}, []int{2})
+	list.Name = "Breakfast"
+	list.Items[1].Checked = true
+
+	want := `Breakfast
+  for Pancakes, 2 servings
+
+Produce
+[ ] green onion (1 1/2)
+
+Dairy and eggs
+[x] egg (1)
+[ ] milk (125 ml)
+
+Baking
+[ ] flour (3/4 cup)
+`
+	if got := Text(list); got != want {
+		t.Errorf("Text() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+package shopping
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/internal/ingredient"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// Build makes an unsaved list of what to buy for recipes, the i-th scaled to
+// servings[i] servings when both that and the recipe's yield give a number.
+// Lines naming the same ingredient are merged into one item with their
+// quantities summed, converting units where they measure the same thing.
+// Staples such as salt and water are left off.
+func Build(recipes []model.Recipe, servings []int) model.ShoppingList {
+	type entry struct {
+		name     string
+		measures []ingredient.Measure
+		lines    []string
+	}
+	var entries []*entry
+	byName := map[string]*entry{}
+
+	list := model.ShoppingList{Recipes: make([]model.ShoppingRecipe, len(recipes))}
+	for i, recipe := range recipes {
+		factor := 1.0
+		base, ok := recipe.Servings()
+		list.Recipes[i] = model.ShoppingRecipe{ID: recipe.ID, Name: recipe.Name}
+		if ok {
+			list.Recipes[i].Servings = base
+			if i < len(servings) && servings[i] > 0 {
+				factor = float64(servings[i]) / float64(base)
+				list.Recipes[i].Servings = servings[i]
+			}
+		}
+
+		for _, line := range recipe.Ingredients {
+			name := ingredient.Name(line)
+			if name == "" || ingredient.IsStaple(name) {
+				continue
+			}
+			e, ok := byName[name]
+			if !ok {
+				e = &entry{name: name}
+				byName[name] = e
+				entries = append(entries, e)
+			}
+			e.lines = append(e.lines, strings.TrimSpace(line))
+			quantity, unit, _, _ := ingredient.Split(line)
+			if amount, ok := ingredient.Amount(quantity); ok {
+				e.measures = append(e.measures, ingredient.Measure{Amount: amount * factor, Unit: unit})
+			}
+		}
+	}
+
+	list.Items = make([]model.ShoppingItem, len(entries))
+	for i, e := range entries {
+		item := model.ShoppingItem{Name: e.name, Aisle: ingredient.Aisle(e.name), Lines: e.lines, Quantities: []model.Quantity{}}
+		for _, m := range ingredient.Sum(e.measures) {
+			item.Quantities = append(item.Quantities, model.Quantity{Amount: math.Round(m.Amount*1000) / 1000, Unit: m.Unit})
+		}
+		list.Items[i] = item
+	}
+	slices.SortFunc(list.Items, func(a, b model.ShoppingItem) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(ingredient.Aisles, a.Aisle), slices.Index(ingredient.Aisles, b.Aisle)),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	for i := range list.Items {
+		list.Items[i].ID = strconv.Itoa(i + 1)
+	}
+	return list
+}
+
+// Text writes a list as plain text for printing or pasting into a message:
+// the name and recipes, then the items under a heading per aisle with a
+// box that is ticked once the item is checked off.
+func Text(list model.ShoppingList) string {
+	var b strings.Builder
+	b.WriteString(list.Name + "\n")
+	for _, r := range list.Recipes {
+		if r.Servings > 0 {
+			fmt.Fprintf(&b, "  for %s, %d servings\n", r.Name, r.Servings)
+		} else {
+			fmt.Fprintf(&b, "  for %s\n", r.Name)
+		}
+	}
+
+	aisle := ""
+	for _, item := range list.Items {
+		if item.Aisle != aisle {
+			aisle = item.Aisle
+			b.WriteString("\n" + strings.ToUpper(aisle[:1]) + aisle[1:] + "\n")
+		}
+		box := "[ ]"
+		if item.Checked {
+			box = "[x]"
+		}
+		b.WriteString(box + " " + item.Name)
+		if len(item.Quantities) > 0 {
+			amounts := make([]string, len(item.Quantities))
+			for i, q := range item.Quantities {
+				amounts[i] = ingredient.Measure{Amount: q.Amount, Unit: q.Unit}.String()
+			}
+			b.WriteString(" (" + strings.Join(amounts, " + ") + ")")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,339 @@
+// Package cookbook lays out recipes as printable PDF pages: one recipe per
+// page, ingredients in a narrow column beside numbered instructions, and a
+// table of contents linking to each recipe when there is more than one.
+package cookbook
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/internal/pdf"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// Layout, in points.
+const (
+	margin          = 56
+	ingredientWidth = 160
+	columnGap       = 24
+	footerHeight    = 24
+	bodySize        = 10
+	bodyLeading     = 13.5
+)
+
+type options struct {
+	title string
+	size  pdf.Size
+}
+
+// Option configures Write.
+type Option func(*options)
+
+// WithTitle sets the title shown above the table of contents and by PDF
+// viewers; it defaults to "Cookbook".
+func WithTitle(title string) Option {
+	return func(o *options) {
+		o.title = title
+	}
+}
+
+// WithPageSize sets the paper size; it defaults to A4.
+func WithPageSize(size pdf.Size) Option {
+	return func(o *options) {
+		o.size = size
+	}
+}
+
+// Write writes recipes as a PDF. A single recipe is written on its own;
+// otherwise the recipes follow a table of contents. Each recipe starts on a
+// new page and continues on the next when it is too long for one.
+func Write(w io.Writer, recipes []model.Recipe, opts ...Option) error {
+	o := options{title: "Cookbook", size: pdf.A4}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	doc := pdf.New(o.size)
+	l := layout{doc: doc, size: o.size}
+
+	var contents []*pdf.Page
+	if len(recipes) == 1 {
+		doc.SetTitle(recipes[0].Name)
+	} else {
+		doc.SetTitle(o.title)
+		for range l.contentsPages(len(recipes)) {
+			contents = append(contents, l.addPage())
+		}
+	}
+
+	starts := make([]*pdf.Page, len(recipes))
+	for i, recipe := range recipes {
+		starts[i] = l.recipe(recipe)
+	}
+	if contents != nil {
+		l.contents(contents, o.title, recipes, starts)
+	}
+	l.footers()
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+type layout struct {
+	doc   *pdf.Document
+	size  pdf.Size
+	pages []*pdf.Page
+}
+
+func (l *layout) addPage() *pdf.Page {
+	p := l.doc.AddPage()
+	l.pages = append(l.pages, p)
+	return p
+}
+
+func (l *layout) width() float64 {
+	return l.size.Width - 2*margin
+}
+
+// bottom is the lowest a line of body text may reach.
+func (l *layout) bottom() float64 {
+	return l.size.Height - margin - footerHeight
+}
+
+// footers numbers every page.
+func (l *layout) footers() {
+	for _, p := range l.pages {
+		n := strconv.Itoa(p.Number())
+		p.Gray(0.4)
+		p.Text((l.size.Width-pdf.Helvetica.Width(n, 9))/2, l.size.Height-margin+footerHeight/2, pdf.Helvetica, 9, n)
+	}
+}
+
+// Table of contents layout.
+const (
+	bookTitleSize  = 26
+	contentsSize   = 11
+	contentsHeight = 20
+)
+
+// contentsTop is where the entries start on the first page of contents,
+// below the book title and heading.
+func contentsTop() float64 {
+	return margin + bookTitleSize + 48
+}
+
+// contentsPages returns how many pages the table of contents takes.
+func (l *layout) contentsPages(entries int) int {
+	first := int((l.bottom() - contentsTop()) / contentsHeight)
+	rest := int((l.bottom() - margin) / contentsHeight)
+	if entries <= first {
+		return 1
+	}
+	return 1 + int(math.Ceil(float64(entries-first)/float64(rest)))
+}
+
+// contents fills the table of contents pages, linking each entry to the
+// first page of its recipe.
+func (l *layout) contents(pages []*pdf.Page, title string, recipes []model.Recipe, starts []*pdf.Page) {
+	page := pages[0]
+	page.Text(margin, margin+bookTitleSize, pdf.HelveticaBold, bookTitleSize, title)
+	page.Text(margin, margin+bookTitleSize+30, pdf.HelveticaBold, 14, "Contents")
+	y := contentsTop()
+
+	for i, recipe := range recipes {
+		if y+contentsHeight > l.bottom() {
+			pages = pages[1:]
+			page, y = pages[0], margin
+		}
+		number := strconv.Itoa(starts[i].Number())
+		numberWidth := pdf.Helvetica.Width(number, contentsSize)
+		right := margin + l.width()
+		name := truncate(recipe.Name, pdf.Helvetica, contentsSize, l.width()-numberWidth-24)
+		nameWidth := pdf.Helvetica.Width(name, contentsSize)
+
+		baseline := y + contentsSize + 3
+		page.Gray(0)
+		page.Text(margin, baseline, pdf.Helvetica, contentsSize, name)
+		page.Text(right-numberWidth, baseline, pdf.Helvetica, contentsSize, number)
+
+		// Dot leaders between the name and the page number.
+		dot := pdf.Helvetica.Width(" .", contentsSize)
+		if dots := int((right - numberWidth - margin - nameWidth - 8) / dot); dots > 0 {
+			page.Gray(0.6)
+			page.Text(right-numberWidth-4-float64(dots)*dot, baseline, pdf.Helvetica, contentsSize, strings.Repeat(" .", dots))
+		}
+		page.Link(margin, y, l.width(), contentsHeight, starts[i])
+		y += contentsHeight
+	}
+}
+
+// truncate shortens s with an ellipsis to fit width.
+func truncate(s string, font pdf.Font, size, width float64) string {
+	if font.Width(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && font.Width(string(runes)+"…", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "…"
+}
+
+// row is one line or gap of a column; draw places it with its top at y.
+type row struct {
+	height float64
+	draw   func(p *pdf.Page, y float64)
+}
+
+// recipe lays out one recipe from a new page and returns that page.
+func (l *layout) recipe(r model.Recipe) *pdf.Page {
+	first := l.addPage()
+	y := l.header(first, r)
+
+	left := l.ingredientRows(r, margin)
+	right := l.instructionRows(r, margin+ingredientWidth+columnGap, l.width()-ingredientWidth-columnGap)
+
+	page := first
+	for {
+		left = fill(page, left, y, l.bottom())
+		right = fill(page, right, y, l.bottom())
+		if len(left) == 0 && len(right) == 0 {
+			return first
+		}
+		page = l.addPage()
+		page.Gray(0.4)
+		page.Text(margin, margin+9, pdf.HelveticaOblique, 9, truncate(r.Name, pdf.HelveticaOblique, 9, l.width()-60)+" (continued)")
+		y = margin + 28
+	}
+}
+
+// fill draws rows from y down until the next would pass bottom, and returns
+// the rows left over.
+func fill(page *pdf.Page, rows []row, y, bottom float64) []row {
+	for i, r := range rows {
+		if y+r.height > bottom && i > 0 {
+			return rows[i:]
+		}
+		r.draw(page, y)
+		y += r.height
+	}
+	return nil
+}
+
+// header writes the name, facts and tags, and returns where the columns start.
+func (l *layout) header(page *pdf.Page, r model.Recipe) float64 {
+	y := float64(margin)
+	for _, line := range pdf.HelveticaBold.Wrap(r.Name, 22, l.width()) {
+		y += 26
+		page.Gray(0)
+		page.Text(margin, y, pdf.HelveticaBold, 22, line)
+	}
+
+	var facts []string
+	if r.Yield != "" {
+		facts = append(facts, "Yield "+r.Yield)
+	}
+	for _, d := range []struct{ label, value string }{{"Prep", r.PrepTime}, {"Cook", r.CookTime}, {"Total", r.TotalTime}} {
+		if d.value != "" {
+			facts = append(facts, d.label+" "+model.HumanDuration(d.value))
+		}
+	}
+	for _, m := range []string{r.Difficulty, r.Cuisine, r.Course, strings.Join(r.Diets, ", ")} {
+		if m != "" {
+			facts = append(facts, m)
+		}
+	}
+	page.Gray(0.35)
+	if len(facts) > 0 {
+		for _, line := range pdf.Helvetica.Wrap(strings.Join(facts, " · "), bodySize, l.width()) {
+			y += 16
+			page.Text(margin, y, pdf.Helvetica, bodySize, line)
+		}
+	}
+	if len(r.Tags) > 0 {
+		for _, line := range pdf.HelveticaOblique.Wrap(strings.Join(r.Tags, ", "), 9, l.width()) {
+			y += 14
+			page.Text(margin, y, pdf.HelveticaOblique, 9, line)
+		}
+	}
+
+	y += 12
+	page.Gray(0.7)
+	page.Line(margin, y, margin+l.width(), y, 0.75)
+	return y + 16
+}
+
+// heading is a column title such as "Ingredients".
+func heading(x float64, text string) row {
+	return row{height: 22, draw: func(p *pdf.Page, y float64) {
+		p.Gray(0)
+		p.Text(x, y+12, pdf.HelveticaBold, 12, text)
+	}}
+}
+
+// gap is empty space between items.
+func gap(height float64) row {
+	return row{height: height, draw: func(*pdf.Page, float64) {}}
+}
+
+// textRow is one line of body text, with an optional marker such as a
+// bullet or step number written before it.
+func textRow(x float64, font pdf.Font, marker string, markerRight float64, text string) row {
+	return row{height: bodyLeading, draw: func(p *pdf.Page, y float64) {
+		p.Gray(0)
+		if marker != "" {
+			p.Text(markerRight-pdf.Helvetica.Width(marker, bodySize), y+bodySize, pdf.Helvetica, bodySize, marker)
+		}
+		p.Text(x, y+bodySize, font, bodySize, text)
+	}}
+}
+
+func (l *layout) ingredientRows(r model.Recipe, x float64) []row {
+	rows := []row{heading(x, "Ingredients")}
+	const indent = 10
+	for _, ingredient := range r.Ingredients {
+		for i, line := range pdf.Helvetica.Wrap(ingredient, bodySize, ingredientWidth-indent) {
+			marker := ""
+			if i == 0 {
+				marker = "•"
+			}
+			rows = append(rows, textRow(x+indent, pdf.Helvetica, marker, x+6, line))
+		}
+		rows = append(rows, gap(3))
+	}
+	return rows
+}
+
+func (l *layout) instructionRows(r model.Recipe, x, width float64) []row {
+	rows := []row{heading(x, "Instructions")}
+	const indent = 22
+	sections := r.Sections
+	if len(sections) == 0 {
+		sections = []model.InstructionSection{{Steps: r.Instructions}}
+	}
+	for s, section := range sections {
+		if section.Title != "" {
+			if s > 0 {
+				rows = append(rows, gap(6))
+			}
+			for _, line := range pdf.HelveticaBold.Wrap(section.Title, bodySize, width) {
+				rows = append(rows, textRow(x, pdf.HelveticaBold, "", 0, line))
+			}
+			rows = append(rows, gap(3))
+		}
+		for n, step := range section.Steps {
+			for i, line := range pdf.Helvetica.Wrap(step, bodySize, width-indent) {
+				marker := ""
+				if i == 0 {
+					marker = fmt.Sprintf("%d.", n+1)
+				}
+				rows = append(rows, textRow(x+indent, pdf.Helvetica, marker, x+indent-6, line))
+			}
+			rows = append(rows, gap(5))
+		}
+	}
+	return rows
+}
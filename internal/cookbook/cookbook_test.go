@@ -0,0 +1,121 @@
+package cookbook
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/pdf"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// pages returns the decompressed content of each page of a PDF from Write.
+func pages(t *testing.T, out []byte) []string {
+	t.Helper()
+	var pages []string
+	for _, m := range regexp.MustCompile(`/Length (\d+) /Filter /FlateDecode >>\nstream\n`).FindAllSubmatchIndex(out, -1) {
+		length, _ := strconv.Atoi(string(out[m[2]:m[3]]))
+		r, err := zlib.NewReader(bytes.NewReader(out[m[1] : m[1]+length]))
+		if err != nil {
+			t.Fatalf("page stream: %v", err)
+		}
+		data, _ := io.ReadAll(r)
+		pages = append(pages, string(data))
+	}
+	return pages
+}
+
+func soup() model.Recipe {
+	return model.Recipe{
+		Name:         "Tomato Soup",
+		Tags:         []string{"soup", "vegetarian"},
+		Ingredients:  []string{"6 tomatoes", "1 onion"},
+		Instructions: []string{"Roast the tomatoes.", "Blend with the onion."},
+		Yield:        "2 bowls",
+		TotalTime:    "PT40M",
+	}
+}
+
+func TestWriteSingleRecipe(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, []model.Recipe{soup()}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got := pages(t, buf.Bytes())
+	if len(got) != 1 {
+		t.Fatalf("expected 1 page, got %d", len(got))
+	}
+	for _, want := range []string{"(Tomato Soup) Tj", "(Yield 2 bowls \xb7 Total 40 min) Tj", "(Ingredients) Tj", "(\x95) Tj", "(6 tomatoes) Tj", "(2.) Tj", "(Blend with the onion.) Tj"} {
+		if !strings.Contains(got[0], want) {
+			t.Errorf("page lacks %q:\n%s", want, got[0])
+		}
+	}
+	if strings.Contains(got[0], "(Contents) Tj") {
+		t.Error("a single recipe should have no table of contents")
+	}
+}
+
+func TestWriteCookbook(t *testing.T) {
+	long := model.Recipe{Name: "Feast", Ingredients: []string{"everything"}}
+	for i := 0; i < 80; i++ {
+		long.Instructions = append(long.Instructions, fmt.Sprintf("Step %d: keep cooking the feast slowly, tasting and seasoning as you go along.", i+1))
+	}
+	toast := model.Recipe{
+		Name:        "Toast",
+		Ingredients: []string{"bread"},
+		Sections: []model.InstructionSection{
+			{Title: "Toast", Steps: []string{"Toast the bread."}},
+			{Title: "Serve", Steps: []string{"Butter it."}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, []model.Recipe{soup(), long, toast}, WithTitle("Family Favourites")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.Bytes()
+	got := pages(t, out)
+
+	// Contents, soup, the feast over several pages, then toast.
+	if len(got) < 5 {
+		t.Fatalf("expected at least 5 pages, got %d", len(got))
+	}
+	for _, want := range []string{"(Family Favourites) Tj", "(Contents) Tj", "(Tomato Soup) Tj", "(Feast) Tj", "(Toast) Tj"} {
+		if !strings.Contains(got[0], want) {
+			t.Errorf("contents lack %q:\n%s", want, got[0])
+		}
+	}
+	last := strconv.Itoa(len(got))
+	if !strings.Contains(got[0], "("+last+") Tj") {
+		t.Errorf("expected toast listed on page %s:\n%s", last, got[0])
+	}
+	if !strings.Contains(got[3], "(Feast \\(continued\\)) Tj") {
+		t.Errorf("expected the feast to continue on page 4:\n%s", got[3])
+	}
+	if !strings.Contains(got[len(got)-1], "(Serve) Tj") || !strings.Contains(got[len(got)-1], "(1.) Tj") {
+		t.Errorf("expected section titles and numbering restarting per section:\n%s", got[len(got)-1])
+	}
+	for i, page := range got {
+		if !strings.Contains(page, "("+strconv.Itoa(i+1)+") Tj") {
+			t.Errorf("page %d lacks its number", i+1)
+		}
+	}
+	if n := bytes.Count(out, []byte("/Subtype /Link")); n != 3 {
+		t.Errorf("expected 3 contents links, got %d", n)
+	}
+}
+
+func TestContentsPages(t *testing.T) {
+	l := layout{size: pdf.A4}
+	if l.contentsPages(0) != 1 || l.contentsPages(10) != 1 {
+		t.Error("expected a short list on one page")
+	}
+	if n := l.contentsPages(200); n < 5 {
+		t.Errorf("expected 200 entries over several pages, got %d", n)
+	}
+}
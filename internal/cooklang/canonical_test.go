@@ -0,0 +1,109 @@
+package cooklang
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// canonicalSuite is the layout of the Cooklang spec's canonical.yaml.
+type canonicalSuite struct {
+	Version int `yaml:"version"`
+	Tests   map[string]struct {
+		Source string `yaml:"source"`
+		Result struct {
+			Steps    [][]map[string]any `yaml:"steps"`
+			Metadata map[string]string  `yaml:"metadata"`
+		} `yaml:"result"`
+	} `yaml:"tests"`
+}
+
+func TestCanonical(t *testing.T) {
+	data, err := os.ReadFile("testdata/canonical.yaml")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var suite canonicalSuite
+	if err := yaml.Unmarshal(data, &suite); err != nil {
+		t.Fatalf("decode fixture: %v", err)
+	}
+
+	names := make([]string, 0, len(suite.Tests))
+	for name := range suite.Tests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		tc := suite.Tests[name]
+		t.Run(name, func(t *testing.T) {
+			r, err := Parse(tc.Source)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			got := canonicalSteps(r.Steps())
+			want := tc.Result.Steps
+			for _, step := range want {
+				for _, item := range step {
+					if q, ok := item["quantity"]; ok {
+						item["quantity"] = canonicalQuantity(q)
+					}
+				}
+			}
+			if want == nil {
+				want = [][]map[string]any{}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("steps = %v, want %v", got, want)
+			}
+
+			wantMeta := tc.Result.Metadata
+			if wantMeta == nil {
+				wantMeta = map[string]string{}
+			}
+			if !reflect.DeepEqual(r.Metadata, wantMeta) {
+				t.Errorf("metadata = %v, want %v", r.Metadata, wantMeta)
+			}
+		})
+	}
+}
+
+// canonicalSteps writes parsed steps with the fields canonical.yaml uses
+// for each item type.
+func canonicalSteps(steps []Step) [][]map[string]any {
+	out := [][]map[string]any{}
+	for _, step := range steps {
+		items := []map[string]any{}
+		for _, item := range step {
+			m := map[string]any{"type": item.Type}
+			switch item.Type {
+			case TypeText:
+				m["value"] = item.Value
+			case TypeCookware:
+				m["name"], m["quantity"] = item.Name, canonicalQuantity(item.Quantity)
+			default:
+				m["name"], m["quantity"], m["units"] = item.Name, canonicalQuantity(item.Quantity), item.Units
+			}
+			items = append(items, m)
+		}
+		out = append(out, items)
+	}
+	return out
+}
+
+// canonicalQuantity compares numbers by value, whether YAML read them as
+// integers or floats.
+func canonicalQuantity(q any) any {
+	switch v := q.(type) {
+	case int:
+		return float64(v)
+	case float64, string:
+		return v
+	}
+	return fmt.Sprint(q)
+}
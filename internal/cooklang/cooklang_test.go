@@ -0,0 +1,190 @@
+package cooklang
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+const curry = `---
+title: Chickpea Curry
+tags: [vegan, quick]
+cuisine: indian
+---
+>> servings: 4|8
+>> prep time: 10 min
+>> cook time: 1 h 30 min
+
+[- family recipe,
+   handed down -]
+= Base
+
+Fry @onion{1}(chopped) in @oil{2%tbsp} using a #large pot{}
+until soft, ~{5%minutes}. -- stir often
+
+Add @chickpeas{400%g} and @salt.
+
+= Finish
+
+Add more @oil{1%tbsp} and @chickpeas{200%g}.
+`
+
+func TestParseDocument(t *testing.T) {
+	r, err := Parse(curry)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if r.Metadata["title"] != "Chickpea Curry" || r.Metadata["tags"] != "vegan, quick" || r.Metadata["servings"] != "4|8" {
+		t.Errorf("Metadata = %v", r.Metadata)
+	}
+	if len(r.Sections) != 2 || r.Sections[0].Name != "Base" || len(r.Sections[0].Steps) != 2 || r.Sections[1].Name != "Finish" {
+		t.Fatalf("Sections = %+v", r.Sections)
+	}
+	first := r.Sections[0].Steps[0]
+	if first[1].Name != "onion" || first[1].Note != "chopped" {
+		t.Errorf("onion = %+v", first[1])
+	}
+	if last := first[len(first)-1]; last.Type != TypeText || last.Value != "." {
+		t.Errorf("comment not stripped: %+v", last)
+	}
+}
+
+func TestToRecipe(t *testing.T) {
+	doc, err := Parse(curry)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	r := doc.ToRecipe("fallback")
+
+	if r.Name != "Chickpea Curry" {
+		t.Errorf("Name = %q", r.Name)
+	}
+	if want := []string{"vegan", "quick"}; !slices.Equal(r.Tags, want) {
+		t.Errorf("Tags = %q, want %q", r.Tags, want)
+	}
+	if r.Cuisine != "indian" || !slices.Equal(r.Equipment, []string{"large pot"}) {
+		t.Errorf("Cuisine and equipment = %q %q", r.Cuisine, r.Equipment)
+	}
+	if r.Yield != "4 servings" || r.PrepTime != "PT10M" || r.CookTime != "PT1H30M" || r.TotalTime != "" {
+		t.Errorf("Yield and times = %q %q %q %q", r.Yield, r.PrepTime, r.CookTime, r.TotalTime)
+	}
+	if want := []string{"1 onion, chopped", "3 tbsp oil", "600 g chickpeas", "salt"}; !slices.Equal(r.Ingredients, want) {
+		t.Errorf("Ingredients = %q, want %q", r.Ingredients, want)
+	}
+	want := []model.InstructionSection{
+		{Title: "Base", Steps: []string{"Fry onion in oil using a large pot until soft, 5 minutes.", "Add chickpeas and salt."}},
+		{Title: "Finish", Steps: []string{"Add more oil and chickpeas."}},
+	}
+	if !reflect.DeepEqual(r.Sections, want) {
+		t.Errorf("Sections = %+v", r.Sections)
+	}
+	if len(r.Instructions) != 3 {
+		t.Errorf("Instructions = %q", r.Instructions)
+	}
+}
+
+func TestToRecipeFallbackName(t *testing.T) {
+	doc, _ := Parse("Boil @water{1%l}.")
+	r := doc.ToRecipe("pasta")
+	if r.Name != "pasta" || r.Sections != nil || !slices.Equal(r.Instructions, []string{"Boil water."}) {
+		t.Errorf("recipe = %+v", r)
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	in := model.Recipe{
+		Name:        "Pancakes",
+		Tags:        []string{"breakfast", "sweet"},
+		Ingredients: []string{"250 g plain flour, sifted", "2 eggs", "500 ml milk", "1 pinch salt", "butter"},
+		Instructions: []string{
+			"Whisk the plain flour, eggs and salt with the milk.",
+			"Fry in a pan for 2 minutes each side. Email me @ home #1 -- if stuck.",
+		},
+		Yield:      "8 servings",
+		PrepTime:   "PT5M",
+		Difficulty: "easy",
+		Cuisine:    "french",
+		Course:     "breakfast",
+		Equipment:  []string{"pan", "griddle"},
+		Diets:      []string{"vegetarian"},
+	}
+
+	src := Format(in)
+	doc, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	out := doc.ToRecipe("")
+
+	if out.Name != in.Name || !slices.Equal(out.Tags, in.Tags) || out.Yield != in.Yield || out.PrepTime != in.PrepTime {
+		t.Errorf("metadata = %+v\n%s", out, src)
+	}
+	if out.Difficulty != in.Difficulty || out.Cuisine != in.Cuisine || out.Course != in.Course || !slices.Equal(out.Diets, in.Diets) {
+		t.Errorf("metadata = %+v\n%s", out, src)
+	}
+	// griddle is never mentioned, so it comes first too.
+	if want := []string{"griddle", "pan"}; !slices.Equal(out.Equipment, want) {
+		t.Errorf("Equipment = %q, want %q\n%s", out.Equipment, want, src)
+	}
+	// butter is never mentioned, so it comes first from the leading step.
+	if want := []string{"butter", "250 g plain flour, sifted", "2 eggs", "1 pinch salt", "500 ml milk"}; !slices.Equal(out.Ingredients, want) {
+		t.Errorf("Ingredients = %q, want %q\n%s", out.Ingredients, want, src)
+	}
+	if out.Instructions[1] != in.Instructions[0] {
+		t.Errorf("Instructions[1] = %q\n%s", out.Instructions[1], src)
+	}
+	if want := "Fry in a pan for 2 minutes each side. Email me @ home # 1 - - if stuck."; out.Instructions[2] != want {
+		t.Errorf("Instructions[2] = %q, want %q", out.Instructions[2], want)
+	}
+}
+
+func TestFormatSections(t *testing.T) {
+	in := model.Recipe{
+		Name:        "Tart",
+		Ingredients: []string{"1 pastry case", "3 apples"},
+		Sections: []model.InstructionSection{
+			{Title: "Case", Steps: []string{"Bake the pastry case."}},
+			{Title: "Filling", Steps: []string{"Slice the apples.", "Fill the case."}},
+		},
+	}
+	in.Instructions = []string{"Bake the pastry case.", "Slice the apples.", "Fill the case."}
+
+	doc, err := Parse(Format(in))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	out := doc.ToRecipe("")
+	if !reflect.DeepEqual(out.Sections, in.Sections) || !slices.Equal(out.Ingredients, in.Ingredients) {
+		t.Errorf("recipe = %+v\n%s", out, Format(in))
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := map[string]string{
+		"":            "",
+		"PT45M":       "PT45M",
+		"pt90m":       "PT1H30M",
+		"45":          "PT45M",
+		"1 h 30 min":  "PT1H30M",
+		"1.5 hours":   "PT1H30M",
+		"2 days":      "P2D",
+		"20 seconds":  "PT20S",
+		"a long time": "a long time",
+	}
+	for in, want := range tests {
+		if got := duration(in); got != want {
+			t.Errorf("duration(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFormatQuantity(t *testing.T) {
+	tests := map[float64]string{2: "2", 0.5: "1/2", 1.5: "1 1/2", 1.0 / 3: "1/3", 0.375: "3/8", 0.3: "0.3"}
+	for in, want := range tests {
+		if got := formatQuantity(in); got != want {
+			t.Errorf("formatQuantity(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
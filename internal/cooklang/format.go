@@ -0,0 +1,159 @@
+package cooklang
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/internal/ingredient"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// Format writes a recipe as a Cooklang document. Each ingredient is marked up
+// where its name first appears in the instructions; ingredients the
+// instructions never mention are listed in a step of their own at the start,
+// and equipment is marked up as cookware the same way. Yield, times and the
+// other metadata fields are written as metadata, lists comma separated.
+func Format(recipe model.Recipe) string {
+	var b strings.Builder
+	meta := func(key, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			b.WriteString(">> " + key + ": " + strings.ReplaceAll(value, "\n", " ") + "\n")
+		}
+	}
+	meta("title", recipe.Name)
+	meta("tags", strings.Join(recipe.Tags, ", "))
+	meta("servings", strings.TrimSuffix(recipe.Yield, " servings"))
+	meta("prep time", recipe.PrepTime)
+	meta("cook time", recipe.CookTime)
+	meta("time", recipe.TotalTime)
+	meta("difficulty", recipe.Difficulty)
+	meta("cuisine", recipe.Cuisine)
+	meta("course", recipe.Course)
+	meta("diet", strings.Join(recipe.Diets, ", "))
+
+	sections := recipe.Sections
+	if len(sections) == 0 {
+		sections = []model.InstructionSection{{Steps: recipe.Instructions}}
+	}
+	steps := make([][]string, len(sections))
+	for i, section := range sections {
+		steps[i] = make([]string, len(section.Steps))
+		for j, step := range section.Steps {
+			steps[i][j] = escape(strings.Join(strings.Fields(step), " "))
+		}
+	}
+
+	// Longer names go first so "egg yolk" is not taken for "egg".
+	type marked struct{ name, markup string }
+	ingredients := make([]marked, 0, len(recipe.Ingredients))
+	for _, line := range recipe.Ingredients {
+		quantity, unit, name, note := ingredient.Split(line)
+		if markup := ingredientMarkup(quantity, unit, name, note); markup != "" {
+			ingredients = append(ingredients, marked{name, markup})
+		}
+	}
+	for _, tool := range recipe.Equipment {
+		if name := cookwareName(tool); name != "" {
+			ingredients = append(ingredients, marked{name, "#" + name + "{}"})
+		}
+	}
+	order := make([]int, len(ingredients))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(ingredients[order[i]].name) > len(ingredients[order[j]].name)
+	})
+	// Mentions are first replaced by a private use rune per ingredient, so
+	// later names cannot match inside markup already placed.
+	found := make([]bool, len(ingredients))
+	for _, i := range order {
+		found[i] = annotate(steps, ingredients[i].name, string(placeholder+rune(i)))
+	}
+	var unused []string
+	var markups []string
+	for i, in := range ingredients {
+		markups = append(markups, string(placeholder+rune(i)), in.markup)
+		if !found[i] {
+			unused = append(unused, in.markup)
+		}
+	}
+	restore := strings.NewReplacer(markups...)
+	for _, section := range steps {
+		for j := range section {
+			section[j] = restore.Replace(section[j])
+		}
+	}
+
+	if len(unused) > 0 {
+		b.WriteString("\n" + strings.Join(unused, ", ") + "\n")
+	}
+	for i, section := range sections {
+		if section.Title != "" {
+			b.WriteString("\n== " + strings.ReplaceAll(section.Title, "\n", " ") + " ==\n")
+		}
+		for _, step := range steps[i] {
+			if step != "" {
+				b.WriteString("\n" + step + "\n")
+			}
+		}
+	}
+	return strings.TrimPrefix(b.String(), "\n")
+}
+
+// ingredientMarkup writes @name{quantity%unit}(note), always with braces so
+// names of several words need no special care.
+func ingredientMarkup(quantity, unit, name, note string) string {
+	clean := strings.NewReplacer("{", "", "}", "", "%", "", "(", "", ")", "")
+	name = strings.TrimSpace(clean.Replace(name))
+	if name == "" {
+		return ""
+	}
+	amount := clean.Replace(quantity)
+	if unit != "" {
+		amount += "%" + clean.Replace(unit)
+	}
+	markup := "@" + name + "{" + amount + "}"
+	if note = clean.Replace(note); note != "" {
+		markup += "(" + note + ")"
+	}
+	return markup
+}
+
+// cookwareName drops the characters that would end #name{} markup early.
+func cookwareName(name string) string {
+	return strings.TrimSpace(strings.NewReplacer("{", "", "}", "").Replace(name))
+}
+
+// placeholder is the first of the private use runes standing in for markup
+// while ingredients are matched.
+const placeholder = '\uE000'
+
+// annotate replaces the first whole-word mention of name in steps with
+// markup.
+func annotate(steps [][]string, name, markup string) bool {
+	word := regexp.MustCompile(`(?i)(^|[^\pL\pN])` + regexp.QuoteMeta(name) + `($|[^\pL\pN])`)
+	for _, section := range steps {
+		for i, step := range section {
+			if loc := word.FindStringSubmatchIndex(step); loc != nil {
+				section[i] = step[:loc[3]] + markup + step[loc[4]:]
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// escape keeps instruction text from being read as markup or comments: a
+// sigil gets a space after it, and comment openers are broken up.
+func escape(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		b.WriteRune(r)
+		if strings.ContainsRune("@#~", r) && i+1 < len(s) && s[i+1] != ' ' {
+			b.WriteByte(' ')
+		}
+	}
+	return strings.NewReplacer("--", "- -", "[-", "[ -").Replace(b.String())
+}
@@ -0,0 +1,287 @@
+// Package cooklang reads and writes recipes in the Cooklang markup language
+// (https://cooklang.org), where ingredients, cookware and timers are marked up
+// inline in the steps: "Fry @onion{1} in a #pan{} for ~{5%minutes}".
+package cooklang
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Item types.
+const (
+	TypeText       = "text"
+	TypeIngredient = "ingredient"
+	TypeCookware   = "cookware"
+	TypeTimer      = "timer"
+)
+
+// Item is one piece of a step: plain text or a marked-up ingredient, cookware
+// or timer.
+type Item struct {
+	Type string
+	// Value is the text of a text item
+	Value string
+	// Name is the name of an ingredient, cookware or timer
+	Name string
+	// Quantity is a float64 when it is a number or fraction and a string
+	// otherwise; ingredients default to "some", cookware to 1 and timers to ""
+	Quantity any
+	// Units is the unit of an ingredient or timer quantity
+	Units string
+	// Note is an ingredient's preparation, as in @onion{1}(chopped)
+	Note string
+}
+
+// Step is a paragraph of the recipe.
+type Step []Item
+
+// Section is a run of steps under an optional "= Title" line.
+type Section struct {
+	Name  string
+	Steps []Step
+}
+
+// Recipe is a parsed Cooklang document.
+type Recipe struct {
+	// Metadata holds ">> key: value" lines and YAML front matter; lists in
+	// front matter are joined with ", "
+	Metadata map[string]string
+	Sections []Section
+}
+
+// Steps returns the steps of every section in order.
+func (r *Recipe) Steps() []Step {
+	var steps []Step
+	for _, section := range r.Sections {
+		steps = append(steps, section.Steps...)
+	}
+	return steps
+}
+
+var (
+	blockComment = regexp.MustCompile(`(?s)\[-.*?-\]`)
+	// fraction is a/b with an optional space around the slash; a leading zero
+	// makes "01/2" text rather than a number, as the spec requires
+	fraction = regexp.MustCompile(`^([1-9]\d*)\s*/\s*([1-9]\d*)$`)
+	number   = regexp.MustCompile(`^(0|[1-9]\d*)(\.\d+)?$`)
+)
+
+// Parse reads a Cooklang document. Steps are paragraphs separated by blank
+// lines, with line breaks inside a paragraph read as spaces. Markup that does
+// not parse, such as "@" followed by a space, is kept as text.
+func Parse(src string) (*Recipe, error) {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	r := &Recipe{Metadata: map[string]string{}}
+
+	src, err := frontMatter(src, r.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	src = blockComment.ReplaceAllString(src, "")
+
+	section := Section{}
+	var paragraph []string
+	flush := func() {
+		if text := strings.TrimSpace(strings.Join(paragraph, " ")); text != "" {
+			section.Steps = append(section.Steps, parseStep(text))
+		}
+		paragraph = nil
+	}
+
+	for _, line := range strings.Split(src, "\n") {
+		if i := strings.Index(line, "--"); i >= 0 {
+			line = line[:i]
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, ">>"):
+			flush()
+			if key, value, ok := strings.Cut(trimmed[2:], ":"); ok {
+				r.Metadata[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		case strings.HasPrefix(trimmed, ">"):
+			// A note about the recipe, not part of any step.
+			flush()
+		case strings.HasPrefix(trimmed, "="):
+			flush()
+			if section.Name != "" || len(section.Steps) > 0 {
+				r.Sections = append(r.Sections, section)
+			}
+			section = Section{Name: strings.TrimSpace(strings.Trim(trimmed, "="))}
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+	if section.Name != "" || len(section.Steps) > 0 {
+		r.Sections = append(r.Sections, section)
+	}
+	return r, nil
+}
+
+// frontMatter moves a leading YAML block between "---" lines into metadata
+// and returns the rest of the document.
+func frontMatter(src string, metadata map[string]string) (string, error) {
+	rest, ok := strings.CutPrefix(src, "---\n")
+	if !ok {
+		return src, nil
+	}
+	block, body, ok := strings.Cut(rest, "\n---")
+	if !ok {
+		return src, nil
+	}
+	_, body, _ = strings.Cut(body, "\n")
+
+	var values map[string]any
+	if err := yaml.Unmarshal([]byte(block), &values); err != nil {
+		return "", err
+	}
+	for key, value := range values {
+		metadata[key] = metadataString(value)
+	}
+	return body, nil
+}
+
+func metadataString(v any) string {
+	switch v := v.(type) {
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = metadataString(item)
+		}
+		return strings.Join(parts, ", ")
+	case string:
+		return v
+	case nil:
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(fmtAny(v), "["), "]"))
+}
+
+func fmtAny(v any) string {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// parseStep splits a paragraph into text and markup.
+func parseStep(text string) Step {
+	var step Step
+	addText := func(s string) {
+		if s == "" {
+			return
+		}
+		if n := len(step); n > 0 && step[n-1].Type == TypeText {
+			step[n-1].Value += s
+			return
+		}
+		step = append(step, Item{Type: TypeText, Value: s})
+	}
+
+	for text != "" {
+		i := strings.IndexAny(text, "@#~")
+		if i < 0 {
+			addText(text)
+			break
+		}
+		addText(text[:i])
+		item, n := parseMarkup(text[i:])
+		if n == 0 {
+			addText(text[i : i+1])
+			text = text[i+1:]
+			continue
+		}
+		step = append(step, item)
+		text = text[i+n:]
+	}
+	return step
+}
+
+// parseMarkup reads the ingredient, cookware or timer at the start of s and
+// returns it with the number of bytes it used, or zero bytes when s does not
+// start with valid markup.
+func parseMarkup(s string) (Item, int) {
+	sigil := s[0]
+	item := Item{Type: map[byte]string{'@': TypeIngredient, '#': TypeCookware, '~': TypeTimer}[sigil]}
+	rest := s[1:]
+
+	var name, amount string
+	var n int
+	switch {
+	case sigil == '~' && strings.HasPrefix(rest, "{"):
+		// An unnamed timer.
+		end := strings.IndexByte(rest, '}')
+		if end < 0 {
+			return Item{}, 0
+		}
+		amount, n = rest[1:end], 1+end+1
+	default:
+		first, _ := utf8.DecodeRuneInString(rest)
+		if rest == "" || !isWordRune(first) {
+			return Item{}, 0
+		}
+		if open := strings.IndexByte(rest, '{'); open > 0 && !strings.ContainsAny(rest[:open], "@#~{}") {
+			if end := strings.IndexByte(rest[open:], '}'); end > 0 {
+				name, amount, n = rest[:open], rest[open+1:open+end], 1+open+end+1
+				break
+			}
+		}
+		end := strings.IndexFunc(rest, func(r rune) bool { return !isWordRune(r) })
+		if end < 0 {
+			end = len(rest)
+		}
+		name, n = rest[:end], 1+end
+		amount = ""
+	}
+
+	item.Name = strings.TrimSpace(name)
+	quantity, units, _ := strings.Cut(amount, "%")
+	item.Quantity = parseQuantity(quantity)
+	item.Units = strings.TrimSpace(units)
+	switch {
+	case item.Quantity == "" && item.Type == TypeIngredient:
+		item.Quantity = "some"
+	case item.Quantity == "" && item.Type == TypeCookware:
+		item.Quantity = 1.0
+	}
+
+	if item.Type == TypeIngredient && strings.HasPrefix(s[n:], "(") {
+		if end := strings.IndexByte(s[n:], ')'); end > 0 {
+			item.Note = strings.TrimSpace(s[n+1 : n+end])
+			n += end + 1
+		}
+	}
+	return item, n
+}
+
+// isWordRune reports whether r can be part of a single-word name: anything
+// but whitespace and punctuation, so emoji are names too.
+func isWordRune(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsPunct(r) && r != utf8.RuneError
+}
+
+// parseQuantity returns a number for integers, decimals and fractions and the
+// trimmed text otherwise.
+func parseQuantity(s string) any {
+	s = strings.TrimSpace(s)
+	if number.MatchString(s) {
+		f, _ := strconv.ParseFloat(s, 64)
+		return f
+	}
+	if m := fraction.FindStringSubmatch(s); m != nil {
+		a, _ := strconv.ParseFloat(m[1], 64)
+		b, _ := strconv.ParseFloat(m[2], 64)
+		return a / b
+	}
+	return s
+}
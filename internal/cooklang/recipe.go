@@ -0,0 +1,259 @@
+package cooklang
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// tagKeys are the metadata keys whose comma separated values become tags.
+var tagKeys = []string{"tags", "category"}
+
+// ToRecipe converts a parsed document to a recipe. The name is the "title"
+// metadata, or fallback when there is none; the ID is the "id" metadata.
+// Ingredients are collected from the steps in order of first use, with the
+// quantities of repeated ingredients added up when their units agree, and
+// cookware becomes the equipment. The first "cuisine" and "course" become
+// those fields; "diet" values and a "difficulty" the model does not know
+// become tags instead.
+func (r *Recipe) ToRecipe(fallback string) model.Recipe {
+	recipe := model.Recipe{
+		ID:           model.RecipeID(r.Metadata["id"]),
+		Name:         r.Metadata["title"],
+		Tags:         []string{},
+		Ingredients:  []string{},
+		Instructions: []string{},
+	}
+	if recipe.Name == "" {
+		recipe.Name = fallback
+	}
+
+	for _, key := range tagKeys {
+		recipe.Tags = append(recipe.Tags, list(r.Metadata[key])...)
+	}
+	if cuisines := list(r.Metadata["cuisine"]); len(cuisines) > 0 {
+		recipe.Cuisine = cuisines[0]
+		recipe.Tags = append(recipe.Tags, cuisines[1:]...)
+	}
+	if courses := list(r.Metadata["course"]); len(courses) > 0 {
+		recipe.Course = courses[0]
+		recipe.Tags = append(recipe.Tags, courses[1:]...)
+	}
+	for _, diet := range list(r.Metadata["diet"]) {
+		if slices.Contains(model.Diets, strings.ToLower(diet)) {
+			recipe.Diets = append(recipe.Diets, diet)
+		} else {
+			recipe.Tags = append(recipe.Tags, diet)
+		}
+	}
+	if difficulty := r.metadata("difficulty"); slices.Contains(model.Difficulties, strings.ToLower(difficulty)) {
+		recipe.Difficulty = difficulty
+	} else if difficulty != "" {
+		recipe.Tags = append(recipe.Tags, difficulty)
+	}
+
+	// "2|4|6" lists the servings the recipe scales to; the first is the base.
+	recipe.Yield, _, _ = strings.Cut(r.metadata("servings", "yield"), "|")
+	if recipe.Yield = strings.TrimSpace(recipe.Yield); isNumber(recipe.Yield) {
+		recipe.Yield += " servings"
+	}
+	recipe.PrepTime = duration(r.metadata("prep time", "prep_time", "prepTime"))
+	recipe.CookTime = duration(r.metadata("cook time", "cook_time", "cookTime"))
+	recipe.TotalTime = duration(r.metadata("time", "total time", "total_time", "totalTime", "duration"))
+
+	var ingredients ingredientList
+	titled := false
+	for _, section := range r.Sections {
+		steps := make([]string, 0, len(section.Steps))
+		for _, step := range section.Steps {
+			var b strings.Builder
+			for _, item := range step {
+				b.WriteString(item.text())
+				switch {
+				case item.Type == TypeIngredient:
+					ingredients.add(item)
+				case item.Type == TypeCookware && !slices.ContainsFunc(recipe.Equipment, func(e string) bool { return strings.EqualFold(e, item.Name) }):
+					recipe.Equipment = append(recipe.Equipment, item.Name)
+				}
+			}
+			if text := strings.Join(strings.Fields(b.String()), " "); text != "" {
+				steps = append(steps, text)
+			}
+		}
+		recipe.Instructions = append(recipe.Instructions, steps...)
+		recipe.Sections = append(recipe.Sections, model.InstructionSection{Title: section.Name, Steps: steps})
+		titled = titled || section.Name != ""
+	}
+	if !titled {
+		recipe.Sections = nil
+	}
+	recipe.Ingredients = ingredients.lines()
+	return recipe
+}
+
+// list splits a comma separated metadata value.
+func list(value string) []string {
+	var out []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// metadata returns the first of keys that is set.
+func (r *Recipe) metadata(keys ...string) string {
+	for _, key := range keys {
+		if v := strings.TrimSpace(r.Metadata[key]); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// text is how an item reads in an instruction: ingredients and cookware by
+// name and timers by their duration.
+func (item Item) text() string {
+	switch item.Type {
+	case TypeText:
+		return item.Value
+	case TypeTimer:
+		if amount := amount(item.Quantity, item.Units); amount != "" {
+			return amount
+		}
+	}
+	return item.Name
+}
+
+// amount writes a quantity and its units, as "1.5 kg".
+func amount(quantity any, units string) string {
+	var q string
+	switch v := quantity.(type) {
+	case float64:
+		q = formatQuantity(v)
+	case string:
+		q = v
+	}
+	return strings.TrimSpace(q + " " + units)
+}
+
+// formatQuantity writes halves, thirds, quarters and eighths as fractions,
+// "1 1/2", so quantities read back the way cooks write them.
+func formatQuantity(v float64) string {
+	whole, frac := math.Modf(v)
+	if frac > 1e-9 {
+		for _, d := range []float64{2, 3, 4, 8} {
+			if n := math.Round(frac * d); math.Abs(frac*d-n) < 1e-6 {
+				f := strconv.FormatFloat(n, 'f', -1, 64) + "/" + strconv.FormatFloat(d, 'f', -1, 64)
+				if whole == 0 {
+					return f
+				}
+				return strconv.FormatFloat(whole, 'f', -1, 64) + " " + f
+			}
+		}
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// ingredientList collects ingredients in order of first use.
+type ingredientList struct {
+	items []*listedIngredient
+}
+
+type listedIngredient struct {
+	name    string
+	amounts []Item
+	notes   []string
+}
+
+func (l *ingredientList) add(item Item) {
+	var in *listedIngredient
+	for _, existing := range l.items {
+		if strings.EqualFold(existing.name, item.Name) {
+			in = existing
+			break
+		}
+	}
+	if in == nil {
+		in = &listedIngredient{name: item.Name}
+		l.items = append(l.items, in)
+	}
+	if item.Note != "" && !slices.Contains(in.notes, item.Note) {
+		in.notes = append(in.notes, item.Note)
+	}
+	if item.Quantity == "some" && item.Units == "" {
+		return
+	}
+	for i, a := range in.amounts {
+		sum, ok := a.Quantity.(float64)
+		more, ok2 := item.Quantity.(float64)
+		if ok && ok2 && strings.EqualFold(a.Units, item.Units) {
+			in.amounts[i].Quantity = sum + more
+			return
+		}
+	}
+	in.amounts = append(in.amounts, item)
+}
+
+// lines writes each ingredient as "250 g flour", joining amounts in units
+// that cannot be added with "+" and appending notes after a comma.
+func (l *ingredientList) lines() []string {
+	lines := make([]string, 0, len(l.items))
+	for _, in := range l.items {
+		amounts := make([]string, len(in.amounts))
+		for i, a := range in.amounts {
+			amounts[i] = amount(a.Quantity, a.Units)
+		}
+		line := strings.TrimSpace(strings.Join(amounts, " + ") + " " + in.name)
+		if len(in.notes) > 0 {
+			line += ", " + strings.Join(in.notes, ", ")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// durationPart is one "1.5 hours" or "30min" in a free-form duration.
+var durationPart = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(days?|d|hours?|hrs?|h|minutes?|mins?|m|seconds?|secs?|s)?\b`)
+
+// duration converts a free-form duration such as "1 h 30 min" or "90
+// minutes" to ISO 8601. A bare number is minutes. ISO 8601 values are kept,
+// and anything else is returned as is for validation to report.
+func duration(s string) string {
+	if s == "" {
+		return ""
+	}
+	if d, err := model.ParseDuration(s); err == nil {
+		return model.FormatDuration(d)
+	}
+	matches := durationPart.FindAllStringSubmatch(s, -1)
+	if matches == nil {
+		return s
+	}
+	var total time.Duration
+	for _, m := range matches {
+		n, _ := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
+		unit := time.Minute
+		switch u := strings.ToLower(m[2]); {
+		case strings.HasPrefix(u, "d"):
+			unit = 24 * time.Hour
+		case strings.HasPrefix(u, "h"):
+			unit = time.Hour
+		case strings.HasPrefix(u, "s"):
+			unit = time.Second
+		}
+		total += time.Duration(n * float64(unit))
+	}
+	return model.FormatDuration(total)
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// BatchMode selects how a batch reacts when one of its items fails.
+type BatchMode int
+
+const (
+	// BatchBestEffort applies every item it can; the others fail on their own.
+	BatchBestEffort BatchMode = iota
+	// BatchAtomic applies every item or none of them.
+	BatchAtomic
+)
+
+var (
+	// ErrBatchAborted is returned when an atomic batch is rolled back because
+	// an item failed; the per-item errors say which.
+	ErrBatchAborted = errors.New("batch aborted")
+	// ErrBatchUnsupported is returned for atomic batches against a repository
+	// that cannot apply several writes as one.
+	ErrBatchUnsupported = errors.New("atomic batches are not supported by this repository")
+)
+
+// RecipeBatchRepository is implemented by repositories that can apply many
+// operations in one call. Results and per-item errors line up with the input
+// and a nil per-item error means the item succeeded. The returned error is set
+// when the batch as a whole failed: ErrBatchAborted after an atomic batch was
+// rolled back, or a storage error, after which a best-effort batch may have
+// been partly applied.
+type RecipeBatchRepository interface {
+	CreateMany(context.Context, []model.Recipe, BatchMode) ([]model.Recipe, []error, error)
+	GetMany(context.Context, []model.RecipeID) ([]model.Recipe, []error, error)
+	UpdateMany(context.Context, []model.Recipe, BatchMode) ([]model.Recipe, []error, error)
+	DeleteMany(context.Context, []model.RecipeID, BatchMode) ([]error, error)
+}
+
+// Batch returns repo's own batch implementation, or one that applies the items
+// one at a time and refuses BatchAtomic.
+func Batch(repo RecipeRepository) RecipeBatchRepository {
+	if batch, ok := repo.(RecipeBatchRepository); ok {
+		return batch
+	}
+	return sequentialBatch{repo}
+}
+
+type sequentialBatch struct {
+	repo RecipeRepository
+}
+
+func (s sequentialBatch) CreateMany(ctx context.Context, recipes []model.Recipe, mode BatchMode) ([]model.Recipe, []error, error) {
+	if mode == BatchAtomic {
+		return nil, nil, ErrBatchUnsupported
+	}
+	results := make([]model.Recipe, len(recipes))
+	errs := make([]error, len(recipes))
+	for i, recipe := range recipes {
+		results[i], errs[i] = s.repo.Create(ctx, recipe)
+	}
+	return results, errs, nil
+}
+
+func (s sequentialBatch) GetMany(ctx context.Context, ids []model.RecipeID) ([]model.Recipe, []error, error) {
+	results := make([]model.Recipe, len(ids))
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		results[i], errs[i] = s.repo.GetByID(ctx, id)
+	}
+	return results, errs, nil
+}
+
+func (s sequentialBatch) UpdateMany(ctx context.Context, recipes []model.Recipe, mode BatchMode) ([]model.Recipe, []error, error) {
+	if mode == BatchAtomic {
+		return nil, nil, ErrBatchUnsupported
+	}
+	results := make([]model.Recipe, len(recipes))
+	errs := make([]error, len(recipes))
+	for i, recipe := range recipes {
+		results[i], errs[i] = s.repo.Update(ctx, recipe)
+	}
+	return results, errs, nil
+}
+
+func (s sequentialBatch) DeleteMany(ctx context.Context, ids []model.RecipeID, mode BatchMode) ([]error, error) {
+	if mode == BatchAtomic {
+		return nil, ErrBatchUnsupported
+	}
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		errs[i] = s.repo.Delete(ctx, id)
+	}
+	return errs, nil
+}
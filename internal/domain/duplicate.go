@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// Duplicate is an existing recipe suspected to be the same as another.
+type Duplicate struct {
+	ID   model.RecipeID `json:"id"`
+	Name string         `json:"name"`
+	// Score is how alike the two recipes are, from 0 to 1
+	Score float64 `json:"score"`
+}
+
+// DuplicateError rejects a recipe that looks like one already stored. It
+// matches ErrConflict with errors.Is.
+type DuplicateError struct {
+	Duplicates []Duplicate
+}
+
+func (e *DuplicateError) Error() string {
+	ids := make([]string, len(e.Duplicates))
+	for i, d := range e.Duplicates {
+		ids[i] = string(d.ID)
+		if d.ID == "" {
+			// a recipe of the same batch that was not created
+			ids[i] = strconv.Quote(d.Name)
+		}
+	}
+	return fmt.Sprintf("%v: possible duplicate of %s", ErrConflict, strings.Join(ids, ", "))
+}
+
+// Is reports whether target is ErrConflict.
+func (e *DuplicateError) Is(target error) bool {
+	return target == ErrConflict
+}
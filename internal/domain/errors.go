@@ -4,6 +4,9 @@ import "errors"
 
 var (
 	ErrNotFound      = errors.New("recipe not found")
+	ErrImageNotFound = errors.New("image not found")
+	ErrListNotFound  = errors.New("shopping list not found")
+	ErrItemNotFound  = errors.New("shopping list item not found")
 	ErrInvalidInput  = errors.New("invalid input")
 	ErrConflict      = errors.New("recipe conflict")
 	ErrPersistence   = errors.New("persistence error")
@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// MaxFacetValues bounds the tags and cuisines listed in Facets; the most
+// common are kept.
+const MaxFacetValues = 50
+
+// TimeBucket is a range of total times above the previous bucket's Max, up to
+// and including its own. The last bucket has no Max.
+type TimeBucket struct {
+	Label string
+	Max   time.Duration
+}
+
+// TimeBuckets are the total time ranges facets are counted in, shortest first.
+var TimeBuckets = []TimeBucket{
+	{"0-15m", 15 * time.Minute},
+	{"15-30m", 30 * time.Minute},
+	{"30-60m", time.Hour},
+	{"1-2h", 2 * time.Hour},
+	{"over-2h", 0},
+}
+
+// UnknownTime is the bucket of recipes without a usable total time.
+const UnknownTime = "unknown"
+
+// TimeBucketOf returns the label of the bucket the recipe's total time falls in.
+func TimeBucketOf(recipe model.Recipe) string {
+	total, ok := recipe.TotalDuration()
+	if !ok {
+		return UnknownTime
+	}
+	return timeBucket(total)
+}
+
+func timeBucket(total time.Duration) string {
+	for _, b := range TimeBuckets {
+		if b.Max == 0 || total <= b.Max {
+			return b.Label
+		}
+	}
+	return UnknownTime
+}
+
+// FacetCount is the number of recipes having a value.
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Facets counts the recipes matching a filter by tag, cuisine, difficulty and
+// total time.
+type Facets struct {
+	Total int `json:"total"`
+	// Tags and Cuisines are the MaxFacetValues most common, most common first
+	Tags     []FacetCount `json:"tags"`
+	Cuisines []FacetCount `json:"cuisines"`
+	// Difficulties follow model.Difficulties and TotalTimes follow
+	// TimeBuckets then UnknownTime, with zero counts included
+	Difficulties []FacetCount `json:"difficulties"`
+	TotalTimes   []FacetCount `json:"totalTimes"`
+}
+
+// NewFacets orders and trims raw counts the way Facets lists them.
+func NewFacets(total int, tags, cuisines, difficulties, times map[string]int) Facets {
+	f := Facets{
+		Total:        total,
+		Tags:         topCounts(tags),
+		Cuisines:     topCounts(cuisines),
+		Difficulties: make([]FacetCount, 0, len(model.Difficulties)),
+		TotalTimes:   make([]FacetCount, 0, len(TimeBuckets)+1),
+	}
+	for _, d := range model.Difficulties {
+		f.Difficulties = append(f.Difficulties, FacetCount{d, difficulties[d]})
+	}
+	for _, b := range TimeBuckets {
+		f.TotalTimes = append(f.TotalTimes, FacetCount{b.Label, times[b.Label]})
+	}
+	f.TotalTimes = append(f.TotalTimes, FacetCount{UnknownTime, times[UnknownTime]})
+	return f
+}
+
+func topCounts(counts map[string]int) []FacetCount {
+	out := make([]FacetCount, 0, len(counts))
+	for value, n := range counts {
+		if n > 0 {
+			out = append(out, FacetCount{value, n})
+		}
+	}
+	slices.SortFunc(out, func(a, b FacetCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Value, b.Value)
+	})
+	if len(out) > MaxFacetValues {
+		out = out[:MaxFacetValues]
+	}
+	return out
+}
+
+// FacetCounter tallies facets over a changing set of recipes. It is not safe
+// for concurrent use.
+type FacetCounter struct {
+	total                               int
+	tags, cuisines, difficulties, times map[string]int
+}
+
+// NewFacetCounter returns a counter of no recipes.
+func NewFacetCounter() *FacetCounter {
+	return &FacetCounter{
+		tags:         map[string]int{},
+		cuisines:     map[string]int{},
+		difficulties: map[string]int{},
+		times:        map[string]int{},
+	}
+}
+
+// Add counts a recipe.
+func (c *FacetCounter) Add(recipe model.Recipe) {
+	c.count(recipe, 1)
+}
+
+// Remove uncounts a recipe added earlier.
+func (c *FacetCounter) Remove(recipe model.Recipe) {
+	c.count(recipe, -1)
+}
+
+func (c *FacetCounter) count(recipe model.Recipe, delta int) {
+	c.total += delta
+	for _, tag := range uniqueTags(recipe.Tags) {
+		bump(c.tags, tag, delta)
+	}
+	if recipe.Cuisine != "" {
+		bump(c.cuisines, recipe.Cuisine, delta)
+	}
+	if recipe.Difficulty != "" {
+		bump(c.difficulties, recipe.Difficulty, delta)
+	}
+	bump(c.times, TimeBucketOf(recipe), delta)
+}
+
+// bump adds delta to counts[key], deleting keys that drop to zero so the maps
+// do not keep values no recipe has any more.
+func bump(counts map[string]int, key string, delta int) {
+	if n := counts[key] + delta; n > 0 {
+		counts[key] = n
+	} else {
+		delete(counts, key)
+	}
+}
+
+// uniqueTags drops repeats, which stored recipes from before normalization may have.
+func uniqueTags(tags []string) []string {
+	if len(tags) < 2 {
+		return tags
+	}
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// Facets returns the counts so far.
+func (c *FacetCounter) Facets() Facets {
+	return NewFacets(c.total, c.tags, c.cuisines, c.difficulties, c.times)
+}
+
+// RecipeFaceter is implemented by repositories that count facets themselves,
+// without handing every matching recipe to the caller.
+type RecipeFaceter interface {
+	Facets(ctx context.Context, filter RecipeFilter) (Facets, error)
+}
+
+// CountFacets counts the facets of the recipes in repo matching filter, using
+// the repository's own counting when it has one and streaming otherwise.
+func CountFacets(ctx context.Context, repo RecipeRepository, filter RecipeFilter) (Facets, error) {
+	if faceter, ok := repo.(RecipeFaceter); ok {
+		return faceter.Facets(ctx, filter)
+	}
+	counter := NewFacetCounter()
+	err := Stream(ctx, repo, filter, func(recipe model.Recipe) error {
+		counter.Add(recipe)
+		return nil
+	})
+	if err != nil {
+		return Facets{}, err
+	}
+	return counter.Facets(), nil
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// RecipeImageRepository is implemented by repositories that store a recipe's
+// images apart from its other fields, so that Update leaves them alone and
+// adding or removing one does not rewrite the rest of the recipe. Both
+// methods return the recipe as it is afterwards.
+type RecipeImageRepository interface {
+	AddImage(ctx context.Context, id model.RecipeID, image model.Image) (model.Recipe, error)
+	RemoveImage(ctx context.Context, id model.RecipeID, imageID string) (model.Recipe, error)
+}
+
+// AddImage appends image to the images of the recipe with the given ID,
+// using the repository's own image storage when it has one and updating the
+// whole recipe otherwise.
+func AddImage(ctx context.Context, repo RecipeRepository, id model.RecipeID, image model.Image) (model.Recipe, error) {
+	if images, ok := repo.(RecipeImageRepository); ok {
+		return images.AddImage(ctx, id, image)
+	}
+	recipe, err := repo.GetByID(ctx, id)
+	if err != nil {
+		return model.Recipe{}, err
+	}
+	recipe.Images = append(slices.Clone(recipe.Images), image)
+	return repo.Update(ctx, recipe)
+}
+
+// RemoveImage removes an image from the recipe with the given ID like
+// AddImage adds one. An unknown image returns ErrImageNotFound.
+func RemoveImage(ctx context.Context, repo RecipeRepository, id model.RecipeID, imageID string) (model.Recipe, error) {
+	if images, ok := repo.(RecipeImageRepository); ok {
+		return images.RemoveImage(ctx, id, imageID)
+	}
+	recipe, err := repo.GetByID(ctx, id)
+	if err != nil {
+		return model.Recipe{}, err
+	}
+	i := slices.IndexFunc(recipe.Images, func(image model.Image) bool { return image.ID == imageID })
+	if i < 0 {
+		return model.Recipe{}, fmt.Errorf("image %s: %w", imageID, ErrImageNotFound)
+	}
+	recipe.Images = slices.Delete(slices.Clone(recipe.Images), i, i+1)
+	return repo.Update(ctx, recipe)
+}
@@ -14,3 +14,31 @@ type RecipeRepository interface {
 	Delete(context.Context, model.RecipeID) error
 	GetByTag(context.Context, string) ([]model.Recipe, error)
 }
+
+// RecipeUpserter is implemented by repositories that can store a recipe under
+// its own ID, which importing and copying data need. Created reports whether
+// the recipe did not exist before.
+type RecipeUpserter interface {
+	Upsert(context.Context, model.Recipe) (created bool, err error)
+}
+
+// RecipeBatchUpserter is implemented by repositories that can upsert many
+// recipes in one write. A failed batch may have been partly applied.
+type RecipeBatchUpserter interface {
+	UpsertMany(context.Context, []model.Recipe) (created int, err error)
+}
+
+// RecipeViewRecorder is implemented by repositories that count how often
+// clients read each recipe, such as the cache ranking recipes for warm-up.
+type RecipeViewRecorder interface {
+	RecordView(context.Context, model.RecipeID) error
+}
+
+// RecordView counts a client read of the recipe with the given ID when repo
+// keeps view counts, and does nothing otherwise.
+func RecordView(ctx context.Context, repo RecipeRepository, id model.RecipeID) error {
+	if views, ok := repo.(RecipeViewRecorder); ok {
+		return views.RecordView(ctx, id)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// ShoppingListRepository stores users' shopping lists. Unknown lists return
+// ErrListNotFound.
+type ShoppingListRepository interface {
+	CreateShoppingList(context.Context, model.ShoppingList) (model.ShoppingList, error)
+	GetShoppingList(context.Context, model.ShoppingListID) (model.ShoppingList, error)
+	// ListShoppingLists returns an owner's lists, newest first
+	ListShoppingLists(ctx context.Context, owner string) ([]model.ShoppingList, error)
+	UpdateShoppingList(context.Context, model.ShoppingList) (model.ShoppingList, error)
+	DeleteShoppingList(context.Context, model.ShoppingListID) error
+}
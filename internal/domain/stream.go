@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// RecipeFilter selects recipes for listing and export. The zero value matches
+// every recipe, and each field that is set narrows the selection.
+type RecipeFilter struct {
+	// Tags selects recipes carrying every one of the tags
+	Tags []string
+	// AnyTags selects recipes carrying at least one of the tags
+	AnyTags []string
+	// ExcludeTags drops recipes carrying any of the tags
+	ExcludeTags []string
+	Cuisine     string
+	Course      string
+	Difficulty  string
+	// Diets selects recipes carrying every one of the flags
+	Diets []string
+	// MaxTotalTime excludes recipes that take longer, or whose time is unknown
+	MaxTotalTime time.Duration
+}
+
+// IsZero reports whether the filter matches every recipe.
+func (f RecipeFilter) IsZero() bool {
+	return len(f.Tags) == 0 && len(f.AnyTags) == 0 && len(f.ExcludeTags) == 0 &&
+		f.Cuisine == "" && f.Course == "" && f.Difficulty == "" &&
+		len(f.Diets) == 0 && f.MaxTotalTime == 0
+}
+
+// Matches reports whether the recipe passes the filter.
+func (f RecipeFilter) Matches(recipe model.Recipe) bool {
+	for _, tag := range f.Tags {
+		if !slices.Contains(recipe.Tags, tag) {
+			return false
+		}
+	}
+	if len(f.AnyTags) > 0 && !slices.ContainsFunc(f.AnyTags, func(tag string) bool { return slices.Contains(recipe.Tags, tag) }) {
+		return false
+	}
+	if slices.ContainsFunc(f.ExcludeTags, func(tag string) bool { return slices.Contains(recipe.Tags, tag) }) {
+		return false
+	}
+	if (f.Cuisine != "" && recipe.Cuisine != f.Cuisine) ||
+		(f.Course != "" && recipe.Course != f.Course) ||
+		(f.Difficulty != "" && recipe.Difficulty != f.Difficulty) {
+		return false
+	}
+	for _, diet := range f.Diets {
+		if !slices.Contains(recipe.Diets, diet) {
+			return false
+		}
+	}
+	if f.MaxTotalTime > 0 {
+		total, ok := recipe.TotalDuration()
+		if !ok || total > f.MaxTotalTime {
+			return false
+		}
+	}
+	return true
+}
+
+// RecipeStreamer is implemented by repositories that can hand out matching
+// recipes one at a time without loading them all first. Stream stops at the
+// first error returned by fn, or when ctx is done, and returns that error.
+type RecipeStreamer interface {
+	Stream(ctx context.Context, filter RecipeFilter, fn func(model.Recipe) error) error
+}
+
+// Stream calls fn for every recipe in repo matching filter, using the
+// repository's own streaming when it has one and loading the candidates with
+// GetAll, or GetByTag for the first required tag, otherwise.
+func Stream(ctx context.Context, repo RecipeRepository, filter RecipeFilter, fn func(model.Recipe) error) error {
+	if streamer, ok := repo.(RecipeStreamer); ok {
+		return streamer.Stream(ctx, filter, fn)
+	}
+
+	var (
+		recipes []model.Recipe
+		err     error
+	)
+	if len(filter.Tags) > 0 {
+		recipes, err = repo.GetByTag(ctx, filter.Tags[0])
+	} else {
+		recipes, err = repo.GetAll(ctx)
+	}
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, recipe := range recipes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !filter.Matches(recipe) {
+			continue
+		}
+		if err := fn(recipe); err != nil {
+			return err
+		}
+	}
+	return nil
+}
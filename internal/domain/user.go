@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// UserRepository stores the accounts allowed to sign in. Passwords are kept
+// as hashes in model.User.Password.
+type UserRepository interface {
+	CreateUser(context.Context, model.User) (model.User, error)
+	GetUserByName(context.Context, string) (model.User, error)
+	CountUsers(context.Context) (int64, error)
+}
@@ -0,0 +1,108 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/audit"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditLog is the read side of the audit trail exposed to administrators.
+type AuditLog interface {
+	Query(context.Context, audit.Filter) ([]audit.Event, error)
+	Verify(context.Context) (audit.VerifyResult, error)
+}
+
+// AuditHandler handles administrative HTTP requests for the audit trail.
+type AuditHandler struct {
+	log AuditLog
+}
+
+// NewAuditHandler creates an AuditHandler. A nil log means auditing is disabled
+// and every endpoint responds with 503.
+func NewAuditHandler(log AuditLog) *AuditHandler {
+	return &AuditHandler{log}
+}
+
+// AuditQuery represents the query parameters for listing audit events.
+type AuditQuery struct {
+	// Actor filters by user name
+	Actor string `form:"actor"`
+	// Action filters by action, e.g. recipe.update
+	Action string `form:"action"`
+	// From is the inclusive RFC 3339 lower time bound
+	From time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	// To is the inclusive RFC 3339 upper time bound
+	To time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	// Limit keeps the most recent matches
+	Limit int `form:"limit"`
+}
+
+// available reports whether auditing is configured and writes a 503 otherwise.
+func (h *AuditHandler) available(ctx *gin.Context) bool {
+	if h.log == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "audit log is not configured"})
+		return false
+	}
+	return true
+}
+
+// QueryHandler handles GET requests listing audit events, oldest first.
+func (h *AuditHandler) QueryHandler(ctx *gin.Context) {
+	if !h.available(ctx) {
+		return
+	}
+
+	var q AuditQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid query: from and to must be RFC 3339 timestamps"})
+		return
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+
+	switch {
+	case q.Limit <= 0:
+		q.Limit = defaultAuditLimit
+	case q.Limit > maxAuditLimit:
+		q.Limit = maxAuditLimit
+	}
+
+	events, err := h.log.Query(ctx.Request.Context(), audit.Filter{
+		Actor:  q.Actor,
+		Action: q.Action,
+		From:   q.From,
+		To:     q.To,
+		Limit:  q.Limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read audit log"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"events": events, "count": len(events)})
+}
+
+// VerifyHandler handles GET requests checking the hash chain for tampering.
+func (h *AuditHandler) VerifyHandler(ctx *gin.Context) {
+	if !h.available(ctx) {
+		return
+	}
+
+	result, err := h.log.Verify(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read audit log"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,108 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/audit"
+	"github.com/gin-gonic/gin"
+)
+
+type mockAuditLog struct {
+	filter audit.Filter
+	result audit.VerifyResult
+}
+
+func (m *mockAuditLog) Query(ctx context.Context, f audit.Filter) ([]audit.Event, error) {
+	m.filter = f
+	return []audit.Event{{Seq: 1, Actor: f.Actor, Action: f.Action}}, nil
+}
+
+func (m *mockAuditLog) Verify(ctx context.Context) (audit.VerifyResult, error) {
+	return m.result, nil
+}
+
+func setupAuditRouter(log AuditLog) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewAuditHandler(log)
+
+	router := gin.New()
+	router.GET("/audit", h.QueryHandler)
+	router.GET("/audit/verify", h.VerifyHandler)
+	return router
+}
+
+func TestAuditQueryHandler(t *testing.T) {
+	log := &mockAuditLog{}
+	router := setupAuditRouter(log)
+
+	req, _ := http.NewRequest("GET", "/audit?actor=alice&action=recipe.delete&from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if log.filter.Actor != "alice" || log.filter.Action != "recipe.delete" {
+		t.Errorf("Unexpected filter: %+v", log.filter)
+	}
+	if !log.filter.From.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) || log.filter.To.IsZero() {
+		t.Errorf("Expected time range in filter, got %+v", log.filter)
+	}
+	if log.filter.Limit != defaultAuditLimit {
+		t.Errorf("Expected default limit, got %d", log.filter.Limit)
+	}
+
+	var resp struct {
+		Count  int           `json:"count"`
+		Events []audit.Event `json:"events"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Count != 1 || len(resp.Events) != 1 {
+		t.Errorf("Expected 1 event, got %+v", resp)
+	}
+}
+
+func TestAuditQueryHandlerInvalidRange(t *testing.T) {
+	router := setupAuditRouter(&mockAuditLog{})
+
+	for _, query := range []string{"from=yesterday", "from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z"} {
+		req, _ := http.NewRequest("GET", "/audit?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestAuditVerifyHandler(t *testing.T) {
+	router := setupAuditRouter(&mockAuditLog{result: audit.VerifyResult{Events: 3, BrokenAt: 2, Reason: "event hash does not match its content"}})
+
+	req, _ := http.NewRequest("GET", "/audit/verify", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var result audit.VerifyResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if result.Valid || result.BrokenAt != 2 {
+		t.Errorf("Expected broken chain in response, got %+v", result)
+	}
+}
+
+func TestAuditHandlerNotConfigured(t *testing.T) {
+	router := setupAuditRouter(nil)
+
+	req, _ := http.NewRequest("GET", "/audit", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+}
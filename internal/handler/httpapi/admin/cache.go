@@ -0,0 +1,229 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-demo/recipes-web/internal/cache/redisrecipe"
+	"github.com/gin-demo/recipes-web/internal/repository"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+// CacheAdmin is the set of cache operations exposed to administrators.
+type CacheAdmin interface {
+	InspectCache(context.Context, model.RecipeID) (redisrecipe.Entry, bool, error)
+	EvictByID(context.Context, model.RecipeID) error
+	EvictByTag(context.Context, string) (int64, error)
+	FlushCache(context.Context) (int64, error)
+	CacheStats(context.Context) (redisrecipe.Stats, error)
+	WarmUp(context.Context, repository.WarmUpOptions) (repository.WarmUpResult, error)
+}
+
+// Default bounds of a warm-up requested through the API, matching the
+// defaults of the startup warm-up.
+const (
+	DefaultWarmUpLimit       = 100
+	DefaultWarmUpConcurrency = 8
+)
+
+// CacheHandler handles administrative HTTP requests for the recipe cache.
+type CacheHandler struct {
+	cache CacheAdmin
+	// maxLimit and maxConcurrency bound requested warm-ups; a zero
+	// maxLimit allows every recipe
+	maxLimit       int
+	maxConcurrency int
+}
+
+// CacheOption configures optional CacheHandler behaviour.
+type CacheOption func(*CacheHandler)
+
+// WithWarmUpLimits bounds the warm-ups requested through the API to limit
+// recipes, zero allowing every recipe, loaded concurrency at a time. They are
+// also what an empty request gets. The defaults are DefaultWarmUpLimit and
+// DefaultWarmUpConcurrency.
+func WithWarmUpLimits(limit, concurrency int) CacheOption {
+	return func(h *CacheHandler) {
+		if limit >= 0 {
+			h.maxLimit = limit
+		}
+		if concurrency > 0 {
+			h.maxConcurrency = concurrency
+		}
+	}
+}
+
+// NewCacheHandler creates a CacheHandler. A nil cache means caching is disabled
+// and every endpoint responds with 503.
+func NewCacheHandler(cache CacheAdmin, opts ...CacheOption) *CacheHandler {
+	h := &CacheHandler{cache: cache, maxLimit: DefaultWarmUpLimit, maxConcurrency: DefaultWarmUpConcurrency}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// cacheRecipeRequest represents the URI parameters for a single cache entry.
+type cacheRecipeRequest struct {
+	ID model.RecipeID `uri:"id" binding:"required"`
+}
+
+// cacheTagRequest represents the URI parameters for tag eviction.
+type cacheTagRequest struct {
+	Tag string `uri:"tag" binding:"required"`
+}
+
+// WarmUpRequest represents the optional request body for a manual warm-up.
+// Values over the handler's bounds are lowered to them.
+type WarmUpRequest struct {
+	// Limit is the number of most-viewed recipes to preload; zero preloads
+	// as many as allowed
+	Limit int `json:"limit"`
+	// Concurrency bounds the number of recipes loaded in parallel; zero
+	// uses the most allowed
+	Concurrency int `json:"concurrency"`
+}
+
+// available reports whether a cache is configured and writes a 503 otherwise.
+func (h *CacheHandler) available(ctx *gin.Context) bool {
+	if h.cache == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "cache is not configured"})
+		return false
+	}
+	return true
+}
+
+// InspectHandler handles GET requests returning a cached recipe and its TTL.
+func (h *CacheHandler) InspectHandler(ctx *gin.Context) {
+	if !h.available(ctx) {
+		return
+	}
+
+	var req cacheRecipeRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID"})
+		return
+	}
+
+	entry, found, err := h.cache.InspectCache(ctx.Request.Context(), req.ID)
+	if err != nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "cache unavailable"})
+		return
+	}
+	if !found {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "recipe not cached"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"recipe": entry.Recipe,
+		"ttl":    entry.TTL.String(),
+	})
+}
+
+// EvictByIDHandler handles DELETE requests removing one recipe from the cache.
+func (h *CacheHandler) EvictByIDHandler(ctx *gin.Context) {
+	if !h.available(ctx) {
+		return
+	}
+
+	var req cacheRecipeRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID"})
+		return
+	}
+
+	if err := h.cache.EvictByID(ctx.Request.Context(), req.ID); err != nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "cache unavailable"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// EvictByTagHandler handles DELETE requests removing all cached recipes with a tag.
+func (h *CacheHandler) EvictByTagHandler(ctx *gin.Context) {
+	if !h.available(ctx) {
+		return
+	}
+
+	var req cacheTagRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "tag is required"})
+		return
+	}
+
+	evicted, err := h.cache.EvictByTag(ctx.Request.Context(), req.Tag)
+	if err != nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "cache unavailable"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"evicted": evicted})
+}
+
+// FlushHandler handles DELETE requests removing every cached recipe.
+func (h *CacheHandler) FlushHandler(ctx *gin.Context) {
+	if !h.available(ctx) {
+		return
+	}
+
+	evicted, err := h.cache.FlushCache(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "cache unavailable"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"evicted": evicted})
+}
+
+// StatsHandler handles GET requests reporting cache hit/miss statistics.
+func (h *CacheHandler) StatsHandler(ctx *gin.Context) {
+	if !h.available(ctx) {
+		return
+	}
+
+	stats, err := h.cache.CacheStats(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "cache unavailable", "stats": stats})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// WarmUpHandler handles POST requests preloading recipes into the cache.
+func (h *CacheHandler) WarmUpHandler(ctx *gin.Context) {
+	if !h.available(ctx) {
+		return
+	}
+
+	var req WarmUpRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+	if req.Limit < 0 || req.Concurrency < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit and concurrency must not be negative"})
+		return
+	}
+
+	opts := repository.WarmUpOptions{Limit: req.Limit, Concurrency: req.Concurrency}
+	if h.maxLimit > 0 && (opts.Limit == 0 || opts.Limit > h.maxLimit) {
+		opts.Limit = h.maxLimit
+	}
+	if opts.Concurrency == 0 || opts.Concurrency > h.maxConcurrency {
+		opts.Concurrency = h.maxConcurrency
+	}
+
+	result, err := h.cache.WarmUp(ctx.Request.Context(), opts)
+	if err != nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
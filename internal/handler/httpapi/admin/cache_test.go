@@ -0,0 +1,220 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/cache/redisrecipe"
+	"github.com/gin-demo/recipes-web/internal/handler/httpapi/middleware"
+	"github.com/gin-demo/recipes-web/internal/repository"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+type mockCache struct {
+	entries    map[model.RecipeID]model.Recipe
+	err        error
+	warmUpOpts repository.WarmUpOptions
+}
+
+func (m *mockCache) InspectCache(ctx context.Context, id model.RecipeID) (redisrecipe.Entry, bool, error) {
+	if m.err != nil {
+		return redisrecipe.Entry{}, false, m.err
+	}
+	r, ok := m.entries[id]
+	return redisrecipe.Entry{Recipe: r, TTL: time.Minute}, ok, nil
+}
+
+func (m *mockCache) EvictByID(ctx context.Context, id model.RecipeID) error {
+	delete(m.entries, id)
+	return m.err
+}
+
+func (m *mockCache) EvictByTag(ctx context.Context, tag string) (int64, error) {
+	return 2, m.err
+}
+
+func (m *mockCache) FlushCache(ctx context.Context) (int64, error) {
+	n := int64(len(m.entries))
+	m.entries = map[model.RecipeID]model.Recipe{}
+	return n, m.err
+}
+
+func (m *mockCache) CacheStats(ctx context.Context) (redisrecipe.Stats, error) {
+	return redisrecipe.Stats{Hits: 3, Misses: 1, HitRatio: 0.75, Entries: int64(len(m.entries))}, m.err
+}
+
+func (m *mockCache) WarmUp(ctx context.Context, opts repository.WarmUpOptions) (repository.WarmUpResult, error) {
+	m.warmUpOpts = opts
+	return repository.WarmUpResult{Loaded: 5}, m.err
+}
+
+func setupTestRouter(cache CacheAdmin, role string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewCacheHandler(cache)
+
+	group := router.Group("/admin")
+	group.Use(func(c *gin.Context) { c.Set("role", role) }, middleware.RequireRole("admin"))
+	{
+		group.GET("/cache/stats", h.StatsHandler)
+		group.GET("/cache/recipes/:id", h.InspectHandler)
+		group.DELETE("/cache/recipes/:id", h.EvictByIDHandler)
+		group.DELETE("/cache/tags/:tag", h.EvictByTagHandler)
+		group.DELETE("/cache", h.FlushHandler)
+		group.POST("/cache/warmup", h.WarmUpHandler)
+	}
+
+	return router
+}
+
+func serve(router *gin.Engine, method, path string, body []byte) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestInspectHandler(t *testing.T) {
+	cache := &mockCache{entries: map[model.RecipeID]model.Recipe{"1": {ID: "1", Name: "Cached"}}}
+	router := setupTestRouter(cache, "admin")
+
+	w := serve(router, "GET", "/admin/cache/recipes/1", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var out struct {
+		Recipe model.Recipe `json:"recipe"`
+		TTL    string       `json:"ttl"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &out)
+	if out.Recipe.Name != "Cached" || out.TTL != "1m0s" {
+		t.Errorf("Unexpected response: %s", w.Body.String())
+	}
+
+	// Not cached
+	w2 := serve(router, "GET", "/admin/cache/recipes/2", nil)
+	if w2.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w2.Code)
+	}
+
+	// Redis error
+	cache.err = errors.New("connection refused")
+	w3 := serve(router, "GET", "/admin/cache/recipes/1", nil)
+	if w3.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w3.Code)
+	}
+}
+
+func TestEvictHandlers(t *testing.T) {
+	cache := &mockCache{entries: map[model.RecipeID]model.Recipe{"1": {ID: "1"}, "2": {ID: "2"}}}
+	router := setupTestRouter(cache, "admin")
+
+	w := serve(router, "DELETE", "/admin/cache/recipes/1", nil)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if _, ok := cache.entries["1"]; ok {
+		t.Error("Entry should be evicted")
+	}
+
+	w2 := serve(router, "DELETE", "/admin/cache/tags/italian", nil)
+	if w2.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w2.Code)
+	}
+
+	w3 := serve(router, "DELETE", "/admin/cache", nil)
+	if w3.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w3.Code)
+	}
+	if len(cache.entries) != 0 {
+		t.Error("Cache should be flushed")
+	}
+}
+
+func TestStatsAndWarmUpHandlers(t *testing.T) {
+	cache := &mockCache{entries: map[model.RecipeID]model.Recipe{}}
+	router := setupTestRouter(cache, "admin")
+
+	w := serve(router, "GET", "/admin/cache/stats", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var stats redisrecipe.Stats
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	if stats.Hits != 3 || stats.HitRatio != 0.75 {
+		t.Errorf("Unexpected stats: %s", w.Body.String())
+	}
+
+	body, _ := json.Marshal(WarmUpRequest{Limit: 10, Concurrency: 4})
+	w2 := serve(router, "POST", "/admin/cache/warmup", body)
+	if w2.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w2.Code)
+	}
+	if cache.warmUpOpts.Limit != 10 || cache.warmUpOpts.Concurrency != 4 {
+		t.Errorf("Warm-up options not passed through: %+v", cache.warmUpOpts)
+	}
+
+	// Empty body warms as much as allowed
+	w3 := serve(router, "POST", "/admin/cache/warmup", nil)
+	if w3.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w3.Code)
+	}
+	if cache.warmUpOpts != (repository.WarmUpOptions{Limit: DefaultWarmUpLimit, Concurrency: DefaultWarmUpConcurrency}) {
+		t.Errorf("Expected the default bounds, got %+v", cache.warmUpOpts)
+	}
+
+	// Invalid body
+	w4 := serve(router, "POST", "/admin/cache/warmup", []byte("invalid"))
+	if w4.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w4.Code)
+	}
+}
+
+func TestWarmUpHandlerBoundsRequest(t *testing.T) {
+	cache := &mockCache{entries: map[model.RecipeID]model.Recipe{}}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/admin/cache/warmup", NewCacheHandler(cache, WithWarmUpLimits(50, 2)).WarmUpHandler)
+
+	body, _ := json.Marshal(WarmUpRequest{Limit: 1_000_000, Concurrency: 10_000})
+	if w := serve(router, "POST", "/admin/cache/warmup", body); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if cache.warmUpOpts != (repository.WarmUpOptions{Limit: 50, Concurrency: 2}) {
+		t.Errorf("Expected the request lowered to the bounds, got %+v", cache.warmUpOpts)
+	}
+
+	for _, req := range []WarmUpRequest{{Limit: -1}, {Concurrency: -1}} {
+		body, _ := json.Marshal(req)
+		if w := serve(router, "POST", "/admin/cache/warmup", body); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %+v, got %d", req, w.Code)
+		}
+	}
+}
+
+func TestCacheHandlerWithoutCache(t *testing.T) {
+	router := setupTestRouter(nil, "admin")
+
+	for _, path := range []string{"/admin/cache/stats", "/admin/cache/recipes/1"} {
+		w := serve(router, "GET", path, nil)
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: expected status 503, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestCacheHandlerRequiresAdmin(t *testing.T) {
+	router := setupTestRouter(&mockCache{}, "viewer")
+
+	w := serve(router, "DELETE", "/admin/cache", nil)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
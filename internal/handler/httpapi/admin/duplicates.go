@@ -0,0 +1,96 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/similar"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+// DuplicateAdmin is the set of duplicate recipe operations exposed to administrators.
+type DuplicateAdmin interface {
+	DuplicateClusters(context.Context) ([]recipe.DuplicateCluster, error)
+	MergeRecipes(context.Context, model.RecipeID, []model.RecipeID) (model.Recipe, error)
+}
+
+// DuplicateHandler handles administrative HTTP requests for duplicate recipes.
+type DuplicateHandler struct {
+	recipes DuplicateAdmin
+}
+
+// NewDuplicateHandler creates a DuplicateHandler.
+func NewDuplicateHandler(recipes DuplicateAdmin) *DuplicateHandler {
+	return &DuplicateHandler{recipes}
+}
+
+// DuplicateCluster is a group of recipes suspected to be the same.
+type DuplicateCluster struct {
+	// Score is how alike the least alike pair linking them is, from 0 to 1
+	Score   float64        `json:"score"`
+	Recipes []model.Recipe `json:"recipes"`
+}
+
+// DuplicateReport is the body answering GET /admin/recipes/duplicates.
+type DuplicateReport struct {
+	Clusters []DuplicateCluster `json:"clusters"`
+}
+
+// MergeRequest is the body of POST /admin/recipes/merge.
+type MergeRequest struct {
+	// Keep is the recipe the others are merged into
+	Keep model.RecipeID `json:"keep"`
+	// Duplicates are deleted once merged
+	Duplicates []model.RecipeID `json:"duplicates"`
+}
+
+// ReportHandler handles GET requests listing every cluster of suspected
+// duplicates in the catalog, most alike first.
+func (h *DuplicateHandler) ReportHandler(ctx *gin.Context) {
+	clusters, err := h.recipes.DuplicateClusters(ctx.Request.Context())
+	if err != nil {
+		switch {
+		case errors.Is(err, similar.ErrNotReady):
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	report := DuplicateReport{Clusters: make([]DuplicateCluster, len(clusters))}
+	for i, c := range clusters {
+		report.Clusters[i] = DuplicateCluster{Score: c.Score, Recipes: c.Recipes}
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// MergeHandler handles POST requests merging duplicates into the recipe
+// kept, answering with the merged recipe.
+func (h *DuplicateHandler) MergeHandler(ctx *gin.Context) {
+	var req MergeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	merged, err := h.recipes.MergeRecipes(ctx.Request.Context(), req.Keep, req.Duplicates)
+	if err != nil {
+		var verr *domain.ValidationError
+		switch {
+		case errors.As(err, &verr):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error(), "fields": verr.Fields})
+		case errors.Is(err, domain.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, merged)
+}
@@ -0,0 +1,90 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/similar"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+type mockDuplicates struct {
+	clusters []recipe.DuplicateCluster
+	err      error
+	merged   []model.RecipeID
+}
+
+func (m *mockDuplicates) DuplicateClusters(ctx context.Context) ([]recipe.DuplicateCluster, error) {
+	return m.clusters, m.err
+}
+
+func (m *mockDuplicates) MergeRecipes(ctx context.Context, keep model.RecipeID, ids []model.RecipeID) (model.Recipe, error) {
+	if m.err != nil {
+		return model.Recipe{}, m.err
+	}
+	m.merged = ids
+	return model.Recipe{ID: keep, Name: "Merged"}, nil
+}
+
+func setupDuplicateRouter(recipes DuplicateAdmin) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewDuplicateHandler(recipes)
+	router.GET("/admin/recipes/duplicates", h.ReportHandler)
+	router.POST("/admin/recipes/merge", h.MergeHandler)
+	return router
+}
+
+func TestDuplicateReportHandler(t *testing.T) {
+	recipes := &mockDuplicates{clusters: []recipe.DuplicateCluster{
+		{Score: 0.9, Recipes: []model.Recipe{{ID: "1", Name: "Cake"}, {ID: "2", Name: "Cakes"}}},
+	}}
+	router := setupDuplicateRouter(recipes)
+
+	w := serve(router, "GET", "/admin/recipes/duplicates", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var report DuplicateReport
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if len(report.Clusters) != 1 || report.Clusters[0].Score != 0.9 || len(report.Clusters[0].Recipes) != 2 {
+		t.Errorf("Unexpected report: %s", w.Body.String())
+	}
+
+	recipes.err = similar.ErrNotReady
+	if w := serve(router, "GET", "/admin/recipes/duplicates", nil); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 before the index is built, got %d", w.Code)
+	}
+}
+
+func TestMergeHandler(t *testing.T) {
+	recipes := &mockDuplicates{}
+	router := setupDuplicateRouter(recipes)
+
+	w := serve(router, "POST", "/admin/recipes/merge", []byte(`{"keep": "1", "duplicates": ["2", "3"]}`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if len(recipes.merged) != 2 {
+		t.Errorf("Duplicates not passed through: %v", recipes.merged)
+	}
+
+	for err, status := range map[error]int{
+		&domain.ValidationError{Fields: []domain.FieldError{{Field: "duplicates", Message: "must not be empty"}}}: http.StatusBadRequest,
+		fmt.Errorf("recipe 2: %w", domain.ErrNotFound):                                                            http.StatusNotFound,
+	} {
+		recipes.err = err
+		if w := serve(router, "POST", "/admin/recipes/merge", []byte(`{"keep": "1", "duplicates": ["2"]}`)); w.Code != status {
+			t.Errorf("%v: expected status %d, got %d", err, status, w.Code)
+		}
+	}
+	if w := serve(router, "POST", "/admin/recipes/merge", []byte("invalid")); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid body, got %d", w.Code)
+	}
+}
@@ -1,10 +1,16 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
+	"github.com/gin-demo/recipes-web/internal/audit"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/password"
 	"github.com/gin-demo/recipes-web/model"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -25,39 +31,64 @@ But don’t worry about that yet.
 type Config struct {
 	Secret string
 	Issuer string
+	// TokenTTL is how long signed tokens stay valid; zero means 15 minutes
+	TokenTTL time.Duration
+	// Auditor records every sign-in attempt; nil disables auditing
+	Auditor audit.Recorder
+	// Users holds the accounts allowed to sign in. While it holds none, or
+	// when it is nil, only the built-in demo admin is admitted
+	Users domain.UserRepository
 }
 
+const defaultTokenTTL = 15 * time.Minute
+
 type AuthHandler struct {
 	config Config
 }
 
 func New(config Config) *AuthHandler {
+	if config.TokenTTL <= 0 {
+		config.TokenTTL = defaultTokenTTL
+	}
 	return &AuthHandler{config: config}
 }
 
 func (ah *AuthHandler) SignInHandler(ctx *gin.Context) {
 	var user model.User
 	if err := ctx.ShouldBindJSON(&user); err != nil {
+		ah.recordSignIn(ctx, user.UserName, "malformed request")
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error": "username or password is required",
 		})
 		return
 	}
 
-	if user.UserName != "admin" || user.Password != "password" {
+	role, err := ah.authenticate(ctx.Request.Context(), user)
+	if errors.Is(err, errInvalidCredentials) {
+		slog.WarnContext(ctx.Request.Context(), "sign-in failed", "user", user)
+		ah.recordSignIn(ctx, user.UserName, "invalid credentials")
 		ctx.JSON(http.StatusUnauthorized, gin.H{
 			"error": "invalid username or password",
 		})
 		return
 	}
+	if err != nil {
+		slog.ErrorContext(ctx.Request.Context(), "user lookup failed", "error", err)
+		ah.recordSignIn(ctx, user.UserName, "user lookup failed")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "something went wrong"})
+		return
+	}
 
-	expiryAt := time.Now().Add(15 * time.Minute)
-	token, err := ah.createToken(user.UserName, expiryAt)
+	expiryAt := time.Now().Add(ah.config.TokenTTL)
+	token, err := ah.createToken(user.UserName, role, expiryAt)
 	if err != nil {
+		ah.recordSignIn(ctx, user.UserName, "token signing failed")
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "something went wrong"})
 		return
 	}
 
+	ah.recordSignIn(ctx, user.UserName, "")
+
 	ctx.JSON(http.StatusOK, JWTOutput{
 		Token:   token,
 		Expires: expiryAt,
@@ -75,10 +106,52 @@ type JWTOutput struct {
 	Expires time.Time `json:"expiresIn"`
 }
 
-func (ah *AuthHandler) createToken(userName string, expiry time.Time) (string, error) {
+var errInvalidCredentials = errors.New("invalid credentials")
+
+// authenticate checks the credentials and returns the user's role. The demo
+// admin is checked on every attempt, so it stops working as soon as the
+// first account is created.
+func (ah *AuthHandler) authenticate(ctx context.Context, user model.User) (string, error) {
+	demo, err := ah.demoOnly(ctx)
+	if err != nil {
+		return "", err
+	}
+	if demo {
+		if user.UserName != "admin" || user.Password != "password" {
+			return "", errInvalidCredentials
+		}
+		return "admin", nil
+	}
+
+	stored, err := ah.config.Users.GetUserByName(ctx, user.UserName)
+	if errors.Is(err, domain.ErrNotFound) {
+		return "", errInvalidCredentials
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := password.Check(stored.Password, user.Password); err != nil {
+		return "", errInvalidCredentials
+	}
+	return stored.Role, nil
+}
+
+// demoOnly reports whether there are no accounts, so the demo admin signs in.
+func (ah *AuthHandler) demoOnly(ctx context.Context) (bool, error) {
+	if ah.config.Users == nil {
+		return true, nil
+	}
+	count, err := ah.config.Users.CountUsers(ctx)
+	if err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
+func (ah *AuthHandler) createToken(userName, role string, expiry time.Time) (string, error) {
 	claims := Claims{
 		UserName: userName,
-		Role:     "admin",
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiry),
 			Issuer:    ah.config.Issuer,
@@ -92,3 +165,20 @@ func (ah *AuthHandler) createToken(userName string, expiry time.Time) (string, e
 	}
 	return token.SignedString([]byte(secretKey))
 }
+
+// recordSignIn audits a sign-in attempt; failure is empty on success.
+func (ah *AuthHandler) recordSignIn(ctx *gin.Context, userName, failure string) {
+	if ah.config.Auditor == nil {
+		return
+	}
+
+	ev := audit.Event{Action: audit.ActionSignIn, Actor: userName, Outcome: audit.OutcomeSuccess}
+	if failure != "" {
+		ev.Outcome = audit.OutcomeFailure
+		ev.Error = failure
+	}
+
+	if err := ah.config.Auditor.Record(ctx.Request.Context(), ev); err != nil {
+		slog.ErrorContext(ctx.Request.Context(), "failed to record audit event", "action", ev.Action, "error", err)
+	}
+}
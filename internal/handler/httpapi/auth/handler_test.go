@@ -2,14 +2,18 @@ package auth
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
 	"time"
 
+	"github.com/gin-demo/recipes-web/internal/audit"
+	"github.com/gin-demo/recipes-web/internal/domain"
 	"github.com/gin-demo/recipes-web/internal/handler/httpapi/middleware"
+	"github.com/gin-demo/recipes-web/internal/password"
+	"github.com/gin-demo/recipes-web/model"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -87,18 +91,16 @@ func TestAuthMiddleware_ValidAndInvalidToken(t *testing.T) {
 
     // configure secret for middleware
     secret := "middleware-secret"
-    os.Setenv("JWT_SECRET", secret)
-    defer os.Unsetenv("JWT_SECRET")
 
     ah := New(Config{Secret: secret, Issuer: "test-issuer"})
     expiry := time.Now().Add(10 * time.Minute)
-    token, err := ah.createToken("admin", expiry)
+    token, err := ah.createToken("admin", "admin", expiry)
     if err != nil {
         t.Fatalf("failed to create token: %v", err)
     }
 
     router := gin.New()
-    router.GET("/protected", middleware.AuthMiddleware(), func(c *gin.Context) {
+    router.GET("/protected", middleware.AuthMiddleware(secret), func(c *gin.Context) {
         c.JSON(200, gin.H{"userName": c.GetString("userName"), "role": c.GetString("role")})
     })
 
@@ -119,3 +121,130 @@ func TestAuthMiddleware_ValidAndInvalidToken(t *testing.T) {
         t.Errorf("expected 401 when authorization header missing, got %d", w2.Code)
     }
 }
+
+type recordedSignIns struct {
+	events []audit.Event
+}
+
+func (r *recordedSignIns) Record(ctx context.Context, ev audit.Event) error {
+	r.events = append(r.events, ev)
+	return nil
+}
+
+func TestSignInHandler_Audited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := &recordedSignIns{}
+	ah := New(Config{Secret: "test-secret", Issuer: "test-issuer", Auditor: recorder})
+	router := gin.New()
+	router.POST("/signin", ah.SignInHandler)
+
+	for _, body := range []string{`{"userName":"admin","password":"password"}`, `{"userName":"admin","password":"wrong"}`} {
+		req, _ := http.NewRequest("POST", "/signin", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if len(recorder.events) != 2 {
+		t.Fatalf("expected 2 audit events, got %d", len(recorder.events))
+	}
+	if ev := recorder.events[0]; ev.Action != audit.ActionSignIn || ev.Actor != "admin" || ev.Outcome != audit.OutcomeSuccess {
+		t.Errorf("unexpected success event: %+v", ev)
+	}
+	if ev := recorder.events[1]; ev.Outcome != audit.OutcomeFailure || ev.Error == "" {
+		t.Errorf("unexpected failure event: %+v", ev)
+	}
+}
+
+type stubUsers struct {
+	users map[string]model.User
+}
+
+func (s *stubUsers) CreateUser(ctx context.Context, u model.User) (model.User, error) {
+	s.users[u.UserName] = u
+	return u, nil
+}
+
+func (s *stubUsers) GetUserByName(ctx context.Context, name string) (model.User, error) {
+	u, ok := s.users[name]
+	if !ok {
+		return model.User{}, domain.ErrNotFound
+	}
+	return u, nil
+}
+
+func (s *stubUsers) CountUsers(ctx context.Context) (int64, error) {
+	return int64(len(s.users)), nil
+}
+
+func TestSignInHandler_UserRepository(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hash, err := password.Hash("editor-pass")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	users := &stubUsers{users: map[string]model.User{"ed": {UserName: "ed", Password: hash, Role: "editor"}}}
+	ah := New(Config{Secret: "test-secret", Issuer: "test-issuer", Users: users})
+	router := gin.New()
+	router.POST("/signin", ah.SignInHandler)
+
+	signIn := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/signin", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := signIn(`{"userName":"ed","password":"editor-pass"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var out JWTOutput
+	_ = json.Unmarshal(w.Body.Bytes(), &out)
+	claims := &Claims{}
+	if _, err := jwt.ParseWithClaims(out.Token, claims, func(*jwt.Token) (any, error) { return []byte("test-secret"), nil }); err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	if claims.Role != "editor" {
+		t.Errorf("expected role editor, got %q", claims.Role)
+	}
+
+	if w := signIn(`{"userName":"ed","password":"wrong-pass"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a wrong password, got %d", w.Code)
+	}
+	if w := signIn(`{"userName":"admin","password":"password"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected the demo admin to be disabled, got %d", w.Code)
+	}
+}
+
+func TestSignInHandler_DemoAdminUntilFirstAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	users := &stubUsers{users: map[string]model.User{}}
+	ah := New(Config{Secret: "test-secret", Issuer: "test-issuer", Users: users})
+	router := gin.New()
+	router.POST("/signin", ah.SignInHandler)
+
+	signIn := func(body string) int {
+		req, _ := http.NewRequest("POST", "/signin", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := signIn(`{"userName":"admin","password":"password"}`); code != http.StatusOK {
+		t.Fatalf("expected the demo admin to sign in without accounts, got %d", code)
+	}
+
+	hash, err := password.Hash("editor-pass")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	users.CreateUser(context.Background(), model.User{UserName: "ed", Password: hash, Role: "editor"})
+	if code := signIn(`{"userName":"admin","password":"password"}`); code != http.StatusUnauthorized {
+		t.Errorf("expected the demo admin to be disabled once an account exists, got %d", code)
+	}
+	if code := signIn(`{"userName":"ed","password":"editor-pass"}`); code != http.StatusOK {
+		t.Errorf("expected the new account to sign in, got %d", code)
+	}
+}
@@ -0,0 +1,181 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+// BatchCreateRequest is the body of POST /recipes:batchCreate.
+type BatchCreateRequest struct {
+	Recipes []model.Recipe `json:"recipes"`
+	// Atomic applies every item or none; by default each item succeeds or fails on its own
+	Atomic bool `json:"atomic"`
+}
+
+// BatchUpdateItem updates one recipe in POST /recipes:batchUpdate.
+type BatchUpdateItem struct {
+	// ID is the unique identifier of the recipe to update
+	ID model.RecipeID `json:"id"`
+	UpdateRecipeRequest
+}
+
+// BatchUpdateRequest is the body of POST /recipes:batchUpdate.
+type BatchUpdateRequest struct {
+	Updates []BatchUpdateItem `json:"updates"`
+	Atomic  bool              `json:"atomic"`
+}
+
+// BatchIDsRequest is the body of POST /recipes:batchGet and /recipes:batchDelete.
+type BatchIDsRequest struct {
+	IDs []model.RecipeID `json:"ids"`
+	// Atomic is ignored by batchGet
+	Atomic bool `json:"atomic"`
+}
+
+// BatchItemResult is the outcome of one item, with the status a single request would have had.
+type BatchItemResult struct {
+	Index  int                 `json:"index"`
+	Status int                 `json:"status"`
+	Recipe *model.Recipe       `json:"recipe,omitempty"`
+	Error  string              `json:"error,omitempty"`
+	Fields []domain.FieldError `json:"fields,omitempty"`
+	// Duplicates are the stored recipes a created one looks like
+	Duplicates []domain.Duplicate `json:"duplicates,omitempty"`
+}
+
+// BatchResponse reports every item of a batch in request order.
+type BatchResponse struct {
+	Results   []BatchItemResult `json:"results"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	// Aborted is set when an atomic batch was rolled back
+	Aborted bool `json:"aborted,omitempty"`
+}
+
+// BatchCreateHandler handles POST /recipes:batchCreate. Suspected duplicates
+// are reported per item, or reject their item with duplicates=reject.
+func (handler *Handler) BatchCreateHandler(ctx *gin.Context) {
+	dup, ok := duplicateMode(ctx)
+	if !ok {
+		return
+	}
+	var req BatchCreateRequest
+	if err := handler.bindBody(ctx, &req, handler.ctrl.Limits().MaxBatchSize); err != nil {
+		ctx.JSON(bodyErrorStatus(err), gin.H{"error": "invalid request body"})
+		return
+	}
+
+	results, err := handler.ctrl.CreateRecipes(ctx.Request.Context(), req.Recipes, batchMode(req.Atomic), dup)
+	writeBatch(ctx, results, err, http.StatusCreated, true)
+}
+
+// BatchGetHandler handles POST /recipes:batchGet.
+func (handler *Handler) BatchGetHandler(ctx *gin.Context) {
+	var req BatchIDsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	results, err := handler.ctrl.GetRecipes(ctx.Request.Context(), req.IDs)
+	writeBatch(ctx, results, err, http.StatusOK, true)
+}
+
+// BatchUpdateHandler handles POST /recipes:batchUpdate.
+func (handler *Handler) BatchUpdateHandler(ctx *gin.Context) {
+	var req BatchUpdateRequest
+	if err := handler.bindBody(ctx, &req, handler.ctrl.Limits().MaxBatchSize); err != nil {
+		ctx.JSON(bodyErrorStatus(err), gin.H{"error": "invalid request body"})
+		return
+	}
+
+	cmds := make([]recipe.BatchUpdateCommand, len(req.Updates))
+	for i, item := range req.Updates {
+		cmds[i] = recipe.BatchUpdateCommand{ID: item.ID, UpdateRecipeCommand: item.command()}
+	}
+
+	results, err := handler.ctrl.UpdateRecipes(ctx.Request.Context(), cmds, batchMode(req.Atomic))
+	writeBatch(ctx, results, err, http.StatusOK, true)
+}
+
+// BatchDeleteHandler handles POST /recipes:batchDelete.
+func (handler *Handler) BatchDeleteHandler(ctx *gin.Context) {
+	var req BatchIDsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	results, err := handler.ctrl.DeleteRecipes(ctx.Request.Context(), req.IDs, batchMode(req.Atomic))
+	writeBatch(ctx, results, err, http.StatusNoContent, false)
+}
+
+func batchMode(atomic bool) domain.BatchMode {
+	if atomic {
+		return domain.BatchAtomic
+	}
+	return domain.BatchBestEffort
+}
+
+// writeBatch responds 200 with per-item results, 409 when an atomic batch was
+// aborted, 400 when the batch itself was rejected and 500 when storage failed.
+func writeBatch(ctx *gin.Context, results []recipe.BatchResult, err error, success int, withRecipe bool) {
+	if results == nil && errors.Is(err, domain.ErrInvalidInput) {
+		invalidInput(ctx, err)
+		return
+	}
+
+	resp := BatchResponse{
+		Results: make([]BatchItemResult, len(results)),
+		Aborted: errors.Is(err, domain.ErrBatchAborted),
+	}
+	for i, result := range results {
+		item := BatchItemResult{Index: i, Status: success, Duplicates: result.Duplicates}
+		if result.Err != nil {
+			item.Status, item.Error = itemError(result.Err)
+			var verr *domain.ValidationError
+			if errors.As(result.Err, &verr) {
+				item.Fields = verr.Fields
+			}
+			var derr *domain.DuplicateError
+			if errors.As(result.Err, &derr) {
+				item.Duplicates = derr.Duplicates
+			}
+			resp.Failed++
+		} else {
+			if withRecipe {
+				item.Recipe = &result.Recipe
+			}
+			resp.Succeeded++
+		}
+		resp.Results[i] = item
+	}
+
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, resp)
+	case resp.Aborted:
+		ctx.JSON(http.StatusConflict, resp)
+	default:
+		ctx.JSON(http.StatusInternalServerError, resp)
+	}
+}
+
+// itemError maps an item's error onto the status and message a single request would get.
+func itemError(err error) (int, string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		return http.StatusBadRequest, domain.ErrInvalidInput.Error()
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, domain.ErrConflict), errors.Is(err, domain.ErrBatchAborted):
+		return http.StatusConflict, err.Error()
+	default:
+		return http.StatusInternalServerError, "internal error"
+	}
+}
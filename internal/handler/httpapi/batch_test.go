@@ -0,0 +1,118 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+func setupBatchRouter(repo *mockRepo) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := New(recipe.New(repo))
+
+	router.GET("/recipes/:id", handler.GetRecipeByIDHandler)
+	router.POST(`/recipes\:batchCreate`, handler.BatchCreateHandler)
+	router.POST(`/recipes\:batchGet`, handler.BatchGetHandler)
+	router.POST(`/recipes\:batchUpdate`, handler.BatchUpdateHandler)
+	router.POST(`/recipes\:batchDelete`, handler.BatchDeleteHandler)
+	router.POST(`/recipes\:import`, handler.ImportHandler)
+	return router
+}
+
+func postBatch(t *testing.T, router *gin.Engine, path, body string) (int, BatchResponse) {
+	t.Helper()
+	req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp BatchResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp
+}
+
+func TestBatchCreateHandler(t *testing.T) {
+	router := setupBatchRouter(&mockRepo{})
+
+	code, resp := postBatch(t, router, "/recipes:batchCreate", `{"recipes":[{"name":"Soup"},{"name":""}]}`)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if resp.Succeeded != 1 || resp.Failed != 1 {
+		t.Errorf("Expected 1 succeeded and 1 failed, got %+v", resp)
+	}
+	if resp.Results[0].Status != http.StatusCreated || resp.Results[0].Recipe == nil {
+		t.Errorf("Unexpected first result: %+v", resp.Results[0])
+	}
+	if resp.Results[1].Status != http.StatusBadRequest || len(resp.Results[1].Fields) != 1 {
+		t.Errorf("Expected field errors on the second result, got %+v", resp.Results[1])
+	}
+
+	// Atomic with an invalid item
+	code, resp = postBatch(t, router, "/recipes:batchCreate", `{"atomic":true,"recipes":[{"name":"Soup"},{"name":""}]}`)
+	if code != http.StatusConflict || !resp.Aborted || resp.Results[0].Status != http.StatusConflict {
+		t.Errorf("Expected an aborted batch with status 409, got %d %+v", code, resp)
+	}
+
+	// Empty batch
+	code, _ = postBatch(t, router, "/recipes:batchCreate", `{"recipes":[]}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an empty batch, got %d", code)
+	}
+}
+
+func TestBatchGetAndDeleteHandlers(t *testing.T) {
+	repo := &mockRepo{}
+	repo.getByIDFunc = func(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+		if id == "missing" {
+			return model.Recipe{}, domain.ErrNotFound
+		}
+		return model.Recipe{ID: id, Name: "Soup"}, nil
+	}
+	router := setupBatchRouter(repo)
+
+	code, resp := postBatch(t, router, "/recipes:batchGet", `{"ids":["a","missing"]}`)
+	if code != http.StatusOK || resp.Results[0].Recipe == nil || resp.Results[1].Status != http.StatusNotFound {
+		t.Errorf("Unexpected batchGet response %d %+v", code, resp)
+	}
+
+	code, resp = postBatch(t, router, "/recipes:batchDelete", `{"ids":["a"]}`)
+	if code != http.StatusOK || resp.Results[0].Status != http.StatusNoContent || resp.Results[0].Recipe != nil {
+		t.Errorf("Unexpected batchDelete response %d %+v", code, resp)
+	}
+
+	// The single-recipe route still matches next to the batch routes
+	req, _ := http.NewRequest("GET", "/recipes/a", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected GET /recipes/a to still work, got %d", w.Code)
+	}
+}
+
+func TestBatchUpdateHandler(t *testing.T) {
+	repo := &mockRepo{}
+	repo.getByIDFunc = func(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+		return model.Recipe{ID: id, Name: "Soup"}, nil
+	}
+	router := setupBatchRouter(repo)
+
+	code, resp := postBatch(t, router, "/recipes:batchUpdate", `{"updates":[{"id":"a","name":"Stew"},{"id":"b","tags":["bad,tag"]}]}`)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if resp.Results[0].Recipe == nil || resp.Results[0].Recipe.Name != "Stew" {
+		t.Errorf("Expected the first recipe renamed, got %+v", resp.Results[0])
+	}
+	if resp.Results[1].Status != http.StatusBadRequest {
+		t.Errorf("Expected the second update to fail validation, got %+v", resp.Results[1])
+	}
+}
@@ -0,0 +1,97 @@
+package httpapi
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/internal/cookbook"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+// CookbookRequest selects the recipes of a cookbook, by ID in the order given
+// or by tag.
+type CookbookRequest struct {
+	IDs []model.RecipeID `json:"ids"`
+	Tag string           `json:"tag"`
+	// Title is printed above the table of contents; it defaults to "Cookbook"
+	Title string `json:"title"`
+}
+
+// CookbookHandler handles POST /cookbooks/render. The recipes are read
+// through the controller like any other request, so a missing ID is a 404
+// listing every ID that was not found, and a tag with too many recipes is
+// refused like an oversized batch.
+func (handler *Handler) CookbookHandler(ctx *gin.Context) {
+	var req CookbookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	req.Tag = strings.TrimSpace(req.Tag)
+	if (len(req.IDs) == 0) == (req.Tag == "") {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of ids and tag is required"})
+		return
+	}
+
+	var recipes []model.Recipe
+	if req.Tag != "" {
+		var err error
+		if recipes, err = handler.ctrl.GetRecipeByTag(ctx.Request.Context(), req.Tag); err != nil {
+			writeCookbookError(ctx, err)
+			return
+		}
+		if limit := handler.ctrl.Limits().MaxBatchSize; limit > 0 && len(recipes) > limit {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "too many recipes for one cookbook", "count": len(recipes), "limit": limit})
+			return
+		}
+	} else {
+		results, err := handler.ctrl.GetRecipes(ctx.Request.Context(), req.IDs)
+		if err != nil {
+			writeCookbookError(ctx, err)
+			return
+		}
+		var missing []model.RecipeID
+		for i, result := range results {
+			switch {
+			case errors.Is(result.Err, domain.ErrNotFound):
+				missing = append(missing, req.IDs[i])
+			case result.Err != nil:
+				writeCookbookError(ctx, result.Err)
+				return
+			default:
+				recipes = append(recipes, result.Recipe)
+			}
+		}
+		if len(missing) > 0 {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": domain.ErrNotFound.Error(), "ids": missing})
+			return
+		}
+	}
+
+	var opts []cookbook.Option
+	if title := strings.TrimSpace(req.Title); title != "" {
+		opts = append(opts, cookbook.WithTitle(title))
+	}
+	var buf bytes.Buffer
+	if err := cookbook.Write(&buf, recipes, opts...); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	ctx.Header("Content-Disposition", `attachment; filename="cookbook.pdf"`)
+	ctx.Data(http.StatusOK, pdfType, buf.Bytes())
+}
+
+func writeCookbookError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput):
+		invalidInput(ctx, err)
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+	}
+}
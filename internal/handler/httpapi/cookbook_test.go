@@ -0,0 +1,99 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+func setupCookbookRouter(repo *mockRepo) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := New(recipe.New(repo))
+	router.POST("/cookbooks/render", handler.CookbookHandler)
+	return router
+}
+
+func postCookbook(router *gin.Engine, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/cookbooks/render", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCookbookHandler(t *testing.T) {
+	repo := &mockRepo{
+		getByIDFunc: func(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+			if id == "missing" || id == "gone" {
+				return model.Recipe{}, domain.ErrNotFound
+			}
+			return model.Recipe{ID: id, Name: "Recipe " + string(id)}, nil
+		},
+		getByTagFunc: func(ctx context.Context, tag string) ([]model.Recipe, error) {
+			if tag != "soup" {
+				return nil, domain.ErrNotFound
+			}
+			return []model.Recipe{{ID: "s1", Name: "Leek Soup"}, {ID: "s2", Name: "Pea Soup"}}, nil
+		},
+	}
+	router := setupCookbookRouter(repo)
+
+	w := postCookbook(router, `{"ids":["a","b"],"title":"Weeknights"}`)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/pdf" {
+		t.Fatalf("Expected a PDF, got %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	if !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")) || bytes.Count(w.Body.Bytes(), []byte("/Type /Page ")) != 3 {
+		t.Errorf("Expected contents and two recipe pages")
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="cookbook.pdf"` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+
+	w = postCookbook(router, `{"tag":"soup"}`)
+	if w.Code != http.StatusOK || bytes.Count(w.Body.Bytes(), []byte("/Subtype /Link")) != 2 {
+		t.Errorf("Expected a cookbook of the tagged recipes, got %d", w.Code)
+	}
+
+	// Every missing ID is reported
+	w = postCookbook(router, `{"ids":["a","missing","gone"]}`)
+	var resp struct{ IDs []string }
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusNotFound || strings.Join(resp.IDs, ",") != "missing,gone" {
+		t.Errorf("Expected 404 listing the missing IDs, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = postCookbook(router, `{"tag":"cake"}`)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown tag, got %d", w.Code)
+	}
+
+	for _, body := range []string{`{}`, `{"ids":["a"],"tag":"soup"}`, `not json`} {
+		if w := postCookbook(router, body); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestGetRecipeByIDHandlerPDFDownload(t *testing.T) {
+	repo := &mockRepo{getByIDFunc: func(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+		return renderRecipeFixture(), nil
+	}}
+	router := setupTestRouter(repo)
+
+	w := getWithAccept(router, "/recipes/r1.pdf", "")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/pdf" || !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")) {
+		t.Fatalf("Expected a PDF, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="r1.pdf"` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+}
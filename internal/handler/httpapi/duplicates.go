@@ -0,0 +1,50 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// PossibleDuplicatesHeader lists the IDs of the stored recipes a created one looks like.
+const PossibleDuplicatesHeader = "X-Possible-Duplicates"
+
+// duplicateMode reads the duplicates query parameter of a create: warn, the
+// default, or reject. It writes a 400 and returns false for anything else.
+func duplicateMode(ctx *gin.Context) (recipe.DuplicateMode, bool) {
+	switch ctx.Query("duplicates") {
+	case "", "warn":
+		return recipe.DuplicatesWarn, true
+	case "reject":
+		return recipe.DuplicatesReject, true
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "duplicates must be warn or reject"})
+		return 0, false
+	}
+}
+
+// conflict writes a 409, listing the suspected duplicates of a rejected recipe.
+func conflict(ctx *gin.Context, err error) {
+	body := gin.H{"error": err.Error()}
+	var derr *domain.DuplicateError
+	if errors.As(err, &derr) {
+		body["error"] = domain.ErrConflict.Error()
+		body["duplicates"] = derr.Duplicates
+	}
+	ctx.JSON(http.StatusConflict, body)
+}
+
+func setPossibleDuplicates(ctx *gin.Context, dups []domain.Duplicate) {
+	if len(dups) == 0 {
+		return
+	}
+	ids := make([]string, len(dups))
+	for i, d := range dups {
+		ids[i] = string(d.ID)
+	}
+	ctx.Header(PossibleDuplicatesHeader, strings.Join(ids, ", "))
+}
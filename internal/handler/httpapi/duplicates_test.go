@@ -0,0 +1,68 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/similar"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+func TestCreateHandlersCheckDuplicates(t *testing.T) {
+	repo := &mockRepo{
+		listFunc: func(ctx context.Context) ([]model.Recipe, error) {
+			return []model.Recipe{{ID: "1", Name: "Pancakes", Ingredients: []string{"2 eggs", "1 cup flour", "1 cup milk"}}}, nil
+		},
+	}
+	idx := similar.NewIndex()
+	if err := idx.Rebuild(context.Background(), repo); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	handler := New(recipe.New(repo, recipe.WithSimilarIndex(idx)))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/recipes", handler.CreateRecipeHandler)
+	router.POST("/recipes:batchCreate", handler.BatchCreateHandler)
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	pancakes := `{"name": "Pancake", "ingredients": ["eggs", "flour", "milk"]}`
+
+	w := post("/recipes?duplicates=reject", pancakes)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d: %s", w.Code, w.Body.String())
+	}
+	var conflict struct {
+		Duplicates []domain.Duplicate `json:"duplicates"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &conflict)
+	if len(conflict.Duplicates) != 1 || conflict.Duplicates[0].ID != "1" || conflict.Duplicates[0].Name != "Pancakes" {
+		t.Errorf("Expected recipe 1 as the duplicate, got %s", w.Body.String())
+	}
+
+	if w := post("/recipes?duplicates=maybe", pancakes); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown mode, got %d", w.Code)
+	}
+
+	w = post("/recipes:batchCreate?duplicates=reject", `{"recipes": [`+pancakes+`, {"name": "Waffles"}]}`)
+	var resp BatchResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Failed != 1 || resp.Results[0].Status != http.StatusConflict || len(resp.Results[0].Duplicates) != 1 || resp.Results[1].Status != http.StatusCreated {
+		t.Errorf("Expected only the pancakes to be rejected, got %s", w.Body.String())
+	}
+
+	w = post("/recipes", pancakes)
+	if w.Code != http.StatusCreated || w.Header().Get(PossibleDuplicatesHeader) != "1" {
+		t.Errorf("Expected 201 warning of recipe 1, got %d %q", w.Code, w.Header().Get(PossibleDuplicatesHeader))
+	}
+}
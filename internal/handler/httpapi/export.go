@@ -0,0 +1,108 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/recipeio"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+// exportFlushEvery is how many recipes are written between flushes to the client.
+const exportFlushEvery = 100
+
+// ExportRequest represents the query parameters of GET /recipes/export.
+type ExportRequest struct {
+	// Format is json, ndjson or csv; ndjson when empty
+	Format string `form:"format"`
+	FilterRequest
+}
+
+// ExportHandler handles GET requests streaming every matching recipe as a
+// file download. Recipes are written as they are read from the repository, so
+// memory use does not grow with the number of recipes, and the export stops
+// as soon as the client goes away.
+func (handler *Handler) ExportHandler(ctx *gin.Context) {
+	var req ExportRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid query"})
+		return
+	}
+	filter, err := req.filter()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Format == "" {
+		req.Format = string(recipeio.FormatNDJSON)
+	}
+	format, err := recipeio.ParseFormat(req.Format)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	enc, err := recipeio.NewEncoder(ctx.Writer, format)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+
+	// Headers are sent with the first recipe so a failure before then can
+	// still be reported with a proper status.
+	started := false
+	start := func() {
+		if started {
+			return
+		}
+		started = true
+		filename := fmt.Sprintf("recipes-%s.%s", time.Now().UTC().Format("20060102"), format)
+		ctx.Header("Content-Type", format.ContentType())
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		ctx.Status(http.StatusOK)
+	}
+
+	reqCtx := ctx.Request.Context()
+	err = handler.ctrl.ExportRecipes(reqCtx, filter, func(recipe model.Recipe) error {
+		start()
+		if err := enc.Encode(recipe); err != nil {
+			return err
+		}
+		if enc.Count()%exportFlushEvery == 0 {
+			if err := enc.Flush(); err != nil {
+				return err
+			}
+			ctx.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		start()
+		err = enc.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	if reqCtx.Err() != nil {
+		// The client went away; there is nobody left to tell.
+		return
+	}
+	if !started && errors.Is(err, domain.ErrInvalidInput) {
+		invalidInput(ctx, err)
+		return
+	}
+	if !started {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	// The status is already on the wire, so the body is left truncated (a
+	// JSON export lacks its closing bracket) and the failure is logged.
+	_ = ctx.Error(err)
+	slog.ErrorContext(reqCtx, "recipe export aborted", "format", format, "written", enc.Count(), "error", err)
+}
@@ -0,0 +1,133 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/recipeio"
+	"github.com/gin-demo/recipes-web/internal/repository/memory"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+func setupExportRouter(repo domain.RecipeRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := New(recipe.New(repo))
+
+	router.GET("/recipes/export", handler.ExportHandler)
+	return router
+}
+
+func TestExportHandler(t *testing.T) {
+	repo := &mockRepo{
+		listFunc: func(ctx context.Context) ([]model.Recipe, error) {
+			return []model.Recipe{{ID: "1", Name: "R1"}, {ID: "2", Name: "R2"}}, nil
+		},
+	}
+	router := setupExportRouter(repo)
+
+	req, _ := http.NewRequest("GET", "/recipes/export", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Expected ndjson by default, got %q", got)
+	}
+	if got := w.Header().Get("Content-Disposition"); !strings.HasPrefix(got, `attachment; filename="recipes-`) || !strings.HasSuffix(got, `.ndjson"`) {
+		t.Errorf("Unexpected Content-Disposition %q", got)
+	}
+	if lines := strings.Count(w.Body.String(), "\n"); lines != 2 {
+		t.Errorf("Expected 2 lines, got %d", lines)
+	}
+
+	// JSON is a complete array
+	req, _ = http.NewRequest("GET", "/recipes/export?format=json", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var recipes []model.Recipe
+	if err := json.Unmarshal(w.Body.Bytes(), &recipes); err != nil || len(recipes) != 2 {
+		t.Errorf("Expected a JSON array of 2 recipes, got %d, %v", len(recipes), err)
+	}
+
+	// Unknown format
+	req, _ = http.NewRequest("GET", "/recipes/export?format=xml", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+
+	// Storage error before anything was written
+	repo.listFunc = func(ctx context.Context) ([]model.Recipe, error) {
+		return nil, errors.New("error")
+	}
+	req, _ = http.NewRequest("GET", "/recipes/export", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Disposition") != "" {
+		t.Error("Expected no attachment on error")
+	}
+}
+
+func TestExportHandlerStreamsFilteredCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recipes.json")
+	data, _ := json.Marshal([]model.Recipe{
+		{ID: "1", Name: "R1", Tags: []string{"soup"}},
+		{ID: "2", Name: "R2", Tags: []string{"cake"}},
+		{ID: "3", Name: "R3", Tags: []string{"soup"}},
+	})
+	os.WriteFile(path, data, 0644)
+	repo, err := memory.New(path)
+	if err != nil {
+		t.Fatalf("memory.New: %v", err)
+	}
+	router := setupExportRouter(repo)
+
+	req, _ := http.NewRequest("GET", "/recipes/export?format=csv&tag=soup", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Errorf("Unexpected Content-Type %q", got)
+	}
+	recipes, err := recipeio.Read(w.Body, recipeio.FormatCSV)
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	if len(recipes) != 2 || recipes[0].ID != "1" || recipes[1].ID != "3" {
+		t.Errorf("Expected recipes 1 and 3, got %+v", recipes)
+	}
+
+	// A client that has gone away gets nothing written
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ = http.NewRequestWithContext(ctx, "GET", "/recipes/export", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Body.Len() != 0 {
+		t.Errorf("Expected an empty body after cancellation, got %q", w.Body.String())
+	}
+}
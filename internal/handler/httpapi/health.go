@@ -0,0 +1,36 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/gin-demo/recipes-web/internal/health"
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler handles liveness and readiness probes.
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+// NewHealthHandler creates a new HealthHandler with the given checker.
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{checker}
+}
+
+// LivenessHandler handles GET /healthz and only reports that the process is serving requests.
+func (handler *HealthHandler) LivenessHandler(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// ReadinessHandler handles GET /readyz with per-dependency status and latency.
+// Only a failing report returns 503; warnings still accept traffic.
+func (handler *HealthHandler) ReadinessHandler(ctx *gin.Context) {
+	report := handler.checker.Ready(ctx.Request.Context())
+
+	status := http.StatusOK
+	if report.Status == health.StatusFail {
+		status = http.StatusServiceUnavailable
+	}
+
+	ctx.JSON(status, report)
+}
@@ -0,0 +1,73 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/health"
+	"github.com/gin-gonic/gin"
+)
+
+func setupHealthRouter(checker *health.Checker) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewHealthHandler(checker)
+
+	router.GET("/healthz", handler.LivenessHandler)
+	router.GET("/readyz", handler.ReadinessHandler)
+
+	return router
+}
+
+func TestLivenessHandler(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Register("mongo", true, func(ctx context.Context) error { return errors.New("down") })
+	router := setupHealthRouter(checker)
+
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Liveness ignores dependencies
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestReadinessHandler(t *testing.T) {
+	redisErr := errors.New("connection refused")
+	checker := health.NewChecker(time.Second)
+	checker.Register("mongo", true, func(ctx context.Context) error { return nil })
+	checker.Register("redis", false, func(ctx context.Context) error { return redisErr })
+	router := setupHealthRouter(checker)
+
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Optional dependency down only warns
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	var report health.Report
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if report.Status != health.StatusWarn {
+		t.Errorf("Expected warn, got %s", report.Status)
+	}
+	if report.Checks["redis"].Status != health.StatusWarn {
+		t.Errorf("Expected redis warn, got %+v", report.Checks["redis"])
+	}
+
+	// Readiness fails once shutdown begins
+	checker.SetShuttingDown()
+	w2 := httptest.NewRecorder()
+	router.ServeHTTP(w2, req)
+	if w2.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w2.Code)
+	}
+}
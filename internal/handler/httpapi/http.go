@@ -2,7 +2,9 @@ package httpapi
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-demo/recipes-web/internal/controller/recipe"
 	"github.com/gin-demo/recipes-web/internal/domain"
@@ -12,50 +14,210 @@ import (
 
 // Handler handles HTTP requests for recipe operations.
 type Handler struct {
-	ctrl *recipe.Controller
+	ctrl      *recipe.Controller
+	renderers *Renderers
+}
+
+// Option configures optional Handler behaviour.
+type Option func(*Handler)
+
+// WithRenderers serves recipe reads in the formats of rs instead of those of NewRenderers.
+func WithRenderers(rs *Renderers) Option {
+	return func(handler *Handler) {
+		handler.renderers = rs
+	}
 }
 
 // New creates a new Handler with the given controller.
-func New(ctrl *recipe.Controller) *Handler {
-	return &Handler{ctrl}
+func New(ctrl *recipe.Controller, opts ...Option) *Handler {
+	handler := &Handler{ctrl: ctrl, renderers: NewRenderers()}
+	for _, opt := range opts {
+		opt(handler)
+	}
+	return handler
 }
 
-// CreateRecipeHandler handles POST requests to create a new recipe.
+// CreateRecipeHandler handles POST requests to create a new recipe. The IDs
+// of stored recipes it looks like are listed in the X-Possible-Duplicates
+// header, or with duplicates=reject the request fails with 409 listing them.
 func (handler *Handler) CreateRecipeHandler(ctx *gin.Context) {
+	dup, ok := duplicateMode(ctx)
+	if !ok {
+		return
+	}
 	var r model.Recipe
-	if err := ctx.ShouldBindJSON(&r); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
+	if err := handler.bindBody(ctx, &r, 1); err != nil {
+		ctx.JSON(bodyErrorStatus(err), gin.H{
 			"error": "invalid request body",
 		})
 		return
 	}
 
-	result, err := handler.ctrl.CreateRecipe(ctx.Request.Context(), r)
+	result, dups, err := handler.ctrl.CreateRecipe(ctx.Request.Context(), r, dup)
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrInvalidInput):
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			invalidInput(ctx, err)
+		case errors.Is(err, domain.ErrConflict):
+			conflict(ctx, err)
 		default:
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
 		return
 	}
 
+	setPossibleDuplicates(ctx, dups)
 	ctx.JSON(http.StatusCreated, result)
 }
 
-// ListRecipeHandler handles GET requests to list all recipes.
+// FilterRequest represents the query parameters narrowing a list, search,
+// export or facet count. Each tag parameter may be repeated.
+type FilterRequest struct {
+	// Tag requires every tag given
+	Tag []string `form:"tag"`
+	// AnyTag requires at least one of the tags given
+	AnyTag []string `form:"anyTag"`
+	// NotTag excludes recipes carrying any of the tags given
+	NotTag     []string `form:"notTag"`
+	Cuisine    string   `form:"cuisine"`
+	Course     string   `form:"course"`
+	Difficulty string   `form:"difficulty"`
+	// Diet may be repeated to require several flags
+	Diet []string `form:"diet"`
+	// MaxTotalTime is a duration such as 30m or PT30M
+	MaxTotalTime string `form:"maxTotalTime"`
+}
+
+// filter returns the domain filter for the request's parameters.
+func (req FilterRequest) filter() (domain.RecipeFilter, error) {
+	f := domain.RecipeFilter{
+		Tags:        req.Tag,
+		AnyTags:     req.AnyTag,
+		ExcludeTags: req.NotTag,
+		Cuisine:     req.Cuisine,
+		Course:      req.Course,
+		Difficulty:  req.Difficulty,
+		Diets:       req.Diet,
+	}
+	if req.MaxTotalTime != "" {
+		d, err := time.ParseDuration(req.MaxTotalTime)
+		if err != nil {
+			if d, err = model.ParseDuration(req.MaxTotalTime); err != nil {
+				return f, fmt.Errorf("maxTotalTime must be a duration such as 30m or PT30M, got %q", req.MaxTotalTime)
+			}
+		}
+		f.MaxTotalTime = d
+	}
+	return f, nil
+}
+
+// ListRecipesRequest represents the query parameters of a list or search.
+type ListRecipesRequest struct {
+	FilterRequest
+	// Facets wraps the recipes in an object alongside their facet counts
+	Facets bool `form:"facets"`
+}
+
+// ListRecipeHandler handles GET requests to list all recipes, or those
+// passing the filters in the query.
 func (handler *Handler) ListRecipeHandler(ctx *gin.Context) {
-	recipes, err := handler.ctrl.ListRecipes(ctx)
+	var req ListRecipesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid query"})
+		return
+	}
+	filter, err := req.filter()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	renderer, ok := handler.negotiateList(ctx, req.Facets)
+	if !ok {
+		return
+	}
+
+	var recipes []model.Recipe
+	if filter.IsZero() {
+		recipes, err = handler.ctrl.ListRecipes(ctx.Request.Context())
+	} else {
+		recipes, err = handler.ctrl.SearchRecipes(ctx.Request.Context(), filter)
+	}
 	if err != nil {
 		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			invalidInput(ctx, err)
 		default:
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
 		}
 		return
 	}
 
-	ctx.JSON(http.StatusOK, recipes)
+	handler.respondList(ctx, renderer, recipes, filter, req.Facets)
+}
+
+// ListRecipesResponse is the body of a list or search asking for facets.
+type ListRecipesResponse struct {
+	Recipes []model.Recipe `json:"recipes"`
+	Facets  domain.Facets  `json:"facets"`
+}
+
+// negotiateList negotiates the format of a list. Facets come only as JSON,
+// since the other formats have nowhere to put them, and PDF is refused before
+// the recipes are loaded; cookbooks come from POST /cookbooks/render.
+func (handler *Handler) negotiateList(ctx *gin.Context, facets bool) (Renderer, bool) {
+	renderer, ok := handler.negotiate(ctx)
+	switch {
+	case !ok || renderer == nil:
+	case renderer.ContentType() == pdfType:
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "lists are not available as application/pdf; use POST /cookbooks/render"})
+		return nil, false
+	case facets:
+		ctx.JSON(http.StatusNotAcceptable, gin.H{"error": "facets are only available as application/json"})
+		return nil, false
+	}
+	return renderer, ok
+}
+
+// respondList renders recipes, wrapped with the facets of filter when asked for.
+func (handler *Handler) respondList(ctx *gin.Context, renderer Renderer, recipes []model.Recipe, filter domain.RecipeFilter, facets bool) {
+	if !facets {
+		renderRecipes(ctx, renderer, recipes)
+		return
+	}
+	counts, err := handler.ctrl.Facets(ctx.Request.Context(), filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	ctx.JSON(http.StatusOK, ListRecipesResponse{Recipes: recipes, Facets: counts})
+}
+
+// FacetsHandler handles GET requests counting the recipes passing the
+// filters in the query by tag, cuisine, difficulty and total time.
+func (handler *Handler) FacetsHandler(ctx *gin.Context) {
+	var req FilterRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid query"})
+		return
+	}
+	filter, err := req.filter()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	facets, err := handler.ctrl.Facets(ctx.Request.Context(), filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			invalidInput(ctx, err)
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, facets)
 }
 
 // UpdateRecipeIDRequest represents the URI parameters for updating a recipe.
@@ -72,6 +234,34 @@ type UpdateRecipeRequest struct {
 	Tags []string `json:"tags"`
 	// Ingredients is the optional new list of ingredients for the recipe
 	Ingredients []string `json:"ingredients"`
+	// The metadata fields are optional; an empty value clears the field
+	Yield      *string  `json:"yield"`
+	PrepTime   *string  `json:"prepTime"`
+	CookTime   *string  `json:"cookTime"`
+	TotalTime  *string  `json:"totalTime"`
+	Difficulty *string  `json:"difficulty"`
+	Cuisine    *string  `json:"cuisine"`
+	Course     *string  `json:"course"`
+	Equipment  []string `json:"equipment"`
+	Diets      []string `json:"diets"`
+}
+
+// command returns the controller command for the request.
+func (req UpdateRecipeRequest) command() recipe.UpdateRecipeCommand {
+	return recipe.UpdateRecipeCommand{
+		Name:        req.Name,
+		Tags:        req.Tags,
+		Ingredients: req.Ingredients,
+		Yield:       req.Yield,
+		PrepTime:    req.PrepTime,
+		CookTime:    req.CookTime,
+		TotalTime:   req.TotalTime,
+		Difficulty:  req.Difficulty,
+		Cuisine:     req.Cuisine,
+		Course:      req.Course,
+		Equipment:   req.Equipment,
+		Diets:       req.Diets,
+	}
 }
 
 // UpdateRecipeHandler handles PUT requests to update an existing recipe.
@@ -86,24 +276,20 @@ func (handler *Handler) UpdateRecipeHandler(ctx *gin.Context) {
 	}
 
 	var body UpdateRecipeRequest
-	if err := ctx.ShouldBindJSON(&body); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
+	if err := handler.bindBody(ctx, &body, 1); err != nil {
+		ctx.JSON(bodyErrorStatus(err), gin.H{
 			"error": "need reciept ID",
 		})
 		return
 	}
 
-	cmd := recipe.UpdateRecipeCommand{
-		Name:        body.Name,
-		Tags:        body.Tags,
-		Ingredients: body.Ingredients,
-	}
-
-	updatedRecipe, err := handler.ctrl.UpdateRecipe(ctx.Request.Context(), req.ID, cmd)
+	updatedRecipe, err := handler.ctrl.UpdateRecipe(ctx.Request.Context(), req.ID, body.command())
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrNotFound):
 			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidInput):
+			invalidInput(ctx, err)
 		default:
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
 		}
@@ -113,27 +299,61 @@ func (handler *Handler) UpdateRecipeHandler(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, updatedRecipe)
 }
 
-// SearchRecipeRequest represents the query parameters for searching recipes by tag.
-type SearchRecipeRequest struct {
-	// Tag is the tag to search recipes by
-	Tag string `form:"tag" binding:"required"`
+// bindBody decodes a JSON body of up to items recipes, each within the
+// controller's payload limit. items of zero means no limit.
+func (handler *Handler) bindBody(ctx *gin.Context, obj any, items int) error {
+	if limit := handler.ctrl.Limits().MaxPayloadBytes; limit > 0 && items > 0 {
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, int64(limit)*int64(items))
+	}
+	return ctx.ShouldBindJSON(obj)
+}
+
+// bodyErrorStatus is 413 for bodies over the size limit and 400 otherwise.
+func bodyErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// invalidInput responds 400, listing each field error so clients can point at
+// the offending fields.
+func invalidInput(ctx *gin.Context, err error) {
+	body := gin.H{"error": err.Error()}
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		body["error"] = domain.ErrInvalidInput.Error()
+		body["fields"] = verr.Fields
+	}
+	ctx.JSON(http.StatusBadRequest, body)
 }
 
-// ListRecipesByTagHandler handles GET requests to list recipes by tag.
+// ListRecipesByTagHandler handles GET requests to list recipes by tag. At
+// least one tag or anyTag is required.
 func (handler *Handler) ListRecipesByTagHandler(ctx *gin.Context) {
-	var req SearchRecipeRequest
-	if err := ctx.ShouldBindQuery(&req); err != nil {
+	var req ListRecipesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil || (len(req.Tag) == 0 && len(req.AnyTag) == 0) {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error": "tag is required",
 		})
 		return
 	}
+	filter, err := req.filter()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	renderer, ok := handler.negotiateList(ctx, req.Facets)
+	if !ok {
+		return
+	}
 
-	recipes, err := handler.ctrl.GetRecipeByTag(ctx.Request.Context(), req.Tag)
+	recipes, err := handler.ctrl.SearchRecipes(ctx.Request.Context(), filter)
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrInvalidInput):
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			invalidInput(ctx, err)
 		case errors.Is(err, domain.ErrNotFound):
 			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		default:
@@ -142,7 +362,7 @@ func (handler *Handler) ListRecipesByTagHandler(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, recipes)
+	handler.respondList(ctx, renderer, recipes, filter, req.Facets)
 }
 
 // SearchByIDRequest represents the URI parameters for searching a recipe by ID.
@@ -151,7 +371,9 @@ type SearchByIDRequest struct {
 	ID model.RecipeID `uri:"id" binding:"required"`
 }
 
-// GetRecipeByIDHandler handles GET requests to retrieve a recipe by ID.
+// GetRecipeByIDHandler handles GET requests to retrieve a recipe by ID. An ID
+// ending in a registered extension, such as /recipes/{id}.cook, downloads the
+// recipe in that format as an attachment.
 func (handler *Handler) GetRecipeByIDHandler(ctx *gin.Context) {
 	var req SearchByIDRequest
 
@@ -161,8 +383,15 @@ func (handler *Handler) GetRecipeByIDHandler(ctx *gin.Context) {
 		})
 		return
 	}
+	id, ext, renderer, download := handler.renderers.Extension(req.ID)
+	if !download {
+		var ok bool
+		if renderer, ok = handler.negotiate(ctx); !ok {
+			return
+		}
+	}
 
-	result, err := handler.ctrl.GetRecipeByID(ctx.Request.Context(), req.ID)
+	result, err := handler.ctrl.GetRecipeByID(ctx.Request.Context(), id)
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrNotFound):
@@ -174,8 +403,12 @@ func (handler *Handler) GetRecipeByIDHandler(ctx *gin.Context) {
 		}
 		return
 	}
+	handler.ctrl.RecordView(ctx.Request.Context(), result.ID)
 
-	ctx.JSON(http.StatusOK, result)
+	if download {
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s%s"`, result.ID, ext))
+	}
+	renderRecipe(ctx, renderer, result)
 }
 
 // DeleteByIDRequest represents the URI parameters for deleting a recipe by ID.
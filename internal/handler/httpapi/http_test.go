@@ -7,6 +7,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 
 	"github.com/gin-demo/recipes-web/internal/controller/recipe"
@@ -77,6 +78,7 @@ func setupTestRouter(repo *mockRepo) *gin.Engine {
 
 	router.GET("/recipes", handler.ListRecipeHandler)
 	router.GET("/recipes/search", handler.ListRecipesByTagHandler)
+	router.GET("/recipes/facets", handler.FacetsHandler)
 	router.GET("/recipes/:id", handler.GetRecipeByIDHandler)
 	router.POST("/recipes", handler.CreateRecipeHandler)
 	router.DELETE("/recipes/:id", handler.DeleteRecipeHandler)
@@ -150,6 +152,115 @@ func TestListRecipeHandler(t *testing.T) {
 	}
 }
 
+func TestListRecipeHandlerFilters(t *testing.T) {
+	repo := &mockRepo{
+		listFunc: func(ctx context.Context) ([]model.Recipe, error) {
+			return []model.Recipe{
+				{ID: "1", Tags: []string{"quick"}, Cuisine: "indian", TotalTime: "PT25M"},
+				{ID: "2", Tags: []string{"quick", "spicy"}, Cuisine: "indian", TotalTime: "PT1H"},
+				{ID: "3", Tags: []string{"vegan"}, Cuisine: "thai", TotalTime: "PT20M", Diets: []string{"vegan"}},
+			}, nil
+		},
+		getByTagFunc: func(ctx context.Context, tag string) ([]model.Recipe, error) {
+			return []model.Recipe{{ID: "4", Tags: []string{tag}, Cuisine: "indian", PrepTime: "PT10M"}}, nil
+		},
+	}
+	router := setupTestRouter(repo)
+
+	tests := []struct {
+		query string
+		want  int
+		ids   []model.RecipeID
+	}{
+		{"/recipes?maxTotalTime=30m&cuisine=indian", http.StatusOK, []model.RecipeID{"1"}},
+		{"/recipes?maxTotalTime=PT30M", http.StatusOK, []model.RecipeID{"1", "3"}},
+		{"/recipes?diet=vegan", http.StatusOK, []model.RecipeID{"3"}},
+		{"/recipes/search?tag=curry&cuisine=indian&maxTotalTime=15m", http.StatusOK, []model.RecipeID{"4"}},
+		{"/recipes?maxTotalTime=half-an-hour", http.StatusBadRequest, nil},
+		{"/recipes?difficulty=trivial", http.StatusBadRequest, nil},
+		{"/recipes/search?tag=curry&diet=paleo", http.StatusBadRequest, nil},
+		{"/recipes?anyTag=quick&anyTag=vegan&notTag=spicy", http.StatusOK, []model.RecipeID{"1", "3"}},
+		{"/recipes/search?anyTag=spicy", http.StatusOK, []model.RecipeID{"2"}},
+		{"/recipes/search?tag=curry&tag=quick", http.StatusOK, nil},
+		{"/recipes/search?notTag=spicy", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", tt.query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.query, tt.want, w.Code, w.Body.String())
+			continue
+		}
+		if tt.want != http.StatusOK {
+			continue
+		}
+		var recipes []model.Recipe
+		json.Unmarshal(w.Body.Bytes(), &recipes)
+		var ids []model.RecipeID
+		for _, r := range recipes {
+			ids = append(ids, r.ID)
+		}
+		if !slices.Equal(ids, tt.ids) {
+			t.Errorf("%s: expected %v, got %v", tt.query, tt.ids, ids)
+		}
+	}
+}
+
+func TestFacetsHandler(t *testing.T) {
+	repo := &mockRepo{
+		listFunc: func(ctx context.Context) ([]model.Recipe, error) {
+			return []model.Recipe{
+				{ID: "1", Tags: []string{"quick"}, Cuisine: "indian", Difficulty: "easy", TotalTime: "PT25M"},
+				{ID: "2", Tags: []string{"quick", "spicy"}, Cuisine: "indian", TotalTime: "PT3H"},
+				{ID: "3", Tags: []string{"vegan"}, Cuisine: "thai"},
+			}, nil
+		},
+	}
+	router := setupTestRouter(repo)
+
+	req, _ := http.NewRequest("GET", "/recipes/facets?cuisine=indian", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var facets domain.Facets
+	json.Unmarshal(w.Body.Bytes(), &facets)
+	if facets.Total != 2 || len(facets.Tags) != 2 || facets.Tags[0] != (domain.FacetCount{Value: "quick", Count: 2}) {
+		t.Errorf("Unexpected facets %+v", facets)
+	}
+	if facets.TotalTimes[1].Count != 1 || facets.TotalTimes[4].Count != 1 {
+		t.Errorf("Unexpected total times %+v", facets.TotalTimes)
+	}
+
+	// Facets alongside a list
+	req, _ = http.NewRequest("GET", "/recipes?notTag=spicy&facets=true", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var list ListRecipesResponse
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list.Recipes) != 2 || list.Facets.Total != 2 {
+		t.Errorf("Expected two recipes with their facets, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Other formats have no room for facets
+	req, _ = http.NewRequest("GET", "/recipes/search?tag=quick&facets=true", nil)
+	req.Header.Set("Accept", "text/markdown")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("Expected status 406, got %d", w.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/recipes/facets?difficulty=trivial", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
 func TestUpdateRecipeHandler(t *testing.T) {
 	repo := &mockRepo{}
 	router := setupTestRouter(repo)
@@ -165,6 +276,17 @@ func TestUpdateRecipeHandler(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
 
+	// Metadata
+	meta := []byte(`{"difficulty":"Easy","cookTime":"pt45m","equipment":["Dutch oven"],"diets":["vegetarian"]}`)
+	req, _ = http.NewRequest("PUT", "/recipes/1", bytes.NewBuffer(meta))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var updated model.Recipe
+	json.Unmarshal(w.Body.Bytes(), &updated)
+	if w.Code != http.StatusOK || updated.Difficulty != "easy" || updated.CookTime != "PT45M" || len(updated.Equipment) != 1 || len(updated.Diets) != 1 {
+		t.Errorf("Expected the metadata updated, got %d: %s", w.Code, w.Body.String())
+	}
+
 	// Invalid JSON body
 	req2, _ := http.NewRequest("PUT", "/recipes/1", bytes.NewBuffer([]byte("invalid")))
 	w2 := httptest.NewRecorder()
@@ -240,6 +362,40 @@ func TestListRecipesByTagHandler(t *testing.T) {
 	}
 }
 
+// viewedRepo counts the views recorded through it.
+type viewedRepo struct {
+	*mockRepo
+	views []model.RecipeID
+}
+
+func (v *viewedRepo) RecordView(ctx context.Context, id model.RecipeID) error {
+	v.views = append(v.views, id)
+	return nil
+}
+
+func TestGetRecipeByIDHandlerRecordsView(t *testing.T) {
+	repo := &viewedRepo{mockRepo: &mockRepo{getByIDFunc: func(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+		if id == "1" {
+			return model.Recipe{ID: "1", Name: "Test"}, nil
+		}
+		return model.Recipe{}, domain.ErrNotFound
+	}}}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	ctrl := recipe.New(repo)
+	router.GET("/recipes/:id", New(ctrl).GetRecipeByIDHandler)
+
+	getWithAccept(router, "/recipes/1", "")
+	getWithAccept(router, "/recipes/1.pdf", "")
+	getWithAccept(router, "/recipes/missing", "")
+	if _, err := ctrl.GetRecipeByID(context.Background(), "1"); err != nil {
+		t.Fatalf("GetRecipeByID failed: %v", err)
+	}
+	if len(repo.views) != 2 || repo.views[0] != "1" {
+		t.Errorf("Expected a view per client read, got %v", repo.views)
+	}
+}
+
 func TestGetRecipeByIDHandler(t *testing.T) {
 	repo := &mockRepo{
 		getByIDFunc: func(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
@@ -339,9 +495,9 @@ func TestEdgeCases(t *testing.T) {
 	req, _ := http.NewRequest("POST", "/recipes", bytes.NewBuffer(body))
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
-	// Should still create even with empty name
-	if w.Code != http.StatusCreated {
-		t.Errorf("Expected status 201 for empty name, got %d", w.Code)
+	// Empty names are rejected by validation
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for empty name, got %d", w.Code)
 	}
 
 	// Large lists
@@ -374,3 +530,58 @@ func TestEdgeCases(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestCreateRecipeHandlerFieldErrors(t *testing.T) {
+	router := setupTestRouter(&mockRepo{})
+
+	body := []byte(`{"id":"mine","name":"","tags":["bad,tag"]}`)
+	req, _ := http.NewRequest("POST", "/recipes", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+	var resp struct {
+		Error  string              `json:"error"`
+		Fields []domain.FieldError `json:"fields"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Error != domain.ErrInvalidInput.Error() || len(resp.Fields) != 3 {
+		t.Errorf("Expected 3 field errors, got %+v", resp)
+	}
+}
+
+func TestCreateRecipeHandlerBodyTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := New(recipe.New(&mockRepo{}, recipe.WithLimits(recipe.Limits{MaxPayloadBytes: 64})))
+	router.POST("/recipes", handler.CreateRecipeHandler)
+
+	body, _ := json.Marshal(model.Recipe{Name: "Soup", Instructions: []string{string(bytes.Repeat([]byte("x"), 100))}})
+	req, _ := http.NewRequest("POST", "/recipes", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", w.Code)
+	}
+}
+
+func TestUpdateRecipeHandlerFieldErrors(t *testing.T) {
+	repo := &mockRepo{}
+	repo.getByIDFunc = func(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+		return model.Recipe{ID: id, Name: "Soup"}, nil
+	}
+	router := setupTestRouter(repo)
+
+	req, _ := http.NewRequest("PUT", "/recipes/1", bytes.NewBuffer([]byte(`{"name":""}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
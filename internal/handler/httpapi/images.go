@@ -0,0 +1,120 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/internal/blob"
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/imaging"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead allows for the form around an uploaded image.
+const multipartOverhead = 64 << 10
+
+// acceptedImageTypes are listed when an upload is refused for its type.
+var acceptedImageTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// AddImageHandler handles POST /recipes/:id/images. The image is the "file"
+// field of a multipart form, and its type is judged from its content rather
+// than its name or declared type. The response is the stored image with the
+// URLs of its variants.
+func (handler *Handler) AddImageHandler(ctx *gin.Context) {
+	limit := handler.ctrl.Limits().MaxImageBytes
+	if limit > 0 {
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, int64(limit)+multipartOverhead)
+	}
+	upload, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(bodyErrorStatus(err), gin.H{"error": "file is required"})
+		return
+	}
+	if limit > 0 && upload.Size > int64(limit) {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("image is larger than %d bytes", limit)})
+		return
+	}
+	file, err := upload.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid upload"})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid upload"})
+		return
+	}
+
+	image, err := handler.ctrl.AddRecipeImage(ctx.Request.Context(), model.RecipeID(ctx.Param("id")), data)
+	if err != nil {
+		switch {
+		case errors.Is(err, imaging.ErrUnsupportedType):
+			ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error(), "accepted": acceptedImageTypes})
+		case errors.Is(err, imaging.ErrTooLarge):
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		default:
+			writeImageError(ctx, err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, image)
+}
+
+// DeleteImageHandler handles DELETE /recipes/:id/images/:imageID.
+func (handler *Handler) DeleteImageHandler(ctx *gin.Context) {
+	if err := handler.ctrl.DeleteRecipeImage(ctx.Request.Context(), model.RecipeID(ctx.Param("id")), ctx.Param("imageID")); err != nil {
+		writeImageError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func writeImageError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound), errors.Is(err, domain.ErrImageNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput):
+		invalidInput(ctx, err)
+	case errors.Is(err, recipe.ErrImagesDisabled):
+		ctx.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+	}
+}
+
+// ImageHandler serves stored image variants.
+type ImageHandler struct {
+	store blob.Store
+}
+
+// NewImageHandler creates a new ImageHandler reading from store.
+func NewImageHandler(store blob.Store) *ImageHandler {
+	return &ImageHandler{store}
+}
+
+// ServeHandler handles GET /images/*key. Every upload gets new keys, so
+// responses may be cached indefinitely.
+func (handler *ImageHandler) ServeHandler(ctx *gin.Context) {
+	obj, err := handler.store.Get(ctx.Request.Context(), strings.TrimPrefix(ctx.Param("key"), "/"))
+	if err != nil {
+		switch {
+		case errors.Is(err, blob.ErrNotFound), errors.Is(err, blob.ErrInvalidKey):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "image not found"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+	defer obj.Close()
+
+	ctx.DataFromReader(http.StatusOK, obj.Size, obj.ContentType, obj, map[string]string{
+		"Cache-Control":          "public, max-age=31536000, immutable",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
@@ -0,0 +1,153 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/blob"
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+// setupImageRouter serves recipe r1 from a mock repository, with images
+// stored in a temporary directory.
+func setupImageRouter(t *testing.T, limits recipe.Limits) (*gin.Engine, *model.Recipe) {
+	t.Helper()
+	stored := &model.Recipe{ID: "r1", Name: "Soup"}
+	repo := &mockRepo{
+		getByIDFunc: func(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+			if id != stored.ID {
+				return model.Recipe{}, domain.ErrNotFound
+			}
+			return *stored, nil
+		},
+		updateFunc: func(ctx context.Context, r model.Recipe) (model.Recipe, error) {
+			*stored = r
+			return r, nil
+		},
+	}
+	store, err := blob.NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := New(recipe.New(repo, recipe.WithImages(store, "/images"), recipe.WithLimits(limits)))
+	router.POST("/recipes/:id/images", handler.AddImageHandler)
+	router.DELETE("/recipes/:id/images/:imageID", handler.DeleteImageHandler)
+	router.GET("/images/*key", NewImageHandler(store).ServeHandler)
+	return router, stored
+}
+
+func uploadImage(router *gin.Engine, path string, data []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	// The declared name and type are ignored in favour of the content.
+	part, _ := form.CreateFormFile("file", "photo.png")
+	part.Write(data)
+	form.Close()
+
+	req, _ := http.NewRequest("POST", path, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageHandlers(t *testing.T) {
+	router, stored := setupImageRouter(t, recipe.DefaultLimits())
+
+	w := uploadImage(router, "/recipes/r1/images", testJPEG(t, 1200, 900))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var img model.Image
+	json.Unmarshal(w.Body.Bytes(), &img)
+	if len(img.Variants) != 3 || img.Variants[1].Name != "medium" || img.Variants[1].Width != 800 || img.Variants[1].Height != 600 {
+		t.Fatalf("Unexpected image %+v", img)
+	}
+	if len(stored.Images) != 1 {
+		t.Errorf("Expected the image on the recipe, got %+v", stored.Images)
+	}
+
+	req, _ := http.NewRequest("GET", img.Variants[0].URL, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/jpeg" || !bytes.HasPrefix(w.Body.Bytes(), []byte{0xFF, 0xD8}) {
+		t.Fatalf("Expected the thumbnail, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if w.Header().Get("Cache-Control") == "" {
+		t.Error("Expected a Cache-Control header")
+	}
+
+	req, _ = http.NewRequest("DELETE", "/recipes/r1/images/"+img.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	req, _ = http.NewRequest("GET", img.Variants[0].URL, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected the deleted thumbnail to be gone, got %d", w.Code)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/recipes/r1/images/"+img.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown image, got %d", w.Code)
+	}
+}
+
+func TestAddImageHandlerRejections(t *testing.T) {
+	limits := recipe.DefaultLimits()
+	limits.MaxImageBytes = 4 << 10
+	limits.MaxImagePixels = 100 * 100
+	router, _ := setupImageRouter(t, limits)
+
+	tests := []struct {
+		name string
+		path string
+		data []byte
+		want int
+	}{
+		{"not an image", "/recipes/r1/images", []byte("<svg onload=alert(1)></svg>"), http.StatusUnsupportedMediaType},
+		{"too many bytes", "/recipes/r1/images", bytes.Repeat([]byte{0}, 8<<10), http.StatusRequestEntityTooLarge},
+		{"too many pixels", "/recipes/r1/images", testJPEG(t, 200, 100), http.StatusRequestEntityTooLarge},
+		{"unknown recipe", "/recipes/r2/images", testJPEG(t, 10, 10), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := uploadImage(router, tt.path, tt.data); w.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.want, w.Code, w.Body.String())
+		}
+	}
+
+	req, _ := http.NewRequest("POST", "/recipes/r1/images", bytes.NewReader(testJPEG(t, 10, 10)))
+	req.Header.Set("Content-Type", "image/jpeg")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a multipart form, got %d", w.Code)
+	}
+}
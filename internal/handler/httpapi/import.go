@@ -0,0 +1,92 @@
+package httpapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/internal/cooklang"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/schemaorg"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportBytes bounds an uploaded page; recipe blogs are large but not this large.
+const maxImportBytes = 5 << 20
+
+// ImportHandler handles POST /recipes:import. The body is an HTML page or a
+// JSON-LD document, sent as is or as the "file" field of a multipart form.
+// Every schema.org Recipe found is created, each on its own, and reported
+// like a batch create. A Cooklang file is read instead when format=cooklang
+// is given, the body is sent as text/x-cooklang or the upload is named
+// *.cook; a file without a title is named after the upload. Duplicates are
+// reported or rejected as by BatchCreateHandler.
+func (handler *Handler) ImportHandler(ctx *gin.Context) {
+	dup, ok := duplicateMode(ctx)
+	if !ok {
+		return
+	}
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportBytes)
+
+	body := io.Reader(ctx.Request.Body)
+	isCooklang := strings.EqualFold(ctx.Query("format"), "cooklang") || ctx.ContentType() == cooklangType
+	var filename string
+	if strings.HasPrefix(ctx.ContentType(), "multipart/") {
+		upload, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.JSON(bodyErrorStatus(err), gin.H{"error": "file is required"})
+			return
+		}
+		file, err := upload.Open()
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid upload"})
+			return
+		}
+		defer file.Close()
+		body = file
+		filename = upload.Filename
+		isCooklang = isCooklang || strings.EqualFold(filepath.Ext(filename), ".cook")
+	}
+
+	var recipes []model.Recipe
+	var err error
+	if isCooklang {
+		recipes, err = parseCooklang(body, filename)
+	} else {
+		limits := handler.ctrl.Limits()
+		recipes, err = schemaorg.Parse(body, schemaorg.WithTagLimits(limits.MaxTags, limits.MaxTagLength))
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, schemaorg.ErrNoRecipe):
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(bodyErrorStatus(err), gin.H{"error": "invalid request body"})
+		}
+		return
+	}
+
+	results, err := handler.ctrl.CreateRecipes(ctx.Request.Context(), recipes, domain.BatchBestEffort, dup)
+	writeBatch(ctx, results, err, http.StatusCreated, true)
+}
+
+// parseCooklang reads one recipe from a Cooklang file, named after the file
+// when it has no title.
+func parseCooklang(r io.Reader, filename string) ([]model.Recipe, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := cooklang.Parse(string(src))
+	if err != nil {
+		return nil, err
+	}
+	var name string
+	if filename != "" {
+		name = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+	return []model.Recipe{doc.ToRecipe(name)}, nil
+}
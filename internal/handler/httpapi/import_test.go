@@ -0,0 +1,112 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const importPage = `<html><head>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@type": "Recipe", "name": "Tomato Soup",
+ "keywords": "soup, Vegetarian", "recipeYield": "2 bowls", "totalTime": "PT40M",
+ "recipeIngredient": ["4 tomatoes", "1 onion"],
+ "recipeInstructions": [{"@type": "HowToStep", "text": "Roast the tomatoes."}, {"@type": "HowToStep", "text": "Blend with the onion."}]}
+</script></head><body></body></html>`
+
+func TestImportHandler(t *testing.T) {
+	router := setupBatchRouter(&mockRepo{})
+
+	code, resp := postBatch(t, router, "/recipes:import", importPage)
+	if code != http.StatusOK || resp.Succeeded != 1 {
+		t.Fatalf("Expected one created recipe, got %d %+v", code, resp)
+	}
+	r := resp.Results[0].Recipe
+	if r == nil || r.Name != "Tomato Soup" || len(r.Ingredients) != 2 || len(r.Instructions) != 2 {
+		t.Fatalf("Unexpected recipe %+v", r)
+	}
+	if r.Yield != "2 bowls" || r.TotalTime != "PT40M" || len(r.Tags) != 2 || r.Tags[1] != "vegetarian" {
+		t.Errorf("Unexpected metadata %+v", r)
+	}
+
+	// No recipe on the page
+	code, _ = postBatch(t, router, "/recipes:import", `<html><body>nothing</body></html>`)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422, got %d", code)
+	}
+
+	// Broken JSON-LD document
+	code, _ = postBatch(t, router, "/recipes:import", `{"@type": "Recipe"`)
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", code)
+	}
+}
+
+func TestImportHandlerMultipart(t *testing.T) {
+	router := setupBatchRouter(&mockRepo{})
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "soup.html")
+	part.Write([]byte(importPage))
+	form.Close()
+
+	req, _ := http.NewRequest("POST", "/recipes:import", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp BatchResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Succeeded != 1 {
+		t.Errorf("Expected one created recipe, got %d %s", w.Code, w.Body.String())
+	}
+
+	// Missing file field
+	body.Reset()
+	form = multipart.NewWriter(&body)
+	form.WriteField("other", "x")
+	form.Close()
+	req, _ = http.NewRequest("POST", "/recipes:import", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestImportHandlerCooklang(t *testing.T) {
+	router := setupBatchRouter(&mockRepo{})
+
+	src := ">> title: Pasta\n>> servings: 2\n\nBoil @water{2%l} in a #pot{}.\n\nCook @spaghetti{200%g} for ~{10%minutes}.\n"
+	code, resp := postBatch(t, router, "/recipes:import?format=cooklang", src)
+	if code != http.StatusOK || resp.Succeeded != 1 {
+		t.Fatalf("Expected one created recipe, got %d %+v", code, resp)
+	}
+	r := resp.Results[0].Recipe
+	if r.Name != "Pasta" || r.Yield != "2 servings" || len(r.Ingredients) != 2 || r.Instructions[1] != "Cook spaghetti for 10 minutes." {
+		t.Errorf("Unexpected recipe %+v", r)
+	}
+
+	// An untitled upload is named after the file
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "Garlic Bread.cook")
+	part.Write([]byte("Spread @butter on @bread{1%loaf}."))
+	form.Close()
+
+	req, _ := http.NewRequest("POST", "/recipes:import", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Succeeded != 1 || resp.Results[0].Recipe.Name != "Garlic Bread" {
+		t.Errorf("Expected a recipe named after the file, got %d %s", w.Code, w.Body.String())
+	}
+}
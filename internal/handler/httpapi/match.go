@@ -0,0 +1,83 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+// MatchRequest represents the body of POST /recipes/match.
+type MatchRequest struct {
+	// Ingredients are what the user has, as names such as "green onions"
+	Ingredients []string `json:"ingredients"`
+	// Ignore adds to the staples, such as salt and water, never counted as missing
+	Ignore []string `json:"ignore"`
+	// MaxMissing drops recipes lacking more ingredients; it defaults to 2
+	MaxMissing *int `json:"maxMissing"`
+	// Limit bounds the matches returned; it defaults to 20
+	Limit int `json:"limit"`
+}
+
+// MatchResult is one recipe in the response of POST /recipes/match.
+type MatchResult struct {
+	Recipe model.Recipe `json:"recipe"`
+	// Needed counts the ingredients other than staples, Have those at hand
+	Needed   int      `json:"needed"`
+	Have     int      `json:"have"`
+	Missing  []string `json:"missing"`
+	Coverage float64  `json:"coverage"`
+}
+
+// MatchResponse is the body answering POST /recipes/match.
+type MatchResponse struct {
+	Matches []MatchResult `json:"matches"`
+}
+
+// MatchHandler handles POST requests ranking the recipes that can be made
+// from the ingredients in the body, fully makeable recipes first.
+func (handler *Handler) MatchHandler(ctx *gin.Context) {
+	var req MatchRequest
+	if err := handler.bindBody(ctx, &req, 1); err != nil {
+		ctx.JSON(bodyErrorStatus(err), gin.H{"error": "invalid request body"})
+		return
+	}
+	query := recipe.MatchQuery{
+		Ingredients: req.Ingredients,
+		Ignore:      req.Ignore,
+		MaxMissing:  recipe.DefaultMaxMissing,
+		Limit:       req.Limit,
+	}
+	if req.MaxMissing != nil {
+		query.MaxMissing = *req.MaxMissing
+	}
+
+	matches, err := handler.ctrl.MatchPantry(ctx.Request.Context(), query)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			invalidInput(ctx, err)
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	resp := MatchResponse{Matches: make([]MatchResult, len(matches))}
+	for i, m := range matches {
+		resp.Matches[i] = MatchResult{
+			Recipe:   m.Recipe,
+			Needed:   m.Needed,
+			Have:     m.Needed - len(m.Missing),
+			Missing:  m.Missing,
+			Coverage: m.Coverage(),
+		}
+		if m.Missing == nil {
+			resp.Matches[i].Missing = []string{}
+		}
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
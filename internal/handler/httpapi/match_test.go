@@ -0,0 +1,64 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMatchHandler(t *testing.T) {
+	repo := &mockRepo{
+		listFunc: func(ctx context.Context) ([]model.Recipe, error) {
+			return []model.Recipe{
+				{ID: "1", Name: "Omelette", Ingredients: []string{"3 eggs", "2 scallions, sliced", "salt and pepper"}},
+				{ID: "2", Name: "Potato salad", Ingredients: []string{"1 lb potatoes", "2 eggs", "1/2 cup mayonnaise"}},
+				{ID: "3", Name: "Soup", Ingredients: []string{"1 leek", "1 carrot", "2 cups stock"}},
+			}, nil
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/recipes/match", New(recipe.New(repo)).MatchHandler)
+	post := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/recipes/match", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"ingredients": ["Eggs", "green onion", "potato"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp MatchResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %+v", resp.Matches)
+	}
+	first, second := resp.Matches[0], resp.Matches[1]
+	if first.Recipe.ID != "1" || first.Needed != 2 || first.Have != 2 || len(first.Missing) != 0 || first.Coverage != 1 {
+		t.Errorf("Expected the omelette fully covered first, got %+v", first)
+	}
+	if second.Recipe.ID != "2" || len(second.Missing) != 1 || second.Missing[0] != "1/2 cup mayonnaise" {
+		t.Errorf("Expected the potato salad missing mayonnaise, got %+v", second)
+	}
+
+	w = post(`{"ingredients": ["eggs", "green onion", "potato"], "maxMissing": 0}`)
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Matches) != 1 {
+		t.Errorf("Expected only makeable recipes, got %+v", resp.Matches)
+	}
+
+	for _, body := range []string{`{"ingredients": []}`, `{"ingredients": ["egg"], "maxMissing": -1}`, `{"ingredients": ["egg"], "limit": 1000}`} {
+		if w := post(body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, w.Code)
+		}
+	}
+}
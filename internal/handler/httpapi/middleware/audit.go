@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"github.com/gin-demo/recipes-web/internal/audit"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditContext attaches the client IP to the request context so audit events
+// recorded deeper in the stack can include it.
+func AuditContext() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Request = ctx.Request.WithContext(audit.WithClientIP(ctx.Request.Context(), ctx.ClientIP()))
+		ctx.Next()
+	}
+}
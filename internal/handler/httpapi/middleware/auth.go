@@ -3,17 +3,23 @@ package middleware
 import (
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 
+	"github.com/gin-demo/recipes-web/internal/logging"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthFailureKey is the context key under which a rejected request records why
+// authentication failed, so outer middleware can report it.
+const AuthFailureKey = "authFailure"
+
+// AuthMiddleware rejects requests without a valid HS256 bearer token signed with secret.
+func AuthMiddleware(secret string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 		if !strings.HasPrefix(authHeader, "Bearer ") {
+			ctx.Set(AuthFailureKey, "missing_token")
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"error": "invalid authorization",
 			})
@@ -21,8 +27,8 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		secret := os.Getenv("JWT_SECRET")
 		if secret == "" {
+			ctx.Set(AuthFailureKey, "misconfigured")
 			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 				"error": "server misconfiguration",
 			})
@@ -38,12 +44,31 @@ func AuthMiddleware() gin.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
+			ctx.Set(AuthFailureKey, "invalid_token")
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
 			return
 		}
 
 		ctx.Set("userName", claims["userName"])
 		ctx.Set("role", claims["role"])
+		if userName, ok := claims["userName"].(string); ok {
+			ctx.Request = ctx.Request.WithContext(logging.WithUserName(ctx.Request.Context(), userName))
+		}
+
+		ctx.Next()
+	}
+}
+
+// RequireRole aborts the request unless AuthMiddleware stored the given role.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetString("role") != role {
+			ctx.Set(AuthFailureKey, "forbidden")
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "insufficient permissions",
+			})
+			return
+		}
 
 		ctx.Next()
 	}
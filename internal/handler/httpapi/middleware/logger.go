@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// Logger writes one structured line per request. The request ID comes from the
+// request context; the user name is added once AuthMiddleware has run. Headers
+// are only logged at debug level, with credentials redacted.
+func Logger(logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.String("route", ctx.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("clientIp", ctx.ClientIP()),
+			slog.Int("bytes", ctx.Writer.Size()),
+		}
+
+		reqCtx := ctx.Request.Context()
+		if userName := ctx.GetString("userName"); userName != "" && logging.UserName(reqCtx) == "" {
+			reqCtx = logging.WithUserName(reqCtx, userName)
+		}
+		if len(ctx.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", ctx.Errors.String()))
+		}
+		if logger.Enabled(reqCtx, slog.LevelDebug) {
+			attrs = append(attrs, slog.Any("headers", logging.RedactHeaders(ctx.Request.Header)))
+		}
+
+		logger.LogAttrs(reqCtx, level, "http request", attrs...)
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records request counts and latency by route template, and counts
+// requests rejected by AuthMiddleware or RequireRole.
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		// Route templates such as /recipes/:id keep label cardinality bounded.
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := methodLabel(ctx.Request.Method)
+		status := strconv.Itoa(ctx.Writer.Status())
+
+		m.HTTPRequests.WithLabelValues(method, route, status).Inc()
+		m.HTTPDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+
+		if reason := ctx.GetString(AuthFailureKey); reason != "" {
+			m.AuthFailures.WithLabelValues(reason).Inc()
+		}
+	}
+}
+
+// methodLabel returns the method for the standard methods and "other" for
+// the rest, since clients can send any token as a method.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "other"
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := metrics.New(prometheus.NewRegistry())
+	router := gin.New()
+	router.Use(Metrics(m))
+	router.GET("/recipes/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/private", AuthMiddleware("metrics-secret"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/recipes/1", "/recipes/2", "/private", "/nowhere"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Both recipe IDs share the route template label
+	if got := testutil.ToFloat64(m.HTTPRequests.WithLabelValues("GET", "/recipes/:id", "200")); got != 2 {
+		t.Errorf("Expected 2 requests for route template, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.HTTPRequests.WithLabelValues("GET", "unmatched", "404")); got != 1 {
+		t.Errorf("Expected 1 unmatched request, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.AuthFailures.WithLabelValues("missing_token")); got != 1 {
+		t.Errorf("Expected 1 auth failure, got %v", got)
+	}
+
+	// Made-up methods share one label
+	for _, method := range []string{"FOO", "BAR"} {
+		req, _ := http.NewRequest(method, "/nowhere", nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if got := testutil.ToFloat64(m.HTTPRequests.WithLabelValues("other", "unmatched", "404")); got != 2 {
+		t.Errorf("Expected 2 requests with other methods, got %v", got)
+	}
+}
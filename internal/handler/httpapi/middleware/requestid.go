@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"github.com/gin-demo/recipes-web/internal/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/xid"
+)
+
+// RequestIDHeader is the header used to accept and return the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the gin context key holding the request ID.
+const RequestIDKey = "requestID"
+
+const maxRequestIDLength = 128
+
+// RequestID reuses a well-formed X-Request-ID from the caller or generates a
+// new one, echoes it on the response and attaches it to the request context
+// so every log line for the request carries it.
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = xid.New().String()
+		}
+
+		ctx.Set(RequestIDKey, id)
+		ctx.Header(RequestIDHeader, id)
+		ctx.Request = ctx.Request.WithContext(logging.WithRequestID(ctx.Request.Context(), id))
+
+		ctx.Next()
+	}
+}
+
+// validRequestID rejects empty, oversized or non-printable IDs so callers cannot
+// inject arbitrary content into logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var seen string
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(c *gin.Context) {
+		seen = logging.RequestID(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	// Caller-provided ID is kept
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if seen != "abc-123" || w.Header().Get(RequestIDHeader) != "abc-123" {
+		t.Errorf("Expected request ID abc-123, got context %q header %q", seen, w.Header().Get(RequestIDHeader))
+	}
+
+	// Missing or malformed IDs are replaced
+	req, _ = http.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "bad id\nwith newline")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if seen == "" || strings.Contains(seen, " ") {
+		t.Errorf("Expected generated request ID, got %q", seen)
+	}
+	if w.Header().Get(RequestIDHeader) != seen {
+		t.Errorf("Expected response header %q, got %q", seen, w.Header().Get(RequestIDHeader))
+	}
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	logger, _ := logging.New(&buf, logging.Config{Format: logging.FormatJSON, Level: "debug"})
+
+	router := gin.New()
+	router.Use(RequestID(), Logger(logger))
+	router.GET("/recipes/:id", AuthMiddleware("logger-secret"), func(c *gin.Context) {
+		logger.InfoContext(c.Request.Context(), "handler")
+		c.Status(http.StatusOK)
+	})
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"userName": "alice",
+		"exp":      time.Now().Add(time.Minute).Unix(),
+	})
+	signed, _ := token.SignedString([]byte("logger-secret"))
+
+	req, _ := http.NewRequest("GET", "/recipes/1", nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	req.Header.Set(RequestIDHeader, "req-42")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected handler and request log lines, got %q", buf.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, `"requestId":"req-42"`) || !strings.Contains(line, `"userName":"alice"`) {
+			t.Errorf("Expected request ID and user on every line, got %s", line)
+		}
+	}
+	if !strings.Contains(lines[1], `"route":"/recipes/:id"`) {
+		t.Errorf("Expected route template in request log, got %s", lines[1])
+	}
+	if strings.Contains(buf.String(), signed) {
+		t.Error("Expected Authorization header to be redacted")
+	}
+}
+
+func TestLoggerLevelFollowsStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	router := gin.New()
+	router.Use(Logger(logger))
+	router.GET("/boom", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	req, _ := http.NewRequest("GET", "/boom", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !strings.Contains(buf.String(), "level=ERROR") {
+		t.Errorf("Expected error level for 500, got %q", buf.String())
+	}
+}
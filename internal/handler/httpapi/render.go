@@ -0,0 +1,206 @@
+package httpapi
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+// Renderer writes recipes in one media type.
+type Renderer interface {
+	// ContentType is the Content-Type header sent with the output.
+	ContentType() string
+	// Render writes a single recipe.
+	Render(w io.Writer, recipe model.Recipe) error
+	// RenderList writes several recipes as one document.
+	RenderList(w io.Writer, recipes []model.Recipe) error
+}
+
+// ErrSingleRecipe is returned by RenderList for formats that hold one recipe
+// per document; the client is told the list is not acceptable in that format.
+var ErrSingleRecipe = errors.New("format holds a single recipe")
+
+// Renderers picks a Renderer from a request's Accept header, or from a file
+// extension on a recipe ID as in GET /recipes/{id}.cook. JSON is built in and
+// is what clients get when they accept anything.
+type Renderers struct {
+	types      []string
+	renderers  map[string]Renderer
+	extensions map[string]string
+}
+
+// NewRenderers returns a registry holding JSON and the schema.org JSON-LD,
+// Markdown, plain text, Cooklang and PDF renderers, with Cooklang and PDF
+// also served for the .cook and .pdf extensions.
+func NewRenderers() *Renderers {
+	rs := &Renderers{renderers: map[string]Renderer{}, extensions: map[string]string{}}
+	rs.Register("application/ld+json", jsonLDRenderer{})
+	rs.Register("text/markdown", markdownRenderer{})
+	rs.Register("text/plain", textRenderer{})
+	rs.Register(cooklangType, cooklangRenderer{})
+	rs.Register(pdfType, pdfRenderer{})
+	rs.RegisterExtension(".cook", cooklangType)
+	rs.RegisterExtension(".pdf", pdfType)
+	return rs
+}
+
+// RegisterExtension serves mediaType for recipe IDs ending in ext, such as
+// ".cook"; mediaType must already be registered.
+func (rs *Renderers) RegisterExtension(ext, mediaType string) {
+	rs.extensions[strings.ToLower(ext)] = strings.ToLower(mediaType)
+}
+
+// Extension splits a registered extension off id and returns the bare ID and
+// the renderer for it. ok is false when id has no registered extension.
+func (rs *Renderers) Extension(id model.RecipeID) (bareID model.RecipeID, ext string, r Renderer, ok bool) {
+	i := strings.LastIndexByte(string(id), '.')
+	if i <= 0 {
+		return id, "", nil, false
+	}
+	ext = strings.ToLower(string(id[i:]))
+	mediaType, ok := rs.extensions[ext]
+	if !ok || rs.renderers[mediaType] == nil {
+		return id, "", nil, false
+	}
+	return id[:i], ext, rs.renderers[mediaType], true
+}
+
+// Register serves mediaType with r, replacing any renderer already registered
+// for it. Registering application/json replaces the built-in JSON output.
+func (rs *Renderers) Register(mediaType string, r Renderer) {
+	mediaType = strings.ToLower(mediaType)
+	if _, ok := rs.renderers[mediaType]; !ok {
+		rs.types = append(rs.types, mediaType)
+	}
+	rs.renderers[mediaType] = r
+}
+
+// Types lists the media types that can be served, JSON first.
+func (rs *Renderers) Types() []string {
+	types := []string{jsonType}
+	for _, t := range rs.types {
+		if t != jsonType {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+const jsonType = "application/json"
+
+// Negotiate returns the media type to serve for an Accept header and its
+// renderer, which is nil for the built-in JSON. ok is false when none of the
+// accepted types can be served.
+func (rs *Renderers) Negotiate(accept string) (mediaType string, r Renderer, ok bool) {
+	if strings.TrimSpace(accept) == "" {
+		return rs.lookup(jsonType)
+	}
+
+	for _, want := range parseAccept(accept) {
+		switch {
+		case want == "*/*":
+			return rs.lookup(jsonType)
+		case strings.HasSuffix(want, "/*"):
+			prefix := strings.TrimSuffix(want, "*")
+			for _, t := range rs.Types() {
+				if strings.HasPrefix(t, prefix) {
+					return rs.lookup(t)
+				}
+			}
+		case want == jsonType || rs.renderers[want] != nil:
+			return rs.lookup(want)
+		}
+	}
+	return "", nil, false
+}
+
+func (rs *Renderers) lookup(mediaType string) (string, Renderer, bool) {
+	return mediaType, rs.renderers[mediaType], true
+}
+
+// parseAccept returns the media ranges of an Accept header, most preferred
+// first, leaving out those with q=0.
+func parseAccept(accept string) []string {
+	type mediaRange struct {
+		mediaType string
+		q         float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, mediaRange{mediaType, q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	out := make([]string, len(ranges))
+	for i, r := range ranges {
+		out[i] = r.mediaType
+	}
+	return out
+}
+
+// negotiate responds 406 and returns false when the client accepts none of the
+// registered types.
+func (handler *Handler) negotiate(ctx *gin.Context) (Renderer, bool) {
+	ctx.Header("Vary", "Accept")
+	_, r, ok := handler.renderers.Negotiate(ctx.GetHeader("Accept"))
+	if !ok {
+		ctx.JSON(http.StatusNotAcceptable, gin.H{
+			"error":     "not acceptable",
+			"available": handler.renderers.Types(),
+		})
+	}
+	return r, ok
+}
+
+// renderRecipe writes one recipe in the negotiated format, JSON when r is nil.
+func renderRecipe(ctx *gin.Context, r Renderer, recipe model.Recipe) {
+	if r == nil {
+		ctx.JSON(http.StatusOK, recipe)
+		return
+	}
+	writeRendered(ctx, r, func(w io.Writer) error { return r.Render(w, recipe) })
+}
+
+// renderRecipes writes a list of recipes in the negotiated format, JSON when r is nil.
+func renderRecipes(ctx *gin.Context, r Renderer, recipes []model.Recipe) {
+	if r == nil {
+		ctx.JSON(http.StatusOK, recipes)
+		return
+	}
+	writeRendered(ctx, r, func(w io.Writer) error { return r.RenderList(w, recipes) })
+}
+
+// writeRendered buffers the output so a rendering failure can still be
+// reported as a 500.
+func writeRendered(ctx *gin.Context, r Renderer, write func(io.Writer) error) {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		if errors.Is(err, ErrSingleRecipe) {
+			ctx.JSON(http.StatusNotAcceptable, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	ctx.Data(http.StatusOK, r.ContentType(), buf.Bytes())
+}
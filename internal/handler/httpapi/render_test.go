@@ -0,0 +1,231 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/schemaorg"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+func renderRecipeFixture() model.Recipe {
+	return model.Recipe{
+		ID:           "r1",
+		Name:         "Chicken Curry",
+		Tags:         []string{"indian", "main"},
+		Ingredients:  []string{"1 onion", "2 tbsp *hot* curry paste"},
+		Instructions: []string{"Marinate the chicken.", "Fry the onion.", "Simmer everything together for a good twenty-five minutes, stirring now and then so nothing catches."},
+		Sections: []model.InstructionSection{
+			{Title: "Marinade", Steps: []string{"Marinate the chicken."}},
+			{Title: "Curry", Steps: []string{"Fry the onion.", "Simmer everything together for a good twenty-five minutes, stirring now and then so nothing catches."}},
+		},
+		Yield:     "4 servings",
+		PrepTime:  "PT15M",
+		TotalTime: "PT1H30M",
+		Cuisine:   "indian",
+		Diets:     []string{"gluten-free"},
+	}
+}
+
+func getWithAccept(router *gin.Engine, path, accept string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", path, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestNegotiate(t *testing.T) {
+	rs := NewRenderers()
+	cases := map[string]string{
+		"":                    "application/json",
+		"*/*":                 "application/json",
+		"application/ld+json": "application/ld+json",
+		"text/html, text/markdown;q=0.9, */*;q=0.1": "text/markdown",
+		"text/plain;q=0.5, application/ld+json":     "application/ld+json",
+		"text/*":                                    "text/markdown",
+		"TEXT/PLAIN":                                "text/plain",
+		"application/json;q=0, text/plain":          "text/plain",
+	}
+	for accept, want := range cases {
+		got, _, ok := rs.Negotiate(accept)
+		if !ok || got != want {
+			t.Errorf("Negotiate(%q) = %q, %v; want %q", accept, got, ok, want)
+		}
+	}
+
+	if _, _, ok := rs.Negotiate("text/html, image/*"); ok {
+		t.Error("Expected no match for text/html and images")
+	}
+}
+
+func TestGetRecipeByIDHandlerContentNegotiation(t *testing.T) {
+	repo := &mockRepo{getByIDFunc: func(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+		return renderRecipeFixture(), nil
+	}}
+	router := setupTestRouter(repo)
+
+	// JSON-LD
+	w := getWithAccept(router, "/recipes/r1", "application/ld+json")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/ld+json") {
+		t.Fatalf("Expected JSON-LD, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if w.Header().Get("Vary") != "Accept" {
+		t.Errorf("Expected Vary: Accept, got %q", w.Header().Get("Vary"))
+	}
+	var doc schemaorg.Recipe
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode JSON-LD: %v", err)
+	}
+	if doc.Context != "https://schema.org" || doc.Type != "Recipe" || doc.Name != "Chicken Curry" || len(doc.RecipeInstructions) != 2 {
+		t.Errorf("Unexpected document %+v", doc)
+	}
+
+	// Markdown
+	w = getWithAccept(router, "/recipes/r1", "text/markdown")
+	body := w.Body.String()
+	for _, want := range []string{"# Chicken Curry\n", "**Yield:** 4 servings · **Prep:** 15 min · **Total:** 1 h 30 min · **Cuisine:** indian · **Diet:** gluten-free", "- 2 tbsp \\*hot\\* curry paste\n", "### Curry\n\n1. Fry the onion.\n2. Simmer"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Markdown lacks %q:\n%s", want, body)
+		}
+	}
+
+	// Plain text wraps long steps under their number
+	w = getWithAccept(router, "/recipes/r1", "text/plain")
+	body = w.Body.String()
+	if !strings.HasPrefix(body, "Chicken Curry\n=============\n") || !strings.Contains(body, "  [ ] 1 onion\n") {
+		t.Errorf("Unexpected text:\n%s", body)
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if len(line) > textWidth {
+			t.Errorf("Line longer than %d: %q", textWidth, line)
+		}
+	}
+	if !strings.Contains(body, "  2. Simmer everything") || !strings.Contains(body, "\n     ") {
+		t.Errorf("Expected a wrapped, numbered step:\n%s", body)
+	}
+
+	// Nothing acceptable
+	w = getWithAccept(router, "/recipes/r1", "text/html")
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("Expected status 406, got %d", w.Code)
+	}
+
+	// Errors stay JSON
+	repo.getByIDFunc = func(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+		return model.Recipe{}, domain.ErrNotFound
+	}
+	w = getWithAccept(router, "/recipes/r1", "text/markdown")
+	if w.Code != http.StatusNotFound || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("Expected a JSON 404, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+func TestListRecipeHandlerJSONLDGraph(t *testing.T) {
+	repo := &mockRepo{listFunc: func(ctx context.Context) ([]model.Recipe, error) {
+		return []model.Recipe{renderRecipeFixture(), {ID: "r2", Name: "Toast"}}, nil
+	}}
+	router := setupTestRouter(repo)
+
+	w := getWithAccept(router, "/recipes", "application/ld+json")
+	recipes, err := schemaorg.Parse(w.Body)
+	if err != nil || len(recipes) != 2 || recipes[1].Name != "Toast" {
+		t.Errorf("Expected a parseable graph of 2 recipes, got %d, %v", len(recipes), err)
+	}
+}
+
+func TestListRecipeHandlerRefusesPDF(t *testing.T) {
+	listed := false
+	repo := &mockRepo{listFunc: func(ctx context.Context) ([]model.Recipe, error) {
+		listed = true
+		return []model.Recipe{renderRecipeFixture()}, nil
+	}}
+	router := setupTestRouter(repo)
+
+	w := getWithAccept(router, "/recipes", "application/pdf")
+	if w.Code != http.StatusNotAcceptable || !strings.Contains(w.Body.String(), "/cookbooks/render") {
+		t.Errorf("Expected a 406 pointing to /cookbooks/render, got %d %s", w.Code, w.Body.String())
+	}
+	if listed {
+		t.Error("Expected the recipes not to be loaded")
+	}
+
+	if err := (pdfRenderer{}).RenderList(io.Discard, nil); err != ErrSingleRecipe {
+		t.Errorf("Expected ErrSingleRecipe, got %v", err)
+	}
+}
+
+type upperRenderer struct{}
+
+func (upperRenderer) ContentType() string { return "text/x-shout" }
+
+func (upperRenderer) Render(w io.Writer, r model.Recipe) error {
+	_, err := io.WriteString(w, strings.ToUpper(r.Name))
+	return err
+}
+
+func (u upperRenderer) RenderList(w io.Writer, recipes []model.Recipe) error {
+	for _, r := range recipes {
+		if err := u.Render(w, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCustomRenderer(t *testing.T) {
+	rs := NewRenderers()
+	rs.Register("text/x-shout", upperRenderer{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := New(recipe.New(&mockRepo{}), WithRenderers(rs))
+	router.GET("/recipes/:id", handler.GetRecipeByIDHandler)
+
+	w := getWithAccept(router, "/recipes/r1", "text/x-shout")
+	if w.Body.String() != "TEST" || w.Header().Get("Content-Type") != "text/x-shout" {
+		t.Errorf("Expected the custom renderer, got %q %q", w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
+
+func TestGetRecipeByIDHandlerCooklangDownload(t *testing.T) {
+	var gotID model.RecipeID
+	repo := &mockRepo{getByIDFunc: func(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+		gotID = id
+		return renderRecipeFixture(), nil
+	}}
+	router := setupTestRouter(repo)
+
+	w := getWithAccept(router, "/recipes/r1.cook", "")
+	if w.Code != http.StatusOK || gotID != "r1" {
+		t.Fatalf("Expected the recipe r1, got %d for %q", w.Code, gotID)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/x-cooklang") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="r1.cook"` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	body := w.Body.String()
+	for _, want := range []string{">> title: Chicken Curry\n", "== Curry ==\n", "Fry the @onion{1}."} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Cooklang lacks %q:\n%s", want, body)
+		}
+	}
+
+	// Cooklang holds one recipe, so a list cannot be served in it
+	w = getWithAccept(router, "/recipes", "text/x-cooklang")
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("Expected status 406 for a Cooklang list, got %d", w.Code)
+	}
+}
@@ -0,0 +1,242 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/internal/cookbook"
+	"github.com/gin-demo/recipes-web/internal/cooklang"
+	"github.com/gin-demo/recipes-web/internal/schemaorg"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// jsonLDRenderer writes schema.org Recipe documents, a list as one @graph.
+type jsonLDRenderer struct{}
+
+func (jsonLDRenderer) ContentType() string { return "application/ld+json; charset=utf-8" }
+
+func (jsonLDRenderer) Render(w io.Writer, recipe model.Recipe) error {
+	return json.NewEncoder(w).Encode(schemaorg.FromRecipe(recipe))
+}
+
+func (jsonLDRenderer) RenderList(w io.Writer, recipes []model.Recipe) error {
+	return json.NewEncoder(w).Encode(schemaorg.FromRecipes(recipes))
+}
+
+// markdownRenderer writes recipe cards in CommonMark.
+type markdownRenderer struct{}
+
+func (markdownRenderer) ContentType() string { return "text/markdown; charset=utf-8" }
+
+func (markdownRenderer) Render(w io.Writer, recipe model.Recipe) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", markdownEscape(recipe.Name))
+	if facts := recipeFacts(recipe); len(facts) > 0 {
+		parts := make([]string, len(facts))
+		for i, fact := range facts {
+			parts[i] = fmt.Sprintf("**%s:** %s", fact[0], markdownEscape(fact[1]))
+		}
+		b.WriteString(strings.Join(parts, " · ") + "\n\n")
+	}
+	if len(recipe.Tags) > 0 {
+		tags := make([]string, len(recipe.Tags))
+		for i, tag := range recipe.Tags {
+			tags[i] = "`" + tag + "`"
+		}
+		b.WriteString(strings.Join(tags, " ") + "\n\n")
+	}
+
+	b.WriteString("## Ingredients\n\n")
+	for _, ingredient := range recipe.Ingredients {
+		fmt.Fprintf(&b, "- %s\n", markdownEscape(ingredient))
+	}
+
+	b.WriteString("\n## Instructions\n")
+	for _, section := range instructionSections(recipe) {
+		if section.Title != "" {
+			fmt.Fprintf(&b, "\n### %s\n", markdownEscape(section.Title))
+		}
+		b.WriteString("\n")
+		for i, step := range section.Steps {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, markdownEscape(step))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (m markdownRenderer) RenderList(w io.Writer, recipes []model.Recipe) error {
+	for i, recipe := range recipes {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n---\n\n"); err != nil {
+				return err
+			}
+		}
+		if err := m.Render(w, recipe); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownSpecial are the characters escaped in recipe text so that it is not
+// read as emphasis, links, code or HTML.
+var markdownSpecial = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "#", `\#`,
+)
+
+func markdownEscape(s string) string {
+	return markdownSpecial.Replace(s)
+}
+
+const cooklangType = "text/x-cooklang"
+
+// cooklangRenderer writes a recipe as a Cooklang document. Cooklang has no
+// way to hold several recipes, so lists are refused.
+type cooklangRenderer struct{}
+
+func (cooklangRenderer) ContentType() string { return cooklangType + "; charset=utf-8" }
+
+func (cooklangRenderer) Render(w io.Writer, recipe model.Recipe) error {
+	_, err := io.WriteString(w, cooklang.Format(recipe))
+	return err
+}
+
+func (cooklangRenderer) RenderList(io.Writer, []model.Recipe) error {
+	return ErrSingleRecipe
+}
+
+const pdfType = "application/pdf"
+
+// pdfRenderer writes a recipe as printable PDF pages. Lists are refused so an
+// anonymous request cannot render the whole catalog; POST /cookbooks/render
+// builds cookbooks within the batch limit.
+type pdfRenderer struct{}
+
+func (pdfRenderer) ContentType() string { return pdfType }
+
+func (pdfRenderer) Render(w io.Writer, recipe model.Recipe) error {
+	return cookbook.Write(w, []model.Recipe{recipe})
+}
+
+func (pdfRenderer) RenderList(io.Writer, []model.Recipe) error {
+	return ErrSingleRecipe
+}
+
+// textWidth is the line length of the plain text renderer.
+const textWidth = 72
+
+// textRenderer writes a printable plain text version wrapped at textWidth.
+type textRenderer struct{}
+
+func (textRenderer) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (textRenderer) Render(w io.Writer, recipe model.Recipe) error {
+	var b strings.Builder
+	b.WriteString(recipe.Name + "\n")
+	b.WriteString(strings.Repeat("=", min(len([]rune(recipe.Name)), textWidth)) + "\n\n")
+	if facts := recipeFacts(recipe); len(facts) > 0 {
+		for _, fact := range facts {
+			writeWrapped(&b, fact[0]+": "+fact[1], "", "  ")
+		}
+		b.WriteString("\n")
+	}
+	if len(recipe.Tags) > 0 {
+		writeWrapped(&b, "Tags: "+strings.Join(recipe.Tags, ", "), "", "  ")
+		b.WriteString("\n")
+	}
+
+	b.WriteString("INGREDIENTS\n\n")
+	for _, ingredient := range recipe.Ingredients {
+		writeWrapped(&b, ingredient, "  [ ] ", "      ")
+	}
+
+	b.WriteString("\nINSTRUCTIONS\n")
+	for _, section := range instructionSections(recipe) {
+		b.WriteString("\n")
+		if section.Title != "" {
+			b.WriteString(section.Title + "\n\n")
+		}
+		for i, step := range section.Steps {
+			number := fmt.Sprintf("%3d. ", i+1)
+			writeWrapped(&b, step, number, strings.Repeat(" ", len(number)))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (t textRenderer) RenderList(w io.Writer, recipes []model.Recipe) error {
+	for i, recipe := range recipes {
+		if i > 0 {
+			// A form feed starts each recipe on a new page when printed.
+			if _, err := io.WriteString(w, "\n\f\n"); err != nil {
+				return err
+			}
+		}
+		if err := t.Render(w, recipe); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeWrapped writes s word-wrapped at textWidth, starting with first and
+// indenting continuation lines with rest.
+func writeWrapped(b *strings.Builder, s, first, rest string) {
+	line := first
+	empty := true
+	for _, word := range strings.Fields(s) {
+		if !empty && len([]rune(line))+1+len([]rune(word)) > textWidth {
+			b.WriteString(line + "\n")
+			line, empty = rest, true
+		}
+		if !empty {
+			line += " "
+		}
+		line += word
+		empty = false
+	}
+	b.WriteString(line + "\n")
+}
+
+// recipeFacts lists the yield, times and other metadata that are set, as
+// label and value.
+func recipeFacts(recipe model.Recipe) [][2]string {
+	var facts [][2]string
+	if recipe.Yield != "" {
+		facts = append(facts, [2]string{"Yield", recipe.Yield})
+	}
+	for _, d := range []struct{ label, value string }{
+		{"Prep", recipe.PrepTime}, {"Cook", recipe.CookTime}, {"Total", recipe.TotalTime},
+	} {
+		if d.value != "" {
+			facts = append(facts, [2]string{d.label, model.HumanDuration(d.value)})
+		}
+	}
+	for _, m := range []struct{ label, value string }{
+		{"Difficulty", recipe.Difficulty},
+		{"Cuisine", recipe.Cuisine},
+		{"Course", recipe.Course},
+		{"Diet", strings.Join(recipe.Diets, ", ")},
+		{"Equipment", strings.Join(recipe.Equipment, ", ")},
+	} {
+		if m.value != "" {
+			facts = append(facts, [2]string{m.label, m.value})
+		}
+	}
+	return facts
+}
+
+// instructionSections returns the recipe's sections, or its instructions as
+// one untitled section.
+func instructionSections(recipe model.Recipe) []model.InstructionSection {
+	if len(recipe.Sections) > 0 {
+		return recipe.Sections
+	}
+	return []model.InstructionSection{{Steps: recipe.Instructions}}
+}
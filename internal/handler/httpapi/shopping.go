@@ -0,0 +1,153 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/internal/controller/shopping"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+// textType is the plain-text export of a shopping list.
+const textType = "text/plain"
+
+// ShoppingHandler handles HTTP requests for the signed-in user's shopping lists.
+type ShoppingHandler struct {
+	ctrl *shopping.Controller
+}
+
+// NewShoppingHandler creates a new ShoppingHandler with the given controller.
+func NewShoppingHandler(ctrl *shopping.Controller) *ShoppingHandler {
+	return &ShoppingHandler{ctrl: ctrl}
+}
+
+// ShoppingRecipeRequest selects a recipe for a shopping list.
+type ShoppingRecipeRequest struct {
+	ID model.RecipeID `json:"id"`
+	// Servings scales the recipe's quantities; it defaults to the recipe's yield
+	Servings int `json:"servings"`
+}
+
+// CreateShoppingListRequest represents the body of POST /shopping-lists.
+type CreateShoppingListRequest struct {
+	// Name defaults to the names of the recipes
+	Name    string                  `json:"name"`
+	Recipes []ShoppingRecipeRequest `json:"recipes"`
+}
+
+// CheckItemRequest represents the body of PATCH /shopping-lists/:id/items/:itemID.
+type CheckItemRequest struct {
+	Checked *bool `json:"checked"`
+}
+
+// ShoppingListsResponse is the body answering GET /shopping-lists.
+type ShoppingListsResponse struct {
+	Lists []model.ShoppingList `json:"lists"`
+}
+
+// CreateHandler handles POST /shopping-lists, building a list from the
+// recipes in the body with like ingredients merged across them.
+func (handler *ShoppingHandler) CreateHandler(ctx *gin.Context) {
+	var req CreateShoppingListRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	cmd := shopping.CreateListCommand{Name: req.Name, Recipes: make([]shopping.RecipeServings, len(req.Recipes))}
+	for i, r := range req.Recipes {
+		cmd.Recipes[i] = shopping.RecipeServings{ID: r.ID, Servings: r.Servings}
+	}
+
+	list, err := handler.ctrl.CreateList(ctx.Request.Context(), ctx.GetString("userName"), cmd)
+	if err != nil {
+		writeShoppingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, list)
+}
+
+// ListHandler handles GET /shopping-lists, the signed-in user's lists newest first.
+func (handler *ShoppingHandler) ListHandler(ctx *gin.Context) {
+	lists, err := handler.ctrl.ListLists(ctx.Request.Context(), ctx.GetString("userName"))
+	if err != nil {
+		writeShoppingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ShoppingListsResponse{Lists: lists})
+}
+
+// GetHandler handles GET /shopping-lists/:id. The list is served as plain
+// text when the Accept header prefers text/plain or the ID ends in .txt.
+func (handler *ShoppingHandler) GetHandler(ctx *gin.Context) {
+	id, asText := strings.CutSuffix(ctx.Param("id"), ".txt")
+	if !asText {
+		ctx.Header("Vary", "Accept")
+		asText = prefersText(ctx.GetHeader("Accept"))
+	}
+
+	list, err := handler.ctrl.GetList(ctx.Request.Context(), ctx.GetString("userName"), model.ShoppingListID(id))
+	if err != nil {
+		writeShoppingError(ctx, err)
+		return
+	}
+	if asText {
+		ctx.Data(http.StatusOK, textType+"; charset=utf-8", []byte(shopping.Text(list)))
+		return
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// prefersText reports whether an Accept header ranks plain text above JSON.
+func prefersText(accept string) bool {
+	for _, want := range parseAccept(accept) {
+		switch want {
+		case textType, "text/*":
+			return true
+		case jsonType, "application/*", "*/*":
+			return false
+		}
+	}
+	return false
+}
+
+// CheckItemHandler handles PATCH /shopping-lists/:id/items/:itemID, checking
+// the item off or, with "checked": false, back on. The response is the list.
+func (handler *ShoppingHandler) CheckItemHandler(ctx *gin.Context) {
+	var req CheckItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.Checked == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	list, err := handler.ctrl.CheckItem(ctx.Request.Context(), ctx.GetString("userName"),
+		model.ShoppingListID(ctx.Param("id")), ctx.Param("itemID"), *req.Checked)
+	if err != nil {
+		writeShoppingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// DeleteHandler handles DELETE /shopping-lists/:id.
+func (handler *ShoppingHandler) DeleteHandler(ctx *gin.Context) {
+	err := handler.ctrl.DeleteList(ctx.Request.Context(), ctx.GetString("userName"), model.ShoppingListID(ctx.Param("id")))
+	if err != nil {
+		writeShoppingError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func writeShoppingError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		invalidInput(ctx, err)
+	case errors.Is(err, domain.ErrNotFound), errors.Is(err, domain.ErrListNotFound), errors.Is(err, domain.ErrItemNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+	}
+}
@@ -0,0 +1,134 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/controller/shopping"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/repository/memory"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+func TestShoppingHandlers(t *testing.T) {
+	repo := &mockRepo{
+		getByIDFunc: func(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+			switch id {
+			case "1":
+				return model.Recipe{ID: id, Name: "Omelette", Yield: "1 serving", Ingredients: []string{"3 eggs", "2 tbsp milk"}}, nil
+			case "2":
+				return model.Recipe{ID: id, Name: "Custard", Yield: "4 servings", Ingredients: []string{"4 eggs", "1 cup milk", "1/2 cup sugar"}}, nil
+			}
+			return model.Recipe{}, domain.ErrNotFound
+		},
+	}
+	lists, err := memory.NewShoppingListRepository(filepath.Join(t.TempDir(), "lists.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewShoppingHandler(shopping.New(lists, repo))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// stands in for the auth middleware
+	router.Use(func(ctx *gin.Context) { ctx.Set("userName", ctx.GetHeader("X-User")) })
+	router.POST("/shopping-lists", handler.CreateHandler)
+	router.GET("/shopping-lists", handler.ListHandler)
+	router.GET("/shopping-lists/:id", handler.GetHandler)
+	router.PATCH("/shopping-lists/:id/items/:itemID", handler.CheckItemHandler)
+	router.DELETE("/shopping-lists/:id", handler.DeleteHandler)
+	do := func(method, path, user, body string, header ...string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("X-User", user)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/shopping-lists", "ed", `{"recipes": [{"id": "1", "servings": 2}, {"id": "2"}]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var list model.ShoppingList
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if list.Owner != "ed" || len(list.Items) != 3 {
+		t.Fatalf("Expected ed's list of eggs, milk and sugar, got %+v", list)
+	}
+	eggs := list.Items[0]
+	if eggs.Name != "egg" || len(eggs.Quantities) != 1 || eggs.Quantities[0].Amount != 10 {
+		t.Errorf("Expected 10 eggs first, got %+v", eggs)
+	}
+
+	for _, body := range []string{`{"recipes": []}`, `{"recipes": [{"id": "1", "servings": -2}]}`, `not json`} {
+		if w := do("POST", "/shopping-lists", "ed", body); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", body, w.Code)
+		}
+	}
+	if w := do("POST", "/shopping-lists", "ed", `{"recipes": [{"id": "9"}]}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown recipe, got %d", w.Code)
+	}
+
+	path := "/shopping-lists/" + string(list.ID)
+	w = do("PATCH", path+"/items/"+eggs.ID, "ed", `{"checked": true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 checking an item, got %d: %s", w.Code, w.Body.String())
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if !list.Items[0].Checked {
+		t.Errorf("Expected the eggs checked off, got %+v", list.Items[0])
+	}
+	if w := do("PATCH", path+"/items/"+eggs.ID, "ed", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without checked, got %d", w.Code)
+	}
+	if w := do("PATCH", path+"/items/99", "ed", `{"checked": true}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown item, got %d", w.Code)
+	}
+
+	for _, w := range []*httptest.ResponseRecorder{
+		do("GET", path, "ed", "", "Accept", "text/plain"),
+		do("GET", path+".txt", "ed", ""),
+	} {
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+			t.Fatalf("Expected a plain-text list, got %d %s", w.Code, w.Header().Get("Content-Type"))
+		}
+		if body := w.Body.String(); !strings.Contains(body, "[x] egg (10)") || !strings.Contains(body, "for Custard, 4 servings") {
+			t.Errorf("Expected the checked eggs and the recipes in the text, got:\n%s", body)
+		}
+	}
+	if w := do("GET", path, "ed", "", "Accept", "application/json, text/plain;q=0.5"); !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("Expected JSON when preferred, got %s", w.Header().Get("Content-Type"))
+	}
+
+	if w := do("GET", path, "al", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected another user's list to be 404, got %d", w.Code)
+	}
+	var resp ShoppingListsResponse
+	json.Unmarshal(do("GET", "/shopping-lists", "al", "").Body.Bytes(), &resp)
+	if resp.Lists == nil || len(resp.Lists) != 0 {
+		t.Errorf("Expected an empty list for another user, got %+v", resp.Lists)
+	}
+	json.Unmarshal(do("GET", "/shopping-lists", "ed", "").Body.Bytes(), &resp)
+	if len(resp.Lists) != 1 {
+		t.Errorf("Expected ed's list, got %+v", resp.Lists)
+	}
+
+	if w := do("DELETE", path, "al", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected another user's delete to be 404, got %d", w.Code)
+	}
+	if w := do("DELETE", path, "ed", ""); w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if w := do("GET", path, "ed", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after delete, got %d", w.Code)
+	}
+}
@@ -0,0 +1,67 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/similar"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+// SimilarRequest represents the query parameters of GET /recipes/{id}/similar.
+type SimilarRequest struct {
+	// Limit bounds the recipes returned; it defaults to 10
+	Limit int `form:"limit"`
+}
+
+// SimilarResult is one recipe in the response of GET /recipes/{id}/similar.
+type SimilarResult struct {
+	Recipe model.Recipe `json:"recipe"`
+	// Score is how alike the recipes are, from 0 to 1
+	Score float64 `json:"score"`
+}
+
+// SimilarResponse is the body answering GET /recipes/{id}/similar.
+type SimilarResponse struct {
+	Recipes []SimilarResult `json:"recipes"`
+}
+
+// SimilarHandler handles GET requests for the recipes most like another, by
+// shared tags, ingredients and name words. It answers 503 while the
+// similarity index is first being built.
+func (handler *Handler) SimilarHandler(ctx *gin.Context) {
+	var uri SearchByIDRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid reciept ID"})
+		return
+	}
+	var req SimilarRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid query"})
+		return
+	}
+
+	results, err := handler.ctrl.SimilarRecipes(ctx.Request.Context(), uri.ID, req.Limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidInput):
+			invalidInput(ctx, err)
+		case errors.Is(err, similar.ErrNotReady):
+			ctx.Header("Retry-After", "5")
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
+		return
+	}
+
+	resp := SimilarResponse{Recipes: make([]SimilarResult, len(results))}
+	for i, r := range results {
+		resp.Recipes[i] = SimilarResult{Recipe: r.Recipe, Score: r.Score}
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
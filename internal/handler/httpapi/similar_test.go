@@ -0,0 +1,75 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/controller/recipe"
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/similar"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/gin-gonic/gin"
+)
+
+func TestSimilarHandler(t *testing.T) {
+	recipes := []model.Recipe{
+		{ID: "1", Name: "Tomato soup", Tags: []string{"soup"}, Ingredients: []string{"4 tomatoes", "1 onion"}},
+		{ID: "2", Name: "Leek soup", Tags: []string{"soup"}, Ingredients: []string{"2 leeks", "1 onion"}},
+		{ID: "3", Name: "Pancakes", Tags: []string{"breakfast"}, Ingredients: []string{"2 eggs"}},
+	}
+	repo := &mockRepo{
+		listFunc: func(ctx context.Context) ([]model.Recipe, error) {
+			return recipes, nil
+		},
+		getByIDFunc: func(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+			for _, r := range recipes {
+				if r.ID == id {
+					return r, nil
+				}
+			}
+			return model.Recipe{}, domain.ErrNotFound
+		},
+	}
+	idx := similar.NewIndex()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/recipes/:id/similar", New(recipe.New(repo, recipe.WithSimilarIndex(idx))).SimilarHandler)
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("/recipes/1/similar"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 before the index is built, got %d", w.Code)
+	}
+	if err := idx.Rebuild(context.Background(), repo); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	w := get("/recipes/1/similar")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp SimilarResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Recipes) != 1 || resp.Recipes[0].Recipe.ID != "2" || resp.Recipes[0].Score <= 0 {
+		t.Errorf("Expected only the leek soup, got %+v", resp.Recipes)
+	}
+
+	if w := get("/recipes/3/similar"); w.Code != http.StatusOK || w.Body.String() != `{"recipes":[]}` {
+		t.Errorf("Expected an empty list, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := get("/recipes/missing/similar"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	for _, path := range []string{"/recipes/1/similar?limit=1000", "/recipes/1/similar?limit=x"} {
+		if w := get(path); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", path, w.Code)
+		}
+	}
+}
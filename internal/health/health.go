@@ -0,0 +1,138 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status is the outcome of a health check or of a whole report.
+type Status string
+
+const (
+	// StatusOK means the dependency is healthy.
+	StatusOK Status = "ok"
+	// StatusWarn means an optional dependency is unhealthy but the service can still serve traffic.
+	StatusWarn Status = "warn"
+	// StatusFail means the service should not receive traffic.
+	StatusFail Status = "fail"
+)
+
+// CheckFunc probes a single dependency and returns nil when it is healthy.
+type CheckFunc func(context.Context) error
+
+// Result is the outcome of one dependency check.
+type Result struct {
+	Status    Status  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report aggregates every dependency check.
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type check struct {
+	name     string
+	critical bool
+	fn       CheckFunc
+}
+
+// Checker runs registered dependency checks to decide readiness.
+type Checker struct {
+	mu           sync.RWMutex
+	checks       []check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// NewChecker creates a Checker that bounds each check by timeout.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Register adds a dependency check. A failing critical check fails readiness;
+// a failing optional check only degrades it to warn.
+func (c *Checker) Register(name string, critical bool, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, check{name: name, critical: critical, fn: fn})
+}
+
+// SetShuttingDown makes every later readiness report fail so load balancers
+// stop routing new traffic before the server closes.
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Ready runs every check concurrently and aggregates the results.
+func (c *Checker) Ready(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]check(nil), c.checks...)
+	c.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(checks)+1)}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, chk := range checks {
+		wg.Add(1)
+		go func(chk check) {
+			defer wg.Done()
+			result := c.run(ctx, chk)
+
+			mu.Lock()
+			report.Checks[chk.name] = result
+			mu.Unlock()
+		}(chk)
+	}
+	wg.Wait()
+
+	if c.shuttingDown.Load() {
+		report.Checks["shutdown"] = Result{Status: StatusFail, Error: "server is shutting down"}
+	}
+
+	for _, result := range report.Checks {
+		report.Status = worst(report.Status, result.Status)
+	}
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, chk check) Result {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := chk.fn(ctx)
+	result := Result{
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Error = err.Error()
+		result.Status = StatusWarn
+		if chk.critical {
+			result.Status = StatusFail
+		}
+	}
+
+	return result
+}
+
+func worst(a, b Status) Status {
+	rank := map[Status]int{StatusOK: 0, StatusWarn: 1, StatusFail: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
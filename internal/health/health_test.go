@@ -0,0 +1,81 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReadyAllHealthy(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.Register("mongo", true, func(ctx context.Context) error { return nil })
+	c.Register("redis", false, func(ctx context.Context) error { return nil })
+
+	report := c.Ready(context.Background())
+	if report.Status != StatusOK {
+		t.Errorf("Expected ok, got %s", report.Status)
+	}
+	if len(report.Checks) != 2 {
+		t.Errorf("Expected 2 checks, got %d", len(report.Checks))
+	}
+	if report.Checks["mongo"].LatencyMs < 0 {
+		t.Error("Latency should be recorded")
+	}
+}
+
+func TestReadyOptionalFailureWarns(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.Register("mongo", true, func(ctx context.Context) error { return nil })
+	c.Register("redis", false, func(ctx context.Context) error { return errors.New("connection refused") })
+
+	report := c.Ready(context.Background())
+	if report.Status != StatusWarn {
+		t.Errorf("Expected warn, got %s", report.Status)
+	}
+	if report.Checks["redis"].Error != "connection refused" {
+		t.Errorf("Expected error to be reported, got %q", report.Checks["redis"].Error)
+	}
+}
+
+func TestReadyCriticalFailureFails(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.Register("mongo", true, func(ctx context.Context) error { return errors.New("no primary") })
+	c.Register("redis", false, func(ctx context.Context) error { return errors.New("connection refused") })
+
+	report := c.Ready(context.Background())
+	if report.Status != StatusFail {
+		t.Errorf("Expected fail, got %s", report.Status)
+	}
+}
+
+func TestReadyCheckTimeout(t *testing.T) {
+	c := NewChecker(20 * time.Millisecond)
+	c.Register("slow", true, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	start := time.Now()
+	report := c.Ready(context.Background())
+	if time.Since(start) > time.Second {
+		t.Error("Check should be bounded by the checker timeout")
+	}
+	if report.Checks["slow"].Status != StatusFail {
+		t.Errorf("Expected slow check to fail, got %s", report.Checks["slow"].Status)
+	}
+}
+
+func TestReadyShuttingDown(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.Register("mongo", true, func(ctx context.Context) error { return nil })
+	c.SetShuttingDown()
+
+	report := c.Ready(context.Background())
+	if report.Status != StatusFail {
+		t.Errorf("Expected fail while shutting down, got %s", report.Status)
+	}
+	if _, ok := report.Checks["shutdown"]; !ok {
+		t.Error("Expected shutdown entry in report")
+	}
+}
@@ -0,0 +1,129 @@
+// Package imaging turns uploaded photos into the resized JPEG variants served
+// with recipes. Every variant is decoded and encoded afresh, so EXIF and any
+// other metadata of the upload, such as GPS coordinates, never reach it; the
+// EXIF orientation is applied to the pixels first so photos stay upright.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	// ErrUnsupportedType is returned for data that is not a JPEG, PNG, GIF or WebP image.
+	ErrUnsupportedType = errors.New("unsupported image type")
+	// ErrTooLarge is returned for an image with more pixels than allowed.
+	ErrTooLarge = errors.New("image has too many pixels")
+)
+
+// supported maps the sniffed media types to the decoders' format names.
+var supported = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+// Size is a variant's name and the longest side it is scaled down to.
+type Size struct {
+	Name    string
+	MaxSide int
+}
+
+// Sizes are the variants made of every image, smallest first.
+var Sizes = []Size{
+	{Name: "thumbnail", MaxSide: 320},
+	{Name: "medium", MaxSide: 800},
+	{Name: "large", MaxSide: 1600},
+}
+
+// quality is the JPEG quality of the variants.
+const quality = 85
+
+// Variant is one resized copy of an image, encoded as JPEG.
+type Variant struct {
+	Name          string
+	Width, Height int
+	Data          []byte
+}
+
+// Result is a processed image.
+type Result struct {
+	// Width and Height are the upright size of the upload
+	Width, Height int
+	// Variants follow Sizes
+	Variants []Variant
+}
+
+// Sniff returns the media type of data, judged from its first bytes rather
+// than any name or header sent with it, or ErrUnsupportedType.
+func Sniff(data []byte) (string, error) {
+	mediaType := http.DetectContentType(data)
+	if _, ok := supported[mediaType]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedType, mediaType)
+	}
+	return mediaType, nil
+}
+
+// Process decodes an uploaded image and makes its variants. An image larger
+// than maxPixels, counted as width times height, is refused before it is
+// decoded; zero disables the check. Variants are never scaled up, and
+// transparent areas become white.
+func Process(data []byte, maxPixels int) (Result, error) {
+	mediaType, err := Sniff(data)
+	if err != nil {
+		return Result{}, err
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || format != supported[mediaType] {
+		return Result{}, fmt.Errorf("%w: cannot decode %s", ErrUnsupportedType, mediaType)
+	}
+	if maxPixels > 0 && cfg.Width*cfg.Height > maxPixels {
+		return Result{}, fmt.Errorf("%w: %dx%d is more than %d", ErrTooLarge, cfg.Width, cfg.Height, maxPixels)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: cannot decode %s", ErrUnsupportedType, mediaType)
+	}
+	if mediaType == "image/jpeg" {
+		src = orient(src, exifOrientation(data))
+	}
+
+	bounds := src.Bounds()
+	result := Result{Width: bounds.Dx(), Height: bounds.Dy()}
+	for _, size := range Sizes {
+		w, h := fit(bounds.Dx(), bounds.Dy(), size.MaxSide)
+		dst := image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: quality}); err != nil {
+			return Result{}, err
+		}
+		result.Variants = append(result.Variants, Variant{Name: size.Name, Width: w, Height: h, Data: buf.Bytes()})
+	}
+	return result, nil
+}
+
+// fit scales w×h down to fit within maxSide, keeping the aspect ratio.
+func fit(w, h, maxSide int) (int, int) {
+	if w <= maxSide && h <= maxSide {
+		return w, h
+	}
+	if w >= h {
+		return maxSide, max(1, h*maxSide/w)
+	}
+	return max(1, w*maxSide/h), maxSide
+}
@@ -0,0 +1,145 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// halves returns a w×h image, red on the left and blue on the right.
+func halves(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{255, 0, 0, 255}
+			if x >= w/2 {
+				c = color.RGBA{0, 0, 255, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// withExif inserts an APP1 segment holding an orientation tag and a camera
+// note after the start of a JPEG.
+func withExif(t *testing.T, jpg []byte, orientation uint16) []byte {
+	t.Helper()
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = append(tiff, 0x01, 0x12, 0x00, 0x03, 0, 0, 0, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, "GPS 51.5N 0.1W"...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, 0xFF, 0xE1)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(segment)+2))
+	out = append(out, segment...)
+	return append(out, jpg[2:]...)
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessOrientsAndStripsExif(t *testing.T) {
+	data := withExif(t, encodeJPEG(t, halves(40, 20)), 6)
+	if got := exifOrientation(data); got != 6 {
+		t.Fatalf("exifOrientation = %d, want 6", got)
+	}
+
+	result, err := Process(data, 0)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if result.Width != 20 || result.Height != 40 {
+		t.Errorf("expected the upright size 20x40, got %dx%d", result.Width, result.Height)
+	}
+	for _, v := range result.Variants {
+		if bytes.Contains(v.Data, []byte("Exif")) || bytes.Contains(v.Data, []byte("GPS")) {
+			t.Errorf("%s variant kept the EXIF data", v.Name)
+		}
+	}
+
+	// Turned clockwise, the red left half is on top.
+	thumb, err := jpeg.Decode(bytes.NewReader(result.Variants[0].Data))
+	if err != nil {
+		t.Fatalf("decode thumbnail: %v", err)
+	}
+	if r, _, b, _ := thumb.At(10, 5).RGBA(); r < b {
+		t.Errorf("expected red at the top, got r=%d b=%d", r, b)
+	}
+	if r, _, b, _ := thumb.At(10, 35).RGBA(); b < r {
+		t.Errorf("expected blue at the bottom, got r=%d b=%d", r, b)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 2×1: red, blue. Each orientation is checked by where red ends up.
+	src := halves(2, 1)
+	for orientation, want := range map[int]image.Point{
+		1: {0, 0}, 2: {1, 0}, 3: {1, 0}, 4: {0, 0},
+		5: {0, 0}, 6: {0, 0}, 7: {0, 1}, 8: {0, 1},
+	} {
+		img := orient(src, orientation)
+		if r, _, _, _ := img.At(want.X, want.Y).RGBA(); r != 0xFFFF {
+			t.Errorf("orientation %d: expected red at %v", orientation, want)
+		}
+		if orientation >= 5 && img.Bounds().Dx() != 1 {
+			t.Errorf("orientation %d: expected the sides swapped, got %v", orientation, img.Bounds())
+		}
+	}
+}
+
+func TestProcessVariants(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1000, 500))
+	var buf bytes.Buffer
+	png.Encode(&buf, img) // fully transparent
+
+	result, err := Process(buf.Bytes(), 0)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	want := []Variant{{Name: "thumbnail", Width: 320, Height: 160}, {Name: "medium", Width: 800, Height: 400}, {Name: "large", Width: 1000, Height: 500}}
+	for i, v := range result.Variants {
+		if v.Name != want[i].Name || v.Width != want[i].Width || v.Height != want[i].Height {
+			t.Errorf("variant %d = %s %dx%d, want %s %dx%d", i, v.Name, v.Width, v.Height, want[i].Name, want[i].Width, want[i].Height)
+		}
+		if mediaType, _ := Sniff(v.Data); mediaType != "image/jpeg" {
+			t.Errorf("%s variant is %s", v.Name, mediaType)
+		}
+	}
+
+	thumb, _ := jpeg.Decode(bytes.NewReader(result.Variants[0].Data))
+	if r, g, b, _ := thumb.At(100, 80).RGBA(); r < 0xF000 || g < 0xF000 || b < 0xF000 {
+		t.Errorf("expected transparency to become white, got %d %d %d", r, g, b)
+	}
+
+	if _, err := Process(buf.Bytes(), 1000*499); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestProcessRejectsOtherData(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"text":      []byte("not an image at all"),
+		"pdf":       []byte("%PDF-1.4\n"),
+		"truncated": encodeJPEG(t, halves(8, 8))[:20],
+	} {
+		if _, err := Process(data, 0); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("%s: expected ErrUnsupportedType, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gin-demo/recipes-web/internal/cooklang"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// IsCooklang reports whether path names a .cook file or a directory, the
+// inputs read by CooklangFiles.
+func IsCooklang(path string) bool {
+	if strings.EqualFold(filepath.Ext(path), ".cook") {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// CooklangFiles returns a Source reading a .cook file, or every .cook file
+// directly inside a directory in name order, one recipe per file. The ID of a
+// recipe is its "id" metadata, else a slug of the file name, so importing the
+// same files again updates the same recipes; the file name is also the name
+// of a recipe without a title.
+func CooklangFiles(path string) (Source, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return &cooklangSource{paths: []string{path}}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".cook") {
+			paths = append(paths, filepath.Join(path, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return &cooklangSource{paths: paths}, nil
+}
+
+type cooklangSource struct {
+	paths []string
+}
+
+func (s *cooklangSource) Next() (model.Recipe, error) {
+	if len(s.paths) == 0 {
+		return model.Recipe{}, io.EOF
+	}
+	path := s.paths[0]
+	s.paths = s.paths[1:]
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return model.Recipe{}, err
+	}
+	doc, err := cooklang.Parse(string(src))
+	if err != nil {
+		return model.Recipe{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	recipe := doc.ToRecipe(stem)
+	if strings.TrimSpace(string(recipe.ID)) == "" {
+		recipe.ID = model.RecipeID(slug(stem))
+	}
+	return recipe, nil
+}
+
+// slug lowercases s and joins its words with hyphens: "Garlic Bread" becomes
+// "garlic-bread".
+func slug(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
@@ -0,0 +1,450 @@
+// Package importer upserts recipes from any source into any repository that
+// can store recipes under their own IDs. Runs are idempotent: unchanged
+// recipes are skipped, so an import can be repeated safely.
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/normalize"
+	"github.com/gin-demo/recipes-web/internal/recipeio"
+	"github.com/gin-demo/recipes-web/internal/similar"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+const defaultBatchSize = 100
+
+// ErrUnsupported is returned for repositories that cannot upsert by ID.
+var ErrUnsupported = errors.New("repository cannot store recipes under their own IDs")
+
+// Source yields recipes until it returns io.EOF; recipeio.Decoder is one.
+type Source interface {
+	Next() (model.Recipe, error)
+}
+
+// Target is a repository the importer can write to.
+type Target interface {
+	GetByID(context.Context, model.RecipeID) (model.Recipe, error)
+	domain.RecipeUpserter
+}
+
+// Result counts what an import did, or would do in a dry run.
+type Result struct {
+	Created  int       `json:"created"`
+	Updated  int       `json:"updated"`
+	Skipped  int       `json:"skipped"`
+	Failed   int       `json:"failed"`
+	Failures []Failure `json:"failures,omitempty"`
+	// Duplicates are the records imported although they look like earlier
+	// records of the run
+	Duplicates []Duplicate `json:"duplicates,omitempty"`
+}
+
+// Processed is the number of records read so far.
+func (r Result) Processed() int {
+	return r.Created + r.Updated + r.Skipped + r.Failed
+}
+
+// Failure describes one record that was not imported.
+type Failure struct {
+	// Record is the 1-based position of the record in the source
+	Record int            `json:"record"`
+	ID     model.RecipeID `json:"id"`
+	Error  string         `json:"error"`
+}
+
+// Duplicate is a record suspected to be the same recipe as earlier records
+// of the run, most alike first.
+type Duplicate struct {
+	Record int                `json:"record"`
+	ID     model.RecipeID     `json:"id"`
+	Of     []domain.Duplicate `json:"of"`
+}
+
+// Importer streams recipes from a Source into a repository in batches.
+type Importer struct {
+	repo      Target
+	batch     domain.RecipeBatchUpserter
+	batchSize int
+	dryRun    bool
+	// createOnly skips records whose ID is already stored
+	createOnly bool
+	// images accepts the images records carry
+	images bool
+	// rejectDuplicates fails records looking like earlier ones
+	rejectDuplicates bool
+	normalize        func(model.Recipe) model.Recipe
+	validate         func(model.Recipe) error
+	progress         func(Result)
+}
+
+// Option configures optional Importer behaviour.
+type Option func(*Importer)
+
+// WithBatchSize sets how many recipes are written together.
+func WithBatchSize(n int) Option {
+	return func(imp *Importer) {
+		if n > 0 {
+			imp.batchSize = n
+		}
+	}
+}
+
+// WithDryRun makes the importer classify records without writing them.
+func WithDryRun(dryRun bool) Option {
+	return func(imp *Importer) {
+		imp.dryRun = dryRun
+	}
+}
+
+// WithCreateOnly makes the importer add only recipes whose ID is not stored
+// yet and skip the others, leaving edits made since the last run in place.
+func WithCreateOnly(createOnly bool) Option {
+	return func(imp *Importer) {
+		imp.createOnly = createOnly
+	}
+}
+
+// WithImages makes the importer keep the images records carry, as a copy
+// between backends sharing an image store needs. Otherwise a record with
+// images is rejected, since images are only added by uploading them.
+func WithImages(images bool) Option {
+	return func(imp *Importer) {
+		imp.images = images
+	}
+}
+
+// WithRejectDuplicates makes a record suspected to be the same recipe as an
+// earlier record of the run fail with a *domain.DuplicateError. Otherwise it
+// is imported and listed in Result.Duplicates. Records are compared as the
+// server compares new recipes, but only with the other records of the run.
+func WithRejectDuplicates(reject bool) Option {
+	return func(imp *Importer) {
+		imp.rejectDuplicates = reject
+	}
+}
+
+// WithValidator checks every normalized record with fn, such as
+// recipe.Validate with the server's limits, after the ID and name checks.
+// A record failing it is counted as failed.
+func WithValidator(fn func(model.Recipe) error) Option {
+	return func(imp *Importer) {
+		imp.validate = fn
+	}
+}
+
+// WithNormalizer replaces the clean-up applied to every record before it is
+// validated and compared.
+func WithNormalizer(fn func(model.Recipe) model.Recipe) Option {
+	return func(imp *Importer) {
+		imp.normalize = fn
+	}
+}
+
+// WithProgress registers a callback run after every batch with the running totals.
+func WithProgress(fn func(Result)) Option {
+	return func(imp *Importer) {
+		imp.progress = fn
+	}
+}
+
+// New creates an Importer writing to repo, which must implement
+// domain.RecipeUpserter. Repositories that also implement
+// domain.RecipeBatchUpserter get one write per batch.
+func New(repo domain.RecipeRepository, opts ...Option) (*Importer, error) {
+	target, ok := repo.(Target)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupported, repo)
+	}
+
+	imp := &Importer{
+		repo:      target,
+		batchSize: defaultBatchSize,
+		normalize: Clean,
+	}
+	imp.batch, _ = repo.(domain.RecipeBatchUpserter)
+	for _, opt := range opts {
+		opt(imp)
+	}
+	return imp, nil
+}
+
+// pending is a validated record waiting to be written.
+type pending struct {
+	record int
+	recipe model.Recipe
+	exists bool
+}
+
+// Run imports every recipe from src. Invalid records and records that fail to
+// write are counted and skipped; an error is returned only when src cannot be
+// read or ctx ends, together with the counts so far. Records looking like an
+// earlier record of the run are listed in Result.Duplicates, or fail with
+// WithRejectDuplicates.
+func (imp *Importer) Run(ctx context.Context, src Source) (Result, error) {
+	var (
+		result  Result
+		batch   []pending
+		inBatch = make(map[model.RecipeID]bool)
+		seen    = newRunRecords()
+	)
+
+	flush := func() {
+		if len(batch) > 0 {
+			imp.write(ctx, batch, &result)
+			batch = batch[:0]
+			clear(inBatch)
+		}
+		if imp.progress != nil {
+			imp.progress(result)
+		}
+	}
+
+	for record := 1; ; record++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		recipe, err := src.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			flush()
+			return result, err
+		}
+
+		recipe = imp.normalize(recipe)
+
+		// a repeated ID must see the earlier record's write when it is compared
+		if inBatch[recipe.ID] && !imp.dryRun {
+			flush()
+		}
+
+		p, skip, err := imp.prepare(ctx, record, recipe)
+		if err == nil {
+			err = imp.checkDuplicates(seen, record, recipe, &result)
+		}
+		switch {
+		case err != nil:
+			result.fail(record, recipe.ID, err)
+		case skip:
+			result.Skipped++
+		default:
+			batch = append(batch, p)
+			inBatch[recipe.ID] = true
+		}
+
+		if len(batch) >= imp.batchSize {
+			flush()
+		}
+	}
+
+	flush()
+	return result, nil
+}
+
+// prepare validates one normalized record and compares it with the stored
+// recipe. skip is set when nothing would change.
+func (imp *Importer) prepare(ctx context.Context, record int, recipe model.Recipe) (pending, bool, error) {
+	for _, problem := range recipeio.Check([]model.Recipe{recipe}) {
+		if problem.Severity == recipeio.SeverityError {
+			return pending{}, false, fmt.Errorf("%w: %s", domain.ErrInvalidInput, problem.Message)
+		}
+	}
+	if len(recipe.Images) > 0 && !imp.images {
+		return pending{}, false, fmt.Errorf("%w: images must not be set; upload them through the API", domain.ErrInvalidInput)
+	}
+	if imp.validate != nil {
+		if err := imp.validate(recipe); err != nil {
+			return pending{}, false, err
+		}
+	}
+
+	existing, err := imp.repo.GetByID(ctx, recipe.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		if recipe.PublishedAt.IsZero() {
+			recipe.PublishedAt = time.Now().UTC()
+		}
+		return pending{record: record, recipe: recipe}, false, nil
+	}
+	if err != nil {
+		return pending{}, false, err
+	}
+	if imp.createOnly {
+		return pending{}, true, nil
+	}
+
+	// a record without a date keeps the stored one, so reruns stay unchanged
+	if recipe.PublishedAt.IsZero() {
+		recipe.PublishedAt = existing.PublishedAt
+	}
+	// images are uploaded through the API, and a record replacing the recipe keeps them
+	if !imp.images {
+		recipe.Images = existing.Images
+	}
+	if Equal(existing, recipe) {
+		return pending{}, true, nil
+	}
+	return pending{record: record, recipe: recipe, exists: true}, false, nil
+}
+
+// runRecords are the records of a run accepted so far, compared with each
+// new record to find duplicates within the run.
+type runRecords struct {
+	batch *similar.Batch
+	// recipes are the ID and name of each record, in batch order
+	recipes []domain.Duplicate
+}
+
+func newRunRecords() *runRecords {
+	return &runRecords{batch: similar.NewBatch()}
+}
+
+// checkDuplicates compares a valid record with the accepted records of the
+// run, adding it to them unless it is rejected as a duplicate.
+func (imp *Importer) checkDuplicates(seen *runRecords, record int, recipe model.Recipe, result *Result) error {
+	matches := seen.batch.Duplicates(recipe)
+	if len(matches) > 0 {
+		dups := make([]domain.Duplicate, len(matches))
+		for i, m := range matches {
+			dups[i] = seen.recipes[m.Index]
+			dups[i].Score = m.Score
+		}
+		if imp.rejectDuplicates {
+			return &domain.DuplicateError{Duplicates: dups}
+		}
+		result.Duplicates = append(result.Duplicates, Duplicate{Record: record, ID: recipe.ID, Of: dups})
+	}
+	seen.batch.Add(recipe)
+	seen.recipes = append(seen.recipes, domain.Duplicate{ID: recipe.ID, Name: recipe.Name})
+	return nil
+}
+
+// write stores one batch, falling back to single upserts when the batch write
+// fails so a bad record only fails itself.
+func (imp *Importer) write(ctx context.Context, batch []pending, result *Result) {
+	if imp.dryRun {
+		for _, p := range batch {
+			result.count(p.exists)
+		}
+		return
+	}
+
+	if imp.batch != nil {
+		recipes := make([]model.Recipe, len(batch))
+		for i, p := range batch {
+			recipes[i] = p.recipe
+		}
+		if _, err := imp.batch.UpsertMany(ctx, recipes); err == nil {
+			for _, p := range batch {
+				result.count(p.exists)
+			}
+			return
+		}
+	}
+
+	for _, p := range batch {
+		created, err := imp.repo.Upsert(ctx, p.recipe)
+		if err != nil {
+			result.fail(p.record, p.recipe.ID, err)
+			continue
+		}
+		result.count(!created)
+	}
+}
+
+func (r *Result) count(existed bool) {
+	if existed {
+		r.Updated++
+	} else {
+		r.Created++
+	}
+}
+
+func (r *Result) fail(record int, id model.RecipeID, err error) {
+	r.Failed++
+	r.Failures = append(r.Failures, Failure{Record: record, ID: id, Error: err.Error()})
+}
+
+// Equal reports whether two recipes hold the same data. Publication times are
+// compared at millisecond precision, which is all MongoDB stores.
+func Equal(a, b model.Recipe) bool {
+	return a.ID == b.ID &&
+		a.Name == b.Name &&
+		slices.Equal(a.Tags, b.Tags) &&
+		slices.Equal(a.Ingredients, b.Ingredients) &&
+		slices.Equal(a.Instructions, b.Instructions) &&
+		slices.EqualFunc(a.Sections, b.Sections, sectionEqual) &&
+		a.Yield == b.Yield &&
+		a.PrepTime == b.PrepTime &&
+		a.CookTime == b.CookTime &&
+		a.TotalTime == b.TotalTime &&
+		a.Difficulty == b.Difficulty &&
+		a.Cuisine == b.Cuisine &&
+		a.Course == b.Course &&
+		slices.Equal(a.Equipment, b.Equipment) &&
+		slices.Equal(a.Diets, b.Diets) &&
+		a.PublishedAt.Truncate(time.Millisecond).Equal(b.PublishedAt.Truncate(time.Millisecond))
+}
+
+func sectionEqual(a, b model.InstructionSection) bool {
+	return a.Title == b.Title && slices.Equal(a.Steps, b.Steps)
+}
+
+// Clean trims the ID and applies normalize.Recipe, the same cleaning the
+// controller does on every create and update.
+func Clean(r model.Recipe) model.Recipe {
+	r.ID = model.RecipeID(strings.TrimSpace(string(r.ID)))
+	return normalize.Apply(r)
+}
+
+// FromSlice returns a Source over recipes already in memory.
+func FromSlice(recipes []model.Recipe) Source {
+	return &sliceSource{recipes: recipes}
+}
+
+type sliceSource struct {
+	recipes []model.Recipe
+}
+
+func (s *sliceSource) Next() (model.Recipe, error) {
+	if len(s.recipes) == 0 {
+		return model.Recipe{}, io.EOF
+	}
+	r := s.recipes[0]
+	s.recipes = s.recipes[1:]
+	return r, nil
+}
+
+// ImportFile imports a json, ndjson or csv file, picking the format from its
+// extension, or a .cook file or directory of them.
+func (imp *Importer) ImportFile(ctx context.Context, path string) (Result, error) {
+	if IsCooklang(path) {
+		src, err := CooklangFiles(path)
+		if err != nil {
+			return Result{}, err
+		}
+		return imp.Run(ctx, src)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return Result{}, err
+	}
+	defer file.Close()
+
+	dec, err := recipeio.NewDecoder(file, recipeio.FormatFromPath(path))
+	if err != nil {
+		return Result{}, err
+	}
+	return imp.Run(ctx, dec)
+}
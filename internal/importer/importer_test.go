@@ -0,0 +1,324 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+type mapRepo struct {
+	recipes    map[model.RecipeID]model.Recipe
+	batches    int
+	failBatch  bool
+	failUpsert model.RecipeID
+}
+
+func newMapRepo() *mapRepo {
+	return &mapRepo{recipes: make(map[model.RecipeID]model.Recipe)}
+}
+
+func (m *mapRepo) Create(ctx context.Context, r model.Recipe) (model.Recipe, error) {
+	return r, nil
+}
+
+func (m *mapRepo) GetByID(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+	r, ok := m.recipes[id]
+	if !ok {
+		return model.Recipe{}, domain.ErrNotFound
+	}
+	return r, nil
+}
+
+func (m *mapRepo) GetAll(ctx context.Context) ([]model.Recipe, error) { return nil, nil }
+
+func (m *mapRepo) Update(ctx context.Context, r model.Recipe) (model.Recipe, error) {
+	return r, nil
+}
+
+func (m *mapRepo) Delete(ctx context.Context, id model.RecipeID) error { return nil }
+
+func (m *mapRepo) GetByTag(ctx context.Context, tag string) ([]model.Recipe, error) {
+	return nil, nil
+}
+
+func (m *mapRepo) Upsert(ctx context.Context, r model.Recipe) (bool, error) {
+	if r.ID == m.failUpsert {
+		return false, domain.ErrPersistence
+	}
+	_, exists := m.recipes[r.ID]
+	m.recipes[r.ID] = r
+	return !exists, nil
+}
+
+type batchRepo struct {
+	*mapRepo
+}
+
+func (b batchRepo) UpsertMany(ctx context.Context, recipes []model.Recipe) (int, error) {
+	b.batches++
+	if b.failBatch {
+		return 0, domain.ErrPersistence
+	}
+	created := 0
+	for _, r := range recipes {
+		if _, exists := b.recipes[r.ID]; !exists {
+			created++
+		}
+		b.recipes[r.ID] = r
+	}
+	return created, nil
+}
+
+func recipe(id, name string) model.Recipe {
+	return model.Recipe{
+		ID:           model.RecipeID(id),
+		Name:         name,
+		Tags:         []string{"tag"},
+		Ingredients:  []string{"salt\r"},
+		Instructions: []string{" stir"},
+		PublishedAt:  time.Date(2024, 1, 1, 0, 0, 0, 123456789, time.UTC),
+	}
+}
+
+func TestRun_IsIdempotent(t *testing.T) {
+	repo := batchRepo{newMapRepo()}
+	imp, err := New(repo, WithBatchSize(2))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	input := []model.Recipe{recipe("a", "A"), recipe("b", "B"), recipe("c", "C"), recipe("", "no id")}
+	result, err := imp.Run(context.Background(), FromSlice(input))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Created != 3 || result.Failed != 1 || result.Failures[0].Record != 4 {
+		t.Fatalf("unexpected first result: %+v", result)
+	}
+	if repo.batches != 2 {
+		t.Errorf("expected 2 batch writes, got %d", repo.batches)
+	}
+	if got := repo.recipes["a"].Ingredients[0]; got != "salt" {
+		t.Errorf("expected ingredients to be trimmed, got %q", got)
+	}
+
+	changed := recipe("b", "B v2")
+	result, err = imp.Run(context.Background(), FromSlice([]model.Recipe{recipe("a", "A"), changed}))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Skipped != 1 || result.Updated != 1 || result.Created != 0 {
+		t.Fatalf("unexpected rerun result: %+v", result)
+	}
+}
+
+func TestRun_KeepsStoredDateWhenMissing(t *testing.T) {
+	repo := newMapRepo()
+	imp, _ := New(repo)
+	imp.Run(context.Background(), FromSlice([]model.Recipe{recipe("a", "A")}))
+
+	undated := recipe("a", "A")
+	undated.PublishedAt = time.Time{}
+	result, _ := imp.Run(context.Background(), FromSlice([]model.Recipe{undated}))
+	if result.Skipped != 1 {
+		t.Fatalf("expected the undated record to be unchanged, got %+v", result)
+	}
+}
+
+func TestRun_CreateOnlySkipsStored(t *testing.T) {
+	repo := newMapRepo()
+	repo.recipes["a"] = recipe("a", "Edited")
+	imp, _ := New(repo, WithCreateOnly(true))
+
+	result, err := imp.Run(context.Background(), FromSlice([]model.Recipe{recipe("a", "A"), recipe("b", "B")}))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Created != 1 || result.Skipped != 1 {
+		t.Fatalf("expected one creation and one skip, got %+v", result)
+	}
+	if repo.recipes["a"].Name != "Edited" {
+		t.Errorf("expected the stored recipe to be left alone, got %q", repo.recipes["a"].Name)
+	}
+}
+
+func TestRun_KeepsStoredImages(t *testing.T) {
+	repo := newMapRepo()
+	stored := recipe("a", "A")
+	stored.Images = []model.Image{{ID: "img1"}}
+	repo.recipes["a"] = stored
+	imp, _ := New(repo)
+
+	result, _ := imp.Run(context.Background(), FromSlice([]model.Recipe{recipe("a", "A v2")}))
+	if result.Updated != 1 {
+		t.Fatalf("expected an update, got %+v", result)
+	}
+	if images := repo.recipes["a"].Images; len(images) != 1 || images[0].ID != "img1" {
+		t.Errorf("expected the stored images to be kept, got %+v", images)
+	}
+}
+
+func TestRun_ValidatesRecords(t *testing.T) {
+	repo := newMapRepo()
+	imp, _ := New(repo, WithValidator(func(r model.Recipe) error {
+		if len(r.Tags) > 0 {
+			return domain.ErrInvalidInput
+		}
+		return nil
+	}))
+
+	withImages := recipe("b", "B")
+	withImages.Tags = nil
+	withImages.Images = []model.Image{{ID: "img1"}}
+	result, _ := imp.Run(context.Background(), FromSlice([]model.Recipe{recipe("a", "A"), withImages}))
+	if result.Failed != 2 || len(repo.recipes) != 0 {
+		t.Fatalf("expected both records to fail, got %+v", result)
+	}
+
+	imp, _ = New(repo, WithImages(true))
+	result, _ = imp.Run(context.Background(), FromSlice([]model.Recipe{withImages}))
+	if result.Created != 1 || len(repo.recipes["b"].Images) != 1 {
+		t.Fatalf("expected the copy to keep its images, got %+v", result)
+	}
+}
+
+func TestRun_FindsDuplicatesWithinRun(t *testing.T) {
+	cake := func(id, name string) model.Recipe {
+		r := recipe(id, name)
+		r.Ingredients = []string{"flour", "sugar", "cocoa"}
+		return r
+	}
+	input := []model.Recipe{cake("a", "Chocolate cake"), cake("b", "Chocolate cakes"), recipe("c", "Lemon tart"), cake("a", "Chocolate cake")}
+
+	repo := newMapRepo()
+	imp, _ := New(repo)
+	result, err := imp.Run(context.Background(), FromSlice(input))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Created != 3 || result.Skipped != 1 || len(result.Duplicates) != 2 {
+		t.Fatalf("expected every record imported and two duplicates reported, got %+v", result)
+	}
+	if d := result.Duplicates[0]; d.Record != 2 || d.ID != "b" || len(d.Of) != 1 || d.Of[0].ID != "a" {
+		t.Errorf("unexpected duplicate %+v", d)
+	}
+	// a repeated ID is the same recipe, not a duplicate of itself
+	if d := result.Duplicates[1]; d.Record != 4 || len(d.Of) != 1 || d.Of[0].ID != "b" {
+		t.Errorf("unexpected duplicate %+v", d)
+	}
+
+	repo = newMapRepo()
+	imp, _ = New(repo, WithRejectDuplicates(true))
+	result, _ = imp.Run(context.Background(), FromSlice(input))
+	if result.Created != 2 || result.Failed != 1 || result.Failures[0].Error != "recipe conflict: possible duplicate of a" {
+		t.Fatalf("expected the second cake to fail, got %+v", result)
+	}
+	if _, ok := repo.recipes["b"]; ok || result.Failures[0].ID != "b" {
+		t.Errorf("expected b not to be stored, got %+v", result.Failures)
+	}
+}
+
+func TestRun_DryRun(t *testing.T) {
+	repo := newMapRepo()
+	imp, _ := New(repo, WithDryRun(true))
+
+	result, err := imp.Run(context.Background(), FromSlice([]model.Recipe{recipe("a", "A"), recipe("b", "B")}))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Created != 2 || len(repo.recipes) != 0 {
+		t.Fatalf("expected 2 would-be creations and no writes, got %+v and %d stored", result, len(repo.recipes))
+	}
+}
+
+func TestRun_BatchFailureFallsBackToSingleWrites(t *testing.T) {
+	repo := batchRepo{newMapRepo()}
+	repo.failBatch = true
+	repo.failUpsert = "b"
+	imp, _ := New(repo)
+
+	result, err := imp.Run(context.Background(), FromSlice([]model.Recipe{recipe("a", "A"), recipe("b", "B")}))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Created != 1 || result.Failed != 1 || result.Failures[0].ID != "b" {
+		t.Fatalf("expected only the bad record to fail, got %+v", result)
+	}
+}
+
+type brokenSource struct{ sent bool }
+
+func (s *brokenSource) Next() (model.Recipe, error) {
+	if s.sent {
+		return model.Recipe{}, io.ErrUnexpectedEOF
+	}
+	s.sent = true
+	return recipe("a", "A"), nil
+}
+
+func TestRun_SourceErrorKeepsProgress(t *testing.T) {
+	repo := newMapRepo()
+	imp, _ := New(repo)
+
+	result, err := imp.Run(context.Background(), &brokenSource{})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected the source error, got %v", err)
+	}
+	if result.Created != 1 || len(repo.recipes) != 1 {
+		t.Fatalf("expected records before the error to be written, got %+v", result)
+	}
+}
+
+type readOnlyRepo struct{ domain.RecipeRepository }
+
+func TestNew_RequiresUpserter(t *testing.T) {
+	if _, err := New(readOnlyRepo{}); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestImportFile_CooklangDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Garlic Bread.cook": "Spread @butter{2%tbsp} and @garlic{2%cloves} on @bread{1%loaf}.\n\nBake for ~{10%minutes}.",
+		"soup.cook":         ">> id: tomato-soup\n>> title: Tomato Soup\n\nSimmer @tomatoes{6} in @stock{1%l}.",
+		"notes.txt":         "not a recipe",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := newMapRepo()
+	imp, err := New(repo)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	result, err := imp.ImportFile(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("ImportFile: %v", err)
+	}
+	if result.Created != 2 || result.Failed != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	bread, ok := repo.recipes["garlic-bread"]
+	if !ok || bread.Name != "Garlic Bread" || len(bread.Ingredients) != 3 || bread.Instructions[1] != "Bake for 10 minutes." {
+		t.Errorf("unexpected recipe from file name: %+v", bread)
+	}
+	if repo.recipes["tomato-soup"].Name != "Tomato Soup" {
+		t.Errorf("expected the id and title from metadata, got %+v", repo.recipes)
+	}
+
+	result, err = imp.ImportFile(context.Background(), filepath.Join(dir, "soup.cook"))
+	if err != nil || result.Skipped != 1 {
+		t.Errorf("expected a rerun of one file to be unchanged, got %+v, %v", result, err)
+	}
+}
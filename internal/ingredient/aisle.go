@@ -0,0 +1,89 @@
+package ingredient
+
+import "strings"
+
+// Aisles are the store sections ingredients are grouped under, in the order
+// a shopping trip usually passes them. Anything not recognized is "other".
+var Aisles = []string{
+	"produce", "meat and seafood", "dairy and eggs", "bakery", "pasta and grains",
+	"baking", "spices", "canned and jarred", "condiments and oils", "frozen", "other",
+}
+
+// aisleWords map the words of canonical names onto an aisle. A name is
+// looked up whole first and then word by word from the last, so "chicken
+// stock" is found under "stock" rather than "chicken".
+var aisleWords = map[string]string{
+	// produce
+	"apple": "produce", "arugula": "produce", "avocado": "produce", "banana": "produce",
+	"basil": "produce", "bean sprout": "produce", "bell pepper": "produce", "berry": "produce",
+	"blueberry": "produce", "broccoli": "produce", "cabbage": "produce", "carrot": "produce",
+	"cauliflower": "produce", "celery": "produce", "chili": "produce", "cilantro": "produce",
+	"cucumber": "produce", "eggplant": "produce", "garlic": "produce", "ginger": "produce",
+	"green onion": "produce", "herb": "produce", "kale": "produce", "leek": "produce",
+	"lemon": "produce", "lettuce": "produce", "lime": "produce", "mint": "produce",
+	"mushroom": "produce", "onion": "produce", "orange": "produce", "parsley": "produce",
+	"pear": "produce", "potato": "produce", "rosemary": "produce", "shallot": "produce",
+	"spinach": "produce", "squash": "produce", "strawberry": "produce", "thyme": "produce",
+	"tomato": "produce", "zucchini": "produce", "green bean": "produce",
+	// meat and seafood
+	"bacon": "meat and seafood", "beef": "meat and seafood", "chicken": "meat and seafood",
+	"fish": "meat and seafood", "ham": "meat and seafood", "lamb": "meat and seafood",
+	"pork": "meat and seafood", "prosciutto": "meat and seafood", "salmon": "meat and seafood",
+	"sausage": "meat and seafood", "shrimp": "meat and seafood", "steak": "meat and seafood",
+	"tuna": "meat and seafood", "turkey": "meat and seafood", "guanciale": "meat and seafood",
+	"pancetta": "meat and seafood", "thigh": "meat and seafood", "breast": "meat and seafood",
+	// dairy and eggs
+	"butter": "dairy and eggs", "buttermilk": "dairy and eggs", "cheddar": "dairy and eggs",
+	"cheese": "dairy and eggs", "cream": "dairy and eggs", "egg": "dairy and eggs",
+	"feta": "dairy and eggs", "heavy cream": "dairy and eggs", "milk": "dairy and eggs",
+	"mozzarella": "dairy and eggs", "parmesan": "dairy and eggs", "pecorino": "dairy and eggs",
+	"ricotta": "dairy and eggs", "sour cream": "dairy and eggs", "yogurt": "dairy and eggs",
+	"yolk": "dairy and eggs",
+	// bakery
+	"bagel": "bakery", "baguette": "bakery", "bread": "bakery", "breadcrumb": "bakery",
+	"bun": "bakery", "pita": "bakery", "tortilla": "bakery",
+	// pasta and grains
+	"couscous": "pasta and grains", "noodle": "pasta and grains", "oat": "pasta and grains",
+	"pasta": "pasta and grains", "quinoa": "pasta and grains", "rice": "pasta and grains",
+	"spaghetti": "pasta and grains", "penne": "pasta and grains", "lasagna": "pasta and grains",
+	// baking
+	"baking powder": "baking", "baking soda": "baking", "brown sugar": "baking",
+	"chocolate": "baking", "cocoa": "baking", "cornstarch": "baking", "flour": "baking",
+	"honey": "baking", "powdered sugar": "baking", "sugar": "baking", "vanilla": "baking",
+	"yeast": "baking", "chocolate chip": "baking",
+	// spices
+	"cardamom": "spices", "cayenne": "spices", "cinnamon": "spices",
+	"cumin": "spices", "curry": "spices", "nutmeg": "spices", "oregano": "spices",
+	"paprika": "spices", "peppercorn": "spices", "pepper flake": "spices", "salt": "spices",
+	"pepper": "spices", "turmeric": "spices", "garam masala": "spices", "bay leaf": "spices",
+	// canned and jarred
+	"bean": "canned and jarred", "broth": "canned and jarred", "chickpea": "canned and jarred",
+	"coconut milk": "canned and jarred", "lentil": "canned and jarred", "stock": "canned and jarred",
+	"tomato paste": "canned and jarred", "tomato sauce": "canned and jarred", "passata": "canned and jarred",
+	// condiments and oils
+	"ketchup": "condiments and oils", "mayonnaise": "condiments and oils", "mustard": "condiments and oils",
+	"oil": "condiments and oils", "soy sauce": "condiments and oils", "vinegar": "condiments and oils",
+	"fish sauce": "condiments and oils", "sesame oil": "condiments and oils", "olive oil": "condiments and oils",
+	"sauce": "condiments and oils", "syrup": "condiments and oils", "peanut butter": "condiments and oils",
+	// frozen
+	"ice cream": "frozen", "pea": "frozen",
+}
+
+// Aisle returns the store aisle of a name returned by Name, one of Aisles.
+func Aisle(name string) string {
+	if aisle, ok := aisleWords[name]; ok {
+		return aisle
+	}
+	words := strings.Fields(name)
+	for i := len(words) - 1; i >= 0; i-- {
+		if i > 0 {
+			if aisle, ok := aisleWords[words[i-1]+" "+words[i]]; ok {
+				return aisle
+			}
+		}
+		if aisle, ok := aisleWords[words[i]]; ok {
+			return aisle
+		}
+	}
+	return "other"
+}
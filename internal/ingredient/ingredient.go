@@ -0,0 +1,166 @@
+// Package ingredient reads the free-text ingredient lines of recipes: it
+// splits off quantities and units and reduces what is left to a canonical
+// name, so "3 scallions, thinly sliced" and "green onion" name the same thing.
+package ingredient
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// units are the words read as the unit of an ingredient line rather than
+// part of its name.
+var units = map[string]bool{
+	"g": true, "kg": true, "mg": true, "oz": true, "ounce": true, "ounces": true, "lb": true, "lbs": true,
+	"ml": true, "cl": true, "dl": true, "l": true,
+	"tsp": true, "tbsp": true, "teaspoon": true, "teaspoons": true, "tablespoon": true, "tablespoons": true,
+	"cup": true, "cups": true, "pint": true, "pints": true, "quart": true, "quarts": true,
+	"pinch": true, "pinches": true, "dash": true, "clove": true, "cloves": true,
+	"can": true, "cans": true, "slice": true, "slices": true, "bunch": true, "handful": true,
+}
+
+// leadingQuantity is the amount at the start of an ingredient line: "2",
+// "1.5", "1/2" or "1 1/2".
+var leadingQuantity = regexp.MustCompile(`^(\d+(?:[.,]\d+)?(?:\s+\d+/\d+|\s*/\s*\d+)?)\s+`)
+
+// Split reads "250 g tipo 00 flour, sifted" as quantity "250", unit "g",
+// name "tipo 00 flour" and note "sifted".
+func Split(line string) (quantity, unit, name, note string) {
+	name, note, _ = strings.Cut(strings.TrimSpace(line), ",")
+	name, note = strings.TrimSpace(name), strings.TrimSpace(note)
+	if m := leadingQuantity.FindStringSubmatch(name); m != nil {
+		quantity, name = m[1], name[len(m[0]):]
+		if word, rest, ok := strings.Cut(name, " "); ok && units[strings.ToLower(strings.TrimSuffix(word, "."))] {
+			unit, name = word, strings.TrimSpace(rest)
+		}
+	}
+	return quantity, unit, name, note
+}
+
+// descriptors are words describing how an ingredient is prepared or bought
+// rather than what it is.
+var descriptors = map[string]bool{
+	"about": true, "boneless": true, "chopped": true, "coarsely": true, "cold": true, "cooked": true,
+	"crushed": true, "cubed": true, "diced": true, "dried": true, "extra": true, "finely": true,
+	"fresh": true, "freshly": true, "frozen": true, "grated": true, "ground": true, "halved": true,
+	"heaping": true, "kosher": true, "large": true, "lean": true, "medium": true, "melted": true,
+	"minced": true, "optional": true, "organic": true, "packed": true, "peeled": true, "raw": true,
+	"ripe": true, "roughly": true, "shredded": true, "skinless": true, "sliced": true, "small": true,
+	"softened": true, "thinly": true, "unsalted": true, "virgin": true, "warm": true, "whole": true,
+}
+
+// trailing are phrases ending a name that say when or whether to use the
+// ingredient, as in "salt to taste".
+var trailing = []string{" to taste", " for serving", " for garnish", " to serve", " as needed"}
+
+// synonyms map names onto the one used for the same ingredient everywhere.
+// Keys and values are singular, as Name leaves them.
+var synonyms = map[string]string{
+	"all purpose flour":    "flour",
+	"aubergine":            "eggplant",
+	"bicarbonate of soda":  "baking soda",
+	"capsicum":             "bell pepper",
+	"caster sugar":         "superfine sugar",
+	"chick pea":            "chickpea",
+	"confectioners sugar":  "powdered sugar",
+	"coriander leaf":       "cilantro",
+	"courgette":            "zucchini",
+	"double cream":         "heavy cream",
+	"garbanzo bean":        "chickpea",
+	"icing sugar":          "powdered sugar",
+	"plain flour":          "flour",
+	"prawn":                "shrimp",
+	"rocket":               "arugula",
+	"scallion":             "green onion",
+	"spring onion":         "green onion",
+	"whipping cream":       "heavy cream",
+	"heavy whipping cream": "heavy cream",
+}
+
+// synonymKeys are the synonyms, longest first so "heavy whipping cream" is
+// replaced before "whipping cream".
+var synonymKeys = func() []string {
+	keys := make([]string, 0, len(synonyms))
+	for k := range synonyms {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if d := len(b) - len(a); d != 0 {
+			return d
+		}
+		return strings.Compare(a, b)
+	})
+	return keys
+}()
+
+// irregular are plurals the suffix rules get wrong.
+var irregular = map[string]string{
+	"cookies": "cookie", "halves": "half", "leaves": "leaf", "loaves": "loaf", "pies": "pie",
+	"knives": "knife", "molasses": "molasses", "hummus": "hummus", "couscous": "couscous",
+}
+
+// Name returns the canonical name of the ingredient on a line: lowercased,
+// without quantity, unit, notes, parentheses or preparation words, the last
+// word singular and synonyms replaced. It is empty when nothing is left.
+func Name(line string) string {
+	_, _, name, _ := Split(line)
+	name = strings.ToLower(name)
+	name = parenthetical.ReplaceAllString(markup.ReplaceAllString(name, " "), " ")
+	for _, suffix := range trailing {
+		name = strings.TrimSuffix(name, suffix)
+	}
+
+	words := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) })
+	words = slices.DeleteFunc(words, func(w string) bool { return descriptors[w] })
+	// Units Split left in place, as in "a pinch of salt", and the words
+	// joining them to the name go too.
+	for len(words) > 0 && (units[words[0]] || measures[words[0]] || filler[words[0]]) {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return ""
+	}
+	words[len(words)-1] = Singular(words[len(words)-1])
+
+	name = " " + strings.Join(words, " ") + " "
+	for _, k := range synonymKeys {
+		name = strings.ReplaceAll(name, " "+k+" ", " "+synonyms[k]+" ")
+	}
+	return strings.TrimSpace(name)
+}
+
+// measures are words counting an ingredient that Split does not take as
+// units, as in "1 1-inch piece ginger".
+var measures = map[string]bool{
+	"bag": true, "bottle": true, "box": true, "head": true, "inch": true, "jar": true,
+	"package": true, "packages": true, "piece": true, "pieces": true, "sprig": true, "sprigs": true,
+	"stick": true, "sticks": true,
+}
+
+var filler = map[string]bool{"a": true, "an": true, "of": true}
+
+var (
+	parenthetical = regexp.MustCompile(`\([^)]*\)`)
+	markup        = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Singular undoes the common English plural endings of a lowercase word.
+func Singular(word string) string {
+	if s, ok := irregular[word]; ok {
+		return s
+	}
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 4 && strings.HasSuffix(word, "oes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"),
+		strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"):
+		return word[:len(word)-2]
+	case len(word) > 3 && strings.HasSuffix(word, "s") &&
+		!strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		return word[:len(word)-1]
+	}
+	return word
+}
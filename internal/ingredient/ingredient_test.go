@@ -0,0 +1,143 @@
+package ingredient
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		line                       string
+		quantity, unit, name, note string
+	}{
+		{"250 g tipo 00 flour, sifted", "250", "g", "tipo 00 flour", "sifted"},
+		{"1 1/2 cups milk", "1 1/2", "cups", "milk", ""},
+		{"3 eggs", "3", "", "eggs", ""},
+		{"salt", "", "", "salt", ""},
+	}
+	for _, tt := range tests {
+		quantity, unit, name, note := Split(tt.line)
+		if quantity != tt.quantity || unit != tt.unit || name != tt.name || note != tt.note {
+			t.Errorf("Split(%q) = %q, %q, %q, %q", tt.line, quantity, unit, name, note)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := map[string]string{
+		"3 scallions, thinly sliced":              "green onion",
+		"Spring onions":                           "green onion",
+		"1 lb Yukon gold potatoes, thinly sliced": "yukon gold potato",
+		"2 large tomatoes (about 1 lb)":           "tomato",
+		"1 cup all-purpose flour":                 "flour",
+		"a pinch of salt":                         "salt",
+		"Salt to taste":                           "salt",
+		"2 tbsp extra-virgin olive oil":           "olive oil",
+		"1/2 cup fresh blueberries":               "blueberry",
+		"4 cloves garlic, minced":                 "garlic",
+		"1 can garbanzo beans":                    "chickpea",
+		"2 tsp confectioners' sugar":              "powdered sugar",
+		"1 cup couscous":                          "couscous",
+		"2 tbsp":                                  "",
+	}
+	for line, want := range tests {
+		if got := Name(line); got != want {
+			t.Errorf("Name(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestPantryCheck(t *testing.T) {
+	pantry := NewPantry([]string{"green onions", "Potatoes", "eggs", "yellow onion"}, []string{"olive oil"})
+	lines := []string{
+		"1 lb Yukon gold potatoes",
+		"3 scallions, sliced",
+		"2 eggs",
+		"Salt and pepper to taste",
+		"2 tbsp olive oil",
+		"1 cup water",
+		"1 cup chicken stock",
+		"1 onion",
+	}
+	c := pantry.Check(lines)
+	if c.Needed != 5 {
+		t.Errorf("Expected 5 needed ingredients, got %d", c.Needed)
+	}
+	// "onion" is covered by "yellow onion", the stock is not covered by anything.
+	if want := []string{"1 cup chicken stock"}; !slices.Equal(c.Missing, want) {
+		t.Errorf("Expected %v missing, got %v", want, c.Missing)
+	}
+}
+
+func TestAmount(t *testing.T) {
+	tests := map[string]float64{"2": 2, "1.5": 1.5, "1,5": 1.5, "1/2": 0.5, "1 1/2": 1.5, "3 / 4": 0.75}
+	for quantity, want := range tests {
+		if got, ok := Amount(quantity); !ok || got != want {
+			t.Errorf("Amount(%q) = %v, %v, want %v", quantity, got, ok, want)
+		}
+	}
+	for _, quantity := range []string{"", "a few", "1/0", "1 2 3"} {
+		if got, ok := Amount(quantity); ok {
+			t.Errorf("Amount(%q) = %v, want not ok", quantity, got)
+		}
+	}
+}
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		name     string
+		measures []Measure
+		want     string
+	}{
+		{"same unit", []Measure{{2, "cups"}, {1, "cup"}}, "3 cups"},
+		{"spoons into cups", []Measure{{2, "tbsp"}, {6, "teaspoons"}}, "1/4 cup"},
+		{"small volumes stay in spoons", []Measure{{1, "tsp"}, {1, "tsp"}}, "2 tsp"},
+		{"metric mass", []Measure{{600, "g"}, {0.5, "kg"}}, "1.1 kg"},
+		{"customary mass", []Measure{{8, "oz"}, {1, "lb"}}, "1 1/2 lb"},
+		{"mostly metric", []Measure{{250, "ml"}, {100, "ml"}, {1, "tbsp"}}, "364.79 ml"},
+		{"counts", []Measure{{2, ""}, {1, ""}}, "3"},
+		{"unlike counts and mass", []Measure{{2, "cloves"}, {1, ""}, {1, "clove"}, {10, "g"}}, "3 cloves; 1; 10 g"},
+		{"mass and volume", []Measure{{200, "g"}, {1, "cup"}}, "200 g; 1 cup"},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, m := range Sum(tt.measures) {
+			got = append(got, m.String())
+		}
+		if strings.Join(got, "; ") != tt.want {
+			t.Errorf("%s: Sum() = %q, want %q", tt.name, strings.Join(got, "; "), tt.want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	if got, ok := Convert(3, "tsp", "tbsp"); !ok || math.Abs(got-1) > 1e-9 {
+		t.Errorf("Convert(3 tsp, tbsp) = %v, %v, want 1", got, ok)
+	}
+	if _, ok := Convert(1, "cup", "g"); ok {
+		t.Error("Expected volume not to convert into mass")
+	}
+	if _, ok := Convert(1, "can", "clove"); ok {
+		t.Error("Expected counts of different things not to convert")
+	}
+}
+
+func TestAisle(t *testing.T) {
+	tests := map[string]string{
+		"green onion":    "produce",
+		"chicken stock":  "canned and jarred",
+		"chicken breast": "meat and seafood",
+		"bell pepper":    "produce",
+		"black pepper":   "spices",
+		"egg":            "dairy and eggs",
+		"flour":          "baking",
+		"dragon fruit":   "other",
+	}
+	for name, want := range tests {
+		if got := Aisle(name); got != want {
+			t.Errorf("Aisle(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package ingredient
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Dimension is what a unit measures. Amounts of the same dimension can be
+// added up whatever their units.
+type Dimension int
+
+const (
+	// Count is a number of things, such as "3 eggs" or "2 cloves"; counts
+	// only add up in the same unit
+	Count Dimension = iota
+	Mass
+	Volume
+)
+
+// unit is a canonical unit: its size in grams or millilitres for Mass and
+// Volume, and whether it belongs to the metric system.
+type unit struct {
+	name   string
+	plural string
+	dim    Dimension
+	size   float64
+	metric bool
+}
+
+var canonicalUnits = []unit{
+	{"mg", "mg", Mass, 0.001, true},
+	{"g", "g", Mass, 1, true},
+	{"kg", "kg", Mass, 1000, true},
+	{"oz", "oz", Mass, 28.349523125, false},
+	{"lb", "lb", Mass, 453.59237, false},
+	{"ml", "ml", Volume, 1, true},
+	{"cl", "cl", Volume, 10, true},
+	{"dl", "dl", Volume, 100, true},
+	{"l", "l", Volume, 1000, true},
+	{"tsp", "tsp", Volume, 4.92892159375, false},
+	{"tbsp", "tbsp", Volume, 14.78676478125, false},
+	{"cup", "cups", Volume, 236.5882365, false},
+	{"pint", "pints", Volume, 473.176473, false},
+	{"quart", "quarts", Volume, 946.352946, false},
+	{"pinch", "pinches", Count, 1, false},
+	{"dash", "dashes", Count, 1, false},
+	{"clove", "cloves", Count, 1, false},
+	{"can", "cans", Count, 1, false},
+	{"slice", "slices", Count, 1, false},
+	{"bunch", "bunches", Count, 1, false},
+	{"handful", "handfuls", Count, 1, false},
+}
+
+// unitAliases map every unit Split recognizes onto its canonical unit.
+var unitAliases = func() map[string]unit {
+	aliases := map[string]unit{}
+	for _, u := range canonicalUnits {
+		aliases[u.name] = u
+		aliases[u.plural] = u
+	}
+	for alias, name := range map[string]string{
+		"ounce": "oz", "ounces": "oz", "lbs": "lb",
+		"teaspoon": "tsp", "teaspoons": "tsp", "tablespoon": "tbsp", "tablespoons": "tbsp",
+	} {
+		aliases[alias] = aliases[name]
+	}
+	return aliases
+}()
+
+// lookupUnit returns the canonical unit of a unit returned by Split; the
+// empty unit is a plain count.
+func lookupUnit(name string) unit {
+	if u, ok := unitAliases[strings.ToLower(strings.TrimSuffix(name, "."))]; ok {
+		return u
+	}
+	return unit{dim: Count, size: 1}
+}
+
+// Amount reads a quantity returned by Split as a number: "2", "1.5", "1,5",
+// "1/2" or "1 1/2". ok is false for anything else.
+func Amount(quantity string) (float64, bool) {
+	quantity = strings.ReplaceAll(strings.ReplaceAll(quantity, ",", "."), " / ", "/")
+	total := 0.0
+	fields := strings.Fields(quantity)
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, false
+	}
+	for _, field := range fields {
+		num, den, isFraction := strings.Cut(field, "/")
+		n, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return 0, false
+		}
+		if isFraction {
+			d, err := strconv.ParseFloat(den, 64)
+			if err != nil || d == 0 {
+				return 0, false
+			}
+			n /= d
+		}
+		total += n
+	}
+	return total, true
+}
+
+// Measure is an amount of an ingredient in a unit; Unit is empty for a
+// plain count such as "3 eggs".
+type Measure struct {
+	Amount float64
+	Unit   string
+}
+
+// Dimension returns what the measure's unit measures.
+func (m Measure) Dimension() Dimension {
+	return lookupUnit(m.Unit).dim
+}
+
+// String formats the measure as "1 1/2 cups" or "250 g". Amounts in
+// customary units are written with the nearest common fraction.
+func (m Measure) String() string {
+	u := lookupUnit(m.Unit)
+	amount := formatAmount(m.Amount, u.metric)
+	switch {
+	case u.name == "":
+		return amount
+	case m.Amount > 1:
+		return amount + " " + u.plural
+	default:
+		return amount + " " + u.name
+	}
+}
+
+// fractions are those written out in customary amounts.
+var fractions = []struct {
+	value float64
+	text  string
+}{{1.0 / 8, "1/8"}, {1.0 / 4, "1/4"}, {1.0 / 3, "1/3"}, {1.0 / 2, "1/2"}, {2.0 / 3, "2/3"}, {3.0 / 4, "3/4"}}
+
+func formatAmount(amount float64, metric bool) string {
+	if !metric {
+		whole, frac := math.Modf(amount)
+		for _, f := range fractions {
+			if math.Abs(frac-f.value) < 0.02 {
+				if whole == 0 {
+					return f.text
+				}
+				return strconv.FormatFloat(whole, 'f', -1, 64) + " " + f.text
+			}
+		}
+	}
+	return strconv.FormatFloat(math.Round(amount*100)/100, 'f', -1, 64)
+}
+
+// Convert expresses amount in unit from as an amount in unit to. ok is false
+// when the units measure different dimensions or are counts of different things.
+func Convert(amount float64, from, to string) (float64, bool) {
+	f, t := lookupUnit(from), lookupUnit(to)
+	if f.dim != t.dim || (f.dim == Count && f.name != t.name) {
+		return 0, false
+	}
+	return amount * f.size / t.size, true
+}
+
+// Sum adds up measures, converting units where they measure the same
+// dimension, and returns one measure per dimension of mass and volume and
+// per unit of count, in the order first seen. Totals are expressed in a
+// readable unit of the system most of the measures use.
+func Sum(measures []Measure) []Measure {
+	type total struct {
+		dim    Dimension
+		unit   string
+		amount float64
+		metric int
+	}
+	var totals []*total
+	byKey := map[string]*total{}
+	for _, m := range measures {
+		u := lookupUnit(m.Unit)
+		key := "count:" + u.name
+		if u.dim != Count {
+			key = strconv.Itoa(int(u.dim))
+		}
+		t, ok := byKey[key]
+		if !ok {
+			t = &total{dim: u.dim, unit: u.name}
+			byKey[key] = t
+			totals = append(totals, t)
+		}
+		t.amount += m.Amount * u.size
+		if u.metric {
+			t.metric++
+		} else {
+			t.metric--
+		}
+	}
+
+	out := make([]Measure, len(totals))
+	for i, t := range totals {
+		out[i] = readable(t.amount, t.dim, t.unit, t.metric >= 0)
+	}
+	return out
+}
+
+// readable expresses amount, in grams, millilitres or units of a count, in
+// the unit of its dimension that keeps the number small but not fractional.
+func readable(amount float64, dim Dimension, count string, metric bool) Measure {
+	var steps []string
+	switch {
+	case dim == Count:
+		return Measure{Amount: amount, Unit: count}
+	case dim == Mass && metric:
+		steps = []string{"g", "kg"}
+	case dim == Mass:
+		steps = []string{"oz", "lb"}
+	case metric:
+		steps = []string{"ml", "l"}
+	default:
+		steps = []string{"tsp", "tbsp", "cup"}
+	}
+	name := steps[0]
+	for _, step := range steps[1:] {
+		// a quarter cup reads better than four tablespoons
+		threshold := 1.0
+		if step == "cup" {
+			threshold = 0.25
+		}
+		if amount/unitAliases[step].size >= threshold-1e-9 {
+			name = step
+		}
+	}
+	return Measure{Amount: amount / unitAliases[name].size, Unit: name}
+}
@@ -0,0 +1,97 @@
+package ingredient
+
+import (
+	"slices"
+	"strings"
+)
+
+// Staples are assumed to be in every kitchen and never count as missing.
+var Staples = []string{"salt", "pepper", "black pepper", "white pepper", "sea salt", "water", "ice", "cooking spray"}
+
+var staples = names(Staples)
+
+// IsStaple reports whether a name returned by Name is one of the Staples, or
+// staples joined as in "salt and pepper".
+func IsStaple(name string) bool {
+	return isStaple(name, staples)
+}
+
+func isStaple(name string, set map[string]bool) bool {
+	for _, sep := range []string{" and ", " or "} {
+		if parts := strings.Split(name, sep); len(parts) > 1 {
+			return !slices.ContainsFunc(parts, func(part string) bool { return !isStaple(part, set) })
+		}
+	}
+	return set[name]
+}
+
+// names is the set of the names of items.
+func names(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		if name := Name(item); name != "" {
+			set[name] = true
+		}
+	}
+	return set
+}
+
+// Pantry is the set of ingredients someone has at hand.
+type Pantry struct {
+	items   [][]string
+	staples map[string]bool
+}
+
+// NewPantry returns a pantry holding items, given as names or whole
+// ingredient lines. Staples and the names in ignore are taken as always there.
+func NewPantry(items, ignore []string) *Pantry {
+	p := &Pantry{staples: names(ignore)}
+	for name := range staples {
+		p.staples[name] = true
+	}
+	for _, item := range items {
+		if name := Name(item); name != "" {
+			p.items = append(p.items, strings.Fields(name))
+		}
+	}
+	return p
+}
+
+// Coverage is how much of a recipe's ingredient list a pantry covers.
+type Coverage struct {
+	// Needed counts the ingredient lines that are not staples
+	Needed int
+	// Missing are the lines the pantry lacks, as written but trimmed
+	Missing []string
+}
+
+// Check compares a recipe's ingredient lines against the pantry. Lines that
+// are staples, or have no recognizable name, are left out of Needed.
+func (p *Pantry) Check(lines []string) Coverage {
+	var c Coverage
+	for _, line := range lines {
+		name := Name(line)
+		if name == "" || isStaple(name, p.staples) {
+			continue
+		}
+		c.Needed++
+		if !p.has(strings.Fields(name)) {
+			c.Missing = append(c.Missing, strings.TrimSpace(line))
+		}
+	}
+	return c
+}
+
+// has reports whether an item in the pantry names the ingredient. A more
+// general name covers a more specific one and the other way around, compared
+// by trailing words: "potato" and "yukon gold potato" match, "chicken" and
+// "chicken stock" do not.
+func (p *Pantry) has(words []string) bool {
+	return slices.ContainsFunc(p.items, func(item []string) bool {
+		return hasSuffix(words, item) || hasSuffix(item, words)
+	})
+}
+
+func hasSuffix(words, suffix []string) bool {
+	return len(suffix) <= len(words) && slices.Equal(words[len(words)-len(suffix):], suffix)
+}
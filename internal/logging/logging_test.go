@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewAddsContextAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{Format: FormatJSON})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	ctx := WithUserName(WithRequestID(context.Background(), "req-1"), "alice")
+	logger.InfoContext(ctx, "hello")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("Expected JSON output, got %q", buf.String())
+	}
+	if line["requestId"] != "req-1" {
+		t.Errorf("Expected requestId req-1, got %v", line["requestId"])
+	}
+	if line["userName"] != "alice" {
+		t.Errorf("Expected userName alice, got %v", line["userName"])
+	}
+}
+
+func TestNewRedactsSensitiveAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := New(&buf, Config{Format: FormatText})
+
+	logger.Info("signin", "password", "hunter2", slog.Group("request", "Authorization", "Bearer abc"))
+
+	out := buf.String()
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "Bearer abc") {
+		t.Errorf("Expected secrets to be redacted, got %q", out)
+	}
+	if !strings.Contains(out, Redacted) {
+		t.Errorf("Expected redaction marker, got %q", out)
+	}
+}
+
+func TestNewLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := New(&buf, Config{Level: "warn"})
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), "shown") {
+		t.Errorf("Expected only warn records, got %q", buf.String())
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, Config{Format: "xml"}); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+	if _, err := New(&bytes.Buffer{}, Config{Level: "loud"}); err == nil {
+		t.Error("Expected error for unknown level")
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer abc")
+	h.Set("User-Agent", "curl")
+
+	out := RedactHeaders(h)
+	if out["Authorization"] != Redacted {
+		t.Errorf("Expected Authorization redacted, got %q", out["Authorization"])
+	}
+	if out["User-Agent"] != "curl" {
+		t.Errorf("Expected User-Agent kept, got %q", out["User-Agent"])
+	}
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "recipes"
+
+// Metrics holds the Prometheus collectors shared by the HTTP, repository and cache layers.
+type Metrics struct {
+	HTTPRequests *prometheus.CounterVec
+	HTTPDuration *prometheus.HistogramVec
+	RepoDuration *prometheus.HistogramVec
+	RepoErrors   *prometheus.CounterVec
+	AuthFailures *prometheus.CounterVec
+
+	registerer prometheus.Registerer
+}
+
+// New creates the collectors and registers them with reg.
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		HTTPRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		HTTPDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		RepoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "operation_duration_seconds",
+			Help:      "Recipe repository operation latency by backend and operation.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"backend", "operation"}),
+		RepoErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "errors_total",
+			Help:      "Recipe repository errors by backend, operation and kind.",
+		}, []string{"backend", "operation", "kind"}),
+		AuthFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "failures_total",
+			Help:      "Requests rejected by the auth middleware by reason.",
+		}, []string{"reason"}),
+		registerer: reg,
+	}
+
+	reg.MustRegister(m.HTTPRequests, m.HTTPDuration, m.RepoDuration, m.RepoErrors, m.AuthFailures)
+	return m
+}
+
+// CacheCounters exposes cumulative cache lookup outcomes.
+type CacheCounters interface {
+	CacheCounters() (hits, misses, errors int64)
+}
+
+// RegisterCache exports the lookup counters of a cache as
+// recipes_cache_requests_total{result="hit|miss|error"}.
+func (m *Metrics) RegisterCache(source CacheCounters) {
+	m.registerer.MustRegister(&cacheCollector{
+		source: source,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cache", "requests_total"),
+			"Recipe cache lookups by result.",
+			[]string{"result"}, nil,
+		),
+	})
+}
+
+// cacheCollector reads counters maintained by the cache at scrape time, so
+// the cache itself does not depend on Prometheus.
+type cacheCollector struct {
+	source CacheCounters
+	desc   *prometheus.Desc
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	hits, misses, errors := c.source.CacheCounters()
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, float64(hits), "hit")
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, float64(misses), "miss")
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, float64(errors), "error")
+}
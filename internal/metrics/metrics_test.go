@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeCounters struct{ hits, misses, errors int64 }
+
+func (f *fakeCounters) CacheCounters() (int64, int64, int64) {
+	return f.hits, f.misses, f.errors
+}
+
+func TestNewRegistersCollectors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := New(reg)
+
+	m.HTTPRequests.WithLabelValues("GET", "/recipes/:id", "200").Inc()
+	m.AuthFailures.WithLabelValues("invalid_token").Inc()
+
+	if got := testutil.ToFloat64(m.HTTPRequests.WithLabelValues("GET", "/recipes/:id", "200")); got != 1 {
+		t.Errorf("Expected 1 request, got %v", got)
+	}
+
+	count, err := testutil.GatherAndCount(reg, "recipes_http_requests_total", "recipes_auth_failures_total")
+	if err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 series, got %d", count)
+	}
+}
+
+func TestRegisterCache(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := New(reg)
+	source := &fakeCounters{hits: 7, misses: 2, errors: 1}
+	m.RegisterCache(source)
+
+	expected := `
+# HELP recipes_cache_requests_total Recipe cache lookups by result.
+# TYPE recipes_cache_requests_total counter
+recipes_cache_requests_total{result="error"} 1
+recipes_cache_requests_total{result="hit"} 7
+recipes_cache_requests_total{result="miss"} 2
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "recipes_cache_requests_total"); err != nil {
+		t.Error(err)
+	}
+
+	// Values are read at scrape time
+	source.hits = 8
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(strings.Replace(expected, "} 7", "} 8", 1)), "recipes_cache_requests_total"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,247 @@
+// Package normalize cleans the text fields of recipes and reports every edit it
+// makes, so the same rules can run on each write and as a one-off migration.
+package normalize
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// Change is one edit made to a recipe.
+type Change struct {
+	// Field is the path of the edited value, e.g. ingredients[2] or sections
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	// After is empty when the value was removed
+	After string `json:"after"`
+}
+
+func (c Change) String() string {
+	if c.After == "" {
+		return fmt.Sprintf("%s: removed %q", c.Field, c.Before)
+	}
+	return fmt.Sprintf("%s: %q -> %q", c.Field, c.Before, c.After)
+}
+
+var (
+	// lineBreak separates paragraphs inside one instruction step
+	lineBreak = regexp.MustCompile(`[\r\n]+`)
+	// heading matches a short label such as "To cook the chicken:" opening a paragraph
+	heading = regexp.MustCompile(`^([A-Z][\w '&/-]{2,40}):\s*(.*)$`)
+)
+
+// maxHeadingWords keeps sentences that merely contain a colon from becoming headings.
+const maxHeadingWords = 6
+
+// Apply returns the normalized recipe, discarding the change list.
+func Apply(r model.Recipe) model.Recipe {
+	r, _ = Recipe(r)
+	return r
+}
+
+// Recipe trims whitespace and control characters from every text field, drops
+// empty entries, lowercases difficulty, cuisine and course, lowercases and
+// de-duplicates tags and diets, and splits section headings embedded in the
+// instructions into r.Sections. Normalizing twice changes nothing the second time.
+func Recipe(r model.Recipe) (model.Recipe, []Change) {
+	var changes []Change
+	record := func(field, before, after string) {
+		if before != after {
+			changes = append(changes, Change{Field: field, Before: before, After: after})
+		}
+	}
+
+	name := Text(r.Name)
+	record("name", r.Name, name)
+	r.Name = name
+
+	yield := Text(r.Yield)
+	record("yield", r.Yield, yield)
+	r.Yield = yield
+
+	for _, d := range []struct {
+		field string
+		value *string
+	}{{"prepTime", &r.PrepTime}, {"cookTime", &r.CookTime}, {"totalTime", &r.TotalTime}} {
+		clean := Duration(*d.value)
+		record(d.field, *d.value, clean)
+		*d.value = clean
+	}
+
+	for _, k := range []struct {
+		field string
+		value *string
+	}{{"difficulty", &r.Difficulty}, {"cuisine", &r.Cuisine}, {"course", &r.Course}} {
+		clean := strings.ToLower(Text(*k.value))
+		record(k.field, *k.value, clean)
+		*k.value = clean
+	}
+
+	r.Ingredients = cleanList("ingredients", r.Ingredients, record)
+	r.Equipment = cleanList("equipment", r.Equipment, record)
+	r.Tags = cleanKeywords("tags", r.Tags, record)
+	r.Diets = cleanKeywords("diets", r.Diets, record)
+
+	if len(r.Sections) > 0 {
+		r.Sections, r.Instructions = cleanSections(r.Sections, r.Instructions, record)
+	} else {
+		r.Sections, r.Instructions = splitSections(r.Instructions, record)
+	}
+
+	return r, changes
+}
+
+// Text replaces control characters with spaces, collapses runs of whitespace
+// and trims the result.
+func Text(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Duration rewrites an ISO 8601 duration in canonical form, so "pt90m" becomes
+// "PT1H30M". Anything that does not parse is only trimmed, for validation to report.
+func Duration(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	d, err := model.ParseDuration(s)
+	if err != nil {
+		return s
+	}
+	return model.FormatDuration(d)
+}
+
+func cleanList(field string, items []string, record func(field, before, after string)) []string {
+	out := make([]string, 0, len(items))
+	for i, item := range items {
+		clean := Text(item)
+		record(fmt.Sprintf("%s[%d]", field, i), item, clean)
+		if clean != "" {
+			out = append(out, clean)
+		}
+	}
+	return out
+}
+
+// cleanKeywords lowercases and de-duplicates a list of keywords such as tags.
+func cleanKeywords(field string, keywords []string, record func(field, before, after string)) []string {
+	out := make([]string, 0, len(keywords))
+	seen := make(map[string]bool, len(keywords))
+	for i, keyword := range keywords {
+		clean := strings.ToLower(Text(keyword))
+		if seen[clean] {
+			clean = ""
+		}
+		record(fmt.Sprintf("%s[%d]", field, i), keyword, clean)
+		if clean != "" {
+			seen[clean] = true
+			out = append(out, clean)
+		}
+	}
+	return out
+}
+
+// cleanSections tidies existing sections and rebuilds the flat step list from them.
+func cleanSections(sections []model.InstructionSection, instructions []string, record func(field, before, after string)) ([]model.InstructionSection, []string) {
+	out := make([]model.InstructionSection, 0, len(sections))
+	var steps []string
+	for i, section := range sections {
+		title := Text(section.Title)
+		record(fmt.Sprintf("sections[%d].title", i), section.Title, title)
+		section.Title = title
+		section.Steps = cleanList(fmt.Sprintf("sections[%d].steps", i), section.Steps, record)
+		if section.Title == "" && len(section.Steps) == 0 {
+			continue
+		}
+		out = append(out, section)
+		steps = append(steps, section.Steps...)
+	}
+	if steps == nil {
+		steps = []string{}
+	}
+
+	record("instructions", strings.Join(instructions, " | "), strings.Join(steps, " | "))
+	return out, steps
+}
+
+// splitSections cleans each step and splits it at line breaks. A paragraph
+// that opens with a heading starts a titled section; once any heading is
+// found, every paragraph break starts a new section.
+func splitSections(instructions []string, record func(field, before, after string)) ([]model.InstructionSection, []string) {
+	var (
+		sections = []model.InstructionSection{{}}
+		steps    = []string{}
+		titled   bool
+	)
+
+	for i, step := range instructions {
+		var produced []string
+		for p, paragraph := range lineBreak.Split(step, -1) {
+			text := Text(paragraph)
+			if text == "" {
+				continue
+			}
+
+			if p > 0 || i == 0 {
+				// a heading on a line of its own keeps the paragraph that follows it
+				title, rest, isHeading := splitHeading(text)
+				current := &sections[len(sections)-1]
+				if len(current.Steps) > 0 || (isHeading && current.Title != "") {
+					sections = append(sections, model.InstructionSection{})
+				}
+				if isHeading {
+					sections[len(sections)-1].Title = title
+					titled = true
+					produced = append(produced, title+":")
+					if text = rest; text == "" {
+						continue
+					}
+				}
+			}
+
+			current := &sections[len(sections)-1]
+			current.Steps = append(current.Steps, text)
+			steps = append(steps, text)
+			produced = append(produced, text)
+		}
+		record(fmt.Sprintf("instructions[%d]", i), step, strings.Join(produced, " | "))
+	}
+
+	if !titled {
+		return nil, steps
+	}
+
+	out := sections[:0]
+	titles := make([]string, 0, len(sections))
+	for _, section := range sections {
+		if section.Title == "" && len(section.Steps) == 0 {
+			continue
+		}
+		if section.Steps == nil {
+			section.Steps = []string{}
+		}
+		out = append(out, section)
+		titles = append(titles, section.Title)
+	}
+	record("sections", "", fmt.Sprintf("%d sections: %s", len(out), strings.Join(titles, "; ")))
+	return out, steps
+}
+
+// splitHeading separates a leading "Heading: rest" label from a paragraph.
+func splitHeading(text string) (title, rest string, ok bool) {
+	m := heading.FindStringSubmatch(text)
+	if m == nil || len(strings.Fields(m[1])) > maxHeadingWords {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
@@ -0,0 +1,156 @@
+package normalize
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+func TestRecipeCleansText(t *testing.T) {
+	r, changes := Recipe(model.Recipe{
+		Name:         "  Chicken\t\tCurry \x00",
+		Tags:         []string{" Indian", "indian ", "", "MAIN course"},
+		Ingredients:  []string{"1  onion", "   ", "2 cloves garlic"},
+		Instructions: []string{"Chop the onion.", " "},
+	})
+
+	if r.Name != "Chicken Curry" {
+		t.Errorf("Name = %q", r.Name)
+	}
+	if want := []string{"indian", "main course"}; !slices.Equal(r.Tags, want) {
+		t.Errorf("Tags = %q, want %q", r.Tags, want)
+	}
+	if want := []string{"1 onion", "2 cloves garlic"}; !slices.Equal(r.Ingredients, want) {
+		t.Errorf("Ingredients = %q, want %q", r.Ingredients, want)
+	}
+	if want := []string{"Chop the onion."}; !slices.Equal(r.Instructions, want) {
+		t.Errorf("Instructions = %q, want %q", r.Instructions, want)
+	}
+	if r.Sections != nil {
+		t.Errorf("Sections = %v, want none without headings", r.Sections)
+	}
+	if len(changes) == 0 {
+		t.Fatal("expected changes to be reported")
+	}
+	if changes[0].Field != "name" || changes[0].After != "Chicken Curry" {
+		t.Errorf("first change = %+v", changes[0])
+	}
+}
+
+func TestRecipeSplitsSections(t *testing.T) {
+	r, _ := Recipe(model.Recipe{
+		Instructions: []string{
+			"To marinate the chicken: Mix the yoghurt and spices.",
+			"Leave for an hour.\r\n\r\nTo cook the chicken: Heat the oil.",
+			"Fry until golden.\r\n\r\nServe with rice.",
+		},
+	})
+
+	wantSteps := []string{"Mix the yoghurt and spices.", "Leave for an hour.", "Heat the oil.", "Fry until golden.", "Serve with rice."}
+	if !slices.Equal(r.Instructions, wantSteps) {
+		t.Errorf("Instructions = %q, want %q", r.Instructions, wantSteps)
+	}
+	if len(r.Sections) != 3 {
+		t.Fatalf("got %d sections, want 3: %+v", len(r.Sections), r.Sections)
+	}
+	if r.Sections[0].Title != "To marinate the chicken" || len(r.Sections[0].Steps) != 2 {
+		t.Errorf("section 0 = %+v", r.Sections[0])
+	}
+	if r.Sections[1].Title != "To cook the chicken" || len(r.Sections[1].Steps) != 2 {
+		t.Errorf("section 1 = %+v", r.Sections[1])
+	}
+	if r.Sections[2].Title != "" || !slices.Equal(r.Sections[2].Steps, []string{"Serve with rice."}) {
+		t.Errorf("section 2 = %+v", r.Sections[2])
+	}
+}
+
+func TestRecipeKeepsSentencesWithColons(t *testing.T) {
+	r, _ := Recipe(model.Recipe{
+		Instructions: []string{"Whisk the following together until smooth and glossy: eggs, sugar and butter."},
+	})
+	if r.Sections != nil {
+		t.Errorf("Sections = %+v, want none", r.Sections)
+	}
+}
+
+func TestRecipeIsIdempotent(t *testing.T) {
+	once, _ := Recipe(model.Recipe{
+		Name:         " Stew ",
+		Tags:         []string{"Winter"},
+		Ingredients:  []string{"beef"},
+		Instructions: []string{"For the stew: Brown the beef.\nSimmer.\n\nFor the dumplings: Mix flour and suet."},
+	})
+	twice, changes := Recipe(once)
+	if len(changes) != 0 {
+		t.Errorf("second pass reported changes: %v", changes)
+	}
+	if !slices.Equal(once.Instructions, twice.Instructions) || len(twice.Sections) != len(once.Sections) {
+		t.Errorf("second pass changed the recipe: %+v -> %+v", once, twice)
+	}
+}
+
+func TestRecipeRebuildsInstructionsFromSections(t *testing.T) {
+	r, _ := Recipe(model.Recipe{
+		Instructions: []string{"stale"},
+		Sections: []model.InstructionSection{
+			{Title: " Sauce ", Steps: []string{"Melt butter.", " "}},
+			{Steps: nil},
+			{Title: "Pasta", Steps: []string{"Boil  water."}},
+		},
+	})
+	if want := []string{"Melt butter.", "Boil water."}; !slices.Equal(r.Instructions, want) {
+		t.Errorf("Instructions = %q, want %q", r.Instructions, want)
+	}
+	if len(r.Sections) != 2 || r.Sections[0].Title != "Sauce" {
+		t.Errorf("Sections = %+v", r.Sections)
+	}
+}
+
+func TestRecipeHeadingOnItsOwnLine(t *testing.T) {
+	r, _ := Recipe(model.Recipe{
+		Instructions: []string{"Prepare the ingredients:\r\nPeel the plantain", "Slice the shallot\r\n\r\nCook the plantain:\r\nHeat the oil"},
+	})
+	if len(r.Sections) != 2 {
+		t.Fatalf("got %d sections, want 2: %+v", len(r.Sections), r.Sections)
+	}
+	if r.Sections[0].Title != "Prepare the ingredients" || len(r.Sections[0].Steps) != 2 {
+		t.Errorf("section 0 = %+v", r.Sections[0])
+	}
+	if r.Sections[1].Title != "Cook the plantain" || !slices.Equal(r.Sections[1].Steps, []string{"Heat the oil"}) {
+		t.Errorf("section 1 = %+v", r.Sections[1])
+	}
+}
+
+func TestRecipeCanonicalizesTimes(t *testing.T) {
+	r, _ := Recipe(model.Recipe{Yield: " 4  servings", PrepTime: "pt90m", CookTime: " 20 minutes ", TotalTime: "P0DT0H45M"})
+
+	if r.Yield != "4 servings" {
+		t.Errorf("Yield = %q", r.Yield)
+	}
+	if r.PrepTime != "PT1H30M" || r.TotalTime != "PT45M" {
+		t.Errorf("PrepTime = %q, TotalTime = %q", r.PrepTime, r.TotalTime)
+	}
+	if r.CookTime != "20 minutes" {
+		t.Errorf("CookTime = %q, want it trimmed and left for validation", r.CookTime)
+	}
+}
+
+func TestRecipeCleansMetadata(t *testing.T) {
+	r, _ := Recipe(model.Recipe{
+		Difficulty: " Easy",
+		Cuisine:    "Indian ",
+		Course:     "MAIN",
+		Equipment:  []string{" Stand  mixer", ""},
+		Diets:      []string{"Vegan", "vegan", " gluten-free"},
+	})
+	if r.Difficulty != "easy" || r.Cuisine != "indian" || r.Course != "main" {
+		t.Errorf("got difficulty %q, cuisine %q, course %q", r.Difficulty, r.Cuisine, r.Course)
+	}
+	if want := []string{"Stand mixer"}; !slices.Equal(r.Equipment, want) {
+		t.Errorf("Equipment = %q, want %q", r.Equipment, want)
+	}
+	if want := []string{"vegan", "gluten-free"}; !slices.Equal(r.Diets, want) {
+		t.Errorf("Diets = %q, want %q", r.Diets, want)
+	}
+}
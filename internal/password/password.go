@@ -0,0 +1,34 @@
+// Package password hashes and checks user passwords.
+package password
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrMismatch is returned when a password does not match its hash.
+var ErrMismatch = errors.New("password mismatch")
+
+// MinLength is the shortest password accepted for new accounts.
+const MinLength = 8
+
+// Hash returns a bcrypt hash of plain.
+func Hash(plain string) (string, error) {
+	if len(plain) < MinLength {
+		return "", errors.New("password must be at least 8 characters")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Check reports ErrMismatch unless plain matches hash.
+func Check(hash, plain string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain)); err != nil {
+		return ErrMismatch
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package password
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHashAndCheck(t *testing.T) {
+	hash, err := Hash("correct horse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hash == "correct horse" {
+		t.Fatal("expected the password to be hashed")
+	}
+
+	if err := Check(hash, "correct horse"); err != nil {
+		t.Errorf("expected match, got %v", err)
+	}
+	if err := Check(hash, "wrong horse"); !errors.Is(err, ErrMismatch) {
+		t.Errorf("expected ErrMismatch, got %v", err)
+	}
+}
+
+func TestHashRejectsShortPasswords(t *testing.T) {
+	if _, err := Hash("short"); err == nil {
+		t.Fatal("expected an error for a short password")
+	}
+}
@@ -0,0 +1,138 @@
+package pdf
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Font is one of the standard PDF fonts every viewer has.
+type Font int
+
+// Fonts.
+const (
+	Helvetica Font = iota
+	HelveticaBold
+	HelveticaOblique
+
+	fontCount
+)
+
+func (f Font) name() string {
+	switch f {
+	case HelveticaBold:
+		return "Helvetica-Bold"
+	case HelveticaOblique:
+		return "Helvetica-Oblique"
+	}
+	return "Helvetica"
+}
+
+// Glyph widths of the printable ASCII characters, space to tilde, in
+// thousandths of the font size, from the Adobe font metrics. The oblique
+// face has the regular widths.
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// winAnsi maps the characters of Windows-1252 that are not in Latin-1 to
+// their byte; Latin-1 itself maps to the same byte.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// encode converts s to WinAnsi bytes, replacing what it cannot hold with "?".
+func encode(s string) []byte {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch c, ok := winAnsi[r]; {
+		case ok:
+			b = append(b, c)
+		case r >= 0x20 && r < 0x7F || r >= 0xA0 && r <= 0xFF:
+			b = append(b, byte(r))
+		case r == '\t' || r == '\n':
+			b = append(b, ' ')
+		default:
+			b = append(b, '?')
+		}
+	}
+	return b
+}
+
+// Width returns the width of s in points at the given size. Characters beyond
+// ASCII are measured as an average letter.
+func (f Font) Width(s string, size float64) float64 {
+	widths := &helveticaWidths
+	if f == HelveticaBold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, c := range encode(s) {
+		if c >= 0x20 && c < 0x7F {
+			total += widths[c-0x20]
+		} else {
+			total += widths['n'-0x20]
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Wrap breaks s into lines no wider than width, between words where it can
+// and inside words longer than a line.
+func (f Font) Wrap(s string, size, width float64) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if f.Width(candidate, size) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		for f.Width(word, size) > width {
+			cut := f.fit(word, size, width)
+			lines = append(lines, word[:cut])
+			word = word[cut:]
+		}
+		line = word
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fit returns how many bytes of word fit in width, at least one character.
+func (f Font) fit(word string, size, width float64) int {
+	n := 0
+	for i, r := range word {
+		next := i + utf8.RuneLen(r)
+		if n > 0 && f.Width(word[:next], size) > width {
+			break
+		}
+		n = next
+	}
+	return n
+}
@@ -0,0 +1,224 @@
+// Package pdf writes simple PDF documents in pure Go: text in the standard
+// Helvetica fonts, rules and links between pages, which is what printable
+// recipes need. The standard fonts need no embedding; text is encoded in
+// WinAnsi, so characters outside it print as "?".
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Size is a page size in points, 1/72 inch.
+type Size struct {
+	Width, Height float64
+}
+
+// Page sizes.
+var (
+	A4     = Size{Width: 595.28, Height: 841.89}
+	Letter = Size{Width: 612, Height: 792}
+)
+
+// Document is a PDF being built in memory.
+type Document struct {
+	size  Size
+	title string
+	pages []*Page
+}
+
+// New returns an empty document whose pages are all of one size.
+func New(size Size) *Document {
+	return &Document{size: size}
+}
+
+// SetTitle sets the title shown by PDF viewers.
+func (d *Document) SetTitle(title string) {
+	d.title = title
+}
+
+// Size returns the page size.
+func (d *Document) Size() Size {
+	return d.size
+}
+
+// AddPage appends a blank page.
+func (d *Document) AddPage() *Page {
+	p := &Page{doc: d, number: len(d.pages) + 1}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Page is one page. Positions are in points from the top left corner, and
+// text is placed by its baseline.
+type Page struct {
+	doc     *Document
+	number  int
+	content bytes.Buffer
+	links   []link
+}
+
+type link struct {
+	x, y, w, h float64
+	target     *Page
+}
+
+// Number is the page's 1-based position in the document.
+func (p *Page) Number() int {
+	return p.number
+}
+
+// Text writes s with its baseline at y.
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		font+1, num(size), num(x), num(p.doc.size.Height-y), escape(encode(s)))
+}
+
+// Gray sets the colour of the text and rules drawn after it, from 0 for
+// black to 1 for white.
+func (p *Page) Gray(level float64) {
+	fmt.Fprintf(&p.content, "%s g %s G\n", num(level), num(level))
+}
+
+// Line draws a straight rule.
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	h := p.doc.size.Height
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(h-y1), num(x2), num(h-y2))
+}
+
+// Link makes the rectangle with top left corner x, y a link to target.
+func (p *Page) Link(x, y, w, h float64, target *Page) {
+	p.links = append(p.links, link{x: x, y: y, w: w, h: h, target: target})
+}
+
+// Fixed object numbers; page i uses firstPageObject+2i and its content the
+// next number.
+const (
+	catalogObject = iota + 1
+	pagesObject
+	fontObject      // one per Font, in order
+	infoObject      = fontObject + int(fontCount)
+	firstPageObject = infoObject + 1
+)
+
+// WriteTo writes the document as PDF 1.4.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	offsets := make([]int64, firstPageObject+2*len(d.pages))
+	object := func(n int, body string) {
+		offsets[n] = cw.n
+		fmt.Fprintf(cw, "%d 0 obj\n%s\nendobj\n", n, body)
+	}
+
+	io.WriteString(cw, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object(catalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObject))
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", pageObject(i))
+	}
+	object(pagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	var fonts []string
+	for f := Font(0); f < fontCount; f++ {
+		object(fontObject+int(f), fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.name()))
+		fonts = append(fonts, fmt.Sprintf("/F%d %d 0 R", f+1, fontObject+int(f)))
+	}
+	object(infoObject, fmt.Sprintf("<< /Title %s /Producer (recipes-web) >>", textString(d.title)))
+
+	for i, p := range d.pages {
+		var annots []string
+		for _, l := range p.links {
+			top := d.size.Height - l.y
+			annots = append(annots, fmt.Sprintf("<< /Type /Annot /Subtype /Link /Rect [%s %s %s %s] /Border [0 0 0] /Dest [%d 0 R /XYZ null null null] >>",
+				num(l.x), num(top-l.h), num(l.x+l.w), num(top), pageObject(l.target.number-1)))
+		}
+		object(pageObject(i), fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s >> >> /Contents %d 0 R /Annots [%s] >>",
+			pagesObject, num(d.size.Width), num(d.size.Height), strings.Join(fonts, " "), pageObject(i)+1, strings.Join(annots, " ")))
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		zw.Write(p.content.Bytes())
+		zw.Close()
+		offsets[pageObject(i)+1] = cw.n
+		fmt.Fprintf(cw, "%d 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", pageObject(i)+1, stream.Len())
+		cw.Write(stream.Bytes())
+		io.WriteString(cw, "\nendstream\nendobj\n")
+	}
+
+	xref := cw.n
+	fmt.Fprintf(cw, "xref\n0 %d\n0000000000 65535 f \n", len(offsets))
+	for _, off := range offsets[1:] {
+		fmt.Fprintf(cw, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(cw, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets), catalogObject, infoObject, xref)
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.Flush()
+}
+
+func pageObject(i int) int {
+	return firstPageObject + 2*i
+}
+
+// countingWriter tracks the byte offsets the cross-reference table needs and
+// keeps the first write error.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(b []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(b)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
+
+// num writes a coordinate with at most two decimals.
+func num(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-" {
+		return "0"
+	}
+	return s
+}
+
+// escape protects the delimiters of a PDF literal string.
+func escape(b []byte) string {
+	var s strings.Builder
+	for _, c := range b {
+		switch c {
+		case '\\', '(', ')':
+			s.WriteByte('\\')
+		}
+		s.WriteByte(c)
+	}
+	return s.String()
+}
+
+// textString writes s as a UTF-16 hex string, which PDF viewers show in any
+// script.
+func textString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
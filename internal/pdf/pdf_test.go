@@ -0,0 +1,118 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	doc := New(A4)
+	doc.SetTitle("Soupe à l'oignon")
+	first := doc.AddPage()
+	second := doc.AddPage()
+	first.Text(56, 80, HelveticaBold, 12, "Onion (brown) soup – café ½")
+	first.Line(56, 90, 300, 90, 1)
+	first.Link(56, 70, 200, 14, second)
+	second.Text(56, 80, Helvetica, 10, "日本")
+
+	var buf bytes.Buffer
+	n, err := doc.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("WriteTo = %d, %v; wrote %d", n, err, buf.Len())
+	}
+	out := buf.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatalf("missing header or trailer")
+	}
+
+	// Every cross-reference entry points at its object.
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(out[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	if len(entries) != 10 {
+		t.Fatalf("expected 10 objects, got %d", len(entries))
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(out[off:], []byte(want)) {
+			t.Errorf("object %d: offset %d does not start %q", i+1, off, want)
+		}
+	}
+
+	if !bytes.Contains(out, []byte("/Dest [9 0 R /XYZ null null null]")) {
+		t.Error("expected a link to the second page")
+	}
+	if !bytes.Contains(out, []byte("/Title <FEFF0053006F007500700065002000E0")) {
+		t.Error("expected a UTF-16 title")
+	}
+
+	streams := contentStreams(t, out)
+	if len(streams) != 2 {
+		t.Fatalf("expected 2 content streams, got %d", len(streams))
+	}
+	if want := "(Onion \\(brown\\) soup \x96 caf\xe9 \xbd) Tj"; !strings.Contains(streams[0], want) {
+		t.Errorf("first page lacks %q:\n%s", want, streams[0])
+	}
+	if !strings.Contains(streams[0], "BT /F2 12 Tf 56 761.89 Td") {
+		t.Errorf("expected text placed from the top edge:\n%s", streams[0])
+	}
+	if !strings.Contains(streams[1], "(??) Tj") {
+		t.Errorf("expected characters outside WinAnsi as ?:\n%s", streams[1])
+	}
+}
+
+// contentStreams returns the decompressed content streams of a document
+// written by WriteTo, in order.
+func contentStreams(t *testing.T, out []byte) []string {
+	t.Helper()
+	var streams []string
+	for _, m := range regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n`).FindAllSubmatchIndex(out, -1) {
+		length, _ := strconv.Atoi(string(out[m[2]:m[3]]))
+		r, err := zlib.NewReader(bytes.NewReader(out[m[1] : m[1]+length]))
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+		data, _ := io.ReadAll(r)
+		streams = append(streams, string(data))
+	}
+	return streams
+}
+
+func TestWidthAndWrap(t *testing.T) {
+	if got := Helvetica.Width("Hi", 10); got != 9.44 {
+		t.Errorf("Width(Hi) = %v, want 9.44", got)
+	}
+	if HelveticaBold.Width("m", 10) <= Helvetica.Width("m", 10) {
+		t.Error("expected bold to be wider")
+	}
+
+	lines := Helvetica.Wrap("Simmer the sauce gently until thick", 10, 80)
+	for _, line := range lines {
+		if Helvetica.Width(line, 10) > 80 {
+			t.Errorf("line %q is wider than 80", line)
+		}
+	}
+	if strings.Join(lines, " ") != "Simmer the sauce gently until thick" || len(lines) < 2 {
+		t.Errorf("Wrap = %q", lines)
+	}
+
+	long := Helvetica.Wrap("supercalifragilisticexpialidocious", 10, 50)
+	if len(long) < 2 || strings.Join(long, "") != "supercalifragilisticexpialidocious" {
+		t.Errorf("expected a long word to be broken, got %q", long)
+	}
+	if got := Helvetica.Wrap("", 10, 50); len(got) != 1 || got[0] != "" {
+		t.Errorf("Wrap(empty) = %q", got)
+	}
+}
@@ -0,0 +1,80 @@
+package recipeio
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// Severity grades a Problem.
+type Severity string
+
+const (
+	// SeverityError marks data that cannot be stored or served correctly
+	SeverityError Severity = "error"
+	// SeverityWarning marks incomplete but usable data
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is a data-quality issue found in one recipe.
+type Problem struct {
+	ID       model.RecipeID `json:"id"`
+	Index    int            `json:"index"`
+	Severity Severity       `json:"severity"`
+	Message  string         `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: #%d (%s): %s", p.Severity, p.Index, p.ID, p.Message)
+}
+
+// Errors counts the problems of error severity.
+func Errors(problems []Problem) int {
+	n := 0
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// Check reports missing or repeated IDs and empty names as errors, and
+// recipes without ingredients, instructions or with blank tags as warnings.
+func Check(recipes []model.Recipe) []Problem {
+	var problems []Problem
+	seen := make(map[model.RecipeID]int, len(recipes))
+
+	for i, r := range recipes {
+		report := func(severity Severity, format string, args ...any) {
+			problems = append(problems, Problem{ID: r.ID, Index: i, Severity: severity, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if r.ID == "" {
+			report(SeverityError, "missing id")
+		} else if first, ok := seen[r.ID]; ok {
+			report(SeverityError, "duplicate id, first used by #%d", first)
+		} else {
+			seen[r.ID] = i
+		}
+
+		if strings.TrimSpace(r.Name) == "" {
+			report(SeverityError, "missing name")
+		}
+		if len(r.Ingredients) == 0 {
+			report(SeverityWarning, "no ingredients")
+		}
+		if len(r.Instructions) == 0 {
+			report(SeverityWarning, "no instructions")
+		}
+		for _, tag := range r.Tags {
+			if strings.TrimSpace(tag) == "" {
+				report(SeverityWarning, "empty tag")
+				break
+			}
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,164 @@
+package recipeio
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// Decoder reads recipes one at a time so large files need not fit in memory.
+type Decoder struct {
+	next  func() (model.Recipe, error)
+	count int
+}
+
+// NewDecoder returns a Decoder reading format from r. For CSV the header row is
+// read and checked immediately.
+func NewDecoder(r io.Reader, format Format) (*Decoder, error) {
+	d := &Decoder{}
+	switch format {
+	case FormatJSON:
+		d.next = jsonArray(json.NewDecoder(r))
+	case FormatNDJSON:
+		d.next = ndjson(r)
+	case FormatCSV:
+		next, err := csvRows(r)
+		if err != nil {
+			return nil, err
+		}
+		d.next = next
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+	return d, nil
+}
+
+// Next returns the next recipe, or io.EOF once the input is exhausted. A
+// malformed record ends the stream with an error naming its position.
+func (d *Decoder) Next() (model.Recipe, error) {
+	recipe, err := d.next()
+	if err == io.EOF {
+		return model.Recipe{}, io.EOF
+	}
+	d.count++
+	if err != nil {
+		d.next = func() (model.Recipe, error) { return model.Recipe{}, err }
+		return model.Recipe{}, fmt.Errorf("record %d: %w", d.count, err)
+	}
+	return recipe, nil
+}
+
+func jsonArray(dec *json.Decoder) func() (model.Recipe, error) {
+	started := false
+	return func() (model.Recipe, error) {
+		if !started {
+			tok, err := dec.Token()
+			if err != nil {
+				return model.Recipe{}, err
+			}
+			if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+				return model.Recipe{}, fmt.Errorf("expected a JSON array, got %v", tok)
+			}
+			started = true
+		}
+
+		if !dec.More() {
+			if _, err := dec.Token(); err != nil {
+				return model.Recipe{}, err
+			}
+			return model.Recipe{}, io.EOF
+		}
+
+		var recipe model.Recipe
+		err := dec.Decode(&recipe)
+		return recipe, err
+	}
+}
+
+func ndjson(r io.Reader) func() (model.Recipe, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return func() (model.Recipe, error) {
+		for scanner.Scan() {
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+			var recipe model.Recipe
+			err := json.Unmarshal(scanner.Bytes(), &recipe)
+			return recipe, err
+		}
+		if err := scanner.Err(); err != nil {
+			return model.Recipe{}, err
+		}
+		return model.Recipe{}, io.EOF
+	}
+}
+
+func csvRows(r io.Reader) (func() (model.Recipe, error), error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	if len(header) < csvMinColumns || len(header) > len(csvHeader) || !slices.Equal(header, csvHeader[:len(header)]) {
+		return nil, fmt.Errorf("unexpected csv header %q, want %q", header, csvHeader)
+	}
+	column := func(row []string, i int) string {
+		if i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	return func() (model.Recipe, error) {
+		row, err := cr.Read()
+		if err != nil {
+			return model.Recipe{}, err
+		}
+
+		recipe := model.Recipe{ID: model.RecipeID(row[0]), Name: row[1]}
+		lists := []*[]string{&recipe.Tags, &recipe.Ingredients, &recipe.Instructions}
+		for i, list := range lists {
+			*list = []string{}
+			if row[2+i] == "" {
+				continue
+			}
+			if err := json.Unmarshal([]byte(row[2+i]), list); err != nil {
+				return model.Recipe{}, fmt.Errorf("%s: %w", csvHeader[2+i], err)
+			}
+		}
+		if row[5] != "" {
+			recipe.PublishedAt, err = time.Parse(time.RFC3339Nano, row[5])
+			if err != nil {
+				return model.Recipe{}, fmt.Errorf("publishedAt: %w", err)
+			}
+		}
+		if sections := column(row, 6); sections != "" {
+			if err := json.Unmarshal([]byte(sections), &recipe.Sections); err != nil {
+				return model.Recipe{}, fmt.Errorf("sections: %w", err)
+			}
+		}
+		recipe.Yield = column(row, 7)
+		recipe.PrepTime = column(row, 8)
+		recipe.CookTime = column(row, 9)
+		recipe.TotalTime = column(row, 10)
+		recipe.Difficulty = column(row, 11)
+		recipe.Cuisine = column(row, 12)
+		recipe.Course = column(row, 13)
+		for i, list := range []*[]string{&recipe.Equipment, &recipe.Diets} {
+			if value := column(row, 14+i); value != "" {
+				if err := json.Unmarshal([]byte(value), list); err != nil {
+					return model.Recipe{}, fmt.Errorf("%s: %w", csvHeader[14+i], err)
+				}
+			}
+		}
+		return recipe, nil
+	}, nil
+}
@@ -0,0 +1,154 @@
+package recipeio
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// Encoder writes recipes one at a time so large exports need not fit in
+// memory. The output matches Write for the same recipes.
+type Encoder struct {
+	w      io.Writer
+	format Format
+	json   *json.Encoder
+	csv    *csv.Writer
+	count  int
+}
+
+// NewEncoder returns an Encoder writing format to w. Nothing is written until
+// the first Encode or Close.
+func NewEncoder(w io.Writer, format Format) (*Encoder, error) {
+	e := &Encoder{w: w, format: format}
+	switch format {
+	case FormatJSON:
+	case FormatNDJSON:
+		e.json = json.NewEncoder(w)
+	case FormatCSV:
+		e.csv = csv.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+	return e, nil
+}
+
+// Count returns how many recipes have been encoded.
+func (e *Encoder) Count() int {
+	return e.count
+}
+
+// Encode writes one recipe.
+func (e *Encoder) Encode(recipe model.Recipe) error {
+	var err error
+	switch e.format {
+	case FormatJSON:
+		err = e.encodeJSON(recipe)
+	case FormatNDJSON:
+		err = e.json.Encode(recipe)
+	case FormatCSV:
+		err = e.encodeCSV(recipe)
+	}
+	if err != nil {
+		return err
+	}
+	e.count++
+	return nil
+}
+
+// Flush writes any buffered rows to the underlying writer.
+func (e *Encoder) Flush() error {
+	if e.csv == nil {
+		return nil
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+// Close finishes the output: the closing bracket of a JSON array, or the CSV
+// header of an empty export. It does not close the underlying writer.
+func (e *Encoder) Close() error {
+	switch e.format {
+	case FormatJSON:
+		closing := "\n]\n"
+		if e.count == 0 {
+			closing = "[]\n"
+		}
+		_, err := io.WriteString(e.w, closing)
+		return err
+	case FormatCSV:
+		if e.count == 0 {
+			if err := e.csv.Write(csvHeader); err != nil {
+				return err
+			}
+		}
+		return e.Flush()
+	}
+	return nil
+}
+
+// encodeJSON writes an element of an array indented the way json.Encoder
+// indents a whole slice with SetIndent("", " ").
+func (e *Encoder) encodeJSON(recipe model.Recipe) error {
+	data, err := json.MarshalIndent(recipe, " ", " ")
+	if err != nil {
+		return err
+	}
+	sep := ",\n "
+	if e.count == 0 {
+		sep = "[\n "
+	}
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *Encoder) encodeCSV(recipe model.Recipe) error {
+	if e.count == 0 {
+		if err := e.csv.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+
+	var published string
+	if !recipe.PublishedAt.IsZero() {
+		published = recipe.PublishedAt.Format(time.RFC3339Nano)
+	}
+	row := []string{string(recipe.ID), recipe.Name, "", "", "", published, "",
+		recipe.Yield, recipe.PrepTime, recipe.CookTime, recipe.TotalTime,
+		recipe.Difficulty, recipe.Cuisine, recipe.Course, "", ""}
+	for i, list := range [][]string{recipe.Tags, recipe.Ingredients, recipe.Instructions} {
+		if list == nil {
+			list = []string{}
+		}
+		data, err := json.Marshal(list)
+		if err != nil {
+			return err
+		}
+		row[2+i] = string(data)
+	}
+	if len(recipe.Sections) > 0 {
+		data, err := json.Marshal(recipe.Sections)
+		if err != nil {
+			return err
+		}
+		row[6] = string(data)
+	}
+	// Optional lists are left empty rather than written as [] when unset.
+	for i, list := range [][]string{recipe.Equipment, recipe.Diets} {
+		if len(list) == 0 {
+			continue
+		}
+		data, err := json.Marshal(list)
+		if err != nil {
+			return err
+		}
+		row[14+i] = string(data)
+	}
+	return e.csv.Write(row)
+}
@@ -0,0 +1,104 @@
+// Package recipeio reads and writes recipes in the file formats supported by
+// the import and export tooling.
+package recipeio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// Format names a recipe file format.
+type Format string
+
+// Supported formats.
+const (
+	// FormatJSON is a JSON array, the layout of data/recipe.json
+	FormatJSON Format = "json"
+	// FormatNDJSON is one JSON recipe per line
+	FormatNDJSON Format = "ndjson"
+	// FormatCSV has one row per recipe; list fields hold JSON arrays so items
+	// containing line breaks survive a round trip
+	FormatCSV Format = "csv"
+)
+
+// ErrUnknownFormat is returned for a format other than json, ndjson or csv.
+var ErrUnknownFormat = errors.New("unknown format")
+
+// csvHeader is the column layout of FormatCSV. Columns are only ever added
+// at the end; files written before a column existed lack it and are still
+// accepted, down to the original six.
+var csvHeader = []string{"id", "name", "tags", "ingredients", "instructions", "publishedAt", "sections",
+	"yield", "prepTime", "cookTime", "totalTime", "difficulty", "cuisine", "course", "equipment", "diets"}
+
+// csvMinColumns is the width of the oldest accepted CSV layout.
+const csvMinColumns = 6
+
+// ParseFormat validates a format name.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatJSON, FormatNDJSON, FormatCSV:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w %q, use json, ndjson or csv", ErrUnknownFormat, name)
+}
+
+// ContentType returns the media type served for the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	}
+	return "application/json; charset=utf-8"
+}
+
+// FormatFromPath picks the format from a file extension, defaulting to JSON.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".csv":
+		return FormatCSV
+	}
+	return FormatJSON
+}
+
+// Read decodes every recipe from r.
+func Read(r io.Reader, format Format) ([]model.Recipe, error) {
+	dec, err := NewDecoder(r, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var recipes []model.Recipe
+	for {
+		recipe, err := dec.Next()
+		if err == io.EOF {
+			return recipes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, recipe)
+	}
+}
+
+// Write encodes recipes to w.
+func Write(w io.Writer, format Format, recipes []model.Recipe) error {
+	enc, err := NewEncoder(w, format)
+	if err != nil {
+		return err
+	}
+	for _, recipe := range recipes {
+		if err := enc.Encode(recipe); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
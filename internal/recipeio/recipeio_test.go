@@ -0,0 +1,147 @@
+package recipeio
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+func sampleRecipes() []model.Recipe {
+	return []model.Recipe{
+		{
+			ID:           "r1",
+			Name:         "Pancakes, \"fluffy\"",
+			Tags:         []string{"breakfast", "sweet"},
+			Ingredients:  []string{"2 eggs", "1 cup flour"},
+			Instructions: []string{"Mix", "Fry, flipping once"},
+			Yield:        "8 pancakes",
+			PrepTime:     "PT10M",
+			TotalTime:    "PT25M",
+			Difficulty:   "easy",
+			Cuisine:      "american",
+			Course:       "breakfast",
+			Equipment:    []string{"griddle"},
+			Diets:        []string{"vegetarian"},
+			PublishedAt:  time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC),
+		},
+		{
+			ID:           "r2",
+			Name:         "Toast",
+			Tags:         []string{},
+			Ingredients:  []string{"bread"},
+			Instructions: []string{"Toast"},
+			PublishedAt:  time.Date(2024, 3, 2, 8, 30, 0, 0, time.UTC),
+		},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatNDJSON, FormatCSV} {
+		var buf bytes.Buffer
+		if err := Write(&buf, format, sampleRecipes()); err != nil {
+			t.Fatalf("%s: write: %v", format, err)
+		}
+
+		got, err := Read(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: read: %v", format, err)
+		}
+		if !reflect.DeepEqual(got, sampleRecipes()) {
+			t.Errorf("%s: round trip mismatch:\n got %+v\nwant %+v", format, got, sampleRecipes())
+		}
+	}
+}
+
+func TestFormatFromPathAndParse(t *testing.T) {
+	if FormatFromPath("out.jsonl") != FormatNDJSON || FormatFromPath("out.CSV") != FormatCSV || FormatFromPath("x") != FormatJSON {
+		t.Error("unexpected format from path")
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected an error for xml")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	recipes := append(sampleRecipes(), model.Recipe{ID: "r1", Tags: []string{" "}})
+
+	problems := Check(recipes)
+	if len(problems) != 5 {
+		t.Fatalf("expected 5 problems, got %d: %v", len(problems), problems)
+	}
+	if problems[0].Index != 2 || problems[0].Message != "duplicate id, first used by #0" {
+		t.Errorf("unexpected first problem: %v", problems[0])
+	}
+	if n := Errors(problems); n != 2 {
+		t.Errorf("expected 2 errors, got %d", n)
+	}
+}
+
+func TestDecoder_ReportsBadRecord(t *testing.T) {
+	dec, err := NewDecoder(bytes.NewBufferString(`[{"id":"a"},{"id":7}]`), FormatJSON)
+	if err != nil {
+		t.Fatalf("NewDecoder: %v", err)
+	}
+
+	if r, err := dec.Next(); err != nil || r.ID != "a" {
+		t.Fatalf("expected the first record, got %+v, %v", r, err)
+	}
+	if _, err := dec.Next(); err == nil || !strings.Contains(err.Error(), "record 2") {
+		t.Fatalf("expected an error naming record 2, got %v", err)
+	}
+}
+
+func TestEncoder_JSONMatchesIndentedArray(t *testing.T) {
+	for _, recipes := range [][]model.Recipe{sampleRecipes(), {}} {
+		var got bytes.Buffer
+		if err := Write(&got, FormatJSON, recipes); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+
+		var want bytes.Buffer
+		enc := json.NewEncoder(&want)
+		enc.SetIndent("", " ")
+		if err := enc.Encode(recipes); err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("streamed JSON differs:\n got %q\nwant %q", got.String(), want.String())
+		}
+	}
+}
+
+func TestEncoder_EmptyCSVHasHeader(t *testing.T) {
+	var buf bytes.Buffer
+	enc, err := NewEncoder(&buf, FormatCSV)
+	if err != nil {
+		t.Fatalf("NewEncoder: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got := buf.String(); got != strings.Join(csvHeader, ",")+"\n" {
+		t.Errorf("expected only the header, got %q", got)
+	}
+}
+
+func TestDecoder_AcceptsOlderCSVLayouts(t *testing.T) {
+	for _, columns := range []int{6, 7} {
+		header := strings.Join(csvHeader[:columns], ",")
+		row := `r1,Soup,[],[],[],` + strings.Repeat(",", columns-6)
+		recipes, err := Read(strings.NewReader(header+"\n"+row+"\n"), FormatCSV)
+		if err != nil {
+			t.Fatalf("%d columns: %v", columns, err)
+		}
+		if len(recipes) != 1 || recipes[0].Name != "Soup" || recipes[0].Yield != "" {
+			t.Errorf("%d columns: got %+v", columns, recipes)
+		}
+	}
+
+	if _, err := Read(strings.NewReader("id,name,tags\n"), FormatCSV); err == nil {
+		t.Error("expected a header with too few columns to be rejected")
+	}
+}
@@ -2,12 +2,22 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
 
 	"github.com/gin-demo/recipes-web/internal/cache/redisrecipe"
 	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/tracing"
 	"github.com/gin-demo/recipes-web/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/gin-demo/recipes-web/internal/repository")
+
 // CachedRepository wraps a recipe repository with Redis caching layer.
 type CachedRepository struct {
 	repo  domain.RecipeRepository
@@ -20,12 +30,17 @@ func NewCachedRepository(repo domain.RecipeRepository, cache *redisrecipe.Cache)
 }
 
 // GetByID retrieves a recipe by ID, using the cache when available.
-func (c *CachedRepository) GetByID(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+func (c *CachedRepository) GetByID(ctx context.Context, id model.RecipeID) (r model.Recipe, err error) {
+	ctx, span := tracer.Start(ctx, "CachedRepository.GetByID", trace.WithAttributes(tracing.AttrRecipeID.String(string(id))))
+	defer func() { tracing.End(span, err) }()
+
 	if r, found, err := c.cache.GetByID(ctx, id); err == nil && found {
+		span.SetAttributes(tracing.AttrCacheHit.Bool(true))
 		return r, nil
 	}
+	span.SetAttributes(tracing.AttrCacheHit.Bool(false))
 
-	r, err := c.repo.GetByID(ctx, id)
+	r, err = c.repo.GetByID(ctx, id)
 	if err != nil {
 		return model.Recipe{}, err
 	}
@@ -34,6 +49,11 @@ func (c *CachedRepository) GetByID(ctx context.Context, id model.RecipeID) (mode
 	return r, nil
 }
 
+// RecordView counts a client read of a recipe, ranking it for warm-up.
+func (c *CachedRepository) RecordView(ctx context.Context, id model.RecipeID) error {
+	return c.cache.RecordView(ctx, id)
+}
+
 // Create adds a new recipe and caches the result.
 func (c *CachedRepository) Create(ctx context.Context, r model.Recipe) (model.Recipe, error) {
 	created, err := c.repo.Create(ctx, r)
@@ -54,12 +74,13 @@ func (c *CachedRepository) Update(ctx context.Context, r model.Recipe) (model.Re
 	return updated, nil
 }
 
-// Delete removes a recipe and clears it from the cache.
+// Delete removes a recipe and clears it and its view count from the cache.
 func (c *CachedRepository) Delete(ctx context.Context, id model.RecipeID) error {
 	if err := c.repo.Delete(ctx, id); err != nil {
 		return err
 	}
 	_ = c.cache.DeleteByID(ctx, id)
+	_ = c.cache.RemoveViews(ctx, []model.RecipeID{id})
 	return nil
 }
 
@@ -72,3 +93,303 @@ func (c *CachedRepository) GetAll(ctx context.Context) ([]model.Recipe, error) {
 func (c *CachedRepository) GetByTag(ctx context.Context, tag string) ([]model.Recipe, error) {
 	return c.repo.GetByTag(ctx, tag)
 }
+
+// Stream walks the matching recipes in the underlying repository; exports
+// bypass the cache.
+func (c *CachedRepository) Stream(ctx context.Context, filter domain.RecipeFilter, fn func(model.Recipe) error) error {
+	return domain.Stream(ctx, c.repo, filter, fn)
+}
+
+// Facets counts in the underlying repository; counts are not cached.
+func (c *CachedRepository) Facets(ctx context.Context, filter domain.RecipeFilter) (domain.Facets, error) {
+	return domain.CountFacets(ctx, c.repo, filter)
+}
+
+// AddImage adds an image to a recipe and invalidates its cache entry.
+func (c *CachedRepository) AddImage(ctx context.Context, id model.RecipeID, image model.Image) (model.Recipe, error) {
+	updated, err := domain.AddImage(ctx, c.repo, id, image)
+	if err != nil {
+		return model.Recipe{}, err
+	}
+	_ = c.cache.DeleteByID(ctx, id)
+	return updated, nil
+}
+
+// RemoveImage removes an image from a recipe and invalidates its cache entry.
+func (c *CachedRepository) RemoveImage(ctx context.Context, id model.RecipeID, imageID string) (model.Recipe, error) {
+	updated, err := domain.RemoveImage(ctx, c.repo, id, imageID)
+	if err != nil {
+		return model.Recipe{}, err
+	}
+	_ = c.cache.DeleteByID(ctx, id)
+	return updated, nil
+}
+
+// CreateMany adds many recipes and caches the created ones in one pipeline.
+func (c *CachedRepository) CreateMany(ctx context.Context, recipes []model.Recipe, mode domain.BatchMode) ([]model.Recipe, []error, error) {
+	created, errs, err := domain.Batch(c.repo).CreateMany(ctx, recipes, mode)
+	if err != nil {
+		return created, errs, err
+	}
+	_ = c.cache.SetByIDs(ctx, succeeded(created, errs))
+	return created, errs, nil
+}
+
+// GetMany serves what it can from the cache with one MGET, loads the rest in
+// one repository call and caches them. Batch reads do not count as views.
+func (c *CachedRepository) GetMany(ctx context.Context, ids []model.RecipeID) ([]model.Recipe, []error, error) {
+	cached, _ := c.cache.GetByIDs(ctx, ids)
+
+	var missing []model.RecipeID
+	for _, id := range ids {
+		if _, ok := cached[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	loaded := make(map[model.RecipeID]model.Recipe, len(missing))
+	loadErrs := make(map[model.RecipeID]error)
+	if len(missing) > 0 {
+		recipes, errs, err := domain.Batch(c.repo).GetMany(ctx, missing)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i, id := range missing {
+			if errs[i] != nil {
+				loadErrs[id] = errs[i]
+				continue
+			}
+			loaded[id] = recipes[i]
+		}
+		_ = c.cache.SetByIDs(ctx, succeeded(recipes, errs))
+	}
+
+	results := make([]model.Recipe, len(ids))
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		if r, ok := cached[id]; ok {
+			results[i] = r
+		} else if r, ok := loaded[id]; ok {
+			results[i] = r
+		} else {
+			errs[i] = loadErrs[id]
+		}
+	}
+	return results, errs, nil
+}
+
+// UpdateMany modifies many recipes and invalidates their cache entries with one DEL.
+func (c *CachedRepository) UpdateMany(ctx context.Context, recipes []model.Recipe, mode domain.BatchMode) ([]model.Recipe, []error, error) {
+	updated, errs, err := domain.Batch(c.repo).UpdateMany(ctx, recipes, mode)
+	if errors.Is(err, domain.ErrBatchAborted) {
+		return updated, errs, err
+	}
+
+	// after a storage error any item may have been written, so all are evicted
+	ids := make([]model.RecipeID, 0, len(recipes))
+	for i, r := range recipes {
+		if err != nil || errs[i] == nil {
+			ids = append(ids, r.ID)
+		}
+	}
+	_ = c.cache.DeleteByIDs(ctx, ids)
+	return updated, errs, err
+}
+
+// Upsert stores a recipe under its own ID and invalidates its cache entry.
+func (c *CachedRepository) Upsert(ctx context.Context, r model.Recipe) (bool, error) {
+	created, err := upsert(ctx, c.repo, r)
+	if err != nil {
+		return false, err
+	}
+	_ = c.cache.DeleteByID(ctx, r.ID)
+	return created, nil
+}
+
+// UpsertMany stores many recipes under their own IDs and invalidates their
+// cache entries with one DEL.
+func (c *CachedRepository) UpsertMany(ctx context.Context, recipes []model.Recipe) (int, error) {
+	created, err := upsertMany(ctx, c.repo, recipes)
+
+	// a failed batch may have been partly applied, so all are evicted
+	ids := make([]model.RecipeID, len(recipes))
+	for i, r := range recipes {
+		ids[i] = r.ID
+	}
+	_ = c.cache.DeleteByIDs(ctx, ids)
+	return created, err
+}
+
+// DeleteMany removes many recipes and clears them from the cache with one DEL,
+// and the view counts of those deleted with one ZREM.
+func (c *CachedRepository) DeleteMany(ctx context.Context, ids []model.RecipeID, mode domain.BatchMode) ([]error, error) {
+	errs, err := domain.Batch(c.repo).DeleteMany(ctx, ids, mode)
+	if errors.Is(err, domain.ErrBatchAborted) {
+		return errs, err
+	}
+
+	evict := make([]model.RecipeID, 0, len(ids))
+	deleted := make([]model.RecipeID, 0, len(ids))
+	for i, id := range ids {
+		if err != nil || errs[i] == nil {
+			evict = append(evict, id)
+		}
+		if err == nil && errs[i] == nil {
+			deleted = append(deleted, id)
+		}
+	}
+	_ = c.cache.DeleteByIDs(ctx, evict)
+	_ = c.cache.RemoveViews(ctx, deleted)
+	return errs, err
+}
+
+// succeeded returns the recipes whose item has no error.
+func succeeded(recipes []model.Recipe, errs []error) []model.Recipe {
+	out := make([]model.Recipe, 0, len(recipes))
+	for i, r := range recipes {
+		if errs[i] == nil {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// WarmUpOptions controls which recipes are preloaded into the cache.
+type WarmUpOptions struct {
+	// Limit is the number of most-viewed recipes to preload; zero preloads every recipe
+	Limit int
+	// Concurrency bounds the number of recipes loaded in parallel
+	Concurrency int
+}
+
+// WarmUpResult summarizes a warm-up run.
+type WarmUpResult struct {
+	Loaded int64 `json:"loaded"`
+	Failed int64 `json:"failed"`
+}
+
+// WarmUp preloads recipes from the underlying repository into the cache. When
+// a limit is set the most-viewed recipes are loaded first, topped up from the
+// full catalog if view counts are missing (e.g. after a Redis flush). If Redis
+// is unreachable it returns an error without touching the repository.
+func (c *CachedRepository) WarmUp(ctx context.Context, opts WarmUpOptions) (WarmUpResult, error) {
+	if err := c.cache.Ping(ctx); err != nil {
+		return WarmUpResult{}, fmt.Errorf("cache unavailable: %w", err)
+	}
+
+	ids, known, err := c.warmUpTargets(ctx, opts.Limit)
+	if err != nil {
+		return WarmUpResult{}, err
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		loaded, failed atomic.Int64
+		wg             sync.WaitGroup
+		sem            = make(chan struct{}, concurrency)
+	)
+
+	for _, id := range ids {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return WarmUpResult{Loaded: loaded.Load(), Failed: failed.Load()}, ctx.Err()
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(id model.RecipeID) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			r, ok := known[id]
+			var err error
+			if !ok {
+				r, err = c.repo.GetByID(ctx, id)
+			}
+			if err == nil {
+				err = c.cache.SetByID(ctx, r)
+			}
+			if err != nil {
+				slog.WarnContext(ctx, "cache warm-up failed for recipe", "recipeId", id, "error", err)
+				failed.Add(1)
+				return
+			}
+			loaded.Add(1)
+		}(id)
+	}
+	wg.Wait()
+
+	return WarmUpResult{Loaded: loaded.Load(), Failed: failed.Load()}, nil
+}
+
+// warmUpTargets returns the IDs to preload along with any recipes already
+// fetched from the repository, so they are not loaded a second time.
+func (c *CachedRepository) warmUpTargets(ctx context.Context, limit int) ([]model.RecipeID, map[model.RecipeID]model.Recipe, error) {
+	ids, err := c.cache.TopViewed(ctx, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if limit > 0 && len(ids) >= limit {
+		return ids, nil, nil
+	}
+
+	all, err := c.repo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	known := make(map[model.RecipeID]model.Recipe, len(all))
+	for _, r := range all {
+		known[r.ID] = r
+	}
+
+	seen := make(map[model.RecipeID]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	for _, r := range all {
+		if limit > 0 && len(ids) >= limit {
+			break
+		}
+		if !seen[r.ID] {
+			ids = append(ids, r.ID)
+		}
+	}
+
+	return ids, known, nil
+}
+
+// InspectCache returns the cached entry for a recipe, if any.
+func (c *CachedRepository) InspectCache(ctx context.Context, id model.RecipeID) (redisrecipe.Entry, bool, error) {
+	return c.cache.Inspect(ctx, id)
+}
+
+// EvictByID removes a single recipe from the cache.
+func (c *CachedRepository) EvictByID(ctx context.Context, id model.RecipeID) error {
+	return c.cache.DeleteByID(ctx, id)
+}
+
+// EvictByTag removes every cached recipe carrying the tag.
+func (c *CachedRepository) EvictByTag(ctx context.Context, tag string) (int64, error) {
+	return c.cache.DeleteByTag(ctx, tag)
+}
+
+// FlushCache removes every cached recipe.
+func (c *CachedRepository) FlushCache(ctx context.Context) (int64, error) {
+	return c.cache.Flush(ctx)
+}
+
+// CacheStats reports hit/miss statistics for the cache.
+func (c *CachedRepository) CacheStats(ctx context.Context) (redisrecipe.Stats, error) {
+	return c.cache.Stats(ctx)
+}
+
+// CacheCounters reports cumulative cache hits, misses and errors.
+func (c *CachedRepository) CacheCounters() (hits, misses, errors int64) {
+	return c.cache.Counters()
+}
@@ -8,8 +8,13 @@ import (
 
 	"github.com/gin-demo/recipes-web/internal/cache/redisrecipe"
 	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/tracing"
 	"github.com/gin-demo/recipes-web/model"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 // setupRedisForCachedRepo creates a test Redis client.
@@ -313,6 +318,31 @@ func TestCachedRepositoryDelete(t *testing.T) {
 	}
 }
 
+func TestCachedRepositoryDeleteRemovesViews(t *testing.T) {
+	mockRepo := newMockRepository()
+	client, cache := setupRedisForCachedRepo(t)
+	defer teardownRedisForCachedRepo(t, client)
+
+	ctx := context.Background()
+	cachedRepo := NewCachedRepository(mockRepo, cache)
+	for _, id := range []model.RecipeID{"views-1", "views-2", "views-3"} {
+		mockRepo.recipes = append(mockRepo.recipes, model.Recipe{ID: id, Name: string(id)})
+		_ = cachedRepo.RecordView(ctx, id)
+	}
+
+	if err := cachedRepo.Delete(ctx, "views-1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := cachedRepo.DeleteMany(ctx, []model.RecipeID{"views-2", "missing"}, domain.BatchBestEffort); err != nil {
+		t.Fatalf("DeleteMany failed: %v", err)
+	}
+
+	ids, _ := cache.TopViewed(ctx, 10)
+	if len(ids) != 1 || ids[0] != "views-3" {
+		t.Errorf("Expected only the remaining recipe ranked, got %v", ids)
+	}
+}
+
 func TestCachedRepositoryDeleteNotFound(t *testing.T) {
 	mockRepo := newMockRepository()
 	client, cache := setupRedisForCachedRepo(t)
@@ -340,3 +370,183 @@ func TestCachedRepositoryGetByIDNotFound(t *testing.T) {
 		t.Errorf("Expected ErrNotFound, got %v", err)
 	}
 }
+
+func TestCachedRepositoryWarmUp_All(t *testing.T) {
+	mockRepo := newMockRepository()
+	client, cache := setupRedisForCachedRepo(t)
+	defer teardownRedisForCachedRepo(t, client)
+
+	for _, id := range []model.RecipeID{"warm-1", "warm-2", "warm-3"} {
+		mockRepo.recipes = append(mockRepo.recipes, model.Recipe{ID: id, Name: string(id)})
+	}
+
+	cachedRepo := NewCachedRepository(mockRepo, cache)
+
+	result, err := cachedRepo.WarmUp(context.Background(), WarmUpOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("WarmUp failed: %v", err)
+	}
+	if result.Loaded != 3 || result.Failed != 0 {
+		t.Errorf("Expected 3 loaded, got %+v", result)
+	}
+
+	for _, r := range mockRepo.recipes {
+		if _, found, _ := cache.GetByID(context.Background(), r.ID); !found {
+			t.Errorf("Recipe %s should be cached after warm-up", r.ID)
+		}
+	}
+}
+
+func TestCachedRepositoryWarmUp_MostViewed(t *testing.T) {
+	mockRepo := newMockRepository()
+	client, cache := setupRedisForCachedRepo(t)
+	defer teardownRedisForCachedRepo(t, client)
+
+	for _, id := range []model.RecipeID{"warm-1", "warm-2", "warm-3"} {
+		mockRepo.recipes = append(mockRepo.recipes, model.Recipe{ID: id, Name: string(id)})
+	}
+
+	cachedRepo := NewCachedRepository(mockRepo, cache)
+
+	// Views are recorded for client reads only, not for every GetByID
+	ctx := context.Background()
+	_, _ = cachedRepo.GetByID(ctx, "warm-1")
+	_ = cachedRepo.RecordView(ctx, "warm-3")
+	_ = cachedRepo.RecordView(ctx, "warm-3")
+	_, _ = cache.Flush(ctx)
+
+	result, err := cachedRepo.WarmUp(ctx, WarmUpOptions{Limit: 1, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("WarmUp failed: %v", err)
+	}
+	if result.Loaded != 1 {
+		t.Errorf("Expected 1 loaded, got %+v", result)
+	}
+	if _, found, _ := cache.GetByID(ctx, "warm-3"); !found {
+		t.Error("Most-viewed recipe should be cached")
+	}
+	if _, found, _ := cache.GetByID(ctx, "warm-1"); found {
+		t.Error("Only the most-viewed recipe should be cached")
+	}
+}
+
+func TestCachedRepositoryWarmUp_RedisDown(t *testing.T) {
+	mockRepo := newMockRepository()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:1"})
+	defer client.Close()
+
+	cachedRepo := NewCachedRepository(mockRepo, redisrecipe.NewCache(client, time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if _, err := cachedRepo.WarmUp(ctx, WarmUpOptions{}); err == nil {
+		t.Error("Expected error when Redis is unavailable")
+	}
+}
+
+func TestCachedRepositoryGetByID_TracesCacheHit(t *testing.T) {
+	client, cache := setupRedisForCachedRepo(t)
+	defer teardownRedisForCachedRepo(t, client)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	mockRepo := newMockRepository()
+	mockRepo.recipes = append(mockRepo.recipes, model.Recipe{ID: "traced", Name: "Traced"})
+	repo := NewCachedRepository(mockRepo, cache)
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.GetByID(context.Background(), "traced"); err != nil {
+			t.Fatalf("GetByID failed: %v", err)
+		}
+	}
+
+	var hits []bool
+	for _, span := range recorder.Ended() {
+		if span.Name() != "CachedRepository.GetByID" {
+			continue
+		}
+		for _, attr := range span.Attributes() {
+			if attr.Key == tracing.AttrCacheHit {
+				hits = append(hits, attr.Value.AsBool())
+			}
+		}
+	}
+
+	if len(hits) != 2 || hits[0] || !hits[1] {
+		t.Errorf("Expected a miss then a hit, got %v", hits)
+	}
+}
+
+func TestCachedRepositoryBatch(t *testing.T) {
+	mockRepo := newMockRepository()
+	client, cache := setupRedisForCachedRepo(t)
+	defer teardownRedisForCachedRepo(t, client)
+
+	a := model.Recipe{ID: "batch-a", Name: "A"}
+	b := model.Recipe{ID: "batch-b", Name: "B"}
+	mockRepo.recipes = append(mockRepo.recipes, a, b)
+	_ = cache.SetByID(context.Background(), a)
+
+	cachedRepo := NewCachedRepository(mockRepo, cache)
+
+	// a comes from the cache, b from the repository and is then cached
+	got, errs, err := cachedRepo.GetMany(context.Background(), []model.RecipeID{"batch-a", "batch-b", "missing"})
+	if err != nil || got[0].Name != "A" || got[1].Name != "B" || !errors.Is(errs[2], domain.ErrNotFound) {
+		t.Fatalf("Unexpected GetMany result: %v %v %v", got, errs, err)
+	}
+	if _, found, _ := cache.GetByID(context.Background(), "batch-b"); !found {
+		t.Error("Recipes loaded by GetMany should be cached")
+	}
+
+	a.Name, b.Name = "A2", "B2"
+	_, errs, err = cachedRepo.UpdateMany(context.Background(), []model.Recipe{a, b}, domain.BatchBestEffort)
+	if err != nil || errs[0] != nil || errs[1] != nil {
+		t.Fatalf("UpdateMany failed: %v %v", errs, err)
+	}
+	for _, id := range []model.RecipeID{"batch-a", "batch-b"} {
+		if _, found, _ := cache.GetByID(context.Background(), id); found {
+			t.Errorf("Cache entry %s should be invalidated after UpdateMany", id)
+		}
+	}
+}
+
+// upsertingRepository adds upserts by ID to mockRepository.
+type upsertingRepository struct {
+	*mockRepository
+}
+
+func (m upsertingRepository) Upsert(ctx context.Context, recipe model.Recipe) (bool, error) {
+	if _, err := m.Update(ctx, recipe); err == nil {
+		return false, nil
+	}
+	m.recipes = append(m.recipes, recipe)
+	return true, nil
+}
+
+func TestCachedRepositoryUpsert(t *testing.T) {
+	mockRepo := upsertingRepository{newMockRepository()}
+	client, cache := setupRedisForCachedRepo(t)
+	defer teardownRedisForCachedRepo(t, client)
+
+	a := model.Recipe{ID: "upsert-a", Name: "A"}
+	mockRepo.recipes = append(mockRepo.recipes, a)
+	_ = cache.SetByID(context.Background(), a)
+
+	cachedRepo := NewCachedRepository(mockRepo, cache)
+
+	a.Name = "A2"
+	created, err := cachedRepo.UpsertMany(context.Background(), []model.Recipe{a, {ID: "upsert-b", Name: "B"}})
+	if err != nil || created != 1 {
+		t.Fatalf("UpsertMany = %d, %v; want 1 created", created, err)
+	}
+	if _, found, _ := cache.GetByID(context.Background(), "upsert-a"); found {
+		t.Error("Cache entry should be invalidated after UpsertMany")
+	}
+
+	if _, err := NewCachedRepository(newMockRepository(), cache).Upsert(context.Background(), a); !errors.Is(err, errNoUpsert) {
+		t.Errorf("Expected errNoUpsert from a repository without upserts, got %v", err)
+	}
+}
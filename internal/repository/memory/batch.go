@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// CreateMany adds every recipe with a fresh ID in one file write.
+func (repo *Repository) CreateMany(ctx context.Context, recipes []model.Recipe, mode domain.BatchMode) ([]model.Recipe, []error, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	created := make([]model.Recipe, len(recipes))
+	for i, recipe := range recipes {
+		created[i] = newRecipe(recipe, now)
+	}
+
+	if err := repo.commit(append(slices.Clone(repo.data), created...), nil, created); err != nil {
+		return nil, nil, err
+	}
+	return created, make([]error, len(recipes)), nil
+}
+
+// GetMany looks up every ID under one read lock.
+func (repo *Repository) GetMany(ctx context.Context, ids []model.RecipeID) ([]model.Recipe, []error, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	results := make([]model.Recipe, len(ids))
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		j := slices.IndexFunc(repo.data, func(r model.Recipe) bool { return r.ID == id })
+		if j < 0 {
+			errs[i] = ErrNotFound
+			continue
+		}
+		results[i] = repo.data[j]
+	}
+	return results, errs, nil
+}
+
+// UpdateMany replaces every recipe that exists in one file write. In atomic
+// mode a missing recipe aborts the batch before anything is written.
+func (repo *Repository) UpdateMany(ctx context.Context, recipes []model.Recipe, mode domain.BatchMode) ([]model.Recipe, []error, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	updated := slices.Clone(repo.data)
+	results := make([]model.Recipe, len(recipes))
+	errs := make([]error, len(recipes))
+	var removed, added []model.Recipe
+	failed := false
+	for i, recipe := range recipes {
+		j := slices.IndexFunc(updated, func(r model.Recipe) bool { return r.ID == recipe.ID })
+		if j < 0 {
+			errs[i] = ErrNotFound
+			failed = true
+			continue
+		}
+		removed = append(removed, updated[j])
+		added = append(added, recipe)
+		updated[j] = recipe
+		results[i] = recipe
+	}
+
+	if failed && mode == domain.BatchAtomic {
+		return nil, errs, domain.ErrBatchAborted
+	}
+	if err := repo.commit(updated, removed, added); err != nil {
+		return nil, nil, err
+	}
+	return results, errs, nil
+}
+
+// DeleteMany removes every recipe that exists in one file write. In atomic
+// mode a missing recipe aborts the batch before anything is written.
+func (repo *Repository) DeleteMany(ctx context.Context, ids []model.RecipeID, mode domain.BatchMode) ([]error, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	updated := slices.Clone(repo.data)
+	errs := make([]error, len(ids))
+	var removed []model.Recipe
+	failed := false
+	for i, id := range ids {
+		j := slices.IndexFunc(updated, func(r model.Recipe) bool { return r.ID == id })
+		if j < 0 {
+			errs[i] = ErrNotFound
+			failed = true
+			continue
+		}
+		removed = append(removed, updated[j])
+		updated = slices.Delete(updated, j, j+1)
+	}
+
+	if failed && mode == domain.BatchAtomic {
+		return errs, domain.ErrBatchAborted
+	}
+	if err := repo.commit(updated, removed, nil); err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+// commit saves recipes to the data file and, once that succeeded, makes them
+// the repository's contents, moving the facet counts from the removed recipes
+// to the added ones. The caller holds the write lock.
+func (repo *Repository) commit(recipes, removed, added []model.Recipe) error {
+	if err := saveAll(repo.dataPath, recipes); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistence, err)
+	}
+	repo.data = recipes
+	// Adding first keeps counts from dipping below zero when a batch replaces
+	// a recipe it added itself.
+	for _, r := range added {
+		repo.facets.Add(r)
+	}
+	for _, r := range removed {
+		repo.facets.Remove(r)
+	}
+	return nil
+}
@@ -10,12 +10,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gin-demo/recipes-web/internal/domain"
 	"github.com/gin-demo/recipes-web/model"
 	"github.com/rs/xid"
 )
 
 var (
-	ErrNotFound      = errors.New("recipe not found")
+	// ErrNotFound is the shared domain error so callers can match it with errors.Is.
+	ErrNotFound      = domain.ErrNotFound
 	ErrPersistence   = errors.New("persistence failure")
 	ErrIOFailure     = errors.New("IO failure")
 	ErrSerialization = errors.New("serialization/deserialziation failure")
@@ -23,9 +25,13 @@ var (
 
 // Repository implements the recipe repository interface using in-memory storage with file persistence.
 type Repository struct {
-	mu       sync.RWMutex
+	mu sync.RWMutex
+	// data is replaced, never modified in place, so Stream can walk a snapshot
+	// without holding the lock.
 	data     []model.Recipe
 	dataPath string
+	// facets counts data, kept up to date by commit
+	facets *domain.FacetCounter
 }
 
 // New creates a new Repository instance with data loaded from the specified file path.
@@ -41,7 +47,11 @@ func New(path string) (*Repository, error) {
 		return nil, fmt.Errorf("%w: %v", ErrSerialization, err)
 	}
 
-	return &Repository{data: recipes, dataPath: path}, nil
+	facets := domain.NewFacetCounter()
+	for _, r := range recipes {
+		facets.Add(r)
+	}
+	return &Repository{data: recipes, dataPath: path, facets: facets}, nil
 }
 
 // Create adds a new recipe to the repository.
@@ -49,22 +59,20 @@ func (repo *Repository) Create(ctx context.Context, recipe model.Recipe) (model.
 	repo.mu.Lock()
 	defer repo.mu.Unlock()
 
-	newRecipe := model.Recipe{
-		ID:           model.RecipeID(xid.New().String()),
-		Name:         recipe.Name,
-		Tags:         recipe.Tags,
-		Ingredients:  recipe.Ingredients,
-		Instructions: recipe.Instructions,
-		PublishedAt:  time.Now(),
-	}
-
-	repo.data = append(repo.data, newRecipe)
+	created := newRecipe(recipe, time.Now())
 
-	if err := saveAll(repo.dataPath, repo.data); err != nil {
-		return model.Recipe{}, fmt.Errorf("%w: %v", ErrPersistence, err)
+	if err := repo.commit(append(slices.Clone(repo.data), created), nil, []model.Recipe{created}); err != nil {
+		return model.Recipe{}, err
 	}
 
-	return newRecipe, nil
+	return created, nil
+}
+
+// newRecipe copies the caller's fields into a recipe with a fresh ID.
+func newRecipe(recipe model.Recipe, publishedAt time.Time) model.Recipe {
+	recipe.ID = model.RecipeID(xid.New().String())
+	recipe.PublishedAt = publishedAt
+	return recipe
 }
 
 // GetByID retrieves a recipe by its ID.
@@ -107,11 +115,9 @@ func (repo *Repository) Update(ctx context.Context, recipe model.Recipe) (model.
 			updated := append([]model.Recipe(nil), repo.data...)
 			updated[i] = recipe
 
-			if err := saveAll(repo.dataPath, updated); err != nil {
-				return model.Recipe{}, fmt.Errorf("%w: %v", ErrPersistence, err)
+			if err := repo.commit(updated, []model.Recipe{r}, []model.Recipe{recipe}); err != nil {
+				return model.Recipe{}, err
 			}
-
-			repo.data = updated
 			return recipe, nil
 		}
 	}
@@ -119,6 +125,66 @@ func (repo *Repository) Update(ctx context.Context, recipe model.Recipe) (model.
 	return model.Recipe{}, ErrNotFound
 }
 
+// Upsert stores the recipe under its own ID, replacing any existing recipe with that ID.
+func (repo *Repository) Upsert(ctx context.Context, recipe model.Recipe) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	updated := append([]model.Recipe(nil), repo.data...)
+	i := slices.IndexFunc(updated, func(r model.Recipe) bool { return r.ID == recipe.ID })
+	created := i < 0
+	var removed []model.Recipe
+	if created {
+		updated = append(updated, recipe)
+	} else {
+		removed = append(removed, updated[i])
+		updated[i] = recipe
+	}
+
+	if err := repo.commit(updated, removed, []model.Recipe{recipe}); err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+// UpsertMany stores every recipe under its own ID with a single file write.
+func (repo *Repository) UpsertMany(ctx context.Context, recipes []model.Recipe) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	updated := append([]model.Recipe(nil), repo.data...)
+	index := make(map[model.RecipeID]int, len(updated))
+	for i, r := range updated {
+		index[r.ID] = i
+	}
+
+	created := 0
+	var removed []model.Recipe
+	for _, recipe := range recipes {
+		if i, ok := index[recipe.ID]; ok {
+			removed = append(removed, updated[i])
+			updated[i] = recipe
+			continue
+		}
+		index[recipe.ID] = len(updated)
+		updated = append(updated, recipe)
+		created++
+	}
+
+	if err := repo.commit(updated, removed, recipes); err != nil {
+		return 0, err
+	}
+	return created, nil
+}
+
 // Delete removes a recipe from the repository by ID.
 func (repo *Repository) Delete(ctx context.Context, id model.RecipeID) error {
 	repo.mu.Lock()
@@ -132,14 +198,8 @@ func (repo *Repository) Delete(ctx context.Context, id model.RecipeID) error {
 		}
 
 		if r.ID == id {
-			updated := append(repo.data[0:i], repo.data[i+1:]...)
-
-			if err := saveAll(repo.dataPath, updated); err != nil {
-				return fmt.Errorf("%w: %v", ErrPersistence, err)
-			}
-
-			repo.data = updated
-			return nil
+			updated := slices.Delete(slices.Clone(repo.data), i, i+1)
+			return repo.commit(updated, []model.Recipe{r}, nil)
 		}
 	}
 
@@ -167,6 +227,51 @@ func (repo *Repository) GetByTag(ctx context.Context, tag string) ([]model.Recip
 	return recipes, nil
 }
 
+// Stream calls fn for every recipe matching filter. It walks a snapshot taken
+// under the read lock, so writes made while it runs are neither blocked nor seen.
+func (repo *Repository) Stream(ctx context.Context, filter domain.RecipeFilter, fn func(model.Recipe) error) error {
+	repo.mu.RLock()
+	snapshot := repo.data
+	repo.mu.RUnlock()
+
+	for _, r := range snapshot {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !filter.Matches(r) {
+			continue
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Facets counts the recipes matching filter. The counts for every recipe are
+// kept up to date as recipes change, so the unfiltered catalog costs nothing
+// to count.
+func (repo *Repository) Facets(ctx context.Context, filter domain.RecipeFilter) (domain.Facets, error) {
+	repo.mu.RLock()
+	if filter.IsZero() {
+		defer repo.mu.RUnlock()
+		return repo.facets.Facets(), nil
+	}
+	snapshot := repo.data
+	repo.mu.RUnlock()
+
+	counter := domain.NewFacetCounter()
+	for _, r := range snapshot {
+		if err := ctx.Err(); err != nil {
+			return domain.Facets{}, err
+		}
+		if filter.Matches(r) {
+			counter.Add(r)
+		}
+	}
+	return counter.Facets(), nil
+}
+
 func saveAll(path string, recipes []model.Recipe) error {
 	bytes, err := json.MarshalIndent(&recipes, "", " ")
 	if err != nil {
@@ -175,3 +280,12 @@ func saveAll(path string, recipes []model.Recipe) error {
 
 	return os.WriteFile(path, bytes, 0644)
 }
+
+// CheckWritable reports whether the backing data file can still be opened for writing.
+func (repo *Repository) CheckWritable() error {
+	file, err := os.OpenFile(repo.dataPath, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrIOFailure, err)
+	}
+	return file.Close()
+}
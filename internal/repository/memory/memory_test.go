@@ -3,12 +3,15 @@ package memory
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/gin-demo/recipes-web/internal/domain"
 	"github.com/gin-demo/recipes-web/model"
 )
 
@@ -274,3 +277,233 @@ func TestRepositoryConcurrency(t *testing.T) {
 		t.Errorf("Expected 10 recipes, got %d", len(all))
 	}
 }
+
+func TestRepositoryCheckWritable(t *testing.T) {
+	tempDir := t.TempDir()
+	tempFile := filepath.Join(tempDir, "test.json")
+	os.WriteFile(tempFile, []byte("[]"), 0644)
+
+	repo, _ := New(tempFile)
+
+	if err := repo.CheckWritable(); err != nil {
+		t.Errorf("Expected writable file, got %v", err)
+	}
+
+	os.Remove(tempFile)
+	os.Mkdir(tempFile, 0755)
+	if err := repo.CheckWritable(); err == nil {
+		t.Error("Expected error when data path is not a writable file")
+	}
+}
+
+func TestRepositoryUpsert(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "test.json")
+	os.WriteFile(tempFile, []byte("[]"), 0644)
+
+	repo, _ := New(tempFile)
+	ctx := context.Background()
+	published := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	created, err := repo.Upsert(ctx, model.Recipe{ID: "fixed", Name: "First", PublishedAt: published})
+	if err != nil || !created {
+		t.Fatalf("Expected insert, got created=%v err=%v", created, err)
+	}
+
+	created, err = repo.Upsert(ctx, model.Recipe{ID: "fixed", Name: "Second", PublishedAt: published})
+	if err != nil || created {
+		t.Fatalf("Expected replace, got created=%v err=%v", created, err)
+	}
+
+	got, err := repo.GetByID(ctx, "fixed")
+	if err != nil || got.Name != "Second" || !got.PublishedAt.Equal(published) {
+		t.Errorf("Expected the stored recipe to keep its ID and date, got %+v, %v", got, err)
+	}
+
+	reloaded, _ := New(tempFile)
+	if len(reloaded.data) != 1 {
+		t.Errorf("Expected 1 persisted recipe, got %d", len(reloaded.data))
+	}
+}
+
+func TestRepositoryUpsertMany(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "test.json")
+	os.WriteFile(tempFile, []byte(`[{"id":"a","name":"Old"}]`), 0644)
+
+	repo, _ := New(tempFile)
+	created, err := repo.UpsertMany(context.Background(), []model.Recipe{
+		{ID: "a", Name: "New"},
+		{ID: "b", Name: "B"},
+	})
+	if err != nil || created != 1 {
+		t.Fatalf("Expected 1 created, got %d, %v", created, err)
+	}
+
+	reloaded, _ := New(tempFile)
+	if len(reloaded.data) != 2 || reloaded.data[0].Name != "New" {
+		t.Errorf("Expected the batch to be persisted in order, got %+v", reloaded.data)
+	}
+}
+
+func TestRepositoryCreateMany(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "test.json")
+	os.WriteFile(tempFile, []byte(`[]`), 0644)
+
+	repo, _ := New(tempFile)
+	created, errs, err := repo.CreateMany(context.Background(), []model.Recipe{{Name: "A"}, {Name: "B"}}, domain.BatchAtomic)
+	if err != nil || len(created) != 2 || errs[0] != nil || errs[1] != nil {
+		t.Fatalf("CreateMany failed: %v %v", errs, err)
+	}
+	if created[0].ID == "" || created[0].ID == created[1].ID {
+		t.Errorf("Expected distinct IDs, got %q and %q", created[0].ID, created[1].ID)
+	}
+
+	reloaded, _ := New(tempFile)
+	if len(reloaded.data) != 2 {
+		t.Errorf("Expected 2 persisted recipes, got %d", len(reloaded.data))
+	}
+}
+
+func TestRepositoryUpdateManyModes(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "test.json")
+	os.WriteFile(tempFile, []byte(`[{"id":"a","name":"A"}]`), 0644)
+	repo, _ := New(tempFile)
+	batch := []model.Recipe{{ID: "a", Name: "A2"}, {ID: "missing", Name: "X"}}
+
+	_, errs, err := repo.UpdateMany(context.Background(), batch, domain.BatchAtomic)
+	if !errors.Is(err, domain.ErrBatchAborted) || !errors.Is(errs[1], ErrNotFound) || errs[0] != nil {
+		t.Fatalf("Expected atomic batch to abort on the missing recipe, got %v %v", errs, err)
+	}
+	if got, _ := repo.GetByID(context.Background(), "a"); got.Name != "A" {
+		t.Errorf("Aborted batch must not change anything, got %q", got.Name)
+	}
+
+	updated, errs, err := repo.UpdateMany(context.Background(), batch, domain.BatchBestEffort)
+	if err != nil || errs[0] != nil || !errors.Is(errs[1], ErrNotFound) || updated[0].Name != "A2" {
+		t.Fatalf("Expected best-effort batch to apply the first item, got %v %v %v", updated, errs, err)
+	}
+	reloaded, _ := New(tempFile)
+	if reloaded.data[0].Name != "A2" {
+		t.Errorf("Expected update to be persisted, got %q", reloaded.data[0].Name)
+	}
+}
+
+func TestRepositoryGetAndDeleteMany(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "test.json")
+	os.WriteFile(tempFile, []byte(`[{"id":"a","name":"A"},{"id":"b","name":"B"}]`), 0644)
+	repo, _ := New(tempFile)
+
+	got, errs, err := repo.GetMany(context.Background(), []model.RecipeID{"b", "x"})
+	if err != nil || got[0].Name != "B" || !errors.Is(errs[1], ErrNotFound) {
+		t.Errorf("Unexpected GetMany result: %v %v %v", got, errs, err)
+	}
+
+	errs, err = repo.DeleteMany(context.Background(), []model.RecipeID{"a", "a", "b"}, domain.BatchBestEffort)
+	if err != nil || errs[0] != nil || !errors.Is(errs[1], ErrNotFound) || errs[2] != nil {
+		t.Fatalf("Unexpected DeleteMany result: %v %v", errs, err)
+	}
+	reloaded, _ := New(tempFile)
+	if len(reloaded.data) != 0 {
+		t.Errorf("Expected every recipe deleted, got %+v", reloaded.data)
+	}
+}
+
+func TestRepositoryStream(t *testing.T) {
+	tempDir := t.TempDir()
+	tempFile := filepath.Join(tempDir, "test.json")
+	recipes := []model.Recipe{
+		{ID: "1", Name: "R1", Tags: []string{"a"}},
+		{ID: "2", Name: "R2", Tags: []string{"b"}},
+		{ID: "3", Name: "R3", Tags: []string{"a"}},
+	}
+	data, _ := json.MarshalIndent(recipes, "", " ")
+	os.WriteFile(tempFile, data, 0644)
+
+	repo, _ := New(tempFile)
+
+	// Writes made while streaming do not disturb the snapshot being walked.
+	var seen []model.RecipeID
+	err := repo.Stream(context.Background(), domain.RecipeFilter{}, func(r model.Recipe) error {
+		seen = append(seen, r.ID)
+		if r.ID == "1" {
+			if err := repo.Delete(context.Background(), "1"); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if !reflect.DeepEqual(seen, []model.RecipeID{"1", "2", "3"}) {
+		t.Errorf("Expected the snapshot 1, 2, 3, got %v", seen)
+	}
+
+	// Filtered
+	seen = nil
+	repo.Stream(context.Background(), domain.RecipeFilter{Tags: []string{"a"}}, func(r model.Recipe) error {
+		seen = append(seen, r.ID)
+		return nil
+	})
+	if !reflect.DeepEqual(seen, []model.RecipeID{"3"}) {
+		t.Errorf("Expected only recipe 3, got %v", seen)
+	}
+
+	// Cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = repo.Stream(ctx, domain.RecipeFilter{}, func(r model.Recipe) error {
+		t.Error("fn called after cancellation")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRepositoryFacets(t *testing.T) {
+	tempDir := t.TempDir()
+	tempFile := filepath.Join(tempDir, "test.json")
+	recipes := []model.Recipe{
+		{ID: "1", Name: "R1", Tags: []string{"a", "b"}, Cuisine: "thai", Difficulty: "easy", TotalTime: "PT20M"},
+		{ID: "2", Name: "R2", Tags: []string{"b"}, Cuisine: "thai", PrepTime: "PT1H", CookTime: "PT30M"},
+	}
+	data, _ := json.MarshalIndent(recipes, "", " ")
+	os.WriteFile(tempFile, data, 0644)
+
+	repo, _ := New(tempFile)
+	ctx := context.Background()
+
+	created, _ := repo.Create(ctx, model.Recipe{Name: "R3", Tags: []string{"a"}, Difficulty: "hard"})
+	updated := recipes[0]
+	updated.Tags = []string{"c"}
+	updated.Cuisine = "indian"
+	repo.Update(ctx, updated)
+	repo.UpsertMany(ctx, []model.Recipe{{ID: "4", Name: "R4", Tags: []string{"a"}}, {ID: "4", Name: "R4", Tags: []string{"c"}}})
+	repo.Delete(ctx, created.ID)
+	repo.DeleteMany(ctx, []model.RecipeID{"2"}, domain.BatchBestEffort)
+
+	// The counts kept as recipes changed match counting what is left.
+	facets, err := repo.Facets(ctx, domain.RecipeFilter{})
+	if err != nil {
+		t.Fatalf("Facets failed: %v", err)
+	}
+	reloaded, _ := New(tempFile)
+	if want, _ := reloaded.Facets(ctx, domain.RecipeFilter{}); !reflect.DeepEqual(facets, want) {
+		t.Errorf("Expected %+v, got %+v", want, facets)
+	}
+	if facets.Total != 2 || !reflect.DeepEqual(facets.Tags, []domain.FacetCount{{Value: "c", Count: 2}}) {
+		t.Errorf("Unexpected facets %+v", facets)
+	}
+	if !reflect.DeepEqual(facets.Cuisines, []domain.FacetCount{{Value: "indian", Count: 1}}) {
+		t.Errorf("Unexpected cuisines %+v", facets.Cuisines)
+	}
+	if facets.TotalTimes[1] != (domain.FacetCount{Value: "15-30m", Count: 1}) || facets.TotalTimes[5] != (domain.FacetCount{Value: domain.UnknownTime, Count: 1}) {
+		t.Errorf("Unexpected total times %+v", facets.TotalTimes)
+	}
+
+	// Filtered
+	facets, _ = repo.Facets(ctx, domain.RecipeFilter{Cuisine: "indian"})
+	if facets.Total != 1 || facets.Difficulties[0] != (domain.FacetCount{Value: "easy", Count: 1}) {
+		t.Errorf("Unexpected filtered facets %+v", facets)
+	}
+}
@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/rs/xid"
+)
+
+// ShoppingListRepository implements domain.ShoppingListRepository on top of a JSON file.
+type ShoppingListRepository struct {
+	mu    sync.RWMutex
+	lists []model.ShoppingList
+	path  string
+}
+
+// NewShoppingListRepository loads shopping lists from path. A missing file is
+// treated as an empty repository and is created on the first write.
+func NewShoppingListRepository(path string) (*ShoppingListRepository, error) {
+	repo := &ShoppingListRepository{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return repo, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIOFailure, err)
+	}
+
+	if err := json.Unmarshal(data, &repo.lists); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSerialization, err)
+	}
+	return repo, nil
+}
+
+// CreateShoppingList stores a new list under a fresh ID.
+func (repo *ShoppingListRepository) CreateShoppingList(ctx context.Context, list model.ShoppingList) (model.ShoppingList, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now().UTC()
+	list.ID = model.ShoppingListID(xid.New().String())
+	list.CreatedAt = now
+	list.UpdatedAt = now
+
+	if err := repo.save(append(slices.Clone(repo.lists), list)); err != nil {
+		return model.ShoppingList{}, err
+	}
+	return list, nil
+}
+
+// GetShoppingList returns the list with the given ID.
+func (repo *ShoppingListRepository) GetShoppingList(ctx context.Context, id model.ShoppingListID) (model.ShoppingList, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	if i := repo.find(id); i >= 0 {
+		return repo.lists[i], nil
+	}
+	return model.ShoppingList{}, fmt.Errorf("shopping list %s: %w", id, domain.ErrListNotFound)
+}
+
+// ListShoppingLists returns the owner's lists, newest first.
+func (repo *ShoppingListRepository) ListShoppingLists(ctx context.Context, owner string) ([]model.ShoppingList, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	lists := []model.ShoppingList{}
+	for _, list := range repo.lists {
+		if list.Owner == owner {
+			lists = append(lists, list)
+		}
+	}
+	slices.SortStableFunc(lists, func(a, b model.ShoppingList) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+	return lists, nil
+}
+
+// UpdateShoppingList replaces a stored list, keeping its owner and creation time.
+func (repo *ShoppingListRepository) UpdateShoppingList(ctx context.Context, list model.ShoppingList) (model.ShoppingList, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	i := repo.find(list.ID)
+	if i < 0 {
+		return model.ShoppingList{}, fmt.Errorf("shopping list %s: %w", list.ID, domain.ErrListNotFound)
+	}
+	list.Owner = repo.lists[i].Owner
+	list.CreatedAt = repo.lists[i].CreatedAt
+	list.UpdatedAt = time.Now().UTC()
+
+	lists := slices.Clone(repo.lists)
+	lists[i] = list
+	if err := repo.save(lists); err != nil {
+		return model.ShoppingList{}, err
+	}
+	return list, nil
+}
+
+// DeleteShoppingList removes the list with the given ID.
+func (repo *ShoppingListRepository) DeleteShoppingList(ctx context.Context, id model.ShoppingListID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	i := repo.find(id)
+	if i < 0 {
+		return fmt.Errorf("shopping list %s: %w", id, domain.ErrListNotFound)
+	}
+	return repo.save(slices.Delete(slices.Clone(repo.lists), i, i+1))
+}
+
+func (repo *ShoppingListRepository) find(id model.ShoppingListID) int {
+	return slices.IndexFunc(repo.lists, func(list model.ShoppingList) bool { return list.ID == id })
+}
+
+// save writes lists to the file and only then makes them current, so a
+// failed write leaves the repository as it was.
+func (repo *ShoppingListRepository) save(lists []model.ShoppingList) error {
+	data, err := json.MarshalIndent(lists, "", " ")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSerialization, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(repo.path), 0o755); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistence, err)
+	}
+	if err := os.WriteFile(repo.path, data, 0o644); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistence, err)
+	}
+	repo.lists = lists
+	return nil
+}
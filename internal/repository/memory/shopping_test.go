@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+func TestShoppingListRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "lists.json")
+	ctx := context.Background()
+
+	repo, err := NewShoppingListRepository(path)
+	if err != nil {
+		t.Fatalf("NewShoppingListRepository failed on a missing file: %v", err)
+	}
+
+	first, err := repo.CreateShoppingList(ctx, model.ShoppingList{Owner: "ed", Name: "first"})
+	if err != nil {
+		t.Fatalf("CreateShoppingList failed: %v", err)
+	}
+	if first.ID == "" || first.CreatedAt.IsZero() {
+		t.Errorf("Expected ID and timestamps to be set, got %+v", first)
+	}
+	second, _ := repo.CreateShoppingList(ctx, model.ShoppingList{Owner: "ed", Name: "second"})
+	repo.CreateShoppingList(ctx, model.ShoppingList{Owner: "al", Name: "other"})
+
+	lists, err := repo.ListShoppingLists(ctx, "ed")
+	if err != nil || len(lists) != 2 || lists[0].ID != second.ID {
+		t.Errorf("Expected ed's two lists newest first, got %+v, %v", lists, err)
+	}
+
+	first.Name = "renamed"
+	first.Owner = "al"
+	updated, err := repo.UpdateShoppingList(ctx, first)
+	if err != nil || updated.Name != "renamed" || updated.Owner != "ed" {
+		t.Errorf("Expected the name updated and the owner kept, got %+v, %v", updated, err)
+	}
+
+	reloaded, _ := NewShoppingListRepository(path)
+	got, err := reloaded.GetShoppingList(ctx, first.ID)
+	if err != nil || got.Name != "renamed" {
+		t.Errorf("Expected persisted list, got %+v, %v", got, err)
+	}
+
+	if err := reloaded.DeleteShoppingList(ctx, first.ID); err != nil {
+		t.Fatalf("DeleteShoppingList failed: %v", err)
+	}
+	if _, err := reloaded.GetShoppingList(ctx, first.ID); !errors.Is(err, domain.ErrListNotFound) {
+		t.Errorf("Expected ErrListNotFound after delete, got %v", err)
+	}
+	if err := reloaded.DeleteShoppingList(ctx, first.ID); !errors.Is(err, domain.ErrListNotFound) {
+		t.Errorf("Expected ErrListNotFound deleting twice, got %v", err)
+	}
+	if _, err := reloaded.UpdateShoppingList(ctx, first); !errors.Is(err, domain.ErrListNotFound) {
+		t.Errorf("Expected ErrListNotFound updating a deleted list, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/rs/xid"
+)
+
+// UserRepository implements domain.UserRepository on top of a JSON file.
+// Reads pick up changes other processes, such as recipectl, make to the file.
+type UserRepository struct {
+	mu    sync.Mutex
+	users []model.User
+	path  string
+	// modTime and size identify the version of the file last loaded
+	modTime time.Time
+	size    int64
+}
+
+// NewUserRepository loads users from path. A missing file is treated as an
+// empty repository and is created on the first write.
+func NewUserRepository(path string) (*UserRepository, error) {
+	repo := &UserRepository{path: path}
+	if err := repo.reload(); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// reload reads the file again when it has changed since it was last loaded.
+// The caller holds the write lock.
+func (repo *UserRepository) reload() error {
+	info, err := os.Stat(repo.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrIOFailure, err)
+	}
+	if info.ModTime().Equal(repo.modTime) && info.Size() == repo.size {
+		return nil
+	}
+
+	data, err := os.ReadFile(repo.path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrIOFailure, err)
+	}
+	var users []model.User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return fmt.Errorf("%w: %v", ErrSerialization, err)
+	}
+	repo.users = users
+	repo.modTime, repo.size = info.ModTime(), info.Size()
+	return nil
+}
+
+// current returns the users, reloading the file first if it has changed.
+func (repo *UserRepository) current() ([]model.User, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := repo.reload(); err != nil {
+		return nil, err
+	}
+	return repo.users, nil
+}
+
+// CreateUser stores a new user; the user name must not be taken.
+func (repo *UserRepository) CreateUser(ctx context.Context, user model.User) (model.User, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := repo.reload(); err != nil {
+		return model.User{}, err
+	}
+	for _, u := range repo.users {
+		if u.UserName == user.UserName {
+			return model.User{}, fmt.Errorf("user %q: %w", user.UserName, domain.ErrConflict)
+		}
+	}
+
+	now := time.Now().UTC()
+	user.ID = model.UserID(xid.New().String())
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	users := append(append([]model.User(nil), repo.users...), user)
+	data, err := json.MarshalIndent(users, "", " ")
+	if err != nil {
+		return model.User{}, fmt.Errorf("%w: %v", ErrSerialization, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(repo.path), 0o755); err != nil {
+		return model.User{}, fmt.Errorf("%w: %v", ErrPersistence, err)
+	}
+	// the file holds password hashes, so keep it private
+	if err := os.WriteFile(repo.path, data, 0o600); err != nil {
+		return model.User{}, fmt.Errorf("%w: %v", ErrPersistence, err)
+	}
+
+	repo.users = users
+	if info, err := os.Stat(repo.path); err == nil {
+		repo.modTime, repo.size = info.ModTime(), info.Size()
+	}
+	return user, nil
+}
+
+// GetUserByName returns the user with the given name.
+func (repo *UserRepository) GetUserByName(ctx context.Context, name string) (model.User, error) {
+	users, err := repo.current()
+	if err != nil {
+		return model.User{}, err
+	}
+	for _, u := range users {
+		if u.UserName == name {
+			return u, nil
+		}
+	}
+	return model.User{}, fmt.Errorf("user %q: %w", name, domain.ErrNotFound)
+}
+
+// CountUsers returns the number of stored users.
+func (repo *UserRepository) CountUsers(ctx context.Context) (int64, error) {
+	users, err := repo.current()
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(users)), nil
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+func TestUserRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "users.json")
+	ctx := context.Background()
+
+	repo, err := NewUserRepository(path)
+	if err != nil {
+		t.Fatalf("NewUserRepository failed on a missing file: %v", err)
+	}
+	if n, _ := repo.CountUsers(ctx); n != 0 {
+		t.Errorf("Expected no users, got %d", n)
+	}
+
+	created, err := repo.CreateUser(ctx, model.User{UserName: "ed", Password: "hash", Role: "editor"})
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if created.ID == "" || created.CreatedAt.IsZero() {
+		t.Errorf("Expected ID and timestamps to be set, got %+v", created)
+	}
+
+	if _, err := repo.CreateUser(ctx, model.User{UserName: "ed"}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Expected ErrConflict for a taken name, got %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected users file to be written: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected users file to be private, got %v", info.Mode().Perm())
+	}
+
+	reloaded, _ := NewUserRepository(path)
+	got, err := reloaded.GetUserByName(ctx, "ed")
+	if err != nil || got.Role != "editor" {
+		t.Errorf("Expected persisted user, got %+v, %v", got, err)
+	}
+	if _, err := reloaded.GetUserByName(ctx, "nobody"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// another process, such as recipectl, adds an account to the file
+	if _, err := reloaded.CreateUser(ctx, model.User{UserName: "al", Password: "hash", Role: "admin"}); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if got, err := repo.GetUserByName(ctx, "al"); err != nil || got.Role != "admin" {
+		t.Errorf("Expected the account added to the file, got %+v, %v", got, err)
+	}
+	if n, _ := repo.CountUsers(ctx); n != 2 {
+		t.Errorf("Expected 2 users, got %d", n)
+	}
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/metrics"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// MetricsRepository wraps a recipe repository and records operation latency and errors.
+type MetricsRepository struct {
+	repo    domain.RecipeRepository
+	backend string
+	metrics *metrics.Metrics
+}
+
+// NewMetricsRepository creates a new MetricsRepository labelling every sample with backend.
+func NewMetricsRepository(repo domain.RecipeRepository, backend string, m *metrics.Metrics) *MetricsRepository {
+	return &MetricsRepository{repo, backend, m}
+}
+
+// observe records the latency of one operation and, if it failed, its error kind.
+func (m *MetricsRepository) observe(operation string, start time.Time, err error) {
+	m.metrics.RepoDuration.WithLabelValues(m.backend, operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.metrics.RepoErrors.WithLabelValues(m.backend, operation, errorKind(err)).Inc()
+	}
+}
+
+// errorKind maps an error onto a small fixed set of label values.
+func errorKind(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrNotFound), errors.Is(err, domain.ErrImageNotFound):
+		return "not_found"
+	case errors.Is(err, domain.ErrInvalidInput):
+		return "invalid_input"
+	case errors.Is(err, domain.ErrConflict):
+		return "conflict"
+	case errors.Is(err, domain.ErrBatchAborted):
+		return "aborted"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "internal"
+	}
+}
+
+// Create adds a new recipe.
+func (m *MetricsRepository) Create(ctx context.Context, r model.Recipe) (model.Recipe, error) {
+	start := time.Now()
+	created, err := m.repo.Create(ctx, r)
+	m.observe("create", start, err)
+	return created, err
+}
+
+// GetByID retrieves a recipe by ID.
+func (m *MetricsRepository) GetByID(ctx context.Context, id model.RecipeID) (model.Recipe, error) {
+	start := time.Now()
+	r, err := m.repo.GetByID(ctx, id)
+	m.observe("get_by_id", start, err)
+	return r, err
+}
+
+// GetAll lists every recipe.
+func (m *MetricsRepository) GetAll(ctx context.Context) ([]model.Recipe, error) {
+	start := time.Now()
+	recipes, err := m.repo.GetAll(ctx)
+	m.observe("get_all", start, err)
+	return recipes, err
+}
+
+// Update modifies a recipe.
+func (m *MetricsRepository) Update(ctx context.Context, r model.Recipe) (model.Recipe, error) {
+	start := time.Now()
+	updated, err := m.repo.Update(ctx, r)
+	m.observe("update", start, err)
+	return updated, err
+}
+
+// Delete removes a recipe.
+func (m *MetricsRepository) Delete(ctx context.Context, id model.RecipeID) error {
+	start := time.Now()
+	err := m.repo.Delete(ctx, id)
+	m.observe("delete", start, err)
+	return err
+}
+
+// GetByTag finds recipes carrying a tag.
+func (m *MetricsRepository) GetByTag(ctx context.Context, tag string) ([]model.Recipe, error) {
+	start := time.Now()
+	recipes, err := m.repo.GetByTag(ctx, tag)
+	m.observe("get_by_tag", start, err)
+	return recipes, err
+}
+
+// CreateMany adds many recipes; the whole batch is one sample.
+func (m *MetricsRepository) CreateMany(ctx context.Context, recipes []model.Recipe, mode domain.BatchMode) ([]model.Recipe, []error, error) {
+	start := time.Now()
+	created, errs, err := domain.Batch(m.repo).CreateMany(ctx, recipes, mode)
+	m.observe("create_many", start, err)
+	return created, errs, err
+}
+
+// GetMany retrieves many recipes by ID.
+func (m *MetricsRepository) GetMany(ctx context.Context, ids []model.RecipeID) ([]model.Recipe, []error, error) {
+	start := time.Now()
+	recipes, errs, err := domain.Batch(m.repo).GetMany(ctx, ids)
+	m.observe("get_many", start, err)
+	return recipes, errs, err
+}
+
+// UpdateMany modifies many recipes.
+func (m *MetricsRepository) UpdateMany(ctx context.Context, recipes []model.Recipe, mode domain.BatchMode) ([]model.Recipe, []error, error) {
+	start := time.Now()
+	updated, errs, err := domain.Batch(m.repo).UpdateMany(ctx, recipes, mode)
+	m.observe("update_many", start, err)
+	return updated, errs, err
+}
+
+// DeleteMany removes many recipes.
+func (m *MetricsRepository) DeleteMany(ctx context.Context, ids []model.RecipeID, mode domain.BatchMode) ([]error, error) {
+	start := time.Now()
+	errs, err := domain.Batch(m.repo).DeleteMany(ctx, ids, mode)
+	m.observe("delete_many", start, err)
+	return errs, err
+}
+
+// Stream walks the matching recipes; the whole walk, including the time spent
+// in fn, is one sample.
+func (m *MetricsRepository) Stream(ctx context.Context, filter domain.RecipeFilter, fn func(model.Recipe) error) error {
+	start := time.Now()
+	err := domain.Stream(ctx, m.repo, filter, fn)
+	m.observe("stream", start, err)
+	return err
+}
+
+// Facets counts the matching recipes.
+func (m *MetricsRepository) Facets(ctx context.Context, filter domain.RecipeFilter) (domain.Facets, error) {
+	start := time.Now()
+	facets, err := domain.CountFacets(ctx, m.repo, filter)
+	m.observe("facets", start, err)
+	return facets, err
+}
+
+// Upsert stores a recipe under its own ID.
+func (m *MetricsRepository) Upsert(ctx context.Context, r model.Recipe) (bool, error) {
+	start := time.Now()
+	created, err := upsert(ctx, m.repo, r)
+	m.observe("upsert", start, err)
+	return created, err
+}
+
+// UpsertMany stores many recipes under their own IDs.
+func (m *MetricsRepository) UpsertMany(ctx context.Context, recipes []model.Recipe) (int, error) {
+	start := time.Now()
+	created, err := upsertMany(ctx, m.repo, recipes)
+	m.observe("upsert_many", start, err)
+	return created, err
+}
+
+// AddImage adds an image to a recipe.
+func (m *MetricsRepository) AddImage(ctx context.Context, id model.RecipeID, image model.Image) (model.Recipe, error) {
+	start := time.Now()
+	updated, err := domain.AddImage(ctx, m.repo, id, image)
+	m.observe("add_image", start, err)
+	return updated, err
+}
+
+// RemoveImage removes an image from a recipe.
+func (m *MetricsRepository) RemoveImage(ctx context.Context, id model.RecipeID, imageID string) (model.Recipe, error) {
+	start := time.Now()
+	updated, err := domain.RemoveImage(ctx, m.repo, id, imageID)
+	m.observe("remove_image", start, err)
+	return updated, err
+}
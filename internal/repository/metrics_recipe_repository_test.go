@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/metrics"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsRepositoryRecordsLatency(t *testing.T) {
+	m := metrics.New(prometheus.NewRegistry())
+	mockRepo := newMockRepository()
+	repo := NewMetricsRepository(mockRepo, "memory", m)
+
+	created, err := repo.Create(context.Background(), model.Recipe{Name: "Measured"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := repo.GetByID(context.Background(), created.ID); err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+
+	if got := testutil.CollectAndCount(m.RepoDuration, "recipes_repository_operation_duration_seconds"); got != 2 {
+		t.Errorf("Expected 2 histogram series, got %d", got)
+	}
+	if got := testutil.CollectAndCount(m.RepoErrors); got != 0 {
+		t.Errorf("Expected no errors, got %d", got)
+	}
+}
+
+func TestMetricsRepositoryCountsErrors(t *testing.T) {
+	m := metrics.New(prometheus.NewRegistry())
+	mockRepo := newMockRepository()
+	mockRepo.getAllFunc = func(ctx context.Context) ([]model.Recipe, error) {
+		return nil, errors.New("connection reset")
+	}
+	repo := NewMetricsRepository(mockRepo, "mongo", m)
+
+	_, _ = repo.GetByID(context.Background(), "missing")
+	_, _ = repo.GetAll(context.Background())
+
+	if got := testutil.ToFloat64(m.RepoErrors.WithLabelValues("mongo", "get_by_id", "not_found")); got != 1 {
+		t.Errorf("Expected 1 not_found error, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.RepoErrors.WithLabelValues("mongo", "get_all", "internal")); got != 1 {
+		t.Errorf("Expected 1 internal error, got %v", got)
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	cases := map[error]string{
+		domain.ErrNotFound:       "not_found",
+		domain.ErrInvalidInput:   "invalid_input",
+		domain.ErrConflict:       "conflict",
+		context.DeadlineExceeded: "timeout",
+		context.Canceled:         "canceled",
+		domain.ErrPersistence:    "internal",
+	}
+	for err, want := range cases {
+		if got := errorKind(err); got != want {
+			t.Errorf("errorKind(%v) = %s, want %s", err, got, want)
+		}
+	}
+}
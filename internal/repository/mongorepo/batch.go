@@ -0,0 +1,235 @@
+package mongorepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// CreateMany inserts every recipe with a fresh ID in one insertMany. Atomic
+// batches run in a transaction, which needs a replica set.
+func (repo *Repository) CreateMany(ctx context.Context, recipes []model.Recipe, mode domain.BatchMode) ([]model.Recipe, []error, error) {
+	now := time.Now()
+	created := make([]model.Recipe, len(recipes))
+	docs := make([]any, len(recipes))
+	for i, recipe := range recipes {
+		created[i] = newRecipe(recipe, now)
+		docs[i] = toDocument(created[i])
+	}
+	if len(docs) == 0 {
+		return created, nil, nil
+	}
+
+	collection := repo.collection(RECIPE_COLLECTION)
+	errs := make([]error, len(recipes))
+	err := repo.inTransaction(ctx, mode, func(ctx context.Context) error {
+		clear(errs)
+		return repo.write(ctx, "insertMany", func(ctx context.Context) error {
+			_, err := collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(mode == domain.BatchAtomic))
+			return err
+		})
+	})
+	if err != nil {
+		var bulkErr mongo.BulkWriteException
+		if !errors.As(err, &bulkErr) || len(bulkErr.WriteErrors) == 0 {
+			return nil, nil, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+		}
+		for _, e := range bulkErr.WriteErrors {
+			errs[e.Index] = writeError(e.WriteError)
+			created[e.Index] = model.Recipe{}
+		}
+		if mode == domain.BatchAtomic {
+			return nil, errs, domain.ErrBatchAborted
+		}
+	}
+
+	return created, errs, nil
+}
+
+// GetMany fetches every ID with a single $in query.
+func (repo *Repository) GetMany(ctx context.Context, ids []model.RecipeID) ([]model.Recipe, []error, error) {
+	var stored map[model.RecipeID]model.Recipe
+	err := repo.read(ctx, "find", func(ctx context.Context) error {
+		var err error
+		stored, err = repo.findByIDs(ctx, ids)
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+
+	results := make([]model.Recipe, len(ids))
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		recipe, ok := stored[id]
+		if !ok {
+			errs[i] = domain.ErrNotFound
+			continue
+		}
+		results[i] = recipe
+	}
+	return results, errs, nil
+}
+
+// UpdateMany applies every update that matches a stored recipe in one bulk
+// write and returns the stored results. Atomic batches run in a transaction
+// and abort if any recipe is missing.
+func (repo *Repository) UpdateMany(ctx context.Context, recipes []model.Recipe, mode domain.BatchMode) ([]model.Recipe, []error, error) {
+	ids := make([]model.RecipeID, len(recipes))
+	for i, recipe := range recipes {
+		ids[i] = recipe.ID
+	}
+
+	collection := repo.collection(RECIPE_COLLECTION)
+	results := make([]model.Recipe, len(recipes))
+	errs := make([]error, len(recipes))
+	err := repo.inTransaction(ctx, mode, func(ctx context.Context) error {
+		clear(results)
+		clear(errs)
+
+		existing, err := repo.findByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		var models []mongo.WriteModel
+		for i, recipe := range recipes {
+			if _, ok := existing[recipe.ID]; !ok {
+				errs[i] = domain.ErrNotFound
+				continue
+			}
+			models = append(models, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": recipe.ID}).
+				SetUpdate(bson.M{"$set": editableFields(recipe)}))
+		}
+		if mode == domain.BatchAtomic && slices.ContainsFunc(errs, isErr) {
+			return domain.ErrBatchAborted
+		}
+		if len(models) == 0 {
+			return nil
+		}
+
+		err = repo.write(ctx, "bulkWrite", func(ctx context.Context) error {
+			_, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(mode == domain.BatchAtomic))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		updated, err := repo.findByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for i, recipe := range recipes {
+			if errs[i] == nil {
+				results[i] = updated[recipe.ID]
+			}
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, domain.ErrBatchAborted):
+		return nil, errs, err
+	case err != nil:
+		return nil, nil, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+
+	return results, errs, nil
+}
+
+// DeleteMany removes every stored recipe among ids with one deleteMany.
+// Atomic batches run in a transaction and abort if any recipe is missing.
+func (repo *Repository) DeleteMany(ctx context.Context, ids []model.RecipeID, mode domain.BatchMode) ([]error, error) {
+	collection := repo.collection(RECIPE_COLLECTION)
+	errs := make([]error, len(ids))
+	err := repo.inTransaction(ctx, mode, func(ctx context.Context) error {
+		clear(errs)
+
+		existing, err := repo.findByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		deleted := make(map[model.RecipeID]bool, len(ids))
+		present := make([]model.RecipeID, 0, len(ids))
+		for i, id := range ids {
+			if _, ok := existing[id]; !ok || deleted[id] {
+				errs[i] = domain.ErrNotFound
+				continue
+			}
+			deleted[id] = true
+			present = append(present, id)
+		}
+		if mode == domain.BatchAtomic && slices.ContainsFunc(errs, isErr) {
+			return domain.ErrBatchAborted
+		}
+		if len(present) == 0 {
+			return nil
+		}
+
+		return repo.write(ctx, "deleteMany", func(ctx context.Context) error {
+			_, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": present}})
+			return err
+		})
+	})
+	switch {
+	case errors.Is(err, domain.ErrBatchAborted):
+		return errs, err
+	case err != nil:
+		return nil, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+
+	return errs, nil
+}
+
+// inTransaction runs fn inside a multi-document transaction for atomic
+// batches and directly otherwise. The driver may run fn more than once when
+// the transaction hits a transient error.
+func (repo *Repository) inTransaction(ctx context.Context, mode domain.BatchMode, fn func(context.Context) error) error {
+	if mode != domain.BatchAtomic {
+		return fn(ctx)
+	}
+
+	session, err := repo.mongoclient.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		return nil, fn(ctx)
+	})
+	return err
+}
+
+// findByIDs returns the stored recipes among ids, keyed by ID.
+func (repo *Repository) findByIDs(ctx context.Context, ids []model.RecipeID) (map[model.RecipeID]model.Recipe, error) {
+	recipes, err := repo.find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[model.RecipeID]model.Recipe, len(recipes))
+	for _, recipe := range recipes {
+		byID[recipe.ID] = recipe
+	}
+	return byID, nil
+}
+
+// writeError maps a failed write onto the domain errors.
+func writeError(e mongo.WriteError) error {
+	if e.Code == 11000 {
+		return domain.ErrConflict
+	}
+	return fmt.Errorf("%w: %s", domain.ErrPersistence, e.Message)
+}
+
+func isErr(err error) bool {
+	return err != nil
+}
@@ -0,0 +1,71 @@
+package mongorepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// document is a recipe as stored, with the fields derived from it for
+// queries. Decoding a document into a model.Recipe ignores them.
+type document struct {
+	model.Recipe `bson:",inline"`
+	// TotalSeconds is the recipe's total time in seconds for range queries
+	// and facets, null when it is unknown
+	TotalSeconds *int64 `bson:"totalSeconds"`
+}
+
+func toDocument(recipe model.Recipe) document {
+	return document{Recipe: recipe, TotalSeconds: totalSeconds(recipe)}
+}
+
+func totalSeconds(recipe model.Recipe) *int64 {
+	total, ok := recipe.TotalDuration()
+	if !ok {
+		return nil
+	}
+	seconds := int64(total / time.Second)
+	return &seconds
+}
+
+// BackfillDerived sets the derived fields of documents written before they
+// existed, and returns how many it updated. Running it again finds nothing to do.
+func (repo *Repository) BackfillDerived(ctx context.Context) (int, error) {
+	collection := repo.collection(RECIPE_COLLECTION)
+	cur, err := collection.Find(ctx, bson.M{"totalSeconds": bson.M{"$exists": false}})
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+	defer cur.Close(context.WithoutCancel(ctx))
+
+	var models []mongo.WriteModel
+	for cur.Next(ctx) {
+		var recipe model.Recipe
+		if err := cur.Decode(&recipe); err != nil {
+			return 0, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": recipe.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"totalSeconds": totalSeconds(recipe)}}))
+	}
+	if err := cur.Err(); err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+	if len(models) == 0 {
+		return 0, nil
+	}
+
+	err = repo.write(ctx, "bulkWrite", func(ctx context.Context) error {
+		_, err := collection.BulkWrite(ctx, models)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+	return len(models), nil
+}
@@ -0,0 +1,115 @@
+package mongorepo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Facets counts the matching recipes in a single aggregation, so only the
+// counts leave the server.
+func (repo *Repository) Facets(ctx context.Context, filter domain.RecipeFilter) (domain.Facets, error) {
+	var result []struct {
+		Total        []struct{ N int } `bson:"total"`
+		Tags         []facetGroup      `bson:"tags"`
+		Cuisines     []facetGroup      `bson:"cuisines"`
+		Difficulties []facetGroup      `bson:"difficulties"`
+		TotalTimes   []facetGroup      `bson:"totalTimes"`
+	}
+	err := repo.read(ctx, "aggregate", func(ctx context.Context) error {
+		cur, err := repo.collection(RECIPE_COLLECTION).Aggregate(ctx, facetPipeline(filter))
+		if err != nil {
+			return err
+		}
+		return cur.All(ctx, &result)
+	})
+	if err != nil {
+		return domain.Facets{}, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+	if len(result) == 0 {
+		return domain.NewFacets(0, nil, nil, nil, nil), nil
+	}
+
+	r := result[0]
+	total := 0
+	if len(r.Total) > 0 {
+		total = r.Total[0].N
+	}
+	times := counts(r.TotalTimes)
+	for i, b := range domain.TimeBuckets {
+		times[b.Label] = times[bucketKeys[i]]
+		delete(times, bucketKeys[i])
+	}
+	return domain.NewFacets(total, counts(r.Tags), counts(r.Cuisines), counts(r.Difficulties), times), nil
+}
+
+type facetGroup struct {
+	Value any `bson:"_id"`
+	N     int `bson:"n"`
+}
+
+// counts keys groups by their value as a string; $bucket groups are keyed by
+// their lower boundary.
+func counts(groups []facetGroup) map[string]int {
+	out := make(map[string]int, len(groups))
+	for _, g := range groups {
+		out[fmt.Sprint(g.Value)] = g.N
+	}
+	return out
+}
+
+// bucketBounds are the $bucket boundaries in seconds matching
+// domain.TimeBuckets, and bucketKeys the lower bounds $bucket reports each
+// bucket under. A bucket's Max is inclusive, so the next starts a second later.
+var bucketBounds, bucketKeys = timeBuckets()
+
+func timeBuckets() (bson.A, []string) {
+	bounds := bson.A{int64(0)}
+	keys := make([]string, len(domain.TimeBuckets))
+	for i, b := range domain.TimeBuckets {
+		keys[i] = fmt.Sprint(bounds[i])
+		if b.Max == 0 {
+			bounds = append(bounds, int64(math.MaxInt64))
+			continue
+		}
+		bounds = append(bounds, int64(b.Max/time.Second)+1)
+	}
+	return bounds, keys
+}
+
+// facetPipeline matches filter and counts each facet in its own $facet branch.
+func facetPipeline(filter domain.RecipeFilter) bson.A {
+	// countBy groups by field, most common first, keeping the documents
+	// passing match
+	countBy := func(field string, match bson.M, before ...any) bson.A {
+		return append(bson.A(before),
+			bson.M{"$match": match},
+			bson.M{"$group": bson.M{"_id": "$" + field, "n": bson.M{"$sum": 1}}},
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "n", Value: -1}, {Key: "_id", Value: 1}}}},
+			bson.M{"$limit": domain.MaxFacetValues},
+		)
+	}
+	return bson.A{
+		bson.M{"$match": filterQuery(filter)},
+		bson.M{"$facet": bson.M{
+			"total": bson.A{bson.M{"$count": "n"}},
+			// $setUnion drops repeated tags so each recipe counts once per tag.
+			"tags": countBy("tags", bson.M{},
+				bson.M{"$project": bson.M{"tags": bson.M{"$setUnion": bson.A{bson.M{"$ifNull": bson.A{"$tags", bson.A{}}}}}}},
+				bson.M{"$unwind": "$tags"}),
+			"cuisines":     countBy("cuisine", bson.M{"cuisine": bson.M{"$nin": bson.A{nil, ""}}}),
+			"difficulties": countBy("difficulty", bson.M{"difficulty": bson.M{"$in": model.Difficulties}}),
+			"totalTimes": bson.A{bson.M{"$bucket": bson.M{
+				"groupBy":    "$totalSeconds",
+				"boundaries": bucketBounds,
+				"default":    domain.UnknownTime,
+				"output":     bson.M{"n": bson.M{"$sum": 1}},
+			}}},
+		}},
+	}
+}
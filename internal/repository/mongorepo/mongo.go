@@ -4,61 +4,155 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/resilience"
+	"github.com/gin-demo/recipes-web/internal/tracing"
 	"github.com/gin-demo/recipes-web/model"
 	"github.com/rs/xid"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/gin-demo/recipes-web/internal/repository/mongorepo")
+
 const RECIPE_COLLECTION = "recipes"
 
+const defaultOperationTimeout = 5 * time.Second
+
 // Repository implements the recipe repository interface using MongoDB.
 type Repository struct {
 	mongoclient *mongo.Client
 	dbName      string
+	opTimeout   time.Duration
+	retry       resilience.RetryPolicy
+}
+
+// Option configures optional Repository behaviour.
+type Option func(*Repository)
+
+// WithOperationTimeout sets the deadline applied to each repository call.
+func WithOperationTimeout(timeout time.Duration) Option {
+	return func(repo *Repository) {
+		repo.opTimeout = timeout
+	}
+}
+
+// WithRetryPolicy sets the backoff used for connecting and for read operations.
+func WithRetryPolicy(policy resilience.RetryPolicy) Option {
+	return func(repo *Repository) {
+		repo.retry = policy
+	}
 }
 
 // New creates a new Repository instance connected to the specified MongoDB URI and database.
-func New(uri string, dbName string) (*Repository, error) {
+// The initial ping is retried with backoff so a database that is still starting
+// up does not fail the whole process.
+func New(uri string, dbName string, opts ...Option) (*Repository, error) {
 	if uri == "" || dbName == "" {
 		return nil, fmt.Errorf("%w: %v", domain.ErrPersistence, "MONGO_URI or MONGO_DATABASE can't be empty")
 	}
 
+	repo := &Repository{
+		dbName:    dbName,
+		opTimeout: defaultOperationTimeout,
+		retry:     resilience.DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(repo)
+	}
+
 	client, err := mongo.Connect(options.Client().ApplyURI(uri))
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
-	if err := client.Ping(
-		ctx, readpref.Primary()); err != nil {
+
+	policy := repo.retry
+	policy.Timeout = repo.opTimeout
+	err = resilience.Retry(ctx, policy, func(ctx context.Context) error {
+		return client.Ping(ctx, readpref.Primary())
+	})
+	if err != nil {
+		_ = client.Disconnect(context.Background())
 		return nil, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
 	}
 
-	log.Println("Connected to Mongo DB !!!")
-	return &Repository{mongoclient: client, dbName: dbName}, nil
+	slog.Info("connected to mongo", "database", dbName)
+	repo.mongoclient = client
+	return repo, nil
+}
+
+// Ping checks that the primary is reachable within the operation timeout.
+func (repo *Repository) Ping(ctx context.Context) error {
+	return resilience.WithTimeout(ctx, repo.opTimeout, func(ctx context.Context) error {
+		return repo.mongoclient.Ping(ctx, readpref.Primary())
+	})
+}
+
+// read runs an idempotent read with the operation timeout and retry policy.
+// Not-found results are final and never retried.
+func (repo *Repository) read(ctx context.Context, op string, fn func(context.Context) error) error {
+	ctx, span := repo.startSpan(ctx, op)
+
+	policy := repo.retry
+	policy.Timeout = repo.opTimeout
+	policy.Retryable = func(err error) bool {
+		return !notFound(err)
+	}
+	err := resilience.Retry(ctx, policy, fn)
+
+	if notFound(err) {
+		span.End()
+	} else {
+		tracing.End(span, err)
+	}
+	return err
+}
+
+// notFound reports whether err is a missing document rather than a failure.
+func notFound(err error) bool {
+	return errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrListNotFound)
+}
+
+// write runs a single attempt of a mutation bounded by the operation timeout.
+func (repo *Repository) write(ctx context.Context, op string, fn func(context.Context) error) error {
+	ctx, span := repo.startSpan(ctx, op)
+	err := resilience.WithTimeout(ctx, repo.opTimeout, fn)
+	tracing.End(span, err)
+	return err
+}
+
+// startSpan starts a client span for a MongoDB operation on the recipe collection.
+func (repo *Repository) startSpan(ctx context.Context, op string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "mongo."+op, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "mongodb"),
+			attribute.String("db.namespace", repo.dbName),
+			attribute.String("db.collection.name", RECIPE_COLLECTION),
+			attribute.String("db.operation.name", op),
+		),
+	)
 }
 
 // Create adds a new recipe to the repository.
 func (repo *Repository) Create(ctx context.Context, recipe model.Recipe) (model.Recipe, error) {
-	newRecipe := model.Recipe{
-		ID:           model.RecipeID(xid.New().String()),
-		Name:         recipe.Name,
-		Tags:         recipe.Tags,
-		Ingredients:  recipe.Ingredients,
-		Instructions: recipe.Instructions,
-		PublishedAt:  time.Now(),
-	}
+	created := newRecipe(recipe, time.Now())
 
 	collection := repo.collection(RECIPE_COLLECTION)
-	_, err := collection.InsertOne(ctx, newRecipe)
+	err := repo.write(ctx, "insertOne", func(ctx context.Context) error {
+		_, err := collection.InsertOne(ctx, toDocument(created))
+		return err
+	})
 	if err != nil {
 		var writeErr mongo.WriteException
 		if errors.As(err, &writeErr) {
@@ -71,7 +165,14 @@ func (repo *Repository) Create(ctx context.Context, recipe model.Recipe) (model.
 		return model.Recipe{}, fmt.Errorf("%w", domain.ErrPersistence)
 	}
 
-	return newRecipe, nil
+	return created, nil
+}
+
+// newRecipe copies the caller's fields into a recipe with a fresh ID.
+func newRecipe(recipe model.Recipe, publishedAt time.Time) model.Recipe {
+	recipe.ID = model.RecipeID(xid.New().String())
+	recipe.PublishedAt = publishedAt
+	return recipe
 }
 
 // GetByID retrieves a recipe by its ID.
@@ -80,9 +181,15 @@ func (repo *Repository) GetByID(ctx context.Context, id model.RecipeID) (model.R
 	var recipe model.Recipe
 
 	filter := bson.M{"_id": id}
-	err := collection.FindOne(ctx, filter).Decode(&recipe)
-	if err != nil {
+	err := repo.read(ctx, "findOne", func(ctx context.Context) error {
+		err := collection.FindOne(ctx, filter).Decode(&recipe)
 		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.ErrNotFound
+		}
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
 			return model.Recipe{}, domain.ErrNotFound
 		}
 		return model.Recipe{}, fmt.Errorf("%w", domain.ErrPersistence)
@@ -93,25 +200,14 @@ func (repo *Repository) GetByID(ctx context.Context, id model.RecipeID) (model.R
 
 // GetAll returns all recipes in the repository.
 func (repo *Repository) GetAll(ctx context.Context) ([]model.Recipe, error) {
-	collection := repo.collection(RECIPE_COLLECTION)
-
-	cur, err := collection.Find(ctx, bson.M{})
+	var recipes []model.Recipe
+	err := repo.read(ctx, "find", func(ctx context.Context) error {
+		var err error
+		recipes, err = repo.find(ctx, bson.M{})
+		return err
+	})
 	if err != nil {
-		return []model.Recipe{}, fmt.Errorf("%w", domain.ErrPersistence)
-	}
-	defer cur.Close(ctx)
-
-	recipes := make([]model.Recipe, 0)
-	for cur.Next(ctx) {
-		var recipe model.Recipe
-		if err := cur.Decode(&recipe); err != nil {
-			return nil, domain.ErrPersistence
-		}
-		recipes = append(recipes, recipe)
-	}
-
-	if err := cur.Err(); err != nil {
-		return nil, domain.ErrPersistence
+		return nil, fmt.Errorf("%w", domain.ErrPersistence)
 	}
 
 	return recipes, nil
@@ -122,22 +218,20 @@ func (repo *Repository) Update(ctx context.Context, recipe model.Recipe) (model.
 	collection := repo.collection(RECIPE_COLLECTION)
 
 	filter := bson.M{"_id": recipe.ID}
-	update := bson.M{
-		"$set": bson.M{
-			"name":         recipe.Name,
-			"tags":         recipe.Tags,
-			"ingredients":  recipe.Ingredients,
-			"instructions": recipe.Instructions,
-		},
-	}
+	update := bson.M{"$set": editableFields(recipe)}
 
-	_, err := collection.UpdateOne(ctx, filter, update)
+	err := repo.write(ctx, "updateOne", func(ctx context.Context) error {
+		_, err := collection.UpdateOne(ctx, filter, update)
+		return err
+	})
 	if err != nil {
 		return model.Recipe{}, fmt.Errorf("%w", domain.ErrPersistence)
 	}
 
 	var updated model.Recipe
-	err = collection.FindOne(ctx, filter).Decode(&updated)
+	err = repo.read(ctx, "findOne", func(ctx context.Context) error {
+		return collection.FindOne(ctx, filter).Decode(&updated)
+	})
 	if err != nil {
 		return model.Recipe{}, domain.ErrPersistence
 	}
@@ -145,12 +239,126 @@ func (repo *Repository) Update(ctx context.Context, recipe model.Recipe) (model.
 	return updated, nil
 }
 
+// editableFields are the fields Update overwrites; the ID and publication date never change.
+func editableFields(recipe model.Recipe) bson.M {
+	return bson.M{
+		"name":         recipe.Name,
+		"tags":         recipe.Tags,
+		"ingredients":  recipe.Ingredients,
+		"instructions": recipe.Instructions,
+		"sections":     recipe.Sections,
+		"yield":        recipe.Yield,
+		"prepTime":     recipe.PrepTime,
+		"cookTime":     recipe.CookTime,
+		"totalTime":    recipe.TotalTime,
+		"difficulty":   recipe.Difficulty,
+		"cuisine":      recipe.Cuisine,
+		"course":       recipe.Course,
+		"equipment":    recipe.Equipment,
+		"diets":        recipe.Diets,
+		"totalSeconds": totalSeconds(recipe),
+	}
+}
+
+// AddImage pushes an image onto the recipe's images, leaving its other fields as they are.
+func (repo *Repository) AddImage(ctx context.Context, id model.RecipeID, image model.Image) (model.Recipe, error) {
+	return repo.updateImages(ctx, bson.M{"_id": id}, bson.M{"$push": bson.M{"images": image}}, domain.ErrNotFound)
+}
+
+// RemoveImage pulls an image from the recipe's images. An unknown image
+// returns domain.ErrImageNotFound.
+func (repo *Repository) RemoveImage(ctx context.Context, id model.RecipeID, imageID string) (model.Recipe, error) {
+	filter := bson.M{"_id": id, "images.id": imageID}
+	updated, err := repo.updateImages(ctx, filter, bson.M{"$pull": bson.M{"images": bson.M{"id": imageID}}}, domain.ErrImageNotFound)
+	if errors.Is(err, domain.ErrImageNotFound) {
+		// Tell a missing recipe from a missing image.
+		if _, err := repo.GetByID(ctx, id); err != nil {
+			return model.Recipe{}, err
+		}
+		return model.Recipe{}, fmt.Errorf("image %s: %w", imageID, domain.ErrImageNotFound)
+	}
+	return updated, err
+}
+
+// updateImages applies update to the recipe matching filter and returns it
+// as it is afterwards, or notFoundErr when nothing matches.
+func (repo *Repository) updateImages(ctx context.Context, filter, update bson.M, notFoundErr error) (model.Recipe, error) {
+	collection := repo.collection(RECIPE_COLLECTION)
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated model.Recipe
+	err := repo.write(ctx, "findOneAndUpdate", func(ctx context.Context) error {
+		err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return notFoundErr
+		}
+		return err
+	})
+	if errors.Is(err, notFoundErr) {
+		return model.Recipe{}, err
+	}
+	if err != nil {
+		return model.Recipe{}, fmt.Errorf("%w", domain.ErrPersistence)
+	}
+	return updated, nil
+}
+
+// Upsert stores the recipe under its own ID, replacing any existing document with that ID.
+func (repo *Repository) Upsert(ctx context.Context, recipe model.Recipe) (bool, error) {
+	collection := repo.collection(RECIPE_COLLECTION)
+
+	var result *mongo.UpdateResult
+	err := repo.write(ctx, "replaceOne", func(ctx context.Context) error {
+		var err error
+		result, err = collection.ReplaceOne(ctx, bson.M{"_id": recipe.ID}, toDocument(recipe), options.Replace().SetUpsert(true))
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("%w", domain.ErrPersistence)
+	}
+
+	return result.UpsertedCount > 0, nil
+}
+
+// UpsertMany replaces or inserts every recipe by ID in one unordered bulk write.
+func (repo *Repository) UpsertMany(ctx context.Context, recipes []model.Recipe) (int, error) {
+	if len(recipes) == 0 {
+		return 0, nil
+	}
+
+	models := make([]mongo.WriteModel, len(recipes))
+	for i, recipe := range recipes {
+		models[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": recipe.ID}).
+			SetReplacement(toDocument(recipe)).
+			SetUpsert(true)
+	}
+
+	collection := repo.collection(RECIPE_COLLECTION)
+	var result *mongo.BulkWriteResult
+	err := repo.write(ctx, "bulkWrite", func(ctx context.Context) error {
+		var err error
+		result, err = collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+
+	return int(result.UpsertedCount), nil
+}
+
 // Delete removes a recipe from the repository by ID.
 func (repo *Repository) Delete(ctx context.Context, id model.RecipeID) error {
 	collection := repo.collection(RECIPE_COLLECTION)
 
 	filter := bson.M{"_id": id}
-	result, err := collection.DeleteOne(ctx, filter)
+	var result *mongo.DeleteResult
+	err := repo.write(ctx, "deleteOne", func(ctx context.Context) error {
+		var err error
+		result, err = collection.DeleteOne(ctx, filter)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("%w", domain.ErrPersistence)
 	}
@@ -164,33 +372,79 @@ func (repo *Repository) Delete(ctx context.Context, id model.RecipeID) error {
 
 // GetByTag retrieves all recipes that contain the specified tag.
 func (repo *Repository) GetByTag(ctx context.Context, tag string) ([]model.Recipe, error) {
-	collection := repo.collection(RECIPE_COLLECTION)
-
-	filter := bson.M{"tags": tag}
-	cur, err := collection.Find(ctx, filter)
+	var recipes []model.Recipe
+	err := repo.read(ctx, "find", func(ctx context.Context) error {
+		var err error
+		recipes, err = repo.find(ctx, bson.M{"tags": tag})
+		return err
+	})
 	if err != nil {
 		return nil, domain.ErrPersistence
 	}
+
+	if len(recipes) == 0 {
+		return nil, domain.ErrNotFound
+	}
+
+	return recipes, nil
+}
+
+// find decodes every recipe matching filter.
+func (repo *Repository) find(ctx context.Context, filter any) ([]model.Recipe, error) {
+	cur, err := repo.collection(RECIPE_COLLECTION).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
 	defer cur.Close(ctx)
 
 	recipes := make([]model.Recipe, 0)
 	for cur.Next(ctx) {
 		var r model.Recipe
 		if err := cur.Decode(&r); err != nil {
-			return nil, domain.ErrPersistence
+			return nil, err
 		}
 		recipes = append(recipes, r)
 	}
 
 	if err := cur.Err(); err != nil {
-		return nil, domain.ErrPersistence
+		return nil, err
 	}
 
-	if len(recipes) == 0 {
-		return nil, domain.ErrNotFound
+	return recipes, nil
+}
+
+// recipeIndexes are the secondary indexes queries on the recipe collection rely on.
+var recipeIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "tags", Value: 1}}, Options: options.Index().SetName("tags")},
+	{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetName("name")},
+	{Keys: bson.D{{Key: "cuisine", Value: 1}, {Key: "course", Value: 1}}, Options: options.Index().SetName("cuisine_course")},
+	{Keys: bson.D{{Key: "totalSeconds", Value: 1}}, Options: options.Index().SetName("totalSeconds")},
+}
+
+// EnsureIndexes creates any missing secondary indexes on the recipe collection.
+func (repo *Repository) EnsureIndexes(ctx context.Context) error {
+	indexes := repo.collection(RECIPE_COLLECTION).Indexes()
+	return repo.write(ctx, "createIndexes", func(ctx context.Context) error {
+		_, err := indexes.CreateMany(ctx, recipeIndexes)
+		return err
+	})
+}
+
+// Reindex drops every secondary index on the recipe collection and builds them again.
+func (repo *Repository) Reindex(ctx context.Context) error {
+	indexes := repo.collection(RECIPE_COLLECTION).Indexes()
+	err := repo.write(ctx, "dropIndexes", func(ctx context.Context) error {
+		return indexes.DropAll(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrPersistence, err)
 	}
+	return repo.EnsureIndexes(ctx)
+}
 
-	return recipes, nil
+// Collection exposes another collection of the same database, e.g. for the audit trail.
+func (repo *Repository) Collection(name string) *mongo.Collection {
+	return repo.collection(name)
 }
 
 func (repo *Repository) collection(name string) *mongo.Collection {
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-demo/recipes-web/internal/domain"
 	"github.com/gin-demo/recipes-web/model"
@@ -167,6 +168,60 @@ func TestRepositoryUpdate(t *testing.T) {
 	}
 }
 
+func TestRepositoryImages(t *testing.T) {
+	repo := setupTestRepo(t)
+	defer teardownTestRepo(t, repo)
+
+	ctx := context.Background()
+	created, _ := repo.Create(ctx, model.Recipe{Name: "With Photo"})
+	image := model.Image{ID: "img1", Width: 640, Height: 480, Variants: []model.ImageVariant{
+		{Name: "thumb", Width: 160, Height: 120, URL: "/images/thumb.jpg"},
+	}}
+
+	added, err := repo.AddImage(ctx, created.ID, image)
+	if err != nil {
+		t.Fatalf("AddImage failed: %v", err)
+	}
+	if len(added.Images) != 1 || added.Images[0].ID != "img1" || len(added.Images[0].Variants) != 1 {
+		t.Errorf("Image not added: %+v", added.Images)
+	}
+
+	// Update must keep the images it does not write
+	added.Name = "Renamed"
+	added.Images = nil
+	updated, err := repo.Update(ctx, added)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if len(updated.Images) != 1 {
+		t.Errorf("Update dropped images: %+v", updated.Images)
+	}
+
+	removed, err := repo.RemoveImage(ctx, created.ID, "img1")
+	if err != nil {
+		t.Fatalf("RemoveImage failed: %v", err)
+	}
+	if len(removed.Images) != 0 {
+		t.Errorf("Image not removed: %+v", removed.Images)
+	}
+	if removed.Name != "Renamed" {
+		t.Error("RemoveImage changed other fields")
+	}
+
+	_, err = repo.RemoveImage(ctx, created.ID, "img1")
+	if !errors.Is(err, domain.ErrImageNotFound) {
+		t.Errorf("Expected ErrImageNotFound, got %v", err)
+	}
+	_, err = repo.AddImage(ctx, "nonexistent", image)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	_, err = repo.RemoveImage(ctx, "nonexistent", "img1")
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
 func TestRepositoryDelete(t *testing.T) {
 	repo := setupTestRepo(t)
 	defer teardownTestRepo(t, repo)
@@ -224,3 +279,45 @@ func TestRepositoryGetByTag(t *testing.T) {
 		t.Error("Wrong recipe returned")
 	}
 }
+
+func TestFilterQuery(t *testing.T) {
+	query := filterQuery(domain.RecipeFilter{
+		Tags:         []string{"curry"},
+		ExcludeTags:  []string{"spicy"},
+		Cuisine:      "indian",
+		Diets:        []string{"vegan"},
+		MaxTotalTime: time.Hour,
+	})
+	if len(query) != 4 || query["cuisine"] != "indian" {
+		t.Errorf("Unexpected query %v", query)
+	}
+	tags, ok := query["tags"].(bson.M)
+	if !ok || len(tags) != 2 || tags["$all"] == nil || tags["$nin"] == nil {
+		t.Errorf("Expected tags to require curry and exclude spicy, got %v", query["tags"])
+	}
+	if seconds, ok := query["totalSeconds"].(bson.M); !ok || seconds["$lte"] != int64(3600) {
+		t.Errorf("Expected totalSeconds up to an hour, got %v", query["totalSeconds"])
+	}
+	if diets, ok := query["diets"].(bson.M); !ok || len(diets["$all"].([]string)) != 1 {
+		t.Errorf("Expected diets to require every flag, got %v", query["diets"])
+	}
+	if len(filterQuery(domain.RecipeFilter{})) != 0 {
+		t.Error("Expected an empty query for the zero filter")
+	}
+}
+
+func TestTimeBuckets(t *testing.T) {
+	if len(bucketBounds) != len(domain.TimeBuckets)+1 || len(bucketKeys) != len(domain.TimeBuckets) {
+		t.Fatalf("Expected a boundary per bucket, got %v", bucketBounds)
+	}
+	// A recipe taking exactly 15 minutes falls in the first bucket, as in memory.
+	if bucketBounds[1] != int64(901) || bucketKeys[1] != "901" {
+		t.Errorf("Unexpected boundaries %v", bucketBounds)
+	}
+	if seconds := totalSeconds(model.Recipe{PrepTime: "PT10M", CookTime: "PT20M"}); seconds == nil || *seconds != 1800 {
+		t.Errorf("Expected 1800 seconds, got %v", seconds)
+	}
+	if totalSeconds(model.Recipe{}) != nil {
+		t.Error("Expected no total for a recipe without times")
+	}
+}
@@ -0,0 +1,148 @@
+package mongorepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/rs/xid"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const SHOPPING_LIST_COLLECTION = "shopping_lists"
+
+// ShoppingListRepository implements domain.ShoppingListRepository on the
+// shopping_lists collection.
+type ShoppingListRepository struct {
+	repo *Repository
+}
+
+// ShoppingLists returns a shopping list repository sharing the recipe
+// repository's connection. Lists are indexed by owner here.
+func (repo *Repository) ShoppingLists(ctx context.Context) (*ShoppingListRepository, error) {
+	indexes := repo.collection(SHOPPING_LIST_COLLECTION).Indexes()
+	err := repo.write(ctx, "createIndexes", func(ctx context.Context) error {
+		_, err := indexes.CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "owner", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("owner_createdAt"),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+	return &ShoppingListRepository{repo: repo}, nil
+}
+
+// CreateShoppingList stores a new list under a fresh ID.
+func (lists *ShoppingListRepository) CreateShoppingList(ctx context.Context, list model.ShoppingList) (model.ShoppingList, error) {
+	now := time.Now().UTC()
+	list.ID = model.ShoppingListID(xid.New().String())
+	list.CreatedAt = now
+	list.UpdatedAt = now
+
+	collection := lists.repo.collection(SHOPPING_LIST_COLLECTION)
+	err := lists.repo.write(ctx, "insertOne", func(ctx context.Context) error {
+		_, err := collection.InsertOne(ctx, list)
+		return err
+	})
+	if err != nil {
+		return model.ShoppingList{}, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+	return list, nil
+}
+
+// GetShoppingList returns the list with the given ID.
+func (lists *ShoppingListRepository) GetShoppingList(ctx context.Context, id model.ShoppingListID) (model.ShoppingList, error) {
+	collection := lists.repo.collection(SHOPPING_LIST_COLLECTION)
+
+	var list model.ShoppingList
+	err := lists.repo.read(ctx, "findOne", func(ctx context.Context) error {
+		err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&list)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.ErrListNotFound
+		}
+		return err
+	})
+	if errors.Is(err, domain.ErrListNotFound) {
+		return model.ShoppingList{}, fmt.Errorf("shopping list %s: %w", id, domain.ErrListNotFound)
+	}
+	if err != nil {
+		return model.ShoppingList{}, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+	return list, nil
+}
+
+// ListShoppingLists returns the owner's lists, newest first.
+func (lists *ShoppingListRepository) ListShoppingLists(ctx context.Context, owner string) ([]model.ShoppingList, error) {
+	collection := lists.repo.collection(SHOPPING_LIST_COLLECTION)
+
+	found := []model.ShoppingList{}
+	err := lists.repo.read(ctx, "find", func(ctx context.Context) error {
+		opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+		cursor, err := collection.Find(ctx, bson.M{"owner": owner}, opts)
+		if err != nil {
+			return err
+		}
+		found = []model.ShoppingList{}
+		return cursor.All(ctx, &found)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+	return found, nil
+}
+
+// UpdateShoppingList replaces a stored list's name, recipes and items,
+// keeping its owner and creation time.
+func (lists *ShoppingListRepository) UpdateShoppingList(ctx context.Context, list model.ShoppingList) (model.ShoppingList, error) {
+	collection := lists.repo.collection(SHOPPING_LIST_COLLECTION)
+
+	update := bson.M{"$set": bson.M{
+		"name":      list.Name,
+		"recipes":   list.Recipes,
+		"items":     list.Items,
+		"updatedAt": time.Now().UTC(),
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated model.ShoppingList
+	err := lists.repo.write(ctx, "findOneAndUpdate", func(ctx context.Context) error {
+		err := collection.FindOneAndUpdate(ctx, bson.M{"_id": list.ID}, update, opts).Decode(&updated)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.ErrListNotFound
+		}
+		return err
+	})
+	if errors.Is(err, domain.ErrListNotFound) {
+		return model.ShoppingList{}, fmt.Errorf("shopping list %s: %w", list.ID, domain.ErrListNotFound)
+	}
+	if err != nil {
+		return model.ShoppingList{}, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+	return updated, nil
+}
+
+// DeleteShoppingList removes the list with the given ID.
+func (lists *ShoppingListRepository) DeleteShoppingList(ctx context.Context, id model.ShoppingListID) error {
+	collection := lists.repo.collection(SHOPPING_LIST_COLLECTION)
+
+	var result *mongo.DeleteResult
+	err := lists.repo.write(ctx, "deleteOne", func(ctx context.Context) error {
+		var err error
+		result, err = collection.DeleteOne(ctx, bson.M{"_id": id})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("shopping list %s: %w", id, domain.ErrListNotFound)
+	}
+	return nil
+}
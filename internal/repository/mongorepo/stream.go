@@ -0,0 +1,82 @@
+package mongorepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/tracing"
+	"github.com/gin-demo/recipes-web/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Stream walks a cursor over the matching recipes, decoding one document at a
+// time. It is bounded by ctx rather than the operation timeout, since a full
+// export may legitimately take much longer than a single query.
+func (repo *Repository) Stream(ctx context.Context, filter domain.RecipeFilter, fn func(model.Recipe) error) (err error) {
+	ctx, span := repo.startSpan(ctx, "find")
+	defer func() { tracing.End(span, err) }()
+
+	cur, err := repo.collection(RECIPE_COLLECTION).Find(ctx, filterQuery(filter))
+	if err != nil {
+		return repo.streamError(ctx, err)
+	}
+	defer cur.Close(context.WithoutCancel(ctx))
+
+	for cur.Next(ctx) {
+		var recipe model.Recipe
+		if err := cur.Decode(&recipe); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+		}
+		if err := fn(recipe); err != nil {
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return repo.streamError(ctx, err)
+	}
+	return nil
+}
+
+// filterQuery selects the documents matching filter.
+func filterQuery(filter domain.RecipeFilter) bson.M {
+	query := bson.M{}
+	tags := bson.M{}
+	if len(filter.Tags) > 0 {
+		tags["$all"] = filter.Tags
+	}
+	if len(filter.AnyTags) > 0 {
+		tags["$in"] = filter.AnyTags
+	}
+	if len(filter.ExcludeTags) > 0 {
+		tags["$nin"] = filter.ExcludeTags
+	}
+	if len(tags) > 0 {
+		query["tags"] = tags
+	}
+	for field, value := range map[string]string{
+		"cuisine":    filter.Cuisine,
+		"course":     filter.Course,
+		"difficulty": filter.Difficulty,
+	} {
+		if value != "" {
+			query[field] = value
+		}
+	}
+	if len(filter.Diets) > 0 {
+		query["diets"] = bson.M{"$all": filter.Diets}
+	}
+	if filter.MaxTotalTime > 0 {
+		query["totalSeconds"] = bson.M{"$lte": int64(filter.MaxTotalTime / time.Second)}
+	}
+	return query
+}
+
+// streamError reports a cancelled stream as such rather than as a storage failure.
+func (repo *Repository) streamError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+}
@@ -0,0 +1,97 @@
+package mongorepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+	"github.com/rs/xid"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const USER_COLLECTION = "users"
+
+// UserRepository implements domain.UserRepository on the users collection.
+type UserRepository struct {
+	repo *Repository
+}
+
+// Users returns a user repository sharing the recipe repository's connection.
+// User names are kept unique by an index created here.
+func (repo *Repository) Users(ctx context.Context) (*UserRepository, error) {
+	indexes := repo.collection(USER_COLLECTION).Indexes()
+	err := repo.write(ctx, "createIndexes", func(ctx context.Context) error {
+		_, err := indexes.CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "userName", Value: 1}},
+			Options: options.Index().SetName("userName").SetUnique(true),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+	return &UserRepository{repo: repo}, nil
+}
+
+// CreateUser stores a new user; the user name must not be taken.
+func (users *UserRepository) CreateUser(ctx context.Context, user model.User) (model.User, error) {
+	now := time.Now().UTC()
+	user.ID = model.UserID(xid.New().String())
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	collection := users.repo.collection(USER_COLLECTION)
+	err := users.repo.write(ctx, "insertOne", func(ctx context.Context) error {
+		_, err := collection.InsertOne(ctx, user)
+		return err
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return model.User{}, fmt.Errorf("user %q: %w", user.UserName, domain.ErrConflict)
+	}
+	if err != nil {
+		return model.User{}, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+	return user, nil
+}
+
+// GetUserByName returns the user with the given name.
+func (users *UserRepository) GetUserByName(ctx context.Context, name string) (model.User, error) {
+	collection := users.repo.collection(USER_COLLECTION)
+
+	var user model.User
+	err := users.repo.read(ctx, "findOne", func(ctx context.Context) error {
+		err := collection.FindOne(ctx, bson.M{"userName": name}).Decode(&user)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.ErrNotFound
+		}
+		return err
+	})
+	if errors.Is(err, domain.ErrNotFound) {
+		return model.User{}, fmt.Errorf("user %q: %w", name, domain.ErrNotFound)
+	}
+	if err != nil {
+		return model.User{}, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+	return user, nil
+}
+
+// CountUsers returns the number of stored users.
+func (users *UserRepository) CountUsers(ctx context.Context) (int64, error) {
+	collection := users.repo.collection(USER_COLLECTION)
+
+	var count int64
+	err := users.repo.read(ctx, "countDocuments", func(ctx context.Context) error {
+		var err error
+		count, err = collection.CountDocuments(ctx, bson.M{})
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrPersistence, err)
+	}
+	return count, nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// errNoUpsert is returned by the wrappers' upserts when the repository they
+// wrap cannot store recipes under their own IDs.
+var errNoUpsert = errors.New("repository cannot store recipes under their own IDs")
+
+func upsert(ctx context.Context, repo domain.RecipeRepository, r model.Recipe) (bool, error) {
+	upserter, ok := repo.(domain.RecipeUpserter)
+	if !ok {
+		return false, fmt.Errorf("%w: %T", errNoUpsert, repo)
+	}
+	return upserter.Upsert(ctx, r)
+}
+
+// upsertMany uses repo's own batch upsert when it has one and upserts the
+// recipes one at a time otherwise.
+func upsertMany(ctx context.Context, repo domain.RecipeRepository, recipes []model.Recipe) (int, error) {
+	if batch, ok := repo.(domain.RecipeBatchUpserter); ok {
+		return batch.UpsertMany(ctx, recipes)
+	}
+	created := 0
+	for _, r := range recipes {
+		ok, err := upsert(ctx, repo, r)
+		if err != nil {
+			return created, err
+		}
+		if ok {
+			created++
+		}
+	}
+	return created, nil
+}
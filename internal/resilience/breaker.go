@@ -0,0 +1,154 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by Breaker.Allow while the circuit is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the current position of a circuit breaker.
+type State int
+
+const (
+	// StateClosed lets every call through.
+	StateClosed State = iota
+	// StateOpen rejects calls until the open timeout elapses.
+	StateOpen
+	// StateHalfOpen lets a single trial call through to probe recovery.
+	StateHalfOpen
+)
+
+// String returns the lower-case name of the state.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig controls when a Breaker trips and how long it stays open.
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before a trial call is allowed
+	OpenTimeout time.Duration
+}
+
+// Breaker is a consecutive-failure circuit breaker safe for concurrent use.
+type Breaker struct {
+	mu       sync.Mutex
+	config   BreakerConfig
+	state    State
+	failures int
+	openedAt time.Time
+	trialAt  time.Time
+	trial    bool
+	now      func() time.Time
+}
+
+// NewBreaker creates a closed Breaker. Zero config values fall back to
+// 5 failures and a 10 second open timeout.
+func NewBreaker(config BreakerConfig) *Breaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 10 * time.Second
+	}
+	return &Breaker{config: config, now: time.Now}
+}
+
+// Allow reports whether a call may proceed. Callers that get a nil error
+// should report the outcome with Success or Failure; a half-open trial that is
+// never reported is abandoned after the open timeout.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	switch b.state {
+	case StateOpen:
+		if now.Sub(b.openedAt) < b.config.OpenTimeout {
+			return ErrOpen
+		}
+		b.state = StateHalfOpen
+	case StateHalfOpen:
+		if b.trial && now.Sub(b.trialAt) < b.config.OpenTimeout {
+			return ErrOpen
+		}
+	default:
+		return nil
+	}
+
+	b.trial = true
+	b.trialAt = now
+	return nil
+}
+
+// Success records a successful call and closes the circuit.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateClosed
+	b.failures = 0
+	b.trial = false
+}
+
+// Failure records a failed call, opening the circuit once the threshold is
+// reached or immediately if a half-open trial fails.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.config.FailureThreshold {
+		b.open()
+	}
+}
+
+// Trip forces the circuit open, e.g. when a background health check fails.
+func (b *Breaker) Trip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.open()
+}
+
+// State returns the current state of the circuit.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// Do runs fn if the circuit allows it and records the outcome.
+func (b *Breaker) Do(fn func() error) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		b.Failure()
+		return err
+	}
+
+	b.Success()
+	return nil
+}
+
+func (b *Breaker) open() {
+	b.state = StateOpen
+	b.openedAt = b.now()
+	b.trial = false
+}
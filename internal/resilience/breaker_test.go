@@ -0,0 +1,111 @@
+package resilience
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestBreaker(threshold int, openTimeout time.Duration) (*Breaker, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewBreaker(BreakerConfig{FailureThreshold: threshold, OpenTimeout: openTimeout})
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	b, _ := newTestBreaker(3, time.Second)
+
+	for i := 0; i < 2; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow failed while closed: %v", err)
+		}
+		b.Failure()
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("Expected closed below threshold, got %s", b.State())
+	}
+
+	b.Failure()
+	if b.State() != StateOpen {
+		t.Fatalf("Expected open at threshold, got %s", b.State())
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("Expected ErrOpen, got %v", err)
+	}
+}
+
+func TestBreakerHalfOpenRecovery(t *testing.T) {
+	b, now := newTestBreaker(1, time.Second)
+
+	b.Failure()
+	*now = now.Add(2 * time.Second)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected trial call after open timeout, got %v", err)
+	}
+	if b.State() != StateHalfOpen {
+		t.Fatalf("Expected half-open, got %s", b.State())
+	}
+	// Only one trial at a time
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("Expected second call to be rejected, got %v", err)
+	}
+
+	b.Success()
+	if b.State() != StateClosed {
+		t.Errorf("Expected closed after successful trial, got %s", b.State())
+	}
+}
+
+func TestBreakerHalfOpenFailureReopens(t *testing.T) {
+	b, now := newTestBreaker(3, time.Second)
+
+	b.Trip()
+	*now = now.Add(2 * time.Second)
+	_ = b.Allow()
+	b.Failure()
+
+	if b.State() != StateOpen {
+		t.Errorf("Expected failed trial to reopen, got %s", b.State())
+	}
+}
+
+func TestBreakerAbandonedTrial(t *testing.T) {
+	b, now := newTestBreaker(1, time.Second)
+
+	b.Trip()
+	*now = now.Add(2 * time.Second)
+	_ = b.Allow()
+
+	// The trial never reports back; another one is allowed after the timeout
+	*now = now.Add(2 * time.Second)
+	if err := b.Allow(); err != nil {
+		t.Errorf("Expected a new trial after abandoned one, got %v", err)
+	}
+}
+
+func TestBreakerDo(t *testing.T) {
+	b, _ := newTestBreaker(1, time.Minute)
+
+	if err := b.Do(func() error { return nil }); err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+
+	callErr := errors.New("boom")
+	if err := b.Do(func() error { return callErr }); err != callErr {
+		t.Errorf("Expected call error, got %v", err)
+	}
+
+	called := false
+	err := b.Do(func() error { called = true; return nil })
+	if !errors.Is(err, ErrOpen) || called {
+		t.Errorf("Expected open breaker to short-circuit, got %v (called=%v)", err, called)
+	}
+}
+
+func TestStateString(t *testing.T) {
+	if StateHalfOpen.String() != "half-open" {
+		t.Errorf("Unexpected state name %q", StateHalfOpen.String())
+	}
+}
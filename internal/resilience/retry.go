@@ -0,0 +1,92 @@
+package resilience
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy describes a bounded exponential backoff.
+type RetryPolicy struct {
+	// Attempts is the total number of tries, including the first one
+	Attempts int
+	// BaseDelay is the wait before the second attempt; it doubles each retry
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between attempts
+	MaxDelay time.Duration
+	// Timeout bounds each individual attempt; zero means no per-attempt deadline
+	Timeout time.Duration
+	// Retryable decides whether an error is worth another attempt; nil retries every error
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy is three attempts starting at 100ms, capped at 2s.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:  3,
+	BaseDelay: 100 * time.Millisecond,
+	MaxDelay:  2 * time.Second,
+}
+
+// Retry runs fn until it succeeds, returns a non-retryable error, the attempts
+// are exhausted or ctx is done. Only idempotent operations should be retried.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(context.Context) error) error {
+	attempts := policy.Attempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(policy.backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+
+		err = WithTimeout(ctx, policy.Timeout, fn)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		if policy.Retryable != nil && !policy.Retryable(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// WithTimeout runs fn with a context bounded by timeout. A zero timeout runs
+// fn with ctx unchanged.
+func WithTimeout(ctx context.Context, timeout time.Duration, fn func(context.Context) error) error {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return fn(ctx)
+}
+
+// backoff returns the jittered delay before the given retry attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	if delay <= 0 {
+		return 0
+	}
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			delay = p.MaxDelay
+			break
+		}
+	}
+
+	return delay/2 + rand.N(delay/2+1)
+}
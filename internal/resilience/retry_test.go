@@ -0,0 +1,107 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var fastPolicy = RetryPolicy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+func TestRetrySucceedsAfterFailures(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), fastPolicy, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Retry failed: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	calls := 0
+	callErr := errors.New("down")
+	err := Retry(context.Background(), fastPolicy, func(ctx context.Context) error {
+		calls++
+		return callErr
+	})
+	if err != callErr {
+		t.Errorf("Expected last error, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryNonRetryable(t *testing.T) {
+	policy := fastPolicy
+	final := errors.New("not found")
+	policy.Retryable = func(err error) bool { return err != final }
+
+	calls := 0
+	_ = Retry(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		return final
+	})
+	if calls != 1 {
+		t.Errorf("Expected a single call, got %d", calls)
+	}
+}
+
+func TestRetryPerAttemptTimeout(t *testing.T) {
+	policy := fastPolicy
+	policy.Attempts = 2
+	policy.Timeout = 10 * time.Millisecond
+
+	err := Retry(context.Background(), policy, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{Attempts: 5, BaseDelay: time.Second}
+
+	calls := 0
+	done := make(chan error)
+	go func() {
+		done <- Retry(ctx, policy, func(ctx context.Context) error {
+			calls++
+			return errors.New("down")
+		})
+	}()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Expected error after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Retry did not stop after cancellation")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for attempt := 1; attempt < 10; attempt++ {
+		if d := policy.backoff(attempt); d > policy.MaxDelay {
+			t.Errorf("attempt %d: delay %v exceeds max", attempt, d)
+		}
+	}
+}
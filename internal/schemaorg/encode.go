@@ -0,0 +1,115 @@
+package schemaorg
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// Context is the @context of the documents built here.
+const Context = "https://schema.org"
+
+// Recipe is a schema.org Recipe node. Parse reads documents of this shape
+// back into the same model.Recipe.
+type Recipe struct {
+	Context            string   `json:"@context,omitempty"`
+	Type               string   `json:"@type"`
+	Identifier         string   `json:"identifier,omitempty"`
+	Name               string   `json:"name"`
+	DatePublished      string   `json:"datePublished,omitempty"`
+	Keywords           string   `json:"keywords,omitempty"`
+	RecipeYield        string   `json:"recipeYield,omitempty"`
+	PrepTime           string   `json:"prepTime,omitempty"`
+	CookTime           string   `json:"cookTime,omitempty"`
+	TotalTime          string   `json:"totalTime,omitempty"`
+	RecipeCuisine      string   `json:"recipeCuisine,omitempty"`
+	RecipeCategory     string   `json:"recipeCategory,omitempty"`
+	Tool               []string `json:"tool,omitempty"`
+	SuitableForDiet    []string `json:"suitableForDiet,omitempty"`
+	RecipeIngredient   []string `json:"recipeIngredient"`
+	RecipeInstructions []any    `json:"recipeInstructions"`
+}
+
+// HowToStep is one instruction.
+type HowToStep struct {
+	Type string `json:"@type"`
+	Text string `json:"text"`
+}
+
+// HowToSection is a titled group of instructions.
+type HowToSection struct {
+	Type            string      `json:"@type"`
+	Name            string      `json:"name"`
+	ItemListElement []HowToStep `json:"itemListElement"`
+}
+
+// Graph holds several nodes under one @context.
+type Graph struct {
+	Context string   `json:"@context"`
+	Graph   []Recipe `json:"@graph"`
+}
+
+// FromRecipe builds a standalone schema.org Recipe document. Sections become
+// HowToSections; untitled sections and recipes without sections become
+// plain HowToSteps.
+func FromRecipe(r model.Recipe) Recipe {
+	doc := Recipe{
+		Context:            Context,
+		Type:               "Recipe",
+		Identifier:         string(r.ID),
+		Name:               r.Name,
+		Keywords:           strings.Join(r.Tags, ", "),
+		RecipeYield:        r.Yield,
+		PrepTime:           r.PrepTime,
+		CookTime:           r.CookTime,
+		TotalTime:          r.TotalTime,
+		RecipeCuisine:      r.Cuisine,
+		RecipeCategory:     r.Course,
+		Tool:               r.Equipment,
+		RecipeIngredient:   r.Ingredients,
+		RecipeInstructions: []any{},
+	}
+	for _, diet := range r.Diets {
+		for name, flag := range restrictedDiets {
+			if flag == diet {
+				doc.SuitableForDiet = append(doc.SuitableForDiet, Context+"/"+name)
+			}
+		}
+	}
+	if doc.RecipeIngredient == nil {
+		doc.RecipeIngredient = []string{}
+	}
+	if !r.PublishedAt.IsZero() {
+		doc.DatePublished = r.PublishedAt.UTC().Format(time.RFC3339)
+	}
+
+	sections := r.Sections
+	if len(sections) == 0 {
+		sections = []model.InstructionSection{{Steps: r.Instructions}}
+	}
+	for _, section := range sections {
+		steps := make([]HowToStep, len(section.Steps))
+		for i, text := range section.Steps {
+			steps[i] = HowToStep{Type: "HowToStep", Text: text}
+		}
+		if section.Title == "" {
+			for _, step := range steps {
+				doc.RecipeInstructions = append(doc.RecipeInstructions, step)
+			}
+			continue
+		}
+		doc.RecipeInstructions = append(doc.RecipeInstructions, HowToSection{Type: "HowToSection", Name: section.Title, ItemListElement: steps})
+	}
+	return doc
+}
+
+// FromRecipes builds one document holding every recipe in an @graph.
+func FromRecipes(recipes []model.Recipe) Graph {
+	graph := Graph{Context: Context, Graph: make([]Recipe, len(recipes))}
+	for i, r := range recipes {
+		graph.Graph[i] = FromRecipe(r)
+		graph.Graph[i].Context = ""
+	}
+	return graph
+}
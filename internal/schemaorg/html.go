@@ -0,0 +1,51 @@
+package schemaorg
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// scriptTag matches a whole script element, capturing its attributes and body.
+	scriptTag = regexp.MustCompile(`(?is)<script\b([^>]*)>(.*?)</script\s*>`)
+	// jsonLDType matches the type attribute of a JSON-LD script, quoted or not.
+	jsonLDType = regexp.MustCompile(`(?i)\btype\s*=\s*["']?\s*application/ld\+json`)
+	// lineBreak matches the elements that end a line of text.
+	lineBreak = regexp.MustCompile(`(?i)<br\s*/?>|</(p|li|div|h[1-6])\s*>`)
+	markup    = regexp.MustCompile(`<[^>]*>`)
+)
+
+// scripts returns the bodies of the JSON-LD script elements of an HTML page,
+// without any comment or CDATA wrapper.
+func scripts(page string) []string {
+	var out []string
+	for _, m := range scriptTag.FindAllStringSubmatch(page, -1) {
+		if !jsonLDType.MatchString(m[1]) {
+			continue
+		}
+		body := strings.TrimSpace(m[2])
+		for _, wrapper := range [][2]string{{"<!--", "-->"}, {"//<![CDATA[", "//]]>"}, {"<![CDATA[", "]]>"}} {
+			if strings.HasPrefix(body, wrapper[0]) && strings.HasSuffix(body, wrapper[1]) {
+				body = strings.TrimSpace(body[len(wrapper[0]) : len(body)-len(wrapper[1])])
+			}
+		}
+		out = append(out, body)
+	}
+	return out
+}
+
+// htmlLines strips markup and entities from s, which sites often leave in
+// JSON-LD text, and returns its non-empty lines.
+func htmlLines(s string) []string {
+	s = lineBreak.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(markup.ReplaceAllString(s, ""))
+
+	var out []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
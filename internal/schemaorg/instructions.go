@@ -0,0 +1,79 @@
+package schemaorg
+
+import "github.com/gin-demo/recipes-web/model"
+
+// steps flattens recipeInstructions, which may be text, a list of strings,
+// HowToSteps or HowToSections of HowToSteps, keeping the section titles.
+type steps struct {
+	groups []model.InstructionSection
+	// open is false when the next loose step must start a new untitled group
+	open bool
+}
+
+func (s *steps) add(v any) {
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			s.add(item)
+		}
+	case map[string]any:
+		switch {
+		case hasType(v["@type"], "howtosection"):
+			s.groups = append(s.groups, model.InstructionSection{Title: text(v["name"])})
+			s.open = true
+			s.add(v["itemListElement"])
+			s.open = false
+		case hasType(v["@type"], "itemlist"):
+			s.add(v["itemListElement"])
+		default:
+			// HowToStep, HowToDirection, HowToTip or an untyped step.
+			step := lines(v["text"])
+			if len(step) == 0 {
+				step = lines(v["name"])
+			}
+			for _, line := range step {
+				s.step(line)
+			}
+			s.add(v["itemListElement"])
+		}
+	default:
+		for _, line := range lines(v) {
+			s.step(line)
+		}
+	}
+}
+
+func (s *steps) step(line string) {
+	if !s.open {
+		s.groups = append(s.groups, model.InstructionSection{})
+		s.open = true
+	}
+	last := &s.groups[len(s.groups)-1]
+	last.Steps = append(last.Steps, line)
+}
+
+// all returns every step in order.
+func (s *steps) all() []string {
+	out := []string{}
+	for _, group := range s.groups {
+		out = append(out, group.Steps...)
+	}
+	return out
+}
+
+// sections returns the groups that have steps, or nil when none has a title.
+func (s *steps) sections() []model.InstructionSection {
+	titled := false
+	var out []model.InstructionSection
+	for _, group := range s.groups {
+		if len(group.Steps) == 0 {
+			continue
+		}
+		titled = titled || group.Title != ""
+		out = append(out, group)
+	}
+	if !titled {
+		return nil
+	}
+	return out
+}
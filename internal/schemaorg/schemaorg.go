@@ -0,0 +1,317 @@
+// Package schemaorg extracts recipes from schema.org Recipe markup, either a
+// JSON-LD document or the <script type="application/ld+json"> blocks of a web
+// page.
+package schemaorg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// ErrNoRecipe is returned when a document holds no schema.org Recipe.
+var ErrNoRecipe = errors.New("no schema.org Recipe found")
+
+// Option configures optional parsing behaviour.
+type Option func(*parser)
+
+// WithTagLimits keeps at most maxTags tags of at most maxLength characters,
+// dropping the rest; blogs often list far more keywords than a recipe needs.
+// Zero means no limit.
+func WithTagLimits(maxTags, maxLength int) Option {
+	return func(p *parser) {
+		p.maxTags = maxTags
+		p.maxTagLength = maxLength
+	}
+}
+
+type parser struct {
+	maxTags      int
+	maxTagLength int
+}
+
+// Parse extracts every Recipe from an HTML page or a JSON-LD document. A
+// document starting with '{' or '[' is read as JSON-LD and must be valid;
+// otherwise it is read as HTML and JSON-LD blocks that fail to decode are
+// skipped. The recipes have no ID and are not normalized.
+func Parse(r io.Reader, opts ...Option) ([]model.Recipe, error) {
+	p := &parser{}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("\xef\xbb\xbf"))
+
+	var nodes []map[string]any
+	if len(data) > 0 && (data[0] == '{' || data[0] == '[') {
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid JSON-LD: %w", err)
+		}
+		collect(doc, &nodes)
+	} else {
+		for _, block := range scripts(string(data)) {
+			var doc any
+			if json.Unmarshal([]byte(block), &doc) == nil {
+				collect(doc, &nodes)
+			}
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, ErrNoRecipe
+	}
+
+	recipes := make([]model.Recipe, len(nodes))
+	for i, node := range nodes {
+		recipes[i] = p.recipe(node)
+	}
+	return recipes, nil
+}
+
+// collect appends every Recipe node found in v, looking inside @graph arrays
+// and nested objects such as a WebPage's mainEntity.
+func collect(v any, out *[]map[string]any) {
+	switch v := v.(type) {
+	case map[string]any:
+		if isRecipe(v["@type"]) {
+			*out = append(*out, v)
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			collect(v[key], out)
+		}
+	case []any:
+		for _, item := range v {
+			collect(item, out)
+		}
+	}
+}
+
+// isRecipe reports whether an @type value names Recipe, possibly among other
+// types and possibly as a full IRI.
+func isRecipe(v any) bool {
+	return hasType(v, "recipe")
+}
+
+func hasType(v any, name string) bool {
+	switch v := v.(type) {
+	case string:
+		t := strings.ToLower(strings.TrimSpace(v))
+		if i := strings.LastIndexAny(t, "/:"); i >= 0 {
+			t = t[i+1:]
+		}
+		return t == name
+	case []any:
+		for _, item := range v {
+			if hasType(item, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *parser) recipe(node map[string]any) model.Recipe {
+	name := text(node["name"])
+	if name == "" {
+		name = text(node["headline"])
+	}
+
+	ingredients := node["recipeIngredient"]
+	if ingredients == nil {
+		ingredients = node["ingredients"]
+	}
+
+	instructions := &steps{}
+	instructions.add(node["recipeInstructions"])
+
+	return model.Recipe{
+		Name:         name,
+		Tags:         p.tags(node["recipeCategory"], node["keywords"]),
+		Ingredients:  texts(ingredients),
+		Instructions: instructions.all(),
+		Sections:     instructions.sections(),
+		Yield:        yield(node["recipeYield"]),
+		PrepTime:     duration(node["prepTime"]),
+		CookTime:     duration(node["cookTime"]),
+		TotalTime:    duration(node["totalTime"]),
+		Cuisine:      first(node["recipeCuisine"]),
+		Course:       first(node["recipeCategory"]),
+		Equipment:    tools(node["tool"]),
+		Diets:        diets(node["suitableForDiet"]),
+	}
+}
+
+// first returns the first of a list of categories as a keyword, such as
+// "main course" for ["Main Course", "Dinner"].
+func first(v any) string {
+	for _, item := range texts(v) {
+		for _, keyword := range strings.Split(item, ",") {
+			if k := tagText(keyword); k != "" {
+				return k
+			}
+		}
+	}
+	return ""
+}
+
+// tools returns the names of tools given as text or HowTool nodes.
+func tools(v any) []string {
+	items, ok := v.([]any)
+	if !ok {
+		items = []any{v}
+	}
+	var out []string
+	for _, item := range items {
+		if node, ok := item.(map[string]any); ok && node["name"] != nil {
+			item = node["name"]
+		}
+		if t := text(item); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// restrictedDiets maps the schema.org RestrictedDiet values to model.Diets.
+var restrictedDiets = map[string]string{
+	"GlutenFreeDiet": "gluten-free",
+	"HalalDiet":      "halal",
+	"KosherDiet":     "kosher",
+	"VeganDiet":      "vegan",
+	"VegetarianDiet": "vegetarian",
+}
+
+// diets maps suitableForDiet values, full URLs or bare names, to diet
+// flags, dropping those without an equivalent.
+func diets(v any) []string {
+	var out []string
+	for _, item := range texts(v) {
+		name := item[strings.LastIndexAny(item, "/:")+1:]
+		if diet, ok := restrictedDiets[name]; ok && !slices.Contains(out, diet) {
+			out = append(out, diet)
+		}
+	}
+	return out
+}
+
+// tags turns categories and keywords, either lists or comma-separated
+// strings, into tags: lowercase, without punctuation and without duplicates.
+func (p *parser) tags(values ...any) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, v := range values {
+		for _, item := range texts(v) {
+			for _, keyword := range strings.Split(item, ",") {
+				tag := tagText(keyword)
+				if tag == "" || seen[tag] {
+					continue
+				}
+				if p.maxTagLength > 0 && len([]rune(tag)) > p.maxTagLength {
+					continue
+				}
+				if p.maxTags > 0 && len(tags) == p.maxTags {
+					return tags
+				}
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// tagText keeps the letters, digits, spaces, hyphens and underscores of s.
+func tagText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ' || r == '-' || r == '_':
+			return r
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return r
+		}
+		return -1
+	}, strings.ToLower(s))
+	return strings.Trim(strings.Join(strings.Fields(s), " "), "-_ ")
+}
+
+// yield picks the most descriptive of possibly several yields, such as
+// "4 servings" over "4".
+func yield(v any) string {
+	values := texts(v)
+	for _, value := range values {
+		if strings.IndexFunc(value, unicode.IsLetter) >= 0 {
+			return value
+		}
+	}
+	if len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// duration returns v in canonical ISO 8601 form, or nothing if it does not parse.
+func duration(v any) string {
+	d, err := model.ParseDuration(text(v))
+	if err != nil {
+		return ""
+	}
+	return model.FormatDuration(d)
+}
+
+// text returns v as a single line of plain text.
+func text(v any) string {
+	return strings.Join(strings.Fields(strings.Join(lines(v), " ")), " ")
+}
+
+// texts returns each item of a list, or v itself, as a line of plain text.
+func texts(v any) []string {
+	var out []string
+	items, ok := v.([]any)
+	if !ok {
+		items = []any{v}
+	}
+	for _, item := range items {
+		if t := text(item); t != "" {
+			out = append(out, t)
+		}
+	}
+	if out == nil {
+		return []string{}
+	}
+	return out
+}
+
+// lines returns the non-empty lines of a string, number or {"@value": ...}
+// object with markup removed.
+func lines(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return htmlLines(v)
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case map[string]any:
+		if value, ok := v["@value"]; ok {
+			return lines(value)
+		}
+	}
+	return nil
+}
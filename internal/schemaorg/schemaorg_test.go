@@ -0,0 +1,145 @@
+package schemaorg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/model"
+)
+
+func parseFile(t *testing.T, name string, opts ...Option) ([]model.Recipe, error) {
+	t.Helper()
+	file, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatalf("open fixture: %v", err)
+	}
+	defer file.Close()
+	return Parse(file, opts...)
+}
+
+func TestParseHTMLWithGraph(t *testing.T) {
+	recipes, err := parseFile(t, "blog.html")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(recipes) != 1 {
+		t.Fatalf("Expected 1 recipe, got %d", len(recipes))
+	}
+	r := recipes[0]
+
+	if r.Name != "Weeknight Chicken Curry" {
+		t.Errorf("Name = %q", r.Name)
+	}
+	if want := []string{"main course", "dinner", "chicken curry", "easy dinner", "moms favourite"}; !slices.Equal(r.Tags, want) {
+		t.Errorf("Tags = %q, want %q", r.Tags, want)
+	}
+	if want := "½ cup plain yoghurt"; r.Ingredients[2] != want {
+		t.Errorf("Ingredients[2] = %q, want %q", r.Ingredients[2], want)
+	}
+	if r.Yield != "4 servings" || r.PrepTime != "PT15M" || r.CookTime != "PT30M" || r.TotalTime != "PT45M" {
+		t.Errorf("Yield and times = %q %q %q %q", r.Yield, r.PrepTime, r.CookTime, r.TotalTime)
+	}
+	if r.Cuisine != "indian" || r.Course != "main course" {
+		t.Errorf("Cuisine and course = %q %q", r.Cuisine, r.Course)
+	}
+
+	want := []model.InstructionSection{
+		{Title: "For the marinade", Steps: []string{"Mix the yoghurt with the spices.", "Coat the chicken and leave for 10 minutes."}},
+		{Title: "For the curry", Steps: []string{"Fry the onion in the oil until golden.", "Add the chicken and simmer for 25 minutes."}},
+	}
+	if !reflect.DeepEqual(r.Sections, want) {
+		t.Errorf("Sections = %+v", r.Sections)
+	}
+	if len(r.Instructions) != 4 || r.Instructions[2] != "Fry the onion in the oil until golden." {
+		t.Errorf("Instructions = %q", r.Instructions)
+	}
+}
+
+func TestParseJSONLD(t *testing.T) {
+	recipes, err := parseFile(t, "recipe.jsonld")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	r := recipes[0]
+
+	if r.Name != "Classic Pancakes" {
+		t.Errorf("Name = %q, want the headline", r.Name)
+	}
+	if want := []string{"breakfast", "sweet"}; !slices.Equal(r.Tags, want) {
+		t.Errorf("Tags = %q, want %q", r.Tags, want)
+	}
+	if want := []string{"Whisk the flour, eggs and milk.", "Fry ladlefuls in a hot pan,", "flipping once."}; !slices.Equal(r.Instructions, want) {
+		t.Errorf("Instructions = %q, want %q", r.Instructions, want)
+	}
+	if r.Sections != nil {
+		t.Errorf("Sections = %+v, want none without HowToSections", r.Sections)
+	}
+	if r.Yield != "8" {
+		t.Errorf("Yield = %q", r.Yield)
+	}
+	if r.CookTime != "" {
+		t.Errorf("CookTime = %q, want an unparseable duration dropped", r.CookTime)
+	}
+}
+
+func TestParseTagLimits(t *testing.T) {
+	recipes, err := parseFile(t, "blog.html", WithTagLimits(2, 6))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{"dinner"}; !slices.Equal(recipes[0].Tags, want) {
+		t.Errorf("Tags = %q, want %q", recipes[0].Tags, want)
+	}
+
+	recipes, _ = parseFile(t, "blog.html", WithTagLimits(2, 0))
+	if want := []string{"main course", "dinner"}; !slices.Equal(recipes[0].Tags, want) {
+		t.Errorf("Tags = %q, want %q", recipes[0].Tags, want)
+	}
+}
+
+func TestParseWithoutRecipe(t *testing.T) {
+	if _, err := parseFile(t, "no-recipe.html"); !errors.Is(err, ErrNoRecipe) {
+		t.Errorf("Expected ErrNoRecipe, got %v", err)
+	}
+	if _, err := Parse(strings.NewReader(`{"@type": "Recipe",`)); err == nil || errors.Is(err, ErrNoRecipe) {
+		t.Errorf("Expected a JSON error, got %v", err)
+	}
+}
+
+func TestFromRecipeRoundTrip(t *testing.T) {
+	want := model.Recipe{
+		Name:         "Chicken Curry",
+		Tags:         []string{"main course", "indian"},
+		Ingredients:  []string{"1 onion", "500 g chicken"},
+		Instructions: []string{"Mix the marinade.", "Fry the onion.", "Simmer."},
+		Sections: []model.InstructionSection{
+			{Steps: []string{"Mix the marinade."}},
+			{Title: "For the curry", Steps: []string{"Fry the onion.", "Simmer."}},
+		},
+		Yield:     "4 servings",
+		PrepTime:  "PT15M",
+		TotalTime: "PT1H",
+		Cuisine:   "indian",
+		Course:    "main course",
+		Equipment: []string{"heavy pan"},
+		Diets:     []string{"gluten-free"},
+	}
+
+	data, err := json.Marshal(FromRecipe(want))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	recipes, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !reflect.DeepEqual(recipes[0], want) {
+		t.Errorf("Round trip mismatch:\n got %+v\nwant %+v", recipes[0], want)
+	}
+}
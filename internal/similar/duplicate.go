@@ -0,0 +1,316 @@
+package similar
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/ingredient"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// Two recipes are suspected duplicates when their names have the same words
+// and they share at least sameNameOverlap of their ingredients, or when they
+// share at least nearNameOverlap of their name words and nearOverlap of
+// their ingredients. Overlaps are Jaccard indexes: the share of the union
+// found in both.
+const (
+	sameNameOverlap = 0.5
+	nearNameOverlap = 0.5
+	nearOverlap     = 0.8
+)
+
+// The ingredient set of a recipe is summarized by a MinHash signature cut
+// into bands. Recipes sharing any band are compared; with 8 bands of 4
+// hashes, pairs sharing 80% of their ingredients meet 98% of the time.
+const (
+	signatureSize = 32
+	bandSize      = 4
+)
+
+// fingerprint is what duplicate detection compares of a recipe.
+type fingerprint struct {
+	name string
+	// words and ingredients are sorted and unique; key joins the words
+	words       []string
+	key         string
+	ingredients []string
+	signature   []uint64
+}
+
+func fingerprintOf(recipe model.Recipe) fingerprint {
+	fp := fingerprint{name: recipe.Name, words: nameWords(recipe.Name)}
+	slices.Sort(fp.words)
+	fp.words = slices.Compact(fp.words)
+	fp.key = strings.Join(fp.words, " ")
+
+	for _, line := range recipe.Ingredients {
+		if name := ingredient.Name(line); name != "" && !ingredient.IsStaple(name) {
+			fp.ingredients = append(fp.ingredients, name)
+		}
+	}
+	slices.Sort(fp.ingredients)
+	fp.ingredients = slices.Compact(fp.ingredients)
+	if len(fp.ingredients) > 0 {
+		fp.signature = minHash(fp.ingredients)
+	}
+	return fp
+}
+
+// minHash returns the smallest hash of the items under each of
+// signatureSize hash functions.
+func minHash(items []string) []uint64 {
+	sig := make([]uint64, signatureSize)
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	for _, item := range items {
+		h := fnv.New64a()
+		h.Write([]byte(item))
+		base := h.Sum64()
+		for i := range sig {
+			sig[i] = min(sig[i], mix(base^seed(i)))
+		}
+	}
+	return sig
+}
+
+// seed derives the i-th hash function's seed.
+func seed(i int) uint64 {
+	return mix(uint64(i+1) * 0x9e3779b97f4a7c15)
+}
+
+// mix is the splitmix64 finalizer, spreading every input bit over the output.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// buckets returns the keys under which fp is filed: its name and each band
+// of its signature.
+func (fp fingerprint) buckets() []string {
+	var keys []string
+	if fp.key != "" {
+		keys = append(keys, "name:"+fp.key)
+	}
+	var buf [8 * bandSize]byte
+	for band := 0; band*bandSize < len(fp.signature); band++ {
+		for i, v := range fp.signature[band*bandSize : (band+1)*bandSize] {
+			binary.LittleEndian.PutUint64(buf[8*i:], v)
+		}
+		keys = append(keys, fmt.Sprintf("band%d:%x", band, buf))
+	}
+	return keys
+}
+
+// compare returns how alike two fingerprints are, from 0 to 1, and whether
+// they are suspected duplicates.
+func compare(a, b fingerprint) (float64, bool) {
+	names, ingredients := jaccard(a.words, b.words), jaccard(a.ingredients, b.ingredients)
+	dup := (a.key != "" && a.key == b.key && ingredients >= sameNameOverlap) ||
+		(names >= nearNameOverlap && ingredients >= nearOverlap)
+	return (names + ingredients) / 2, dup
+}
+
+// jaccard is the share of the union of two sorted sets found in both; two
+// empty sets are alike.
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	both := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch c := strings.Compare(a[i], b[j]); {
+		case c == 0:
+			both++
+			i++
+			j++
+		case c < 0:
+			i++
+		default:
+			j++
+		}
+	}
+	return float64(both) / float64(len(a)+len(b)-both)
+}
+
+// Batch finds suspected duplicates among recipes that are not indexed, such
+// as the items of one request, known by the order they were added in. It is
+// not safe for concurrent use.
+type Batch struct {
+	ids     []model.RecipeID
+	prints  []fingerprint
+	buckets map[string][]int
+}
+
+// BatchMatch is a recipe of a Batch suspected to be the same as another.
+type BatchMatch struct {
+	// Index is the position of the recipe in the batch, from 0
+	Index int
+	Score float64
+}
+
+// NewBatch returns an empty Batch.
+func NewBatch() *Batch {
+	return &Batch{buckets: map[string][]int{}}
+}
+
+// Add appends recipe to the batch.
+func (b *Batch) Add(recipe model.Recipe) {
+	fp := fingerprintOf(recipe)
+	for _, key := range fp.buckets() {
+		b.buckets[key] = append(b.buckets[key], len(b.prints))
+	}
+	b.ids = append(b.ids, recipe.ID)
+	b.prints = append(b.prints, fp)
+}
+
+// Duplicates returns the recipes added to the batch suspected to be the same
+// as recipe, most alike first. Those with the same non-empty ID are the same
+// recipe rather than a duplicate, and are left out.
+func (b *Batch) Duplicates(recipe model.Recipe) []BatchMatch {
+	fp := fingerprintOf(recipe)
+	seen := map[int]bool{}
+	var matches []BatchMatch
+	for _, key := range fp.buckets() {
+		for _, i := range b.buckets[key] {
+			if seen[i] || (recipe.ID != "" && b.ids[i] == recipe.ID) {
+				continue
+			}
+			seen[i] = true
+			if score, dup := compare(fp, b.prints[i]); dup {
+				matches = append(matches, BatchMatch{Index: i, Score: score})
+			}
+		}
+	}
+	slices.SortFunc(matches, func(a, b BatchMatch) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Index, b.Index))
+	})
+	return matches
+}
+
+// Cluster is a group of recipes suspected to be the same.
+type Cluster struct {
+	// IDs are sorted
+	IDs []model.RecipeID
+	// Score is the lowest score among the pairs linking the cluster
+	Score float64
+}
+
+// Duplicates returns up to n indexed recipes suspected to be the same as
+// recipe, most alike first, leaving recipe itself out.
+func (ix *Index) Duplicates(recipe model.Recipe, n int) ([]domain.Duplicate, error) {
+	fp := fingerprintOf(recipe)
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if !ix.ready {
+		return nil, ErrNotReady
+	}
+
+	seen := map[model.RecipeID]bool{recipe.ID: true}
+	var dups []domain.Duplicate
+	for _, key := range fp.buckets() {
+		for id := range ix.s.buckets[key] {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			other := ix.s.prints[id]
+			if score, dup := compare(fp, other); dup {
+				dups = append(dups, domain.Duplicate{ID: id, Name: other.name, Score: score})
+			}
+		}
+	}
+	slices.SortFunc(dups, func(a, b domain.Duplicate) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.ID, b.ID))
+	})
+	if len(dups) > n {
+		dups = dups[:n]
+	}
+	return dups, nil
+}
+
+// Clusters groups every indexed recipe suspected to be the same as another,
+// linking duplicates of duplicates. Clusters are ordered by score, highest
+// first, then by their first ID.
+func (ix *Index) Clusters() ([]Cluster, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if !ix.ready {
+		return nil, ErrNotReady
+	}
+
+	parent := map[model.RecipeID]model.RecipeID{}
+	var find func(model.RecipeID) model.RecipeID
+	find = func(id model.RecipeID) model.RecipeID {
+		p, ok := parent[id]
+		if !ok || p == id {
+			return id
+		}
+		root := find(p)
+		parent[id] = root
+		return root
+	}
+	scores := map[model.RecipeID]float64{}
+	compared := map[[2]model.RecipeID]bool{}
+
+	for _, bucket := range ix.s.buckets {
+		if len(bucket) < 2 {
+			continue
+		}
+		ids := make([]model.RecipeID, 0, len(bucket))
+		for id := range bucket {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+		for i, a := range ids {
+			for _, b := range ids[i+1:] {
+				if compared[[2]model.RecipeID{a, b}] {
+					continue
+				}
+				compared[[2]model.RecipeID{a, b}] = true
+				score, dup := compare(ix.s.prints[a], ix.s.prints[b])
+				if !dup {
+					continue
+				}
+				ra, rb := find(a), find(b)
+				low := score
+				for _, r := range []model.RecipeID{ra, rb} {
+					if s, ok := scores[r]; ok {
+						low = min(low, s)
+					}
+				}
+				if ra != rb {
+					parent[rb] = ra
+					delete(scores, rb)
+				}
+				parent[ra] = ra
+				scores[ra] = low
+			}
+		}
+	}
+
+	members := map[model.RecipeID][]model.RecipeID{}
+	for id := range parent {
+		root := find(id)
+		members[root] = append(members[root], id)
+	}
+	clusters := make([]Cluster, 0, len(members))
+	for root, ids := range members {
+		slices.Sort(ids)
+		clusters = append(clusters, Cluster{IDs: ids, Score: scores[root]})
+	}
+	slices.SortFunc(clusters, func(a, b Cluster) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.IDs[0], b.IDs[0]))
+	})
+	return clusters, nil
+}
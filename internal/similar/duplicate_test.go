@@ -0,0 +1,94 @@
+package similar
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+func TestIndexDuplicates(t *testing.T) {
+	catalog := append(slices.Clone(recipes),
+		model.Recipe{ID: "5", Name: "Chocolate Cake", Ingredients: []string{"2 cups flour", "1 cup sugar", "1/2 cup cocoa powder"}},
+		model.Recipe{ID: "6", Name: "Easy chocolate cake", Ingredients: []string{"2 cups flour", "1 cup sugar", "1/2 cup cocoa", "2 eggs"}},
+		model.Recipe{ID: "7", Name: "Chocolate cookies", Ingredients: []string{"2 cups flour", "1 cup sugar", "1/2 cup cocoa"}},
+	)
+	ix := NewIndex()
+	if err := ix.Rebuild(context.Background(), &mockRepo{recipes: catalog}); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	// Same name words, so half the ingredients are enough; the cookies are a
+	// different dish despite the same ingredients.
+	dups, err := ix.Duplicates(model.Recipe{Name: "chocolate cakes", Ingredients: []string{"flour", "sugar", "cocoa"}}, 5)
+	if err != nil {
+		t.Fatalf("Duplicates failed: %v", err)
+	}
+	var got []model.RecipeID
+	for _, d := range dups {
+		got = append(got, d.ID)
+	}
+	if want := []model.RecipeID{"4", "6", "5"}; !slices.Equal(got, want) {
+		t.Fatalf("Expected %v, got %+v", want, dups)
+	}
+	if dups[0] != (domain.Duplicate{ID: "4", Name: "Chocolate cake", Score: 1}) {
+		t.Errorf("Expected an exact match first, got %+v", dups[0])
+	}
+	if dups, _ := ix.Duplicates(catalog[3], 5); len(dups) != 2 {
+		t.Errorf("Expected a recipe not to be its own duplicate, got %+v", dups)
+	}
+
+	clusters, err := ix.Clusters()
+	if err != nil {
+		t.Fatalf("Clusters failed: %v", err)
+	}
+	if len(clusters) != 1 || !slices.Equal(clusters[0].IDs, []model.RecipeID{"4", "5", "6"}) {
+		t.Fatalf("Expected the three cakes in one cluster, got %+v", clusters)
+	}
+	if clusters[0].Score != 0.75 {
+		t.Errorf("Expected the weakest link's score, got %v", clusters[0].Score)
+	}
+
+	// Without the original, the two variants are too far apart.
+	ix.Remove("4")
+	if clusters, _ := ix.Clusters(); len(clusters) != 0 {
+		t.Errorf("Expected no clusters, got %+v", clusters)
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	for _, tc := range []struct {
+		a, b []string
+		want float64
+	}{
+		{nil, nil, 1},
+		{[]string{"a"}, nil, 0},
+		{[]string{"a", "b"}, []string{"b", "c"}, 1.0 / 3},
+		{[]string{"a", "b"}, []string{"a", "b"}, 1},
+	} {
+		if got := jaccard(tc.a, tc.b); got != tc.want {
+			t.Errorf("jaccard(%v, %v) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestBatchDuplicates(t *testing.T) {
+	b := NewBatch()
+	cake := model.Recipe{Name: "Chocolate cake", Ingredients: []string{"flour", "sugar", "cocoa"}}
+	if got := b.Duplicates(cake); len(got) != 0 {
+		t.Fatalf("Expected no duplicates in an empty batch, got %+v", got)
+	}
+	b.Add(cake)
+	b.Add(model.Recipe{Name: "Chocolate cookies", Ingredients: []string{"flour", "sugar", "cocoa"}})
+	b.Add(model.Recipe{ID: "c", Name: "Easy chocolate cake", Ingredients: []string{"flour", "sugar", "cocoa", "eggs"}})
+
+	got := b.Duplicates(model.Recipe{Name: "Chocolate cakes", Ingredients: []string{"cocoa", "sugar", "flour"}})
+	if want := []BatchMatch{{Index: 0, Score: 1}, {Index: 2, Score: 0.875}}; !slices.Equal(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if got := b.Duplicates(model.Recipe{ID: "c", Name: "Easy chocolate cake", Ingredients: []string{"flour", "sugar", "cocoa", "eggs"}}); len(got) != 1 || got[0].Index != 0 {
+		t.Errorf("Expected a recipe not to be its own duplicate, got %+v", got)
+	}
+}
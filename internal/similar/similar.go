@@ -0,0 +1,332 @@
+// Package similar finds related recipes by comparing their tags, ingredients
+// and name words. Each recipe is a TF-IDF vector over those terms, so terms
+// shared by few recipes count for more, and recipes are ranked by the cosine
+// of their vectors. The same index fingerprints recipes to find suspected
+// duplicates; see Duplicates. Everything is computed in memory and the same
+// recipes always give the same ranking.
+package similar
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/internal/ingredient"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+// ErrNotReady is returned until the index has been built once.
+var ErrNotReady = errors.New("similarity index not ready")
+
+// Weights of the kinds of term. Name words are noisier than tags and
+// ingredients, so they count for less.
+const (
+	tagWeight        = 1.0
+	ingredientWeight = 1.0
+	nameWeight       = 0.5
+)
+
+// stopwords are name words that say nothing about the dish.
+var stopwords = map[string]bool{
+	"and": true, "the": true, "with": true, "for": true, "from": true, "easy": true,
+	"best": true, "recipe": true, "style": true, "homemade": true, "simple": true, "quick": true,
+}
+
+// Match is a recipe related to another and how closely, from 0 to 1.
+type Match struct {
+	ID    model.RecipeID
+	Score float64
+}
+
+type term struct {
+	key    string
+	weight float64
+}
+
+// terms returns the recipe's terms sorted by key, so scores are summed in the
+// same order every time.
+func terms(recipe model.Recipe) []term {
+	set := map[string]float64{}
+	for _, tag := range recipe.Tags {
+		set["tag:"+strings.ToLower(tag)] = tagWeight
+	}
+	for _, line := range recipe.Ingredients {
+		if name := ingredient.Name(line); name != "" && !ingredient.IsStaple(name) {
+			set["ingredient:"+name] = ingredientWeight
+		}
+	}
+	for _, word := range nameWords(recipe.Name) {
+		set["name:"+word] = nameWeight
+	}
+
+	out := make([]term, 0, len(set))
+	for key, weight := range set {
+		out = append(out, term{key, weight})
+	}
+	slices.SortFunc(out, func(a, b term) int { return strings.Compare(a.key, b.key) })
+	return out
+}
+
+// nameWords returns the words of a recipe name that say something about the
+// dish, lowercased and singular.
+func nameWords(name string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len(word) > 2 && !stopwords[word] {
+			words = append(words, ingredient.Singular(word))
+		}
+	}
+	return words
+}
+
+// state holds the indexed terms and fingerprint of every recipe.
+type state struct {
+	docs map[model.RecipeID][]term
+	// df counts the recipes having each term; postings lists them
+	df       map[string]int
+	postings map[string]map[model.RecipeID]struct{}
+	// prints are the recipes' fingerprints; buckets group the recipes
+	// whose fingerprints share a bucket key
+	prints  map[model.RecipeID]fingerprint
+	buckets map[string]map[model.RecipeID]struct{}
+}
+
+func newState() *state {
+	return &state{
+		docs:     map[model.RecipeID][]term{},
+		df:       map[string]int{},
+		postings: map[string]map[model.RecipeID]struct{}{},
+		prints:   map[model.RecipeID]fingerprint{},
+		buckets:  map[string]map[model.RecipeID]struct{}{},
+	}
+}
+
+func (s *state) put(id model.RecipeID, ts []term, fp fingerprint) {
+	s.remove(id)
+	s.docs[id] = ts
+	for _, t := range ts {
+		s.df[t.key]++
+		addPosting(s.postings, t.key, id)
+	}
+	s.prints[id] = fp
+	for _, key := range fp.buckets() {
+		addPosting(s.buckets, key, id)
+	}
+}
+
+func (s *state) remove(id model.RecipeID) {
+	for _, t := range s.docs[id] {
+		if s.df[t.key]--; s.df[t.key] == 0 {
+			delete(s.df, t.key)
+		}
+		removePosting(s.postings, t.key, id)
+	}
+	delete(s.docs, id)
+	if fp, ok := s.prints[id]; ok {
+		for _, key := range fp.buckets() {
+			removePosting(s.buckets, key, id)
+		}
+		delete(s.prints, id)
+	}
+}
+
+func addPosting(postings map[string]map[model.RecipeID]struct{}, key string, id model.RecipeID) {
+	if postings[key] == nil {
+		postings[key] = map[model.RecipeID]struct{}{}
+	}
+	postings[key][id] = struct{}{}
+}
+
+func removePosting(postings map[string]map[model.RecipeID]struct{}, key string, id model.RecipeID) {
+	delete(postings[key], id)
+	if len(postings[key]) == 0 {
+		delete(postings, key)
+	}
+}
+
+// idf weighs a term by how few recipes have it.
+func (s *state) idf(key string) float64 {
+	return math.Log(1 + float64(len(s.docs))/float64(max(s.df[key], 1)))
+}
+
+// norm is the length of a recipe's TF-IDF vector.
+func (s *state) norm(ts []term) float64 {
+	sum := 0.0
+	for _, t := range ts {
+		w := t.weight * s.idf(t.key)
+		sum += w * w
+	}
+	return math.Sqrt(sum)
+}
+
+// change is a write made while the index was being rebuilt; ts is nil for a removal.
+type change struct {
+	id model.RecipeID
+	ts []term
+	fp fingerprint
+}
+
+// Index ranks recipes by similarity. It is built from a repository in the
+// background and kept current by Put and Remove as recipes change. It is
+// safe for concurrent use.
+type Index struct {
+	// rebuild serializes rebuilds; mu guards the fields below
+	rebuild sync.Mutex
+	mu      sync.RWMutex
+	s       *state
+	ready   bool
+	// pending records the writes made during a rebuild, to be replayed on
+	// the rebuilt state; it is nil when no rebuild is running
+	pending []change
+}
+
+// NewIndex returns an empty index that is not ready until Rebuild succeeds.
+func NewIndex() *Index {
+	return &Index{s: newState()}
+}
+
+// Ready reports whether the index has been built.
+func (ix *Index) Ready() bool {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.ready
+}
+
+// Len returns the number of recipes indexed.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.s.docs)
+}
+
+// Put indexes a created or updated recipe.
+func (ix *Index) Put(recipe model.Recipe) {
+	ix.apply(change{recipe.ID, terms(recipe), fingerprintOf(recipe)})
+}
+
+// Remove drops a deleted recipe from the index.
+func (ix *Index) Remove(id model.RecipeID) {
+	ix.apply(change{id: id})
+}
+
+func (ix *Index) apply(c change) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.s.apply(c)
+	if ix.pending != nil {
+		ix.pending = append(ix.pending, c)
+	}
+}
+
+func (s *state) apply(c change) {
+	if c.ts == nil {
+		s.remove(c.id)
+	} else {
+		s.put(c.id, c.ts, c.fp)
+	}
+}
+
+// Rebuild indexes every recipe in repo afresh and replaces the current
+// index. Recipes put or removed while it runs are applied on top.
+func (ix *Index) Rebuild(ctx context.Context, repo domain.RecipeRepository) error {
+	ix.rebuild.Lock()
+	defer ix.rebuild.Unlock()
+
+	ix.mu.Lock()
+	ix.pending = []change{}
+	ix.mu.Unlock()
+
+	recipes, err := repo.GetAll(ctx)
+	if err != nil {
+		ix.mu.Lock()
+		ix.pending = nil
+		ix.mu.Unlock()
+		return err
+	}
+	s := newState()
+	for _, recipe := range recipes {
+		s.put(recipe.ID, terms(recipe), fingerprintOf(recipe))
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, c := range ix.pending {
+		s.apply(c)
+	}
+	ix.s, ix.ready, ix.pending = s, true, nil
+	return nil
+}
+
+// Run rebuilds the index now and then every interval until ctx is done,
+// picking up changes made elsewhere, such as by another server or
+// recipectl. Failures are logged and retried at the next interval.
+func (ix *Index) Run(ctx context.Context, repo domain.RecipeRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		if err := ix.Rebuild(ctx, repo); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("similarity index rebuild failed", "error", err)
+		} else {
+			slog.Debug("similarity index rebuilt", "recipes", ix.Len(), "duration", time.Since(start))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Similar returns up to n recipes most like recipe, best first, leaving
+// recipe itself out. Recipes sharing no term with it are never returned.
+// Equal scores are ordered by ID.
+func (ix *Index) Similar(recipe model.Recipe, n int) ([]Match, error) {
+	ts := terms(recipe)
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if !ix.ready {
+		return nil, ErrNotReady
+	}
+	s := ix.s
+
+	// Sum the shared terms' weights into each candidate's dot product,
+	// walking the query terms in order so the sums are reproducible.
+	dots := map[model.RecipeID]float64{}
+	for _, t := range ts {
+		idf := s.idf(t.key)
+		for id := range s.postings[t.key] {
+			if id == recipe.ID {
+				continue
+			}
+			other, _ := slices.BinarySearchFunc(s.docs[id], t.key, func(d term, key string) int { return strings.Compare(d.key, key) })
+			dots[id] += t.weight * s.docs[id][other].weight * idf * idf
+		}
+	}
+
+	norm := s.norm(ts)
+	matches := make([]Match, 0, len(dots))
+	for id, dot := range dots {
+		matches = append(matches, Match{ID: id, Score: dot / (norm * s.norm(s.docs[id]))})
+	}
+	slices.SortFunc(matches, func(a, b Match) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.ID, b.ID))
+	})
+	if len(matches) > n {
+		matches = matches[:n]
+	}
+	return matches, nil
+}
@@ -0,0 +1,98 @@
+package similar
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gin-demo/recipes-web/internal/domain"
+	"github.com/gin-demo/recipes-web/model"
+)
+
+type mockRepo struct {
+	domain.RecipeRepository
+	recipes []model.Recipe
+	err     error
+}
+
+func (m *mockRepo) GetAll(ctx context.Context) ([]model.Recipe, error) {
+	return m.recipes, m.err
+}
+
+var recipes = []model.Recipe{
+	{ID: "1", Name: "Green curry", Tags: []string{"thai", "curry"}, Ingredients: []string{"1 can coconut milk", "2 tbsp green curry paste", "1 lb chicken thighs", "salt"}},
+	{ID: "2", Name: "Red curry", Tags: []string{"thai", "curry"}, Ingredients: []string{"1 can coconut milk", "2 tbsp red curry paste", "1 lb chicken thigh"}},
+	{ID: "3", Name: "Chicken soup", Tags: []string{"soup"}, Ingredients: []string{"1 lb chicken thighs", "2 carrots", "1 onion", "salt"}},
+	{ID: "4", Name: "Chocolate cake", Tags: []string{"dessert"}, Ingredients: []string{"2 cups flour", "1 cup sugar", "1/2 cup cocoa"}},
+}
+
+func ids(matches []Match) []model.RecipeID {
+	var out []model.RecipeID
+	for _, m := range matches {
+		out = append(out, m.ID)
+	}
+	return out
+}
+
+func TestIndexSimilar(t *testing.T) {
+	ix := NewIndex()
+	if _, err := ix.Similar(recipes[0], 5); !errors.Is(err, ErrNotReady) {
+		t.Fatalf("Expected ErrNotReady before the first build, got %v", err)
+	}
+	if err := ix.Rebuild(context.Background(), &mockRepo{recipes: recipes}); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	matches, err := ix.Similar(recipes[0], 5)
+	if err != nil {
+		t.Fatalf("Similar failed: %v", err)
+	}
+	// The cake shares nothing with the curry; salt is a staple and does not count.
+	if want := []model.RecipeID{"2", "3"}; !slices.Equal(ids(matches), want) {
+		t.Fatalf("Expected %v, got %+v", want, matches)
+	}
+	if matches[0].Score <= matches[1].Score || matches[0].Score > 1 || matches[1].Score <= 0 {
+		t.Errorf("Unexpected scores %+v", matches)
+	}
+
+	// The same recipes give the same scores however often they are asked for.
+	again, _ := ix.Similar(recipes[0], 5)
+	if !slices.Equal(again, matches) {
+		t.Errorf("Expected %+v again, got %+v", matches, again)
+	}
+
+	if matches, _ := ix.Similar(recipes[0], 1); len(matches) != 1 {
+		t.Errorf("Expected 1 match, got %+v", matches)
+	}
+}
+
+func TestIndexPutRemove(t *testing.T) {
+	ix := NewIndex()
+	ix.Rebuild(context.Background(), &mockRepo{recipes: recipes})
+
+	ix.Put(model.Recipe{ID: "5", Name: "Thai green curry", Tags: []string{"thai", "curry"}, Ingredients: []string{"coconut milk", "green curry paste", "chicken thighs"}})
+	ix.Remove("2")
+	matches, _ := ix.Similar(recipes[0], 5)
+	if want := []model.RecipeID{"5", "3"}; !slices.Equal(ids(matches), want) {
+		t.Errorf("Expected %v, got %+v", want, matches)
+	}
+	if ix.Len() != 4 {
+		t.Errorf("Expected 4 recipes indexed, got %d", ix.Len())
+	}
+
+	// An update replaces the recipe's terms.
+	ix.Put(model.Recipe{ID: "5", Name: "Brownies", Tags: []string{"dessert"}, Ingredients: []string{"cocoa", "sugar"}})
+	matches, _ = ix.Similar(recipes[3], 5)
+	if len(matches) == 0 || matches[0].ID != "5" {
+		t.Errorf("Expected the brownies first, got %+v", matches)
+	}
+
+	// A failed rebuild keeps the index as it was.
+	if err := ix.Rebuild(context.Background(), &mockRepo{err: domain.ErrPersistence}); err == nil {
+		t.Error("Expected the rebuild to fail")
+	}
+	if !ix.Ready() || ix.Len() != 4 {
+		t.Errorf("Expected the index to be kept, got %d recipes", ix.Len())
+	}
+}
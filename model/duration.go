@@ -0,0 +1,107 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidDuration is returned for a string that is not an ISO 8601 duration
+// made of weeks, days, hours, minutes and seconds.
+var ErrInvalidDuration = errors.New("invalid ISO 8601 duration")
+
+// ParseDuration parses an ISO 8601 duration such as "PT1H30M" or "P1DT2H".
+// Years and months are rejected since their length varies.
+func ParseDuration(s string) (time.Duration, error) {
+	rest, ok := strings.CutPrefix(strings.ToUpper(strings.TrimSpace(s)), "P")
+	if !ok || rest == "" || strings.HasSuffix(rest, "T") {
+		return 0, ErrInvalidDuration
+	}
+
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+	var total time.Duration
+	inTime := false
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime {
+				return 0, ErrInvalidDuration
+			}
+			inTime = true
+			units = map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+			rest = rest[1:]
+			continue
+		}
+
+		i := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i <= 0 {
+			return 0, ErrInvalidDuration
+		}
+		unit, ok := units[rest[i]]
+		if !ok {
+			return 0, ErrInvalidDuration
+		}
+		n, err := strconv.ParseFloat(rest[:i], 64)
+		if err != nil {
+			return 0, ErrInvalidDuration
+		}
+		total += time.Duration(n * float64(unit))
+		// Each unit may appear once, in order.
+		for u := range units {
+			if units[u] >= unit {
+				delete(units, u)
+			}
+		}
+		rest = rest[i+1:]
+	}
+	return total, nil
+}
+
+// FormatDuration writes d as an ISO 8601 duration in days, hours, minutes and
+// whole seconds, such as "PT1H30M". Zero is "PT0S".
+func FormatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+	b.WriteString("P")
+	if days := d / (24 * time.Hour); days > 0 {
+		b.WriteString(strconv.FormatInt(int64(days), 10) + "D")
+		d -= days * 24 * time.Hour
+	}
+	if d >= time.Second {
+		b.WriteString("T")
+		for _, u := range []struct {
+			unit   time.Duration
+			suffix string
+		}{{time.Hour, "H"}, {time.Minute, "M"}, {time.Second, "S"}} {
+			if n := d / u.unit; n > 0 {
+				b.WriteString(strconv.FormatInt(int64(n), 10) + u.suffix)
+				d -= n * u.unit
+			}
+		}
+	}
+	return b.String()
+}
+
+// HumanDuration writes an ISO 8601 duration as "1 h 30 min", or returns it
+// as is when it does not parse.
+func HumanDuration(iso string) string {
+	d, err := ParseDuration(iso)
+	if err != nil {
+		return iso
+	}
+	var parts []string
+	if days := int(d.Hours()) / 24; days > 0 {
+		parts = append(parts, fmt.Sprintf("%d d", days))
+	}
+	if hours := int(d.Hours()) % 24; hours > 0 {
+		parts = append(parts, fmt.Sprintf("%d h", hours))
+	}
+	if minutes := int(d.Minutes()) % 60; minutes > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%d min", minutes))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	valid := map[string]time.Duration{
+		"PT30M":     30 * time.Minute,
+		"PT1H30M":   90 * time.Minute,
+		"pt2h":      2 * time.Hour,
+		"P1DT2H":    26 * time.Hour,
+		"P1W":       7 * 24 * time.Hour,
+		"PT0.5H":    30 * time.Minute,
+		"PT45S":     45 * time.Second,
+		" PT10M ":   10 * time.Minute,
+		"P0DT0H20M": 20 * time.Minute,
+	}
+	for in, want := range valid {
+		got, err := ParseDuration(in)
+		if err != nil || got != want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"", "P", "PT", "30M", "P1M", "P1Y", "PT1H2H", "PT1M1H", "PT1HT2M", "P1DT", "PTH", "1 hour"} {
+		if _, err := ParseDuration(in); err == nil {
+			t.Errorf("ParseDuration(%q) should fail", in)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                                 "PT0S",
+		90 * time.Minute:                  "PT1H30M",
+		26 * time.Hour:                    "P1DT2H",
+		24 * time.Hour:                    "P1D",
+		time.Hour + 5*time.Second:         "PT1H5S",
+		45*time.Second + time.Millisecond: "PT45S",
+	}
+	for in, want := range cases {
+		if got := FormatDuration(in); got != want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHumanDuration(t *testing.T) {
+	cases := map[string]string{"PT1H30M": "1 h 30 min", "P1DT2H": "1 d 2 h", "PT0S": "0 min", "soon": "soon"}
+	for in, want := range cases {
+		if got := HumanDuration(in); got != want {
+			t.Errorf("HumanDuration(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package model
 
-import "time"
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
 
 // RecipeID represents a unique identifier for recipes.
 type RecipeID string
@@ -17,6 +22,99 @@ type Recipe struct {
 	Ingredients []string `json:"ingredients" bson:"ingredients"`
 	// Instructions is a list of steps to prepare the recipe
 	Instructions []string `json:"instructions" bson:"instructions"`
+	// Sections groups the instructions under headings such as "To make the sauce";
+	// when set, Instructions holds the same steps in order
+	Sections []InstructionSection `json:"sections,omitempty" bson:"sections,omitempty"`
+	// Yield is what the recipe makes, such as "4 servings"
+	Yield string `json:"yield,omitempty" bson:"yield,omitempty"`
+	// PrepTime is the preparation time as an ISO 8601 duration such as "PT15M"
+	PrepTime string `json:"prepTime,omitempty" bson:"prepTime,omitempty"`
+	// CookTime is the cooking time as an ISO 8601 duration
+	CookTime string `json:"cookTime,omitempty" bson:"cookTime,omitempty"`
+	// TotalTime is the overall time as an ISO 8601 duration
+	TotalTime string `json:"totalTime,omitempty" bson:"totalTime,omitempty"`
+	// Difficulty is one of Difficulties
+	Difficulty string `json:"difficulty,omitempty" bson:"difficulty,omitempty"`
+	// Cuisine is where the recipe comes from, such as "indian"
+	Cuisine string `json:"cuisine,omitempty" bson:"cuisine,omitempty"`
+	// Course is the part of a meal the dish is served as, such as "main" or "dessert"
+	Course string `json:"course,omitempty" bson:"course,omitempty"`
+	// Equipment lists the tools needed beyond a basic kitchen, such as "stand mixer"
+	Equipment []string `json:"equipment,omitempty" bson:"equipment,omitempty"`
+	// Diets are the dietary flags the recipe satisfies, each one of Diets
+	Diets []string `json:"diets,omitempty" bson:"diets,omitempty"`
+	// Images are the recipe's photos in upload order; they are added and
+	// removed through the images endpoints, never by create or update
+	Images []Image `json:"images,omitempty" bson:"images,omitempty"`
 	// PublishedAt is the timestamp when the recipe was published
 	PublishedAt time.Time `json:"publishedAt" bson:"publishedAt"`
 }
+
+// Difficulties are the difficulty levels a recipe may declare, easiest first.
+var Difficulties = []string{"easy", "medium", "hard"}
+
+// Diets are the dietary flags a recipe may carry.
+var Diets = []string{
+	"vegetarian", "vegan", "pescatarian", "gluten-free", "dairy-free",
+	"nut-free", "egg-free", "low-carb", "halal", "kosher",
+}
+
+// TotalDuration returns TotalTime, or PrepTime plus CookTime when TotalTime
+// is not set. ok is false when neither gives a valid duration.
+func (r Recipe) TotalDuration() (d time.Duration, ok bool) {
+	if total, err := ParseDuration(r.TotalTime); err == nil {
+		return total, true
+	}
+	for _, part := range []string{r.PrepTime, r.CookTime} {
+		if part == "" {
+			continue
+		}
+		dd, err := ParseDuration(part)
+		if err != nil {
+			return 0, false
+		}
+		d += dd
+		ok = true
+	}
+	return d, ok
+}
+
+// Servings reads the number of servings from Yield, as in "4 servings",
+// "Serves 4" or "4-6". ok is false when Yield holds no positive number.
+func (r Recipe) Servings() (n int, ok bool) {
+	digits := strings.FieldsFunc(r.Yield, func(c rune) bool { return !unicode.IsDigit(c) })
+	if len(digits) == 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(digits[0])
+	return n, err == nil && n > 0
+}
+
+// InstructionSection is a titled group of consecutive instruction steps.
+type InstructionSection struct {
+	// Title is the heading of the section; the first section may have none
+	Title string `json:"title,omitempty" bson:"title,omitempty"`
+	// Steps are the section's instructions in order
+	Steps []string `json:"steps" bson:"steps"`
+}
+
+// Image is an uploaded photo of a recipe, stored as resized JPEG variants.
+type Image struct {
+	// ID identifies the image within its recipe
+	ID string `json:"id" bson:"id"`
+	// Width and Height are the size of the upload, turned upright
+	Width  int `json:"width" bson:"width"`
+	Height int `json:"height" bson:"height"`
+	// Variants are the resized copies, smallest first
+	Variants []ImageVariant `json:"variants" bson:"variants"`
+}
+
+// ImageVariant is one size of an image.
+type ImageVariant struct {
+	// Name is thumbnail, medium or large
+	Name   string `json:"name" bson:"name"`
+	Width  int    `json:"width" bson:"width"`
+	Height int    `json:"height" bson:"height"`
+	// URL is where the variant is served
+	URL string `json:"url" bson:"url"`
+}
@@ -74,3 +74,45 @@ func TestRecipeIDString(t *testing.T) {
 		t.Errorf("RecipeID string conversion failed")
 	}
 }
+
+func TestRecipeTotalDuration(t *testing.T) {
+	tests := []struct {
+		name   string
+		recipe Recipe
+		want   time.Duration
+		ok     bool
+	}{
+		{"total time", Recipe{PrepTime: "PT10M", TotalTime: "PT1H"}, time.Hour, true},
+		{"prep and cook", Recipe{PrepTime: "PT10M", CookTime: "PT20M"}, 30 * time.Minute, true},
+		{"cook only", Recipe{CookTime: "PT20M"}, 20 * time.Minute, true},
+		{"unknown", Recipe{}, 0, false},
+		{"invalid part", Recipe{PrepTime: "PT10M", CookTime: "soon"}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.recipe.TotalDuration()
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: TotalDuration() = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRecipeServings(t *testing.T) {
+	tests := []struct {
+		yield string
+		want  int
+		ok    bool
+	}{
+		{"4 servings", 4, true},
+		{"Serves 6", 6, true},
+		{"4-6", 4, true},
+		{"one loaf", 0, false},
+		{"0 servings", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Recipe{Yield: tt.yield}.Servings()
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Servings() of %q = %v, %v, want %v, %v", tt.yield, got, ok, tt.want, tt.ok)
+		}
+	}
+}
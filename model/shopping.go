@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// ShoppingListID represents a unique identifier for shopping lists.
+type ShoppingListID string
+
+// ShoppingList is what to buy to cook a set of recipes, with like
+// ingredients merged and grouped by store aisle.
+type ShoppingList struct {
+	ID ShoppingListID `json:"id" bson:"_id"`
+	// Owner is the user name of the user the list belongs to
+	Owner string `json:"owner" bson:"owner"`
+	Name  string `json:"name" bson:"name"`
+	// Recipes are the recipes the list was made for
+	Recipes []ShoppingRecipe `json:"recipes" bson:"recipes"`
+	// Items are ordered by aisle, then by name
+	Items     []ShoppingItem `json:"items" bson:"items"`
+	CreatedAt time.Time      `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt" bson:"updatedAt"`
+}
+
+// ShoppingRecipe is a recipe on a shopping list and the servings shopped for.
+type ShoppingRecipe struct {
+	ID   RecipeID `json:"id" bson:"id"`
+	Name string   `json:"name" bson:"name"`
+	// Servings is zero when the recipe's yield gives no number of servings
+	// and its quantities were taken as written
+	Servings int `json:"servings" bson:"servings"`
+}
+
+// ShoppingItem is one ingredient to buy, merged across the list's recipes.
+type ShoppingItem struct {
+	// ID identifies the item within its list
+	ID string `json:"id" bson:"id"`
+	// Name is the canonical ingredient name, such as "green onion"
+	Name string `json:"name" bson:"name"`
+	// Aisle is the store section the item is found in
+	Aisle string `json:"aisle" bson:"aisle"`
+	// Quantities are the amounts to buy, one per unit that does not convert
+	// into another; they are empty when no recipe gives an amount
+	Quantities []Quantity `json:"quantities" bson:"quantities"`
+	// Lines are the ingredient lines the item was merged from
+	Lines   []string `json:"lines" bson:"lines"`
+	Checked bool     `json:"checked" bson:"checked"`
+}
+
+// Quantity is an amount in a unit; Unit is empty for a count.
+type Quantity struct {
+	Amount float64 `json:"amount" bson:"amount"`
+	Unit   string  `json:"unit,omitempty" bson:"unit,omitempty"`
+}
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"log/slog"
+	"time"
+)
 
 // UserID presents unique ID for a user.
 type UserID string
@@ -10,10 +13,20 @@ type User struct {
 	ID        UserID    `json:"id" bson:"_id"`
 	UserName  string    `json:"userName" bson:"userName"`
 	Password  string    `json:"password" bson:"password"`
+	Role      string    `json:"role,omitempty" bson:"role,omitempty"`
 	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
 }
 
+// LogValue keeps the password out of logs when a User is logged.
+func (u User) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", string(u.ID)),
+		slog.String("userName", u.UserName),
+		slog.String("role", u.Role),
+	)
+}
+
 /*
 TODO:
 		*************************************
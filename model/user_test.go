@@ -0,0 +1,22 @@
+package model
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestUserLogValueOmitsPassword(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	logger.Info("sign-in", "user", User{UserName: "admin", Password: "secret-pass"})
+
+	if strings.Contains(buf.String(), "secret-pass") {
+		t.Errorf("Expected password to be omitted, got %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "user.userName=admin") {
+		t.Errorf("Expected user name to be logged, got %q", buf.String())
+	}
+}